@@ -0,0 +1,519 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseFlags parses the flags of the command, the positional arguments are
+// refused.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	return nil
+}
+
+func requireFlag(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("-%s is required", name)
+	}
+	return nil
+}
+
+func requireID(name string, value int) error {
+	if value <= 0 {
+		return fmt.Errorf("-%s is required", name)
+	}
+	return nil
+}
+
+type accountOutput struct {
+	ID       int32  `json:"id"`
+	Username string `json:"username"`
+	// Balances holds the amounts of the assets the account held.
+	Balances map[string]string `json:"balances"`
+	Frozen   bool              `json:"frozen"`
+	// Token is set only for created accounts.
+	Token string `json:"token,omitempty"`
+}
+
+// newAccountOutput loads the balances of the account.
+func (app *app) newAccountOutput(account *queries.Account) (accountOutput, error) {
+	balances, err := app.store.GetBalances(account.ID)
+	if err != nil {
+		return accountOutput{}, err
+	}
+
+	output := accountOutput{
+		ID:       account.ID,
+		Username: account.Username,
+		Balances: make(map[string]string, len(balances)),
+		Frozen:   account.Frozen,
+	}
+	for _, balance := range balances {
+		asset := currency.Asset{Symbol: balance.Asset, Precision: int(balance.Precision)}
+		output.Balances[asset.Symbol] = asset.Format(balance.Amount)
+	}
+	return output, nil
+}
+
+func (app *app) printAccount(output accountOutput) error {
+	symbols := make([]string, 0, len(output.Balances))
+	for symbol := range output.Balances {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	balances := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		balances[i] = output.Balances[symbol] + " " + symbol
+	}
+
+	header := []string{"ID", "USERNAME", "BALANCES", "FROZEN"}
+	row := []string{
+		strconv.Itoa(int(output.ID)), output.Username, strings.Join(balances, ", "), strconv.FormatBool(output.Frozen),
+	}
+	if output.Token != "" {
+		header = append(header, "TOKEN")
+		row = append(row, output.Token)
+	}
+	return app.printer.print(output, header, [][]string{row})
+}
+
+func runCreateAccount(app *app, args []string) error {
+	flags := flag.NewFlagSet("create-account", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the account")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("username", *username); err != nil {
+		return err
+	}
+
+	account, token, err := app.store.RegisterAccount(*username)
+	if err != nil {
+		return err
+	}
+	output, err := app.newAccountOutput(account)
+	if err != nil {
+		return err
+	}
+	output.Token = token
+	return app.printAccount(output)
+}
+
+func runAdjust(app *app, args []string) error {
+	flags := flag.NewFlagSet("adjust", flag.ContinueOnError)
+	accountId := flags.Int("account", 0, "account id")
+	currencyName := flags.String("currency", "usd", "symbol of a registered asset, like usd or btc")
+	amount := flags.String("amount", "", "amount to add, negative to subtract, like 1,000.50 or 1.5e-3")
+	reason := flags.String("reason", "", "reason recorded in the journal")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireID("account", *accountId); err != nil {
+		return err
+	}
+	for _, required := range []struct{ name, value string }{
+		{"amount", *amount}, {"reason", *reason}, {"operator", app.operator},
+	} {
+		if err := requireFlag(required.name, required.value); err != nil {
+			return err
+		}
+	}
+
+	asset, err := app.findAsset(*currencyName)
+	if err != nil {
+		return err
+	}
+	params := datastore.AdjustBalanceParams{
+		AccountID: int32(*accountId),
+		Asset:     asset.Symbol,
+		Reason:    *reason,
+		Operator:  app.operator,
+	}
+	if params.Amount, err = asset.ParseWith(*amount, currency.ParseLenient); err != nil {
+		return err
+	}
+
+	success, err := app.store.AdjustBalance(params)
+	if err != nil {
+		return err
+	}
+	if !success {
+		return fmt.Errorf("account %d does not exist or its balance would drop below the reserved amount", *accountId)
+	}
+
+	return app.printAccountById(params.AccountID)
+}
+
+// printAccountById prints the account with its balances.
+func (app *app) printAccountById(accountId int32) error {
+	account, err := app.store.GetAccount(accountId)
+	if err != nil {
+		return err
+	}
+	output, err := app.newAccountOutput(account)
+	if err != nil {
+		return err
+	}
+	return app.printAccount(output)
+}
+
+// findAsset returns the registered asset with the symbol in any case.
+func (app *app) findAsset(symbol string) (currency.Asset, error) {
+	asset, err := app.store.GetAsset(strings.ToUpper(symbol))
+	if err != nil {
+		return currency.Asset{}, err
+	}
+	if asset == nil {
+		return currency.Asset{}, fmt.Errorf("unknown asset %q", symbol)
+	}
+	return currency.Asset{Symbol: asset.Symbol, Precision: int(asset.Precision)}, nil
+}
+
+type journalEntryOutput struct {
+	ID        int64  `json:"id"`
+	Asset     string `json:"asset"`
+	Amount    string `json:"amount"`
+	Reason    string `json:"reason"`
+	Operator  string `json:"operator"`
+	CreatedAt string `json:"createdAt"`
+}
+
+func runJournal(app *app, args []string) error {
+	flags := flag.NewFlagSet("journal", flag.ContinueOnError)
+	accountId := flags.Int("account", 0, "account id")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireID("account", *accountId); err != nil {
+		return err
+	}
+
+	entries, err := app.store.GetBalanceJournal(int32(*accountId))
+	if err != nil {
+		return err
+	}
+	assets, err := app.store.GetAssets()
+	if err != nil {
+		return err
+	}
+	precisions := make(map[string]int, len(assets))
+	for _, asset := range assets {
+		precisions[asset.Symbol] = int(asset.Precision)
+	}
+
+	outputs := make([]journalEntryOutput, 0, len(entries))
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		asset := currency.Asset{Symbol: entry.Asset, Precision: precisions[entry.Asset]}
+		output := journalEntryOutput{
+			ID:        entry.ID,
+			Asset:     asset.Symbol,
+			Amount:    asset.Format(entry.Amount),
+			Reason:    entry.Reason,
+			Operator:  entry.Operator,
+			CreatedAt: entry.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		outputs = append(outputs, output)
+		rows = append(rows, []string{
+			strconv.FormatInt(output.ID, 10), output.Asset, output.Amount, output.Reason, output.Operator, output.CreatedAt,
+		})
+	}
+	return app.printer.print(outputs, []string{"ID", "ASSET", "AMOUNT", "REASON", "OPERATOR", "CREATED AT"}, rows)
+}
+
+func runFreeze(app *app, args []string) error {
+	return setFrozen(app, "freeze", args, true)
+}
+
+func runUnfreeze(app *app, args []string) error {
+	return setFrozen(app, "unfreeze", args, false)
+}
+
+func setFrozen(app *app, name string, args []string, frozen bool) error {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	accountId := flags.Int("account", 0, "account id")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireID("account", *accountId); err != nil {
+		return err
+	}
+
+	success, err := app.store.SetAccountFrozen(int32(*accountId), frozen)
+	if err != nil {
+		return err
+	}
+	if !success {
+		return fmt.Errorf("account %d does not exist", *accountId)
+	}
+
+	return app.printAccountById(int32(*accountId))
+}
+
+type cancelledOrderOutput struct {
+	ID        int32  `json:"id"`
+	AccountID int32  `json:"accountId"`
+	Market    string `json:"market"`
+	Type      string `json:"type"`
+	Quantity  string `json:"quantity"`
+}
+
+func runCancelOrder(app *app, args []string) error {
+	flags := flag.NewFlagSet("cancel-order", flag.ContinueOnError)
+	orderId := flags.Int("order", 0, "order id")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireID("order", *orderId); err != nil {
+		return err
+	}
+
+	order, err := app.store.GetStandingOrder(int32(*orderId))
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return fmt.Errorf("order %d does not exist", *orderId)
+	}
+	if order.State != queries.OrderStateLive {
+		return fmt.Errorf("order %d is %s", *orderId, order.State)
+	}
+	market, err := app.store.GetMarket(order.MarketID)
+	if err != nil {
+		return err
+	}
+
+	// cancelling releases the reservations of the order
+	cancelled, err := app.store.CancelStandingOrder(order.ID)
+	if err != nil {
+		return err
+	}
+	if cancelled == nil {
+		return fmt.Errorf("order %d is no longer live", *orderId)
+	}
+	output := cancelledOrderOutput{
+		ID:        order.ID,
+		AccountID: order.AccountID,
+		Market:    market.Symbol,
+		Type:      strings.ToUpper(string(order.Type)),
+		Quantity:  market.Base.Format(int64(order.Quantity)),
+	}
+	return app.printer.print(
+		output,
+		[]string{"ID", "ACCOUNT", "MARKET", "TYPE", "QUANTITY"},
+		[][]string{{
+			strconv.Itoa(int(output.ID)), strconv.Itoa(int(output.AccountID)), output.Market, output.Type,
+			output.Quantity,
+		}},
+	)
+}
+
+type tradingStatusOutput struct {
+	Halted    bool   `json:"halted"`
+	Reason    string `json:"reason"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+func (app *app) printTradingStatus(status *queries.TradingStatus) error {
+	output := tradingStatusOutput{
+		Halted:    status.Halted,
+		Reason:    status.Reason,
+		UpdatedAt: status.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	return app.printer.print(
+		output,
+		[]string{"HALTED", "REASON", "UPDATED AT"},
+		[][]string{{strconv.FormatBool(output.Halted), output.Reason, output.UpdatedAt}},
+	)
+}
+
+func runHalt(app *app, args []string) error {
+	flags := flag.NewFlagSet("halt", flag.ContinueOnError)
+	reason := flags.String("reason", "", "reason of the halt")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("reason", *reason); err != nil {
+		return err
+	}
+
+	status, err := app.store.SetTradingStatus(true, *reason)
+	if err != nil {
+		return err
+	}
+	return app.printTradingStatus(status)
+}
+
+func runResume(app *app, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("resume", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	status, err := app.store.SetTradingStatus(false, "")
+	if err != nil {
+		return err
+	}
+	return app.printTradingStatus(status)
+}
+
+func runStatus(app *app, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("status", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	status, err := app.store.GetTradingStatus()
+	if err != nil {
+		return err
+	}
+	return app.printTradingStatus(status)
+}
+
+var errReconciliationIssues = errors.New("reconciliation found issues")
+
+func runReconcile(app *app, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("reconcile", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	rows, err := app.store.GetReconciliation()
+	if err != nil {
+		return err
+	}
+	report := newReconciliationReport(rows)
+	if err := app.printer.print(report, reconciliationHeader, report.rows()); err != nil {
+		return err
+	}
+	if report.hasIssues() {
+		return errReconciliationIssues
+	}
+	return nil
+}
+
+type assetOutput struct {
+	Symbol    string `json:"symbol"`
+	Precision int32  `json:"precision"`
+}
+
+func runAssets(app *app, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("assets", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	assets, err := app.store.GetAssets()
+	if err != nil {
+		return err
+	}
+	outputs := make([]assetOutput, 0, len(assets))
+	rows := make([][]string, 0, len(assets))
+	for _, asset := range assets {
+		outputs = append(outputs, assetOutput{Symbol: asset.Symbol, Precision: asset.Precision})
+		rows = append(rows, []string{asset.Symbol, strconv.Itoa(int(asset.Precision))})
+	}
+	return app.printer.print(outputs, []string{"SYMBOL", "PRECISION"}, rows)
+}
+
+func runAddAsset(app *app, args []string) error {
+	flags := flag.NewFlagSet("add-asset", flag.ContinueOnError)
+	symbol := flags.String("symbol", "", "symbol of the asset, like ETH")
+	precision := flags.Int("precision", -1, "number of decimals of amounts")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("symbol", *symbol); err != nil {
+		return err
+	}
+	if *precision < 0 || *precision > maxAssetPrecision {
+		return fmt.Errorf("-precision must be between 0 and %d", maxAssetPrecision)
+	}
+
+	asset, err := app.store.CreateAsset(strings.ToUpper(*symbol), int32(*precision))
+	if err != nil {
+		return err
+	}
+	return app.printer.print(
+		assetOutput{Symbol: asset.Symbol, Precision: asset.Precision},
+		[]string{"SYMBOL", "PRECISION"},
+		[][]string{{asset.Symbol, strconv.Itoa(int(asset.Precision))}},
+	)
+}
+
+// maxAssetPrecision keeps amounts of whole units within int64.
+const maxAssetPrecision = 18
+
+type marketOutput struct {
+	ID         int32  `json:"id"`
+	Symbol     string `json:"symbol"`
+	BaseAsset  string `json:"baseAsset"`
+	QuoteAsset string `json:"quoteAsset"`
+}
+
+func (app *app) printMarkets(markets []datastore.Market) error {
+	outputs := make([]marketOutput, 0, len(markets))
+	rows := make([][]string, 0, len(markets))
+	for _, market := range markets {
+		output := marketOutput{
+			ID:         market.ID,
+			Symbol:     market.Symbol,
+			BaseAsset:  market.Base.Symbol,
+			QuoteAsset: market.Quote.Symbol,
+		}
+		outputs = append(outputs, output)
+		rows = append(rows, []string{strconv.Itoa(int(output.ID)), output.Symbol, output.BaseAsset, output.QuoteAsset})
+	}
+	return app.printer.print(outputs, []string{"ID", "SYMBOL", "BASE", "QUOTE"}, rows)
+}
+
+func runMarkets(app *app, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("markets", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	markets, err := app.store.GetMarkets()
+	if err != nil {
+		return err
+	}
+	return app.printMarkets(markets)
+}
+
+func runAddMarket(app *app, args []string) error {
+	flags := flag.NewFlagSet("add-market", flag.ContinueOnError)
+	base := flags.String("base", "", "symbol of the traded asset")
+	quote := flags.String("quote", "", "symbol of the asset prices are quoted in")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	var assets []currency.Asset
+	for _, required := range []struct{ name, value string }{{"base", *base}, {"quote", *quote}} {
+		if err := requireFlag(required.name, required.value); err != nil {
+			return err
+		}
+		asset, err := app.findAsset(required.value)
+		if err != nil {
+			return err
+		}
+		assets = append(assets, asset)
+	}
+	if assets[0] == assets[1] {
+		return fmt.Errorf("the base and quote assets must differ")
+	}
+
+	market, err := app.store.CreateMarket(assets[0].Symbol, assets[1].Symbol)
+	if err != nil {
+		return err
+	}
+	return app.printMarkets([]datastore.Market{*market})
+}
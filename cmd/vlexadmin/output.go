@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer writes the results either as aligned tables or as JSON.
+type printer struct {
+	out    io.Writer
+	format string
+}
+
+// print writes value as JSON, or the rows under the header as a table.
+func (printer *printer) print(value interface{}, header []string, rows [][]string) error {
+	if printer.format == outputJSON {
+		return printer.printJSON(value)
+	}
+
+	writer := tabwriter.NewWriter(printer.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// printJSON writes the value on a single line so that streamed values can be
+// processed line by line.
+func (printer *printer) printJSON(value interface{}) error {
+	return json.NewEncoder(printer.out).Encode(value)
+}
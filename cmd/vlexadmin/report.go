@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"strconv"
+	"strings"
+)
+
+// balanceReconciliation compares the balance of an asset of an account with
+// the amount reserved by its live orders and the amount it has in the journal.
+// Balances of single accounts differ from the journal by the traded amounts.
+type balanceReconciliation struct {
+	ID       int32    `json:"id"`
+	Username string   `json:"username"`
+	Frozen   bool     `json:"frozen"`
+	Asset    string   `json:"asset"`
+	Amount   string   `json:"amount"`
+	Reserved string   `json:"reserved"`
+	Journal  string   `json:"journal"`
+	Issues   []string `json:"issues,omitempty"`
+}
+
+// assetTotal sums the balances and the journal of an asset.
+type assetTotal struct {
+	Asset   string `json:"asset"`
+	Amount  string `json:"amount"`
+	Journal string `json:"journal"`
+}
+
+// reconciliationReport checks the balances and the totals of every asset.
+// Trades only move amounts between accounts, so the total balances equal the
+// total journal.
+type reconciliationReport struct {
+	Balances []balanceReconciliation `json:"balances"`
+	Totals   []assetTotal            `json:"totals"`
+	Issues   []string                `json:"issues,omitempty"`
+}
+
+var reconciliationHeader = []string{
+	"ID", "USERNAME", "FROZEN", "ASSET", "AMOUNT", "RESERVED", "JOURNAL", "ISSUES",
+}
+
+// newReconciliationReport checks the rows, assets an account never touched
+// are left out.
+func newReconciliationReport(rows []queries.GetReconciliationRow) *reconciliationReport {
+	report := &reconciliationReport{Balances: make([]balanceReconciliation, 0, len(rows))}
+	assets := make(map[string]currency.Asset)
+	var symbols []string
+	amounts := make(map[string]int64)
+	journals := make(map[string]int64)
+	for _, row := range rows {
+		if row.Amount == 0 && row.ReservedAmount == 0 && row.JournalAmount == 0 {
+			continue
+		}
+
+		asset := currency.Asset{Symbol: row.Asset, Precision: int(row.Precision)}
+		if _, ok := assets[asset.Symbol]; !ok {
+			assets[asset.Symbol] = asset
+			symbols = append(symbols, asset.Symbol)
+		}
+
+		balance := balanceReconciliation{
+			ID:       row.ID,
+			Username: row.Username,
+			Frozen:   row.Frozen,
+			Asset:    asset.Symbol,
+			Amount:   asset.Format(row.Amount),
+			Reserved: asset.Format(row.ReservedAmount),
+			Journal:  asset.Format(row.JournalAmount),
+		}
+		if row.ReservedAmount > row.Amount {
+			balance.Issues = append(balance.Issues, fmt.Sprintf("reserved %s exceeds the balance", asset))
+		}
+		report.Balances = append(report.Balances, balance)
+
+		amounts[asset.Symbol] += row.Amount
+		journals[asset.Symbol] += row.JournalAmount
+	}
+
+	for _, symbol := range symbols {
+		asset := assets[symbol]
+		report.Totals = append(report.Totals, assetTotal{
+			Asset:   symbol,
+			Amount:  asset.Format(amounts[symbol]),
+			Journal: asset.Format(journals[symbol]),
+		})
+		if amounts[symbol] != journals[symbol] {
+			report.Issues = append(report.Issues, fmt.Sprintf(
+				"%s balances differ from the journal by %s", symbol, asset.Format(amounts[symbol]-journals[symbol]),
+			))
+		}
+	}
+	return report
+}
+
+func (report *reconciliationReport) hasIssues() bool {
+	if len(report.Issues) > 0 {
+		return true
+	}
+	for _, balance := range report.Balances {
+		if len(balance.Issues) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rows lists the balances followed by the totals of the assets. Issues of the
+// totals are on the last row.
+func (report *reconciliationReport) rows() [][]string {
+	rows := make([][]string, 0, len(report.Balances)+len(report.Totals))
+	for _, balance := range report.Balances {
+		rows = append(rows, []string{
+			strconv.Itoa(int(balance.ID)),
+			balance.Username,
+			strconv.FormatBool(balance.Frozen),
+			balance.Asset,
+			balance.Amount,
+			balance.Reserved,
+			balance.Journal,
+			strings.Join(balance.Issues, "; "),
+		})
+	}
+	for i, total := range report.Totals {
+		var issues string
+		if i == len(report.Totals)-1 {
+			issues = strings.Join(report.Issues, "; ")
+		}
+		rows = append(rows, []string{"TOTAL", "", "", total.Asset, total.Amount, "", total.Journal, issues})
+	}
+	return rows
+}
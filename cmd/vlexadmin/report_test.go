@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReconciliationReport(t *testing.T) {
+	// the seller sold 0.5 BTC for 50 USD to the buyer
+	report := newReconciliationReport([]queries.GetReconciliationRow{
+		{ID: 1, Username: "buyer", Asset: "BTC", Precision: 8, Amount: currency.NewBTC(0.5).Internal()},
+		{
+			ID:            1,
+			Username:      "buyer",
+			Asset:         "USD",
+			Precision:     2,
+			Amount:        currency.NewUSD(50).Internal(),
+			JournalAmount: currency.NewUSD(100).Internal(),
+		},
+		{
+			ID:            2,
+			Username:      "seller",
+			Asset:         "BTC",
+			Precision:     8,
+			Amount:        currency.NewBTC(0.5).Internal(),
+			JournalAmount: currency.NewBTC(1).Internal(),
+		},
+		{ID: 2, Username: "seller", Asset: "USD", Precision: 2, Amount: currency.NewUSD(50).Internal()},
+		{ID: 2, Username: "seller", Asset: "ETH", Precision: 18},
+	})
+	assert.False(t, report.hasIssues())
+	assert.Equal(t, []assetTotal{
+		{Asset: "BTC", Amount: currency.NewBTC(1).String(), Journal: currency.NewBTC(1).String()},
+		{Asset: "USD", Amount: currency.NewUSD(100).String(), Journal: currency.NewUSD(100).String()},
+	}, report.Totals)
+	assert.Len(t, report.rows(), 6)
+
+	report = newReconciliationReport([]queries.GetReconciliationRow{
+		{
+			ID:             1,
+			Username:       "unjournaled",
+			Asset:          "USD",
+			Precision:      2,
+			Amount:         currency.NewUSD(10).Internal(),
+			ReservedAmount: currency.NewUSD(20).Internal(),
+		},
+	})
+	assert.True(t, report.hasIssues())
+	assert.Equal(t, []string{"reserved USD exceeds the balance"}, report.Balances[0].Issues)
+	assert.Equal(t, []string{"USD balances differ from the journal by 10.00"}, report.Issues)
+}
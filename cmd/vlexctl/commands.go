@@ -0,0 +1,483 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/galcik/vlexchange/pkg/client"
+	"github.com/galcik/vlexchange/proto/trading"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parseFlags parses the flags of the command, the positional arguments are
+// refused.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	return nil
+}
+
+func requireFlag(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("-%s is required", name)
+	}
+	return nil
+}
+
+func runRegister(app *app, args []string) error {
+	flags := flag.NewFlagSet("register", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the account")
+	url := flags.String("url", "", "URL of the exchange, creates the profile when it does not exist")
+	save := flags.Bool("save", true, "store the token in the profile")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("username", *username); err != nil {
+		return err
+	}
+
+	name := app.config.profileName(app.profileName)
+	profile, ok := app.config.Profiles[name]
+	if !ok {
+		profile = &Profile{}
+		app.config.Profiles[name] = profile
+	}
+	if *url != "" {
+		profile.URL = *url
+	}
+	if profile.URL == "" {
+		return fmt.Errorf("profile %q has no url, pass -url", name)
+	}
+
+	response, err := client.New(profile.URL, "").Register(app.ctx, client.RegisterRequest{Username: *username})
+	if err != nil {
+		return err
+	}
+	if *save {
+		profile.Token = response.Token
+		profile.KeyPrefix, profile.SigningKey = "", ""
+		if err := app.config.save(app.configPath); err != nil {
+			return err
+		}
+	}
+	return app.printer.print(response, []string{"TOKEN"}, [][]string{{response.Token}})
+}
+
+func runBalance(app *app, args []string) error {
+	flags := flag.NewFlagSet("balance", flag.ContinueOnError)
+	at := flags.String("at", "", "values BTC at the recorded price of the past RFC 3339 time")
+	unit := flags.String("unit", "", "shows BTC formatted in BTC, mBTC or sat")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	apiClient, err := app.client()
+	if err != nil {
+		return err
+	}
+	if *unit != "" {
+		apiClient.Units = []string{*unit}
+	}
+
+	var balance *client.GetBalanceResponse
+	if *at != "" {
+		var atTime time.Time
+		if atTime, err = time.Parse(time.RFC3339, *at); err != nil {
+			return fmt.Errorf("malformed -at: %w", err)
+		}
+		balance, err = apiClient.GetBalanceAt(app.ctx, atTime)
+	} else {
+		balance, err = apiClient.GetBalance(app.ctx)
+	}
+	if err != nil {
+		return err
+	}
+	row := []string{balance.BTC, balance.USD, balance.USDEquivalent, balance.PriceTimestamp}
+	if balance.Display != nil {
+		row = []string{balance.Display.BTC, balance.Display.USD, balance.Display.USDEquivalent, balance.PriceTimestamp}
+	}
+	return app.printer.print(balance, []string{"BTC", "USD", "USD EQUIVALENT", "PRICE TIME"}, [][]string{row})
+}
+
+func runDeposit(app *app, args []string) error {
+	flags := flag.NewFlagSet("deposit", flag.ContinueOnError)
+	currency := flags.String("currency", client.CurrencyUSD, "currency, a registered asset such as usd or btc")
+	amount := flags.String("amount", "", "amount to add")
+	idempotencyKey := flags.String("idempotency-key", "", "deduplicates repeated deposits")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("amount", *amount); err != nil {
+		return err
+	}
+	apiClient, err := app.client()
+	if err != nil {
+		return err
+	}
+
+	ctx := app.ctx
+	if *idempotencyKey != "" {
+		ctx = client.WithIdempotencyKey(ctx, *idempotencyKey)
+	}
+	response, err := apiClient.PostBalance(ctx, client.PostBalanceRequest{TopupAmount: *amount, Currency: *currency})
+	if err != nil {
+		return err
+	}
+	return app.printer.print(response, []string{"SUCCESS"}, [][]string{{strconv.FormatBool(response.Success)}})
+}
+
+func runPlace(app *app, args []string) error {
+	flags := flag.NewFlagSet("place", flag.ContinueOnError)
+	market := flags.String("market", "", "market symbol, BTC-USD by default")
+	side := flags.String("side", "", "buy or sell")
+	quantity := flags.String("quantity", "", "quantity of the base asset")
+	price := flags.String("price", "", "limit price in the quote asset")
+	clientOrderId := flags.String("client-id", "", "client order id")
+	webhookUrl := flags.String("webhook", "", "URL called whenever the order changes")
+	idempotencyKey := flags.String("idempotency-key", "", "deduplicates repeated requests")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	for _, required := range []struct{ name, value string }{
+		{"side", *side}, {"quantity", *quantity}, {"price", *price},
+	} {
+		if err := requireFlag(required.name, required.value); err != nil {
+			return err
+		}
+	}
+	apiClient, err := app.client()
+	if err != nil {
+		return err
+	}
+
+	ctx := app.ctx
+	if *idempotencyKey != "" {
+		ctx = client.WithIdempotencyKey(ctx, *idempotencyKey)
+	}
+	placed, err := apiClient.PostStandingOrder(ctx, client.PostStandingOrderRequest{
+		Market:        *market,
+		Type:          strings.ToLower(*side),
+		Quantity:      *quantity,
+		LimitPrice:    *price,
+		ClientOrderId: *clientOrderId,
+		WebhookUrl:    *webhookUrl,
+	})
+	if err != nil {
+		return err
+	}
+
+	// orders exceeding the balance are cancelled right away
+	order, err := apiClient.GetStandingOrder(app.ctx, placed.OrderId)
+	if client.IsErrorCode(err, client.ErrorCodeNotFound) {
+		return errors.New("order was cancelled, the balance is insufficient")
+	}
+	if err != nil {
+		return err
+	}
+	return app.printOrders([]client.GetStandingOrderResponse{*order}, order)
+}
+
+func runCancel(app *app, args []string) error {
+	flags := flag.NewFlagSet("cancel", flag.ContinueOnError)
+	orderId := flags.Int("id", 0, "order id")
+	clientOrderId := flags.String("client-id", "", "client order id")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if (*orderId == 0) == (*clientOrderId == "") {
+		return errors.New("either -id or -client-id is required")
+	}
+	apiClient, err := app.client()
+	if err != nil {
+		return err
+	}
+
+	if *clientOrderId != "" {
+		err = apiClient.DeleteStandingOrderByClientId(app.ctx, *clientOrderId)
+	} else {
+		err = apiClient.DeleteStandingOrder(app.ctx, int32(*orderId))
+	}
+	if err != nil {
+		return err
+	}
+	return app.printer.print(map[string]bool{"success": true}, []string{"SUCCESS"}, [][]string{{"true"}})
+}
+
+func runOrders(app *app, args []string) error {
+	flags := flag.NewFlagSet("orders", flag.ContinueOnError)
+	market := flags.String("market", "", "market symbol, all markets by default")
+	states := flags.String("state", "", "comma separated states, live, fulfilled or cancelled")
+	sides := flags.String("side", "", "comma separated sides, buy or sell")
+	limit := flags.Int("limit", 0, "orders of a page, at most 200")
+	cursor := flags.String("cursor", "", "cursor of the page")
+	all := flags.Bool("all", false, "follow the cursors to list every order")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	apiClient, err := app.client()
+	if err != nil {
+		return err
+	}
+
+	params := client.ListStandingOrdersParams{Market: *market, Limit: *limit, Cursor: *cursor}
+	if *states != "" {
+		params.States = strings.Split(*states, ",")
+	}
+	if *sides != "" {
+		params.Sides = strings.Split(*sides, ",")
+	}
+
+	var orders []client.GetStandingOrderResponse
+	var page *client.GetStandingOrdersResponse
+	for {
+		if page, err = apiClient.GetStandingOrders(app.ctx, params); err != nil {
+			return err
+		}
+		orders = append(orders, page.Orders...)
+		if !*all || page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+
+	if !*all && app.printer.format == outputJSON {
+		return app.printer.printJSON(page)
+	}
+	if err := app.printOrders(orders, orders); err != nil {
+		return err
+	}
+	if !*all && page.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "next page: -cursor %s\n", page.NextCursor)
+	}
+	return nil
+}
+
+func (app *app) printOrders(orders []client.GetStandingOrderResponse, value interface{}) error {
+	rows := make([][]string, len(orders))
+	for i, order := range orders {
+		rows[i] = []string{
+			strconv.Itoa(int(order.ID)), order.ClientOrderId, order.Market, order.Type, order.State,
+			order.Quantity, order.FilledQuantity, order.LimitPrice, order.CreatedAt,
+		}
+	}
+	return app.printer.print(
+		value,
+		[]string{"ID", "CLIENT ID", "MARKET", "SIDE", "STATE", "QUANTITY", "FILLED", "PRICE", "CREATED"},
+		rows,
+	)
+}
+
+func runBook(app *app, args []string) error {
+	flags := flag.NewFlagSet("book", flag.ContinueOnError)
+	market := flags.String("market", "", "market symbol, BTC-USD by default")
+	depth := flags.Int("depth", 0, "price levels of each side, at most 100")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	apiClient, err := app.client()
+	if err != nil {
+		return err
+	}
+
+	book, err := apiClient.GetMarketOrderBook(app.ctx, *market, *depth)
+	if err != nil {
+		return err
+	}
+
+	// the asks are listed above the bids, the best prices meet in the middle
+	var rows [][]string
+	for i := len(book.Asks) - 1; i >= 0; i-- {
+		level := book.Asks[i]
+		rows = append(rows, []string{"ask", level.Price, level.Quantity, strconv.Itoa(int(level.Orders))})
+	}
+	for _, level := range book.Bids {
+		rows = append(rows, []string{"bid", level.Price, level.Quantity, strconv.Itoa(int(level.Orders))})
+	}
+	return app.printer.print(book, []string{"SIDE", "PRICE", "QUANTITY", "ORDERS"}, rows)
+}
+
+func runMarkets(app *app, args []string) error {
+	flags := flag.NewFlagSet("markets", flag.ContinueOnError)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	apiClient, err := app.client()
+	if err != nil {
+		return err
+	}
+
+	markets, err := apiClient.GetMarkets(app.ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(markets.Markets))
+	for i, market := range markets.Markets {
+		rows[i] = []string{
+			market.Symbol, market.BaseAsset, strconv.Itoa(market.BasePrecision), market.QuoteAsset,
+			strconv.Itoa(market.QuotePrecision),
+		}
+	}
+	return app.printer.print(
+		markets, []string{"SYMBOL", "BASE", "BASE PRECISION", "QUOTE", "QUOTE PRECISION"}, rows,
+	)
+}
+
+func runPrices(app *app, args []string) error {
+	flags := flag.NewFlagSet("prices", flag.ContinueOnError)
+	from := flags.String("from", "", "RFC 3339 start, a day before -to by default")
+	to := flags.String("to", "", "RFC 3339 end, now by default")
+	limit := flags.Int("limit", 0, "number of prices, at most 1000")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	params := client.PriceHistoryParams{Limit: *limit}
+	for _, timeFlag := range []struct {
+		name  string
+		value string
+		time  *time.Time
+	}{{"from", *from, &params.From}, {"to", *to, &params.To}} {
+		if timeFlag.value == "" {
+			continue
+		}
+		var err error
+		if *timeFlag.time, err = time.Parse(time.RFC3339, timeFlag.value); err != nil {
+			return fmt.Errorf("malformed -%s: %w", timeFlag.name, err)
+		}
+	}
+	apiClient, err := app.client()
+	if err != nil {
+		return err
+	}
+
+	history, err := apiClient.GetPriceHistory(app.ctx, params)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(history.Prices))
+	for i, price := range history.Prices {
+		rows[i] = []string{price.Timestamp, price.Price}
+	}
+	return app.printer.print(history, []string{"TIME", "PRICE"}, rows)
+}
+
+// runEvents streams the order events and trades over gRPC until interrupted.
+func runEvents(app *app, args []string) error {
+	flags := flag.NewFlagSet("events", flag.ContinueOnError)
+	orders := flags.Bool("orders", true, "tail the order events of the account")
+	trades := flags.Bool("trades", true, "tail the trades of the exchange")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	profile, err := app.config.profile(app.profileName)
+	if err != nil {
+		return err
+	}
+	if profile.GRPCAddr == "" {
+		return errors.New("the profile has no grpcAddr")
+	}
+	if profile.Token == "" {
+		return errors.New("streaming requires a token in the profile")
+	}
+
+	transport := grpc.WithInsecure()
+	if profile.GRPCTLS {
+		transport = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	}
+	conn, err := grpc.DialContext(app.ctx, profile.GRPCAddr, transport)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tradingClient := trading.NewTradingClient(conn)
+	ctx := metadata.AppendToOutgoingContext(app.ctx, "x-token", profile.Token)
+	events := newEventPrinter(app.printer)
+
+	var streams []func() (proto.Message, error)
+	if *orders {
+		stream, err := tradingClient.StreamOrderEvents(ctx, &trading.StreamOrderEventsRequest{})
+		if err != nil {
+			return err
+		}
+		streams = append(streams, func() (proto.Message, error) { return stream.Recv() })
+	}
+	if *trades {
+		stream, err := tradingClient.StreamTrades(ctx, &trading.StreamTradesRequest{})
+		if err != nil {
+			return err
+		}
+		streams = append(streams, func() (proto.Message, error) { return stream.Recv() })
+	}
+
+	errs := make(chan error, len(streams))
+	for _, recv := range streams {
+		go func(recv func() (proto.Message, error)) {
+			for {
+				message, err := recv()
+				if err != nil {
+					errs <- err
+					return
+				}
+				events.print(message)
+			}
+		}(recv)
+	}
+
+	err = <-errs
+	if app.ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// eventPrinter prints the streamed messages one per line.
+type eventPrinter struct {
+	mutex   sync.Mutex
+	printer *printer
+}
+
+func newEventPrinter(printer *printer) *eventPrinter {
+	return &eventPrinter{printer: printer}
+}
+
+func (events *eventPrinter) print(message proto.Message) {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+
+	if events.printer.format == outputJSON {
+		data, err := protojson.Marshal(message)
+		if err == nil {
+			fmt.Fprintln(events.printer.out, string(data))
+		}
+		return
+	}
+
+	switch event := message.(type) {
+	case *trading.Order:
+		fmt.Fprintf(
+			events.printer.out, "%s order %d %s %s %s quantity %s filled %s price %s\n",
+			time.Now().UTC().Format(time.RFC3339), event.Id, event.ClientOrderId, event.Side, event.State,
+			event.Quantity, event.FilledQuantity, event.LimitPrice,
+		)
+	case *trading.Trade:
+		fmt.Fprintf(
+			events.printer.out, "%s trade %d taker %s quantity %s price %s\n",
+			event.ExecutedAt.AsTime().Format(time.RFC3339), event.Id, event.TakerSide, event.Quantity, event.Price,
+		)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Profile holds the endpoints and credentials of one exchange environment.
+// Requests are signed when SigningKey is set, otherwise Token is sent.
+type Profile struct {
+	URL        string `json:"url"`
+	GRPCAddr   string `json:"grpcAddr,omitempty"`
+	GRPCTLS    bool   `json:"grpcTls,omitempty"`
+	Token      string `json:"token,omitempty"`
+	KeyPrefix  string `json:"keyPrefix,omitempty"`
+	SigningKey string `json:"signingKey,omitempty"`
+}
+
+type Config struct {
+	// Default names the profile used without -profile.
+	Default  string              `json:"default,omitempty"`
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+func defaultConfigPath() string {
+	if path := os.Getenv("VLEXCTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "vlexctl.json"
+	}
+	return filepath.Join(dir, "vlexctl", "config.json")
+}
+
+// loadConfig reads the config file, a missing file is an empty config.
+func loadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: map[string]*Profile{}}
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parsing %s failed: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+	return config, nil
+}
+
+// save writes the config readable by the user only, it holds credentials.
+func (config *Config) save(path string) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// profileName resolves an empty name to the default profile.
+func (config *Config) profileName(name string) string {
+	if name == "" {
+		name = config.Default
+	}
+	if name == "" {
+		name = "default"
+	}
+	return name
+}
+
+// profile returns the named profile, the default one when name is empty.
+func (config *Config) profile(name string) (*Profile, error) {
+	name = config.profileName(name)
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not configured", name)
+	}
+	if profile.URL == "" {
+		return nil, fmt.Errorf("profile %q has no url", name)
+	}
+	return profile, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vlexctl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nested", "config.json")
+
+	config, err := loadConfig(path)
+	require.NoError(t, err)
+	_, err = config.profile("")
+	assert.EqualError(t, err, `profile "default" is not configured`)
+
+	config.Default = "staging"
+	config.Profiles["staging"] = &Profile{URL: "https://staging.test", Token: "secret"}
+	config.Profiles["local"] = &Profile{Token: "secret"}
+	require.NoError(t, config.save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := loadConfig(path)
+	require.NoError(t, err)
+	profile, err := loaded.profile("")
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging.test", profile.URL)
+	_, err = loaded.profile("local")
+	assert.EqualError(t, err, `profile "local" has no url`)
+}
@@ -0,0 +1,127 @@
+// Command vlexctl operates the exchange from the command line.
+//
+// Credentials and endpoints come from a profile of the config file, by
+// default $XDG_CONFIG_HOME/vlexctl/config.json:
+//
+//	{
+//	  "default": "staging",
+//	  "profiles": {
+//	    "staging": {"url": "https://staging.example.com", "grpcAddr": "staging.example.com:9090", "token": "..."}
+//	  }
+//	}
+//
+// A profile holds either a token or the keyPrefix and signingKey of an API
+// key, requests are signed with the latter.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/galcik/vlexchange/pkg/client"
+	"github.com/galcik/vlexchange/pkg/signature"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+type command struct {
+	summary string
+	run     func(app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"register": {"create an account and store its token in the profile", runRegister},
+	"balance":  {"show the balance", runBalance},
+	"deposit":  {"top up the balance", runDeposit},
+	"place":    {"place a limit order", runPlace},
+	"cancel":   {"cancel an order", runCancel},
+	"orders":   {"list orders, newest first", runOrders},
+	"book":     {"show the order book", runBook},
+	"markets":  {"list the markets", runMarkets},
+	"prices":   {"show the recorded BTC prices", runPrices},
+	"events":   {"tail order events and trades", runEvents},
+}
+
+// app is the state shared by the commands.
+type app struct {
+	ctx         context.Context
+	configPath  string
+	config      *Config
+	profileName string
+	printer     *printer
+}
+
+func (app *app) client() (*client.Client, error) {
+	profile, err := app.config.profile(app.profileName)
+	if err != nil {
+		return nil, err
+	}
+	apiClient := client.New(profile.URL, profile.Token)
+	if profile.SigningKey != "" {
+		apiClient.Signer = &signature.Signer{KeyPrefix: profile.KeyPrefix, SigningKey: profile.SigningKey}
+	}
+	return apiClient, nil
+}
+
+func main() {
+	flags := flag.NewFlagSet("vlexctl", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "config file")
+	profileName := flags.String("profile", os.Getenv("VLEXCTL_PROFILE"), "profile of the config file")
+	output := flags.String("o", outputTable, "output format, table or json")
+	flags.Usage = func() { usage(flags) }
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		usage(flags)
+		os.Exit(2)
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "vlexctl: unknown command %q\n", flags.Arg(0))
+		usage(flags)
+		os.Exit(2)
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(os.Stderr, "vlexctl: unknown output format %q\n", *output)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	config, err := loadConfig(*configPath)
+	if err == nil {
+		app := &app{
+			ctx:         ctx,
+			configPath:  *configPath,
+			config:      config,
+			profileName: *profileName,
+			printer:     &printer{out: os.Stdout, format: *output},
+		}
+		err = cmd.run(app, flags.Args()[1:])
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "vlexctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage(flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "usage: vlexctl [flags] <command> [command flags]\n\nflags:\n")
+	flags.PrintDefaults()
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "\ncommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
@@ -1,10 +1,10 @@
 module github.com/galcik/vlexchange
 
-go 1.15
+go 1.16
 
 require (
 	github.com/DATA-DOG/go-txdb v0.1.3
-	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/getkin/kin-openapi v0.53.0
 	github.com/go-sql-driver/mysql v1.5.0 // indirect
 	github.com/google/uuid v1.2.0
 	github.com/gorilla/mux v1.8.0
@@ -12,4 +12,6 @@ require (
 	github.com/koron-go/pgctl v1.1.0
 	github.com/lib/pq v1.9.0
 	github.com/stretchr/testify v1.7.0
+	google.golang.org/grpc v1.36.1
+	google.golang.org/protobuf v1.26.0
 )
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/galcik/vlexchange/internal/apikey"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type postApiKeyRequest struct {
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	AllowedIPs []string `json:"allowedIps"`
+	ExpiresAt  string   `json:"expiresAt"`
+}
+
+type postApiKeyResponse struct {
+	apiKeyResponse
+	Token      string `json:"token"`
+	SigningKey string `json:"signingKey"`
+}
+
+type apiKeyResponse struct {
+	ID         int32    `json:"id"`
+	Prefix     string   `json:"prefix"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	AllowedIPs []string `json:"allowedIps"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+}
+
+func newApiKeyResponse(key *queries.ApiKey) apiKeyResponse {
+	response := apiKeyResponse{
+		ID:         key.ID,
+		Prefix:     key.Prefix,
+		Name:       key.Name,
+		Scopes:     key.Scopes,
+		AllowedIPs: key.AllowedIps,
+		CreatedAt:  key.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if key.ExpiresAt.Valid {
+		response.ExpiresAt = key.ExpiresAt.Time.UTC().Format(time.RFC3339)
+	}
+	if key.LastUsedAt.Valid {
+		response.LastUsedAt = key.LastUsedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return response
+}
+
+func (server *Server) handlePostApiKey(w http.ResponseWriter, req *http.Request) {
+	auth := authFromContext(req.Context())
+	account := accountFromContext(req.Context())
+	store := server.store.WithContext(req.Context())
+
+	var payload postApiKeyRequest
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		writeMalformedRequest(w, req)
+		return
+	}
+
+	if payload.Name == "" {
+		writeValidationError(w, req, "name", "missing name")
+		return
+	}
+
+	scopes, err := apikey.ParseScopes(payload.Scopes)
+	if err != nil {
+		writeValidationError(w, req, "scopes", err.Error())
+		return
+	}
+	if len(scopes) == 0 {
+		writeValidationError(w, req, "scopes", "missing scopes")
+		return
+	}
+	if !apikey.HasScopes(auth.Scopes, scopes...) {
+		writeError(
+			w, req, http.StatusForbidden, ErrorCodeInsufficientScope,
+			"scopes exceed the scopes of the current credential",
+		)
+		return
+	}
+
+	if err := apikey.ValidateAllowedIPs(payload.AllowedIPs); err != nil {
+		writeValidationError(w, req, "allowedIps", err.Error())
+		return
+	}
+
+	var expiresAt time.Time
+	if payload.ExpiresAt != "" {
+		if expiresAt, err = time.Parse(time.RFC3339, payload.ExpiresAt); err != nil {
+			writeValidationError(w, req, "expiresAt", "malformed expiresAt")
+			return
+		}
+		if !expiresAt.After(time.Now()) {
+			writeValidationError(w, req, "expiresAt", "expiresAt is in the past")
+			return
+		}
+	}
+
+	key, token, err := store.CreateApiKey(
+		datastore.CreateApiKeyParams{
+			AccountID:  account.ID,
+			Name:       payload.Name,
+			Scopes:     scopes,
+			AllowedIPs: payload.AllowedIPs,
+			ExpiresAt:  expiresAt,
+		},
+	)
+	if err != nil {
+		writeInternalError(w, req, err)
+		return
+	}
+
+	writeJSONResponse(
+		w,
+		postApiKeyResponse{apiKeyResponse: newApiKeyResponse(key), Token: token, SigningKey: server.signingKey(key)},
+	)
+}
+
+func (server *Server) handleGetApiKeys(w http.ResponseWriter, req *http.Request) {
+	account := accountFromContext(req.Context())
+	store := server.store.WithContext(req.Context())
+
+	keys, err := store.GetApiKeys(account.ID)
+	if err != nil {
+		writeInternalError(w, req, err)
+		return
+	}
+
+	response := make([]apiKeyResponse, len(keys))
+	for i := range keys {
+		response[i] = newApiKeyResponse(&keys[i])
+	}
+	writeJSONResponse(w, response)
+}
+
+func (server *Server) handleDeleteApiKey(w http.ResponseWriter, req *http.Request) {
+	keyId, _ := strconv.Atoi(mux.Vars(req)["id"])
+	account := accountFromContext(req.Context())
+	store := server.store.WithContext(req.Context())
+
+	deleted, err := store.DeleteApiKey(account.ID, int32(keyId))
+	if err != nil {
+		writeInternalError(w, req, err)
+		return
+	}
+
+	if !deleted {
+		writeError(w, req, http.StatusNotFound, ErrorCodeNotFound, "api key not found")
+		return
+	}
+
+	writeJSONResponse(w, map[string]bool{"success": true})
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	cmMocks "github.com/galcik/vlexchange/internal/coinmarket/mocks"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore/testqueries"
+	"github.com/galcik/vlexchange/internal/ratelimit"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// authTestSuite provides an account with the legacy token "111222" and helpers
+// for creating API keys.
+type authTestSuite struct {
+	TestServerSuite
+}
+
+func (suite *authTestSuite) BeforeTest(suiteName, testName string) {
+	suite.TestServerSuite.BeforeTest(suiteName, testName)
+	_, err := suite.queries.CreateAccount(context.Background(), testqueries.CreateAccountParams{
+		Username: "TestUser",
+		Token:    "111222",
+	})
+	suite.Require().NoError(err)
+
+	cmServiceMock := &cmMocks.CoinmarketService{}
+	cmServiceMock.On("GetBTCPriceInUSD", mock.Anything).Return(currency.NewUSD(10000), nil)
+	suite.server.coinmarketService = cmServiceMock
+
+	// tests of rate limiting set their own limits
+	generous := ratelimit.Limit{Rate: 1000, Burst: 1000}
+	suite.server.rateLimits = RateLimitPolicy{IP: generous, Tiers: map[string]ratelimit.Limit{defaultTier: generous}}
+}
+
+func (suite *authTestSuite) doRequest(method, url, token string, body interface{}) *httptest.ResponseRecorder {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		suite.Require().NoError(err)
+	}
+
+	request, err := http.NewRequest(method, url, bytes.NewReader(data))
+	suite.Require().NoError(err)
+	request.RemoteAddr = "203.0.113.7:4321"
+	request.Header.Set("X-Token", token)
+
+	recorder := httptest.NewRecorder()
+	suite.server.router.ServeHTTP(recorder, request)
+	suite.requireConformingResponse(request, recorder)
+	return recorder
+}
+
+func (suite *authTestSuite) createKey(payload map[string]interface{}) postApiKeyResponse {
+	recorder := suite.doRequest(http.MethodPost, "/api_keys", "111222", payload)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+
+	response, err := ioutil.ReadAll(recorder.Body)
+	suite.Require().NoError(err)
+	var keyResponse postApiKeyResponse
+	suite.Require().NoError(json.Unmarshal(response, &keyResponse))
+	return keyResponse
+}
+
+type apiKeyTestSuite struct {
+	authTestSuite
+}
+
+func (suite *apiKeyTestSuite) TestScopes() {
+	key := suite.createKey(map[string]interface{}{"name": "reader", "scopes": []string{"read"}})
+	suite.Equal([]string{"read"}, key.Scopes)
+
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodGet, "/balance", key.Token, nil).Code)
+	suite.Equal(
+		http.StatusForbidden,
+		suite.doRequest(http.MethodPost, "/balance", key.Token, map[string]string{
+			"currency": "usd", "topupAmount": "10",
+		}).Code,
+	)
+	suite.Equal(
+		http.StatusForbidden,
+		suite.doRequest(http.MethodPost, "/api_keys", key.Token, map[string]interface{}{
+			"name": "escalated", "scopes": []string{"read", "trade"},
+		}).Code,
+	)
+	suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/balance", key.Token+"x", nil).Code)
+}
+
+func (suite *apiKeyTestSuite) TestScopeMatrix() {
+	deposit := map[string]string{"currency": "usd", "topupAmount": "10"}
+	order := map[string]string{"type": "sell", "quantity": "0.1", "limitPrice": "10000"}
+	newKey := map[string]interface{}{"name": "nested", "scopes": []string{"read"}}
+
+	tests := []struct {
+		scope  string
+		method string
+		url    string
+		body   interface{}
+		denied bool
+	}{
+		{"read", http.MethodGet, "/balance", nil, false},
+		{"read", http.MethodPost, "/balance", deposit, true},
+		{"read", http.MethodGet, "/standing_orders", nil, false},
+		{"read", http.MethodPost, "/standing_orders", order, true},
+		{"read", http.MethodPost, "/api_keys", newKey, true},
+		{"trade", http.MethodGet, "/balance", nil, true},
+		{"trade", http.MethodPost, "/balance", deposit, true},
+		{"trade", http.MethodPost, "/standing_orders", order, false},
+		{"deposit", http.MethodGet, "/balance", nil, true},
+		{"deposit", http.MethodPost, "/balance", deposit, false},
+		{"deposit", http.MethodPost, "/standing_orders", order, true},
+		{"withdraw", http.MethodPost, "/balance", deposit, true},
+		{"withdraw", http.MethodPost, "/standing_orders", order, true},
+		{"withdraw", http.MethodPost, "/api_keys", newKey, true},
+	}
+	keys := make(map[string]string)
+	for _, test := range tests {
+		token, ok := keys[test.scope]
+		if !ok {
+			token = suite.createKey(map[string]interface{}{"name": test.scope, "scopes": []string{test.scope}}).Token
+			keys[test.scope] = token
+		}
+		code := suite.doRequest(test.method, test.url, token, test.body).Code
+		if test.denied {
+			suite.Equal(http.StatusForbidden, code, "%s %s with %s", test.method, test.url, test.scope)
+		} else {
+			suite.NotEqual(http.StatusForbidden, code, "%s %s with %s", test.method, test.url, test.scope)
+			suite.NotEqual(http.StatusUnauthorized, code, "%s %s with %s", test.method, test.url, test.scope)
+		}
+	}
+}
+
+func (suite *apiKeyTestSuite) TestLegacyTokens() {
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodGet, "/balance", "111222", nil).Code)
+
+	suite.server.legacyTokens = false
+	suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/balance", "111222", nil).Code)
+}
+
+func (suite *apiKeyTestSuite) TestAllowedIPs() {
+	allowed := suite.createKey(map[string]interface{}{
+		"name": "allowed", "scopes": []string{"read"}, "allowedIps": []string{"203.0.113.0/24"},
+	})
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodGet, "/balance", allowed.Token, nil).Code)
+
+	denied := suite.createKey(map[string]interface{}{
+		"name": "denied", "scopes": []string{"read"}, "allowedIps": []string{"198.51.100.1"},
+	})
+	suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/balance", denied.Token, nil).Code)
+}
+
+func (suite *apiKeyTestSuite) TestExpiry() {
+	key := suite.createKey(map[string]interface{}{
+		"name":      "short-lived",
+		"scopes":    []string{"read"},
+		"expiresAt": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	})
+	suite.NotEmpty(key.ExpiresAt)
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodGet, "/balance", key.Token, nil).Code)
+
+	_, err := suite.db.Exec("UPDATE api_key SET expires_at = now() - interval '1 second' WHERE id = $1", key.ID)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/balance", key.Token, nil).Code)
+}
+
+func (suite *apiKeyTestSuite) TestListAndDelete() {
+	key := suite.createKey(map[string]interface{}{"name": "bot", "scopes": []string{"read", "trade"}})
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodGet, "/balance", key.Token, nil).Code)
+
+	recorder := suite.doRequest(http.MethodGet, "/api_keys", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var keys []apiKeyResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &keys))
+	suite.Require().Equal(1, len(keys))
+	suite.Equal("bot", keys[0].Name)
+	suite.NotEmpty(keys[0].LastUsedAt)
+	suite.NotContains(recorder.Body.String(), key.Token)
+
+	url := fmt.Sprintf("/api_keys/%d", key.ID)
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodDelete, url, "111222", nil).Code)
+	suite.Equal(http.StatusNotFound, suite.doRequest(http.MethodDelete, url, "111222", nil).Code)
+	suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/balance", key.Token, nil).Code)
+}
+
+func TestApiKeys(t *testing.T) {
+	suite.Run(t, new(apiKeyTestSuite))
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/apikey"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+type authContextKey struct{}
+
+type authInfo struct {
+	AccountID int32
+	// ApiKeyID is zero for requests authenticated by a legacy account token.
+	ApiKeyID int32
+	Scopes   []apikey.Scope
+}
+
+func authFromContext(ctx context.Context) *authInfo {
+	auth, _ := ctx.Value(authContextKey{}).(*authInfo)
+	return auth
+}
+
+type accountContextKey struct{}
+
+// accountFromContext returns the account loaded by authenticateAccount.
+func accountFromContext(ctx context.Context) *queries.Account {
+	account, _ := ctx.Value(accountContextKey{}).(*queries.Account)
+	return account
+}
+
+// authenticateAccount authenticates the request, loads the account once and
+// stores both in the request context. Unauthenticated requests are rejected.
+func (server *Server) authenticateAccount(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			// signed requests are authenticated by verifySignature already
+			auth := authFromContext(req.Context())
+			if auth == nil {
+				var err error
+				if auth, err = server.authenticate(req); err != nil {
+					writeInternalError(w, req, err)
+					return
+				}
+			}
+
+			if auth == nil {
+				writeError(w, req, http.StatusUnauthorized, ErrorCodeUnauthorized, "unauthorized")
+				return
+			}
+
+			account, err := server.store.WithContext(req.Context()).GetAccount(auth.AccountID)
+			if err != nil {
+				writeInternalError(w, req, err)
+				return
+			}
+
+			if account == nil {
+				writeError(w, req, http.StatusUnauthorized, ErrorCodeUnauthorized, "unauthorized")
+				return
+			}
+
+			ctx := context.WithValue(req.Context(), authContextKey{}, auth)
+			ctx = context.WithValue(ctx, accountContextKey{}, account)
+			next.ServeHTTP(w, req.WithContext(ctx))
+		},
+	)
+}
+
+// requireScopes rejects the request unless the credential holds all the given
+// scopes. It must be used on routes behind authenticateAccount.
+func requireScopes(handler http.HandlerFunc, scopes ...apikey.Scope) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		auth := authFromContext(req.Context())
+		if auth == nil {
+			writeError(w, req, http.StatusUnauthorized, ErrorCodeUnauthorized, "unauthorized")
+			return
+		}
+
+		if !apikey.HasScopes(auth.Scopes, scopes...) {
+			writeError(w, req, http.StatusForbidden, ErrorCodeInsufficientScope, "insufficient scope")
+			return
+		}
+
+		handler(w, req)
+	}
+}
+
+func (server *Server) authenticate(req *http.Request) (*authInfo, error) {
+	return server.authenticateToken(req.Context(), req.Header.Get("X-Token"), remoteIP(req))
+}
+
+// authenticateToken checks an API key or a legacy account token used from the
+// IP address. It returns nil for unknown tokens and for legacy tokens when
+// they are not allowed.
+func (server *Server) authenticateToken(ctx context.Context, token string, ip net.IP) (*authInfo, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	store := server.store.WithContext(ctx)
+	if _, _, ok := apikey.Parse(token); !ok {
+		if !server.legacyTokens {
+			return nil, nil
+		}
+		// legacy account tokens keep full access
+		account, err := store.GetAccountByToken(token)
+		if err != nil || account == nil {
+			return nil, err
+		}
+		if _, logged := server.legacyTokenAccounts.LoadOrStore(account.ID, true); !logged {
+			log.Printf("account %d authenticated with a deprecated legacy token", account.ID)
+		}
+		return &authInfo{AccountID: account.ID, Scopes: apikey.AllScopes}, nil
+	}
+
+	key, err := store.GetApiKeyByToken(token)
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	return authorizeApiKey(store, ip, key)
+}
+
+// authorizeApiKey checks the expiry and IP allowlist of an already verified key.
+func authorizeApiKey(store datastore.Store, ip net.IP, key *queries.ApiKey) (*authInfo, error) {
+	if key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(time.Now()) {
+		return nil, nil
+	}
+
+	if !apikey.IsIPAllowed(key.AllowedIps, ip) {
+		return nil, nil
+	}
+
+	if err := store.TouchApiKey(key.ID); err != nil {
+		return nil, err
+	}
+
+	return &authInfo{AccountID: key.AccountID, ApiKeyID: key.ID, Scopes: apikey.StringsToScopes(key.Scopes)}, nil
+}
+
+func remoteIP(req *http.Request) net.IP {
+	return addrIP(req.RemoteAddr)
+}
+
+// addrIP returns the IP of a host:port address, or nil for other addresses.
+func addrIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.ParseIP(host)
+}
@@ -1,47 +1,170 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/galcik/vlexchange/internal/coinmarket"
 	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type getBalanceResponse struct {
-	BTC           string `json:"BTC"`
-	USD           string `json:"USD"`
-	USDEquivalent string `json:"USDEquivalent"`
+	BTC           currency.BTC `json:"btc"`
+	USD           currency.USD `json:"usd"`
+	USDEquivalent currency.USD `json:"usdEquivalent"`
+	// PriceTimestamp is the time of the BTC price used for USDEquivalent.
+	PriceTimestamp string `json:"priceTimestamp"`
+	// PriceSources are the sources the price was aggregated from.
+	PriceSources []string `json:"priceSources,omitempty"`
+	// Balances holds the amounts of all assets the account held by their
+	// symbols.
+	Balances map[string]string `json:"balances,omitempty"`
+	// Display holds the amounts formatted for people, it is present when the
+	// request asks for units or a language.
+	Display *balanceDisplay `json:"display,omitempty"`
+
+	// amounts are the balances of all assets to be displayed
+	amounts []currency.Money
+}
+
+type balanceDisplay struct {
+	BTC           string            `json:"btc"`
+	USD           string            `json:"usd"`
+	USDEquivalent string            `json:"usdEquivalent"`
+	Balances      map[string]string `json:"balances,omitempty"`
+}
+
+func newBalanceDisplay(formatter *currency.Formatter, balance getBalanceResponse) *balanceDisplay {
+	display := &balanceDisplay{
+		BTC:           formatter.Format(currency.AssetBTC.Money(balance.BTC.Internal())),
+		USD:           formatter.Format(currency.AssetUSD.Money(balance.USD.Internal())),
+		USDEquivalent: formatter.Format(currency.AssetUSD.Money(balance.USDEquivalent.Internal())),
+		Balances:      make(map[string]string, len(balance.amounts)),
+	}
+	for _, amount := range balance.amounts {
+		display.Balances[amount.Asset.Symbol] = formatter.Format(amount)
+	}
+	return display
 }
 
+// handleGetBalance values the BTC balance at the current price, or at the
+// recorded price of the time given by the at parameter.
 func (server *Server) handleGetBalance(w http.ResponseWriter, req *http.Request) {
-	store := server.store.WithContext(req.Context())
-	account, err := store.GetAccountByToken(req.Header.Get("X-Token"))
+	var at time.Time
+	if atStr := req.URL.Query().Get("at"); atStr != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, atStr); err != nil {
+			writeValidationError(w, req, "at", "malformed at")
+			return
+		}
+		if at.After(time.Now()) {
+			writeValidationError(w, req, "at", "at must not be in the future")
+			return
+		}
+	}
+
+	formatter, fieldErr := displayFormatter(w, req)
+	if fieldErr != nil {
+		writeValidationError(w, req, fieldErr.Field, fieldErr.Message)
+		return
+	}
+
+	account := accountFromContext(req.Context())
+	var balance getBalanceResponse
+	var err error
+	if at.IsZero() {
+		balance, err = server.getBalance(req.Context(), account)
+	} else {
+		balance, err = server.getBalanceAt(req.Context(), account, at)
+	}
+	if errors.Is(err, coinmarket.ErrNoPriceHistory) {
+		writeError(w, req, http.StatusNotFound, ErrorCodeNotFound, "no price recorded around the time")
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, req, err)
 		return
 	}
+	if formatter != nil {
+		balance.Display = newBalanceDisplay(formatter, balance)
+	}
 
-	if account == nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	writeJSONResponse(w, balance)
+}
+
+// newBalanceResponse fills the balances of the account without valuing them.
+// It returns the BTC balance to be valued.
+func (server *Server) newBalanceResponse(ctx context.Context, account *queries.Account) (
+	getBalanceResponse,
+	currency.BTC,
+	error,
+) {
+	balances, err := server.store.WithContext(ctx).GetBalances(account.ID)
+	if err != nil {
+		return getBalanceResponse{}, 0, err
 	}
 
-	btcAmount := currency.BTC(account.BtcAmount)
-	usdAmount := currency.USD(account.UsdAmount)
+	response := getBalanceResponse{Balances: make(map[string]string, len(balances))}
+	for _, balance := range balances {
+		asset := currency.Asset{Symbol: balance.Asset, Precision: int(balance.Precision)}
+		response.Balances[asset.Symbol] = asset.Format(balance.Amount)
+		response.amounts = append(response.amounts, asset.Money(balance.Amount))
+		switch asset.Symbol {
+		case currency.AssetBTC.Symbol:
+			response.BTC = currency.BTC(balance.Amount)
+		case currency.AssetUSD.Symbol:
+			response.USD = currency.USD(balance.Amount)
+		}
+	}
+	return response, response.BTC, nil
+}
 
-	btcPrice, err := server.coinmarketService.GetBTCPriceInUSD(req.Context())
+func (server *Server) getBalance(ctx context.Context, account *queries.Account) (getBalanceResponse, error) {
+	response, btcAmount, err := server.newBalanceResponse(ctx, account)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return getBalanceResponse{}, err
 	}
 
-	writeJSONResponse(
-		w, getBalanceResponse{
-			BTC:           btcAmount.String(),
-			USD:           usdAmount.String(),
-			USDEquivalent: btcAmount.USD(btcPrice).String(),
-		},
-	)
+	quote, err := coinmarket.GetBTCQuoteInUSD(ctx, server.coinmarketService)
+	if err != nil {
+		return getBalanceResponse{}, err
+	}
+
+	response.USDEquivalent, err = btcAmount.USD(quote.Price)
+	if err != nil {
+		return getBalanceResponse{}, err
+	}
+	response.PriceTimestamp = quote.Timestamp.UTC().Format(time.RFC3339Nano)
+	response.PriceSources = quote.Sources
+	return response, nil
+}
+
+// getBalanceAt values the current BTC balance at the price of the time.
+func (server *Server) getBalanceAt(ctx context.Context, account *queries.Account, at time.Time) (
+	getBalanceResponse,
+	error,
+) {
+	response, btcAmount, err := server.newBalanceResponse(ctx, account)
+	if err != nil {
+		return getBalanceResponse{}, err
+	}
+
+	price, err := coinmarket.GetBTCPriceAt(ctx, server.coinmarketService, at)
+	if err != nil {
+		return getBalanceResponse{}, err
+	}
+
+	response.USDEquivalent, err = btcAmount.USD(price)
+	if err != nil {
+		return getBalanceResponse{}, err
+	}
+	response.PriceTimestamp = at.UTC().Format(time.RFC3339Nano)
+	return response, nil
 }
 
 type postBalanceRequest struct {
@@ -54,48 +177,50 @@ type postBalanceResponse struct {
 }
 
 func (server *Server) handlePostBalance(w http.ResponseWriter, req *http.Request) {
-	store := server.store.WithContext(req.Context())
-	account, err := store.GetAccountByToken(req.Header.Get("X-Token"))
+	account := accountFromContext(req.Context())
+
+	var payload postBalanceRequest
+	err := json.NewDecoder(req.Body).Decode(&payload)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeMalformedRequest(w, req)
 		return
 	}
 
-	if account == nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	success, err := server.deposit(req.Context(), account, payload)
+	var fieldError *FieldError
+	if errors.As(err, &fieldError) {
+		writeValidationError(w, req, fieldError.Field, fieldError.Message)
+		return
+	}
+	if writeRefusalError(w, req, err) {
+		return
 	}
-
-	var payload postBalanceRequest
-	err = json.NewDecoder(req.Body).Decode(&payload)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeInternalError(w, req, err)
 		return
 	}
 
-	payload.Currency = strings.ToUpper(payload.Currency)
-	usdAmount := currency.USD(0)
-	btcAmount := currency.BTC(0)
-	switch payload.Currency {
-	case "USD":
-		if usdAmount, err = currency.ParseUSD(payload.TopupAmount); err != nil {
-			http.Error(w, "invalid amount", http.StatusBadRequest)
-			return
-		}
-	case "BTC":
-		if btcAmount, err = currency.ParseBTC(payload.TopupAmount); err != nil {
-			http.Error(w, "invalid amount", http.StatusBadRequest)
-			return
-		}
-	default:
-		http.Error(w, fmt.Sprintf("unsupported currency %q", payload.Currency), http.StatusBadRequest)
-		return
+	writeJSONResponse(w, postBalanceResponse{Success: success})
+}
+
+// deposit validates and adds the amount of a registered asset to the account
+// balance. Invalid requests are reported by *FieldError.
+func (server *Server) deposit(ctx context.Context, account *queries.Account, request postBalanceRequest) (bool, error) {
+	store := server.store.WithContext(ctx)
+	symbol := strings.ToUpper(request.Currency)
+	registeredAsset, err := store.GetAsset(symbol)
+	if err != nil {
+		return false, err
+	}
+	if registeredAsset == nil {
+		return false, &FieldError{Field: "currency", Message: fmt.Sprintf("unsupported currency %q", symbol)}
 	}
 
-	success, err := store.DepositAccount(account.ID, btcAmount, usdAmount)
+	asset := currency.Asset{Symbol: registeredAsset.Symbol, Precision: int(registeredAsset.Precision)}
+	amount, err := asset.Parse(request.TopupAmount)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return false, amountFieldError("topupAmount", asset, err, "invalid amount")
 	}
 
-	writeJSONResponse(w, postBalanceResponse{Success: success})
+	return store.DepositAccount(account.ID, asset.Symbol, amount)
 }
@@ -28,13 +28,13 @@ type PostBalanceTestSuite struct {
 }
 
 func (suite *PostBalanceTestSuite) TestPostBalance() {
-	_, err := suite.queries.CreateAccount(context.Background(), testqueries.CreateAccountParams{
-		Username:  "TestUser",
-		Token:     "111222",
-		UsdAmount: currency.NewUSD(40_000).Internal(),
-		BtcAmount: currency.NewBTC(1.5).Internal(),
+	account, err := suite.queries.CreateAccount(context.Background(), testqueries.CreateAccountParams{
+		Username: "TestUser",
+		Token:    "111222",
 	})
 	suite.Require().NoError(err)
+	suite.setBalance(account.ID, "USD", currency.NewUSD(40_000).Internal())
+	suite.setBalance(account.ID, "BTC", currency.NewBTC(1.5).Internal())
 
 	data, err := json.Marshal(suite.testCase.request)
 	suite.Require().NoError(err)
@@ -57,11 +57,8 @@ func (suite *PostBalanceTestSuite) TestPostBalance() {
 	suite.True(ok)
 	suite.Equal(suite.testCase.expectedSuccess, success)
 
-	accounts, err := suite.queries.GetAccounts(context.Background())
-	suite.Require().NoError(err)
-	suite.Equal(1, len(accounts))
-	suite.Equal(suite.testCase.expectedUsd.Internal(), accounts[0].UsdAmount)
-	suite.Equal(suite.testCase.expectedBtc.Internal(), accounts[0].BtcAmount)
+	suite.Equal(suite.testCase.expectedUsd.Internal(), suite.getBalance(account.ID, "USD"))
+	suite.Equal(suite.testCase.expectedBtc.Internal(), suite.getBalance(account.ID, "BTC"))
 }
 
 func TestPostBalance(t *testing.T) {
@@ -97,13 +94,13 @@ type GetBalanceTestSuite struct {
 }
 
 func (suite *GetBalanceTestSuite) TestGetBalance() {
-	_, err := suite.queries.CreateAccount(context.Background(), testqueries.CreateAccountParams{
-		Username:  "TestUser",
-		Token:     "111222",
-		UsdAmount: currency.NewUSD(40_000).Internal(),
-		BtcAmount: currency.NewBTC(1.5).Internal(),
+	account, err := suite.queries.CreateAccount(context.Background(), testqueries.CreateAccountParams{
+		Username: "TestUser",
+		Token:    "111222",
 	})
 	suite.Require().NoError(err)
+	suite.setBalance(account.ID, "USD", currency.NewUSD(40_000).Internal())
+	suite.setBalance(account.ID, "BTC", currency.NewBTC(1.5).Internal())
 
 	request, err := http.NewRequest(http.MethodGet, "/balance", http.NoBody)
 	suite.Require().NotNil(request)
@@ -112,7 +109,7 @@ func (suite *GetBalanceTestSuite) TestGetBalance() {
 
 	recorder := httptest.NewRecorder()
 	cmServiceMock := &cmMocks.CoinmarketService{}
-	cmServiceMock.On("GetBTCPriceInUSD", mock.Anything).Return(float64(10000), nil)
+	cmServiceMock.On("GetBTCPriceInUSD", mock.Anything).Return(currency.NewUSD(10000), nil)
 	suite.server.coinmarketService = cmServiceMock
 
 	suite.server.router.ServeHTTP(recorder, request)
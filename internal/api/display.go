@@ -0,0 +1,42 @@
+package api
+
+import (
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"net/http"
+	"strings"
+)
+
+// displayFormatter returns the formatter of amounts displayed to people, nil
+// when the request asks for neither units nor a language. The unit parameter
+// holds comma separated units, at most one per asset. Unknown languages fall
+// back to English.
+func displayFormatter(w http.ResponseWriter, req *http.Request) (*currency.Formatter, *FieldError) {
+	// the language changes the response, caches have to keep them apart
+	w.Header().Add("Vary", "Accept-Language")
+
+	unitParam := req.URL.Query().Get("unit")
+	acceptLanguage := req.Header.Get("Accept-Language")
+	if unitParam == "" && acceptLanguage == "" {
+		return nil, nil
+	}
+
+	formatter := &currency.Formatter{Locale: currency.LocaleEnglish, TrimZeros: true}
+	if locale, ok := currency.MatchLocale(acceptLanguage); ok {
+		formatter.Locale = locale
+	}
+	if unitParam != "" {
+		formatter.Units = make(map[string]currency.Unit)
+		for _, name := range strings.Split(unitParam, ",") {
+			unit, ok := currency.LookupUnit(strings.TrimSpace(name))
+			if !ok {
+				return nil, &FieldError{Field: "unit", Message: fmt.Sprintf("unknown unit %q", name)}
+			}
+			if _, ok := formatter.Units[unit.Asset]; ok {
+				return nil, &FieldError{Field: "unit", Message: fmt.Sprintf("several units of %s", unit.Asset)}
+			}
+			formatter.Units[unit.Asset] = unit
+		}
+	}
+	return formatter, nil
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/google/uuid"
+	"log"
+	"net/http"
+	"regexp"
+)
+
+// ErrorCode is a stable machine readable error identifier. Messages may change,
+// codes do not.
+type ErrorCode string
+
+const (
+	ErrorCodeMalformedRequest       ErrorCode = "malformed_request"
+	ErrorCodeValidationFailed       ErrorCode = "validation_failed"
+	ErrorCodeUnauthorized           ErrorCode = "unauthorized"
+	ErrorCodeInvalidSignature       ErrorCode = "invalid_signature"
+	ErrorCodeInsufficientScope      ErrorCode = "insufficient_scope"
+	ErrorCodeNotFound               ErrorCode = "not_found"
+	ErrorCodeMethodNotAllowed       ErrorCode = "method_not_allowed"
+	ErrorCodeDuplicateClientOrderId ErrorCode = "duplicate_client_order_id"
+	ErrorCodeOrderNotLive           ErrorCode = "order_not_live"
+	ErrorCodeAccountFrozen          ErrorCode = "account_frozen"
+	ErrorCodeTradingHalted          ErrorCode = "trading_halted"
+	ErrorCodeIdempotencyKeyReused   ErrorCode = "idempotency_key_reused"
+	ErrorCodeRequestInProgress      ErrorCode = "request_in_progress"
+	ErrorCodeRequestTooLarge        ErrorCode = "request_too_large"
+	ErrorCodeRateLimited            ErrorCode = "rate_limited"
+	ErrorCodeInternal               ErrorCode = "internal_error"
+)
+
+// FieldError describes an invalid field of the request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (err *FieldError) Error() string {
+	return err.Field + ": " + err.Message
+}
+
+type Error struct {
+	Code      ErrorCode    `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"requestId"`
+}
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+const requestIdHeader = "X-Request-Id"
+
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type requestIdContextKey struct{}
+
+func requestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+	return requestId
+}
+
+// assignRequestId keeps a well-formed X-Request-Id of the client or generates
+// a new one and returns it in the response header.
+func assignRequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			requestId := req.Header.Get(requestIdHeader)
+			if !validRequestId.MatchString(requestId) {
+				requestId = uuid.New().String()
+			}
+
+			w.Header().Set(requestIdHeader, requestId)
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), requestIdContextKey{}, requestId)))
+		},
+	)
+}
+
+func writeError(w http.ResponseWriter, req *http.Request, status int, code ErrorCode, message string) {
+	writeErrorResponse(w, req, status, Error{Code: code, Message: message})
+}
+
+func writeValidationError(w http.ResponseWriter, req *http.Request, field, message string) {
+	writeErrorResponse(
+		w,
+		req,
+		http.StatusBadRequest,
+		Error{
+			Code:    ErrorCodeValidationFailed,
+			Message: field + ": " + message,
+			Details: []FieldError{{Field: field, Message: message}},
+		},
+	)
+}
+
+// amountFieldError describes an amount of the asset that failed to parse,
+// amounts with too many decimals get a precise message.
+func amountFieldError(field string, asset currency.Asset, err error, message string) *FieldError {
+	if errors.Is(err, currency.ErrPrecision) {
+		message = fmt.Sprintf("at most %d decimals allowed", asset.Precision)
+	}
+	return &FieldError{Field: field, Message: message}
+}
+
+// writeMalformedRequest reports a request body which could not be decoded.
+func writeMalformedRequest(w http.ResponseWriter, req *http.Request) {
+	writeError(w, req, http.StatusBadRequest, ErrorCodeMalformedRequest, "malformed request body")
+}
+
+// writeRefusalError reports orders and deposits refused because of the
+// account freeze or the trading halt. It returns false for other errors.
+func writeRefusalError(w http.ResponseWriter, req *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, datastore.ErrAccountFrozen):
+		writeError(w, req, http.StatusForbidden, ErrorCodeAccountFrozen, "account frozen")
+	case errors.Is(err, datastore.ErrTradingHalted):
+		writeError(w, req, http.StatusServiceUnavailable, ErrorCodeTradingHalted, "trading halted")
+	default:
+		return false
+	}
+	return true
+}
+
+// writeInternalError logs the error and hides its details from the client.
+func writeInternalError(w http.ResponseWriter, req *http.Request, err error) {
+	log.Printf("request %s %s %s failed: %v", requestIdFromContext(req.Context()), req.Method, req.URL.Path, err)
+	writeError(w, req, http.StatusInternalServerError, ErrorCodeInternal, "internal server error")
+}
+
+func writeErrorResponse(w http.ResponseWriter, req *http.Request, status int, apiError Error) {
+	apiError.RequestID = requestIdFromContext(req.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(ErrorResponse{Error: apiError}); err != nil {
+		log.Printf("request %s: writing error response failed: %v", apiError.RequestID, err)
+	}
+}
+
+func handleNotFound(w http.ResponseWriter, req *http.Request) {
+	writeError(w, req, http.StatusNotFound, ErrorCodeNotFound, "resource not found")
+}
+
+func handleMethodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	writeError(w, req, http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed, "method not allowed")
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	cmMocks "github.com/galcik/vlexchange/internal/coinmarket/mocks"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type errorsTestSuite struct {
+	authTestSuite
+}
+
+func (suite *errorsTestSuite) decodeError(recorder *httptest.ResponseRecorder) Error {
+	suite.Equal("application/json", recorder.Header().Get("Content-Type"))
+	var response ErrorResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.NotEmpty(response.Error.RequestID)
+	suite.Equal(recorder.Header().Get(requestIdHeader), response.Error.RequestID)
+	return response.Error
+}
+
+func (suite *errorsTestSuite) TestValidationError() {
+	recorder := suite.doRequest(
+		http.MethodPost, "/standing_orders", "111222", map[string]string{
+			"type": "buy", "quantity": "many", "limitPrice": "100",
+		},
+	)
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	apiError := suite.decodeError(recorder)
+	suite.Equal(ErrorCodeValidationFailed, apiError.Code)
+	suite.Equal([]FieldError{{Field: "quantity", Message: "malformed quantity"}}, apiError.Details)
+
+	recorder = suite.doRequest(
+		http.MethodPost, "/standing_orders", "111222", map[string]string{
+			"type": "buy", "quantity": "1.123456789", "limitPrice": "100",
+		},
+	)
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	apiError = suite.decodeError(recorder)
+	suite.Equal([]FieldError{{Field: "quantity", Message: "at most 8 decimals allowed"}}, apiError.Details)
+
+	recorder = suite.doRequest(http.MethodPost, "/standing_orders", "111222", "not an object")
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal(ErrorCodeMalformedRequest, suite.decodeError(recorder).Code)
+}
+
+func (suite *errorsTestSuite) TestRoutingErrors() {
+	recorder := suite.doRequest(http.MethodGet, "/unknown", "111222", nil)
+	suite.Require().Equal(http.StatusNotFound, recorder.Code)
+	suite.Equal(ErrorCodeNotFound, suite.decodeError(recorder).Code)
+
+	recorder = suite.doRequest(http.MethodPut, "/balance", "111222", nil)
+	suite.Require().Equal(http.StatusMethodNotAllowed, recorder.Code)
+	suite.Equal(ErrorCodeMethodNotAllowed, suite.decodeError(recorder).Code)
+
+	recorder = suite.doRequest(http.MethodGet, "/balance", "", nil)
+	suite.Require().Equal(http.StatusUnauthorized, recorder.Code)
+	suite.Equal(ErrorCodeUnauthorized, suite.decodeError(recorder).Code)
+}
+
+func (suite *errorsTestSuite) TestInternalErrorIsHidden() {
+	cmServiceMock := &cmMocks.CoinmarketService{}
+	cmServiceMock.On("GetBTCPriceInUSD", mock.Anything).Return(currency.USD(0), errors.New("dial tcp 10.0.0.5:443"))
+	suite.server.coinmarketService = cmServiceMock
+
+	recorder := suite.doRequest(http.MethodGet, "/balance", "111222", nil)
+	suite.Require().Equal(http.StatusInternalServerError, recorder.Code)
+	apiError := suite.decodeError(recorder)
+	suite.Equal(ErrorCodeInternal, apiError.Code)
+	suite.NotContains(recorder.Body.String(), "10.0.0.5")
+}
+
+func (suite *errorsTestSuite) TestRequestId() {
+	request := httptest.NewRequest(http.MethodGet, "/balance", nil)
+	request.Header.Set(requestIdHeader, "client-request-1")
+	recorder := httptest.NewRecorder()
+	suite.server.router.ServeHTTP(recorder, request)
+	suite.Equal("client-request-1", suite.decodeError(recorder).RequestID)
+
+	request = httptest.NewRequest(http.MethodGet, "/balance", nil)
+	request.Header.Set(requestIdHeader, "not a valid id")
+	recorder = httptest.NewRecorder()
+	suite.server.router.ServeHTTP(recorder, request)
+	suite.NotEqual("not a valid id", suite.decodeError(recorder).RequestID)
+}
+
+func (suite *errorsTestSuite) TestAccountFrozen() {
+	accounts, err := suite.queries.GetAccounts(context.Background())
+	suite.Require().NoError(err)
+	_, err = suite.server.store.WithContext(context.Background()).SetAccountFrozen(accounts[0].ID, true)
+	suite.Require().NoError(err)
+
+	recorder := suite.doRequest(http.MethodPost, "/balance", "111222", map[string]string{
+		"currency": "usd", "topupAmount": "100",
+	})
+	suite.Require().Equal(http.StatusForbidden, recorder.Code)
+	suite.Equal(ErrorCodeAccountFrozen, suite.decodeError(recorder).Code)
+
+	recorder = suite.doRequest(http.MethodPost, "/standing_orders", "111222", map[string]string{
+		"type": "buy", "quantity": "0.5", "limitPrice": "100",
+	})
+	suite.Require().Equal(http.StatusForbidden, recorder.Code)
+	suite.Equal(ErrorCodeAccountFrozen, suite.decodeError(recorder).Code)
+}
+
+func (suite *errorsTestSuite) TestTradingHalted() {
+	_, err := suite.server.store.WithContext(context.Background()).SetTradingStatus(true, "maintenance")
+	suite.Require().NoError(err)
+
+	recorder := suite.doRequest(http.MethodPost, "/standing_orders", "111222", map[string]string{
+		"type": "buy", "quantity": "0.5", "limitPrice": "100",
+	})
+	suite.Require().Equal(http.StatusServiceUnavailable, recorder.Code)
+	suite.Equal(ErrorCodeTradingHalted, suite.decodeError(recorder).Code)
+}
+
+func TestErrors(t *testing.T) {
+	suite.Run(t, new(errorsTestSuite))
+}
@@ -0,0 +1,473 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/apikey"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/galcik/vlexchange/internal/events"
+	"github.com/galcik/vlexchange/internal/fix"
+	"github.com/google/uuid"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// fixSymbol is the only instrument traded over FIX, the BTC-USD market.
+const fixSymbol = "BTC/USD"
+
+// NewFIXAcceptor creates the FIX 4.4 order entry gateway. Initiators log on
+// with an API key holding the read and trade scopes, or with a legacy account
+// token, in the Password field.
+func (server *Server) NewFIXAcceptor(compID string) *fix.Acceptor {
+	return fix.NewAcceptor(compID, server.store, &fixGateway{server: server})
+}
+
+func (server *Server) ListenAndServeFIX(addr string, compID string) error {
+	return server.NewFIXAcceptor(compID).ListenAndServe(addr)
+}
+
+type fixGateway struct {
+	server *Server
+}
+
+func (gateway *fixGateway) Logon(session *fix.Session, logon *fix.Message) (fix.Handler, error) {
+	ctx := context.Background()
+	ip := addrIP(session.RemoteAddr().String())
+	auth, err := gateway.server.authenticateToken(ctx, logon.Get(fix.TagPassword), ip)
+	if err != nil {
+		log.Printf("fix logon of %s failed: %v", session.TargetCompID, err)
+		return nil, errors.New("internal error")
+	}
+	if auth == nil {
+		return nil, errors.New("unauthorized")
+	}
+	if !apikey.HasScopes(auth.Scopes, apikey.ScopeRead, apikey.ScopeTrade) {
+		return nil, errors.New("insufficient scope")
+	}
+
+	account, err := gateway.server.store.WithContext(ctx).GetAccount(auth.AccountID)
+	if err != nil {
+		log.Printf("fix logon of %s failed: %v", session.TargetCompID, err)
+		return nil, errors.New("internal error")
+	}
+	if account == nil {
+		return nil, errors.New("unauthorized")
+	}
+
+	return &fixOrderSession{server: gateway.server, session: session, account: account}, nil
+}
+
+// fixOrderSession handles the orders of an account logged on over FIX.
+type fixOrderSession struct {
+	server  *Server
+	session *fix.Session
+	account *queries.Account
+	trades  *events.TradeSubscription
+	// mutex sends the reports of fills after the acknowledgement of the order
+	mutex sync.Mutex
+}
+
+func (handler *fixOrderSession) OnLogon() {
+	handler.trades = handler.server.events.SubscribeTrades()
+	go handler.reportTrades(handler.trades)
+}
+
+func (handler *fixOrderSession) OnLogout() {
+	if handler.trades != nil {
+		handler.server.events.UnsubscribeTrades(handler.trades)
+	}
+}
+
+func (handler *fixOrderSession) FromApp(message *fix.Message) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	switch message.Type() {
+	case fix.MsgTypeNewOrderSingle:
+		return handler.newOrderSingle(message)
+	case fix.MsgTypeOrderCancelRequest:
+		return handler.cancelOrder(message)
+	case fix.MsgTypeOrderCancelReplaceRequest:
+		return handler.replaceOrder(message)
+	}
+	return fix.ErrUnsupportedMessageType
+}
+
+func (handler *fixOrderSession) newOrderSingle(message *fix.Message) error {
+	market, err := handler.market()
+	if err != nil {
+		return err
+	}
+	request, reason, text := parseFIXOrder(message, market)
+	if text != "" {
+		return handler.session.Send(newFIXRejection(message, reason, text))
+	}
+
+	order, err := handler.server.placeStandingOrder(context.Background(), handler.account, request)
+	var fieldError *FieldError
+	if errors.As(err, &fieldError) {
+		return handler.session.Send(newFIXRejection(message, fix.OrdRejReasonOther, fieldError.Error()))
+	}
+	if errors.Is(err, datastore.ErrDuplicateClientOrderID) {
+		return handler.session.Send(newFIXRejection(message, fix.OrdRejReasonDuplicateOrder, "duplicate ClOrdID"))
+	}
+	if errors.Is(err, datastore.ErrAccountFrozen) {
+		return handler.session.Send(newFIXRejection(message, fix.OrdRejReasonOther, "account frozen"))
+	}
+	if errors.Is(err, datastore.ErrTradingHalted) {
+		return handler.session.Send(newFIXRejection(message, fix.OrdRejReasonExchangeClosed, "trading halted"))
+	}
+	if err != nil {
+		return err
+	}
+
+	// orders exceeding the balance are created cancelled
+	if order.State == queries.OrderStateCancelled {
+		rejection := newFIXRejection(message, fix.OrdRejReasonExceedsLimit, "insufficient funds")
+		return handler.session.Send(rejection.SetInt(fix.TagOrderID, int(order.ID)))
+	}
+
+	// fills of the order are reported separately by reportTrades
+	report := newFIXExecutionReport(order, fix.ExecTypeNew, fix.OrdStatusNew)
+	report.Set(fix.TagLeavesQty, currency.BTC(order.Quantity+order.FilledQuantity).String())
+	report.Set(fix.TagCumQty, currency.BTC(0).String())
+	report.Set(fix.TagAvgPx, currency.USD(0).String())
+	return handler.session.Send(report)
+}
+
+// market loads the market traded in the session.
+func (handler *fixOrderSession) market() (*datastore.Market, error) {
+	return handler.server.store.WithContext(context.Background()).GetMarket(datastore.DefaultMarketID)
+}
+
+func (handler *fixOrderSession) cancelOrder(message *fix.Message) error {
+	order, err := handler.findOrder(message)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return handler.session.Send(
+			newFIXCancelReject(message, nil, fix.CxlRejResponseToCancel, fix.CxlRejReasonUnknownOrder, "unknown order"),
+		)
+	}
+
+	err = handler.server.cancelStandingOrder(context.Background(), order)
+	if errors.Is(err, datastore.ErrOrderNotLive) {
+		return handler.session.Send(
+			newFIXCancelReject(message, order, fix.CxlRejResponseToCancel, fix.CxlRejReasonTooLate, "order is not live"),
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	order.State = queries.OrderStateCancelled
+	report := newFIXExecutionReport(order, fix.ExecTypeCanceled, fix.OrdStatusCanceled)
+	report.Set(fix.TagClOrdID, message.Get(fix.TagClOrdID))
+	report.Set(fix.TagOrigClOrdID, order.ClientOrderID.String)
+	report.Set(fix.TagLeavesQty, currency.BTC(0).String())
+	return handler.session.Send(report)
+}
+
+// replaceOrder cancels the original order and places a new one for the
+// quantity left after the fills of the original order. Both happen at once,
+// the original order stays live when the replacement is rejected.
+func (handler *fixOrderSession) replaceOrder(message *fix.Message) error {
+	responseTo := fix.CxlRejResponseToCancelReplace
+	order, err := handler.findOrder(message)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return handler.session.Send(
+			newFIXCancelReject(message, nil, responseTo, fix.CxlRejReasonUnknownOrder, "unknown order"),
+		)
+	}
+	if order.State != queries.OrderStateLive {
+		return handler.session.Send(
+			newFIXCancelReject(message, order, responseTo, fix.CxlRejReasonTooLate, "order is not live"),
+		)
+	}
+
+	market, err := handler.market()
+	if err != nil {
+		return err
+	}
+	request, _, text := parseFIXOrder(message, market)
+	if text == "" && request.Type != string(order.Type) {
+		text = "Side cannot be changed"
+	}
+	if text != "" {
+		return handler.session.Send(newFIXCancelReject(message, order, responseTo, fix.CxlRejReasonOther, text))
+	}
+
+	quantity, _ := market.Base.Parse(request.Quantity)
+	remaining := quantity - int64(order.FilledQuantity)
+	if remaining <= 0 {
+		return handler.session.Send(
+			newFIXCancelReject(message, order, responseTo, fix.CxlRejReasonTooLate, "OrderQty is not above CumQty"),
+		)
+	}
+	request.Quantity = market.Base.Format(remaining)
+	request.WebhookUrl = order.WebhookUrl.String
+
+	store := handler.server.store.WithContext(context.Background())
+	duplicate, err := store.GetStandingOrderByClientOrderID(handler.account.ID, request.ClientOrderId)
+	if err != nil {
+		return err
+	}
+	if duplicate != nil {
+		return handler.session.Send(
+			newFIXCancelReject(message, order, responseTo, fix.CxlRejReasonOther, "duplicate ClOrdID"),
+		)
+	}
+
+	replacement, err := handler.server.replaceStandingOrder(context.Background(), handler.account, order, request)
+	if errors.Is(err, datastore.ErrOrderNotLive) {
+		return handler.session.Send(
+			newFIXCancelReject(message, order, responseTo, fix.CxlRejReasonTooLate, "order is not live"),
+		)
+	}
+	var fieldError *FieldError
+	if err != nil && !errors.As(err, &fieldError) && !errors.Is(err, datastore.ErrInsufficientFunds) &&
+		!errors.Is(err, datastore.ErrDuplicateClientOrderID) && !errors.Is(err, datastore.ErrAccountFrozen) &&
+		!errors.Is(err, datastore.ErrTradingHalted) {
+		return err
+	}
+	if err != nil {
+		return handler.session.Send(
+			newFIXCancelReject(message, order, responseTo, fix.CxlRejReasonOther, "replacement rejected: "+err.Error()),
+		)
+	}
+
+	report := newFIXExecutionReport(replacement, fix.ExecTypeReplaced, fix.OrdStatusNew)
+	report.Set(fix.TagOrigClOrdID, order.ClientOrderID.String)
+	report.Set(fix.TagLeavesQty, currency.BTC(replacement.Quantity+replacement.FilledQuantity).String())
+	report.Set(fix.TagCumQty, currency.BTC(0).String())
+	report.Set(fix.TagAvgPx, currency.USD(0).String())
+	return handler.session.Send(report)
+}
+
+// findOrder loads the order addressed by OrderID or OrigClOrdID. Orders of
+// other accounts and markets are not found.
+func (handler *fixOrderSession) findOrder(message *fix.Message) (*queries.StandingOrder, error) {
+	store := handler.server.store.WithContext(context.Background())
+
+	var order *queries.StandingOrder
+	var err error
+	if orderId, ok := message.Lookup(fix.TagOrderID); ok {
+		id, parseErr := strconv.ParseInt(orderId, 10, 32)
+		if parseErr != nil {
+			return nil, nil
+		}
+		order, err = store.GetStandingOrder(int32(id))
+	} else {
+		order, err = store.GetStandingOrderByClientOrderID(handler.account.ID, message.Get(fix.TagOrigClOrdID))
+	}
+	if err != nil || order == nil || !handler.isSessionOrder(order) {
+		return nil, err
+	}
+	return order, nil
+}
+
+// isSessionOrder tells whether the order can be seen in the session, FIX
+// trades the BTC-USD orders of the account only.
+func (handler *fixOrderSession) isSessionOrder(order *queries.StandingOrder) bool {
+	return order.AccountID == handler.account.ID && order.MarketID == datastore.DefaultMarketID
+}
+
+func (handler *fixOrderSession) reportTrades(subscription *events.TradeSubscription) {
+	for trade := range subscription.C {
+		for _, orderId := range []int32{trade.BuyOrderID, trade.SellOrderID} {
+			if err := handler.reportFill(trade, orderId); err != nil {
+				log.Printf("fix report of trade %d failed: %v", trade.ID, err)
+			}
+		}
+	}
+}
+
+// reportFill sends the ExecutionReport of the trade when the order belongs to
+// the account.
+func (handler *fixOrderSession) reportFill(trade queries.Trade, orderId int32) error {
+	store := handler.server.store.WithContext(context.Background())
+	order, err := store.GetStandingOrder(orderId)
+	if err != nil || order == nil || !handler.isSessionOrder(order) {
+		return err
+	}
+
+	// the cumulative values include the earlier trades of the order only
+	trades, err := store.GetOrderTrades(orderId)
+	if err != nil {
+		return err
+	}
+	var cumQty currency.BTC
+	cumPrice := currency.AssetUSD.Money(0)
+	for _, orderTrade := range trades {
+		if orderTrade.ID <= trade.ID {
+			cumQty += currency.BTC(orderTrade.Quantity)
+			// trades are settled by the price rounded half to even
+			tradePrice, err := currency.BTC(orderTrade.Quantity).USD(currency.USD(orderTrade.Price))
+			if err != nil {
+				return err
+			}
+			if cumPrice, err = cumPrice.Add(currency.AssetUSD.Money(tradePrice.Internal())); err != nil {
+				return err
+			}
+		}
+	}
+
+	leavesQty := currency.BTC(order.Quantity+order.FilledQuantity) - cumQty
+	ordStatus := fix.OrdStatusPartiallyFilled
+	if leavesQty <= 0 {
+		ordStatus = fix.OrdStatusFilled
+	}
+
+	report := newFIXExecutionReport(order, fix.ExecTypeTrade, ordStatus)
+	report.Set(fix.TagExecID, fmt.Sprintf("%d-%d", trade.ID, order.ID))
+	report.Set(fix.TagLastQty, currency.BTC(trade.Quantity).String())
+	report.Set(fix.TagLastPx, currency.USD(trade.Price).String())
+	report.Set(fix.TagLeavesQty, leavesQty.String())
+	report.Set(fix.TagCumQty, cumQty.String())
+	report.Set(fix.TagAvgPx, fixAveragePrice(cumQty.Internal(), cumPrice.Amount))
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	return handler.session.Send(report)
+}
+
+// parseFIXOrder validates the order fields of NewOrderSingle and
+// OrderCancelReplaceRequest. Invalid orders are described by the OrdRejReason
+// and the text.
+func parseFIXOrder(message *fix.Message, market *datastore.Market) (postStandingOrderRequest, string, string) {
+	request := postStandingOrderRequest{
+		Quantity:      message.Get(fix.TagOrderQty),
+		LimitPrice:    message.Get(fix.TagPrice),
+		ClientOrderId: message.Get(fix.TagClOrdID),
+	}
+
+	if request.ClientOrderId == "" || !isValidClientOrderId(request.ClientOrderId) {
+		return request, fix.OrdRejReasonOther, "invalid ClOrdID"
+	}
+	if message.Get(fix.TagSymbol) != fixSymbol {
+		return request, fix.OrdRejReasonUnknownSymbol, "unknown Symbol"
+	}
+	if message.Get(fix.TagOrdType) != fix.OrdTypeLimit {
+		return request, fix.OrdRejReasonUnsupportedOrder, "only limit orders are supported"
+	}
+
+	switch message.Get(fix.TagSide) {
+	case fix.SideBuy:
+		request.Type = string(queries.OrderTypeBuy)
+	case fix.SideSell:
+		request.Type = string(queries.OrderTypeSell)
+	default:
+		return request, fix.OrdRejReasonOther, "unknown Side"
+	}
+
+	if quantity, err := market.Base.Parse(request.Quantity); err != nil || quantity <= 0 {
+		return request, fix.OrdRejReasonOther, "invalid OrderQty"
+	}
+	if price, err := market.Quote.Parse(request.LimitPrice); err != nil || price < 0 {
+		return request, fix.OrdRejReasonOther, "invalid Price"
+	}
+
+	return request, "", ""
+}
+
+func fixSide(orderType queries.OrderType) string {
+	if orderType == queries.OrderTypeBuy {
+		return fix.SideBuy
+	}
+	return fix.SideSell
+}
+
+func fixOrdStatus(state queries.OrderState) string {
+	switch state {
+	case queries.OrderStateFulfilled:
+		return fix.OrdStatusFilled
+	case queries.OrderStateCancelled:
+		return fix.OrdStatusCanceled
+	}
+	return fix.OrdStatusNew
+}
+
+// newFIXExecutionReport describes the order, the quantities depending on the
+// kind of the report are set by the caller.
+func newFIXExecutionReport(order *queries.StandingOrder, execType string, ordStatus string) *fix.Message {
+	return fix.NewMessage(fix.MsgTypeExecutionReport).
+		SetInt(fix.TagOrderID, int(order.ID)).
+		Set(fix.TagClOrdID, order.ClientOrderID.String).
+		Set(fix.TagExecID, uuid.New().String()).
+		Set(fix.TagExecType, execType).
+		Set(fix.TagOrdStatus, ordStatus).
+		Set(fix.TagSymbol, fixSymbol).
+		Set(fix.TagSide, fixSide(order.Type)).
+		Set(fix.TagOrdType, fix.OrdTypeLimit).
+		Set(fix.TagOrderQty, currency.BTC(order.Quantity+order.FilledQuantity).String()).
+		Set(fix.TagPrice, currency.USD(order.LimitPrice).String()).
+		Set(fix.TagLeavesQty, currency.BTC(order.Quantity).String()).
+		Set(fix.TagCumQty, currency.BTC(order.FilledQuantity).String()).
+		Set(fix.TagAvgPx, fixAvgPx(order)).
+		Set(fix.TagTransactTime, time.Now().UTC().Format(fix.TimestampFormat))
+}
+
+func fixAvgPx(order *queries.StandingOrder) string {
+	return fixAveragePrice(int64(order.FilledQuantity), int64(order.FilledPrice))
+}
+
+// fixAveragePrice is the USD price per BTC paid for the quantity in total.
+func fixAveragePrice(quantity int64, price int64) string {
+	if quantity == 0 {
+		return currency.USD(0).String()
+	}
+	// the average lies between the prices of the trades, it cannot overflow
+	average, _ := currency.AssetUSD.Money(price).Per(currency.AssetBTC.Money(quantity), currency.RoundHalfEven)
+	return average.Asset.Format(average.Amount)
+}
+
+func newFIXRejection(message *fix.Message, reason string, text string) *fix.Message {
+	return fix.NewMessage(fix.MsgTypeExecutionReport).
+		Set(fix.TagOrderID, "NONE").
+		Set(fix.TagClOrdID, message.Get(fix.TagClOrdID)).
+		Set(fix.TagExecID, uuid.New().String()).
+		Set(fix.TagExecType, fix.ExecTypeRejected).
+		Set(fix.TagOrdStatus, fix.OrdStatusRejected).
+		Set(fix.TagSymbol, message.Get(fix.TagSymbol)).
+		Set(fix.TagSide, message.Get(fix.TagSide)).
+		Set(fix.TagOrderQty, message.Get(fix.TagOrderQty)).
+		Set(fix.TagLeavesQty, currency.BTC(0).String()).
+		Set(fix.TagCumQty, currency.BTC(0).String()).
+		Set(fix.TagAvgPx, currency.USD(0).String()).
+		Set(fix.TagOrdRejReason, reason).
+		Set(fix.TagText, text).
+		Set(fix.TagTransactTime, time.Now().UTC().Format(fix.TimestampFormat))
+}
+
+// newFIXCancelReject rejects the cancel or replace request of the order, which
+// is nil for unknown orders.
+func newFIXCancelReject(
+	message *fix.Message,
+	order *queries.StandingOrder,
+	responseTo string,
+	reason string,
+	text string,
+) *fix.Message {
+	orderId, ordStatus := "NONE", fix.OrdStatusRejected
+	if order != nil {
+		orderId, ordStatus = strconv.Itoa(int(order.ID)), fixOrdStatus(order.State)
+	}
+
+	return fix.NewMessage(fix.MsgTypeOrderCancelReject).
+		Set(fix.TagOrderID, orderId).
+		Set(fix.TagClOrdID, message.Get(fix.TagClOrdID)).
+		Set(fix.TagOrigClOrdID, message.Get(fix.TagOrigClOrdID)).
+		Set(fix.TagOrdStatus, ordStatus).
+		Set(fix.TagCxlRejResponseTo, responseTo).
+		Set(fix.TagCxlRejReason, reason).
+		Set(fix.TagText, text)
+}
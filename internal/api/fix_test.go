@@ -0,0 +1,259 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore/testqueries"
+	"github.com/galcik/vlexchange/internal/fix"
+	"github.com/stretchr/testify/suite"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const fixReceiveTimeout = 5 * time.Second
+
+type fixTestSuite struct {
+	authTestSuite
+	acceptor  *fix.Acceptor
+	initiator *fix.Initiator
+}
+
+func (suite *fixTestSuite) BeforeTest(suiteName, testName string) {
+	suite.authTestSuite.BeforeTest(suiteName, testName)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	suite.acceptor = suite.server.NewFIXAcceptor("VLEX")
+	go suite.acceptor.Serve(listener)
+
+	suite.initiator, err = fix.Dial(listener.Addr().String(), "CLIENT", "VLEX")
+	suite.Require().NoError(err)
+}
+
+func (suite *fixTestSuite) AfterTest(suiteName, testName string) {
+	suite.initiator.Close()
+	suite.acceptor.Close()
+	suite.authTestSuite.AfterTest(suiteName, testName)
+}
+
+func (suite *fixTestSuite) logon() {
+	_, err := suite.initiator.Logon("111222", 30, true)
+	suite.Require().NoError(err)
+}
+
+func (suite *fixTestSuite) receive(msgType string) *fix.Message {
+	message, err := suite.initiator.Receive(fixReceiveTimeout)
+	suite.Require().NoError(err)
+	suite.Require().Equal(msgType, message.Type(), message.String())
+	return message
+}
+
+func (suite *fixTestSuite) deposit(amount string) {
+	recorder := suite.doRequest(http.MethodPost, "/balance", "111222", map[string]string{
+		"currency": "usd", "topupAmount": amount,
+	})
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+}
+
+func newFIXOrder(clOrdID string, side string, quantity string, price string) *fix.Message {
+	return fix.NewMessage(fix.MsgTypeNewOrderSingle).
+		Set(fix.TagClOrdID, clOrdID).
+		Set(fix.TagSymbol, fixSymbol).
+		Set(fix.TagSide, side).
+		Set(fix.TagOrdType, fix.OrdTypeLimit).
+		Set(fix.TagOrderQty, quantity).
+		Set(fix.TagPrice, price).
+		Set(fix.TagTransactTime, time.Now().UTC().Format(fix.TimestampFormat))
+}
+
+func (suite *fixTestSuite) TestLogon() {
+	response, err := suite.initiator.Logon("unknown", 30, true)
+	suite.Error(err)
+	suite.Equal(fix.MsgTypeLogout, response.Type())
+	suite.Equal("unauthorized", response.Get(fix.TagText))
+}
+
+func (suite *fixTestSuite) TestLogonRequiresTradeScope() {
+	key := suite.createKey(map[string]interface{}{"name": "reader", "scopes": []string{"read"}})
+	response, err := suite.initiator.Logon(key.Token, 30, true)
+	suite.Error(err)
+	suite.Equal("insufficient scope", response.Get(fix.TagText))
+}
+
+func (suite *fixTestSuite) TestNewOrderSingle() {
+	suite.deposit("100")
+	suite.logon()
+
+	suite.Require().NoError(suite.initiator.Send(newFIXOrder("fix-1", fix.SideBuy, "0.5", "100")))
+	report := suite.receive(fix.MsgTypeExecutionReport)
+	suite.Equal("fix-1", report.Get(fix.TagClOrdID))
+	suite.Equal(fix.ExecTypeNew, report.Get(fix.TagExecType))
+	suite.Equal(fix.OrdStatusNew, report.Get(fix.TagOrdStatus))
+	suite.Equal(currency.NewBTC(0.5).String(), report.Get(fix.TagLeavesQty))
+
+	order, err := suite.server.store.WithContext(context.Background()).
+		GetStandingOrder(int32(mustFIXInt(report, fix.TagOrderID)))
+	suite.Require().NoError(err)
+	suite.Require().NotNil(order)
+	suite.Equal("fix-1", order.ClientOrderID.String)
+
+	suite.Require().NoError(suite.initiator.Send(newFIXOrder("fix-1", fix.SideBuy, "0.5", "100")))
+	report = suite.receive(fix.MsgTypeExecutionReport)
+	suite.Equal(fix.ExecTypeRejected, report.Get(fix.TagExecType))
+	suite.Equal(fix.OrdRejReasonDuplicateOrder, report.Get(fix.TagOrdRejReason))
+
+	suite.Require().NoError(suite.initiator.Send(
+		newFIXOrder("fix-2", fix.SideBuy, "0.5", "100").Set(fix.TagSymbol, "ETH/USD"),
+	))
+	report = suite.receive(fix.MsgTypeExecutionReport)
+	suite.Equal(fix.ExecTypeRejected, report.Get(fix.TagExecType))
+	suite.Equal(fix.OrdRejReasonUnknownSymbol, report.Get(fix.TagOrdRejReason))
+
+	// the deposit covers the first order only
+	suite.Require().NoError(suite.initiator.Send(newFIXOrder("fix-3", fix.SideBuy, "1", "100")))
+	report = suite.receive(fix.MsgTypeExecutionReport)
+	suite.Equal(fix.ExecTypeRejected, report.Get(fix.TagExecType))
+	suite.Equal(fix.OrdRejReasonExceedsLimit, report.Get(fix.TagOrdRejReason))
+}
+
+func (suite *fixTestSuite) TestFills() {
+	seller, err := suite.queries.CreateAccount(context.Background(), testqueries.CreateAccountParams{
+		Username: "Seller",
+		Token:    "333444",
+	})
+	suite.Require().NoError(err)
+	suite.setBalance(seller.ID, "BTC", currency.NewBTC(1).Internal())
+	suite.deposit("100")
+	suite.logon()
+
+	suite.Require().NoError(suite.initiator.Send(newFIXOrder("fix-buy", fix.SideBuy, "1", "100")))
+	suite.receive(fix.MsgTypeExecutionReport)
+
+	recorder := suite.doRequest(http.MethodPost, "/standing_orders", "333444", map[string]string{
+		"type": "sell", "quantity": "0.4", "limitPrice": "100",
+	})
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+
+	report := suite.receive(fix.MsgTypeExecutionReport)
+	suite.Equal("fix-buy", report.Get(fix.TagClOrdID))
+	suite.Equal(fix.ExecTypeTrade, report.Get(fix.TagExecType))
+	suite.Equal(fix.OrdStatusPartiallyFilled, report.Get(fix.TagOrdStatus))
+	suite.Equal(currency.NewBTC(0.4).String(), report.Get(fix.TagLastQty))
+	suite.Equal(currency.NewUSD(100).String(), report.Get(fix.TagLastPx))
+	suite.Equal(currency.NewBTC(0.4).String(), report.Get(fix.TagCumQty))
+	suite.Equal(currency.NewBTC(0.6).String(), report.Get(fix.TagLeavesQty))
+	suite.Equal(currency.NewUSD(100).String(), report.Get(fix.TagAvgPx))
+}
+
+func (suite *fixTestSuite) TestCancel() {
+	suite.deposit("100")
+	suite.logon()
+
+	suite.Require().NoError(suite.initiator.Send(newFIXOrder("fix-1", fix.SideBuy, "0.5", "100")))
+	suite.receive(fix.MsgTypeExecutionReport)
+
+	suite.Require().NoError(suite.initiator.Send(
+		fix.NewMessage(fix.MsgTypeOrderCancelRequest).
+			Set(fix.TagClOrdID, "fix-1-cancel").
+			Set(fix.TagOrigClOrdID, "fix-1").
+			Set(fix.TagSymbol, fixSymbol).
+			Set(fix.TagSide, fix.SideBuy),
+	))
+	report := suite.receive(fix.MsgTypeExecutionReport)
+	suite.Equal(fix.ExecTypeCanceled, report.Get(fix.TagExecType))
+	suite.Equal("fix-1-cancel", report.Get(fix.TagClOrdID))
+	suite.Equal("fix-1", report.Get(fix.TagOrigClOrdID))
+
+	suite.Require().NoError(suite.initiator.Send(
+		fix.NewMessage(fix.MsgTypeOrderCancelRequest).
+			Set(fix.TagClOrdID, "fix-1-cancel-again").
+			Set(fix.TagOrigClOrdID, "fix-1"),
+	))
+	reject := suite.receive(fix.MsgTypeOrderCancelReject)
+	suite.Equal(fix.CxlRejReasonTooLate, reject.Get(fix.TagCxlRejReason))
+	suite.Equal(fix.CxlRejResponseToCancel, reject.Get(fix.TagCxlRejResponseTo))
+}
+
+func (suite *fixTestSuite) TestReplace() {
+	suite.deposit("100")
+	suite.logon()
+
+	suite.Require().NoError(suite.initiator.Send(newFIXOrder("fix-1", fix.SideBuy, "0.5", "100")))
+	original := suite.receive(fix.MsgTypeExecutionReport)
+
+	replace := newFIXOrder("fix-2", fix.SideBuy, "0.8", "120")
+	replace.Set(fix.TagMsgType, fix.MsgTypeOrderCancelReplaceRequest).Set(fix.TagOrigClOrdID, "fix-1")
+	suite.Require().NoError(suite.initiator.Send(replace))
+	report := suite.receive(fix.MsgTypeExecutionReport)
+	suite.Equal(fix.ExecTypeReplaced, report.Get(fix.TagExecType))
+	suite.Equal("fix-2", report.Get(fix.TagClOrdID))
+	suite.Equal("fix-1", report.Get(fix.TagOrigClOrdID))
+	suite.Equal(currency.NewBTC(0.8).String(), report.Get(fix.TagOrderQty))
+	suite.Equal(currency.NewUSD(120).String(), report.Get(fix.TagPrice))
+	suite.NotEqual(original.Get(fix.TagOrderID), report.Get(fix.TagOrderID))
+
+	// the side of an order cannot be changed
+	replace = newFIXOrder("fix-3", fix.SideSell, "0.8", "120")
+	replace.Set(fix.TagMsgType, fix.MsgTypeOrderCancelReplaceRequest).Set(fix.TagOrigClOrdID, "fix-2")
+	suite.Require().NoError(suite.initiator.Send(replace))
+	reject := suite.receive(fix.MsgTypeOrderCancelReject)
+	suite.Equal(fix.CxlRejResponseToCancelReplace, reject.Get(fix.TagCxlRejResponseTo))
+	suite.Equal("Side cannot be changed", reject.Get(fix.TagText))
+}
+
+func (suite *fixTestSuite) TestReplaceRejected() {
+	suite.deposit("100")
+	suite.logon()
+
+	suite.Require().NoError(suite.initiator.Send(newFIXOrder("fix-1", fix.SideBuy, "0.5", "100")))
+	original := suite.receive(fix.MsgTypeExecutionReport)
+
+	// the replacement is not covered by the balance
+	replace := newFIXOrder("fix-2", fix.SideBuy, "1", "150")
+	replace.Set(fix.TagMsgType, fix.MsgTypeOrderCancelReplaceRequest).Set(fix.TagOrigClOrdID, "fix-1")
+	suite.Require().NoError(suite.initiator.Send(replace))
+	reject := suite.receive(fix.MsgTypeOrderCancelReject)
+	suite.Equal(fix.CxlRejResponseToCancelReplace, reject.Get(fix.TagCxlRejResponseTo))
+	suite.Equal(original.Get(fix.TagOrderID), reject.Get(fix.TagOrderID))
+	suite.Equal(fix.OrdStatusNew, reject.Get(fix.TagOrdStatus))
+	suite.Equal("replacement rejected: insufficient funds", reject.Get(fix.TagText))
+
+	// the original order is left untouched
+	recorder := suite.doRequest(http.MethodGet, "/standing_orders/by-client-id/fix-1", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var order getStandingOrderResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &order))
+	suite.Equal("LIVE", order.State)
+	suite.Equal(currency.NewBTC(0.5).String(), order.Quantity)
+	suite.Equal(
+		http.StatusNotFound,
+		suite.doRequest(http.MethodGet, "/standing_orders/by-client-id/fix-2", "111222", nil).Code,
+	)
+}
+
+func (suite *fixTestSuite) TestTradingHalted() {
+	suite.deposit("100")
+	suite.logon()
+	_, err := suite.server.store.WithContext(context.Background()).SetTradingStatus(true, "maintenance")
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.initiator.Send(newFIXOrder("fix-1", fix.SideBuy, "0.5", "100")))
+	report := suite.receive(fix.MsgTypeExecutionReport)
+	suite.Equal(fix.ExecTypeRejected, report.Get(fix.TagExecType))
+	suite.Equal(fix.OrdRejReasonExchangeClosed, report.Get(fix.TagOrdRejReason))
+}
+
+func mustFIXInt(message *fix.Message, tag int) int {
+	value, err := message.GetInt(tag)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func TestFIX(t *testing.T) {
+	suite.Run(t, new(fixTestSuite))
+}
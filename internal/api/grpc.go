@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/galcik/vlexchange/internal/apikey"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/proto/trading"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log"
+	"net"
+	"strconv"
+)
+
+// tokenMetadataKey carries the API key or legacy account token of gRPC calls.
+const tokenMetadataKey = "x-token"
+
+const tradingMethodPrefix = "/vlexchange.trading.Trading/"
+
+// grpcMethodScopes holds the scopes required by the methods of the trading
+// service, they match the scopes of the HTTP routes.
+var grpcMethodScopes = map[string][]apikey.Scope{
+	tradingMethodPrefix + "PlaceOrder":        {apikey.ScopeTrade},
+	tradingMethodPrefix + "CancelOrder":       {apikey.ScopeTrade},
+	tradingMethodPrefix + "GetOrder":          {apikey.ScopeRead},
+	tradingMethodPrefix + "ListOrders":        {apikey.ScopeRead},
+	tradingMethodPrefix + "GetBalance":        {apikey.ScopeRead},
+	tradingMethodPrefix + "Deposit":           {apikey.ScopeDeposit},
+	tradingMethodPrefix + "StreamOrderEvents": {apikey.ScopeRead},
+	tradingMethodPrefix + "StreamTrades":      {apikey.ScopeRead},
+}
+
+// grpcMethodCosts holds the rate limit tokens taken by methods, other methods
+// cost a single token.
+var grpcMethodCosts = map[string]int{
+	tradingMethodPrefix + "PlaceOrder":  routeCosts["postStandingOrder"],
+	tradingMethodPrefix + "CancelOrder": routeCosts["deleteStandingOrder"],
+	tradingMethodPrefix + "Deposit":     routeCosts["postBalance"],
+}
+
+// NewGRPCServer creates the gRPC server of the trading API. Calls are
+// authenticated and rate limited the same way as HTTP requests.
+func (server *Server) NewGRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(server.authenticateUnaryCall),
+		grpc.StreamInterceptor(server.authenticateStreamCall),
+	)
+	trading.RegisterTradingServer(grpcServer, &tradingService{server: server})
+	return grpcServer
+}
+
+func (server *Server) ListenAndServeGRPC(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return server.NewGRPCServer().Serve(listener)
+}
+
+func (server *Server) authenticateUnaryCall(
+	ctx context.Context,
+	request interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := server.authenticateCall(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+func (server *Server) authenticateStreamCall(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := server.authenticateCall(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticatedStream replaces the context of the stream by the one holding
+// the account.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
+
+// authenticateCall applies the rate limits, authenticates the token of the call
+// and checks its scopes. The returned context holds the auth and the account
+// like the context of authenticated HTTP requests.
+func (server *Server) authenticateCall(ctx context.Context, method string) (context.Context, error) {
+	ip := peerIP(ctx)
+	cost := 1
+	if methodCost, ok := grpcMethodCosts[method]; ok {
+		cost = methodCost
+	}
+
+	ipKey := ""
+	if ip != nil {
+		ipKey = ip.String()
+	}
+	if !server.ipLimiter.Allow(ipKey, server.rateLimits.IP, cost).Allowed {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(tokenMetadataKey); len(values) > 0 {
+			token = values[0]
+		}
+	}
+
+	auth, err := server.authenticateToken(ctx, token, ip)
+	if err != nil {
+		return nil, grpcInternalError(method, err)
+	}
+	if auth == nil {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	account, err := server.store.WithContext(ctx).GetAccount(auth.AccountID)
+	if err != nil {
+		return nil, grpcInternalError(method, err)
+	}
+	if account == nil {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	scopes, ok := grpcMethodScopes[method]
+	if !ok {
+		scopes = apikey.AllScopes
+	}
+	if !apikey.HasScopes(auth.Scopes, scopes...) {
+		return nil, status.Error(codes.PermissionDenied, "insufficient scope")
+	}
+
+	result := server.accountLimiter.Allow(
+		strconv.Itoa(int(account.ID)), server.rateLimits.tierLimit(account.Tier), cost,
+	)
+	if !result.Allowed {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	ctx = context.WithValue(ctx, authContextKey{}, auth)
+	return context.WithValue(ctx, accountContextKey{}, account), nil
+}
+
+func peerIP(ctx context.Context) net.IP {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil
+	}
+	return addrIP(p.Addr.String())
+}
+
+// grpcError converts errors of the shared handler logic to statuses.
+func grpcError(method string, err error) error {
+	var fieldError *FieldError
+	if errors.As(err, &fieldError) {
+		return status.Error(codes.InvalidArgument, fieldError.Error())
+	}
+	if errors.Is(err, datastore.ErrAccountFrozen) {
+		return status.Error(codes.PermissionDenied, "account frozen")
+	}
+	if errors.Is(err, datastore.ErrTradingHalted) {
+		return status.Error(codes.Unavailable, "trading halted")
+	}
+	if errors.Is(err, datastore.ErrOrderNotLive) {
+		return status.Error(codes.FailedPrecondition, "order is not live")
+	}
+	return grpcInternalError(method, err)
+}
+
+// grpcInternalError logs the error and hides its details from the client.
+func grpcInternalError(method string, err error) error {
+	log.Printf("call %s failed: %v", method, err)
+	return status.Error(codes.Internal, "internal server error")
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore/testqueries"
+	"github.com/galcik/vlexchange/proto/trading"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+type grpcTestSuite struct {
+	authTestSuite
+	grpcServer *grpc.Server
+	conn       *grpc.ClientConn
+	client     trading.TradingClient
+}
+
+func (suite *grpcTestSuite) BeforeTest(suiteName, testName string) {
+	suite.authTestSuite.BeforeTest(suiteName, testName)
+
+	listener := bufconn.Listen(1 << 20)
+	suite.grpcServer = suite.server.NewGRPCServer()
+	go suite.grpcServer.Serve(listener)
+
+	var err error
+	suite.conn, err = grpc.Dial(
+		"bufconn",
+		grpc.WithContextDialer(
+			func(context.Context, string) (net.Conn, error) {
+				return listener.Dial()
+			},
+		),
+		grpc.WithInsecure(),
+	)
+	suite.Require().NoError(err)
+	suite.client = trading.NewTradingClient(suite.conn)
+}
+
+func (suite *grpcTestSuite) AfterTest(suiteName, testName string) {
+	suite.conn.Close()
+	suite.grpcServer.Stop()
+	suite.authTestSuite.AfterTest(suiteName, testName)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), tokenMetadataKey, token)
+}
+
+func (suite *grpcTestSuite) TestRequiresAuthentication() {
+	_, err := suite.client.GetBalance(context.Background(), &trading.GetBalanceRequest{})
+	suite.Equal(codes.Unauthenticated, status.Code(err))
+
+	_, err = suite.client.GetBalance(withToken("unknown"), &trading.GetBalanceRequest{})
+	suite.Equal(codes.Unauthenticated, status.Code(err))
+
+	balance, err := suite.client.GetBalance(withToken("111222"), &trading.GetBalanceRequest{})
+	suite.Require().NoError(err)
+	suite.Equal(currency.NewUSD(0).String(), balance.Usd)
+}
+
+func (suite *grpcTestSuite) TestScopes() {
+	key := suite.createKey(map[string]interface{}{"name": "reader", "scopes": []string{"read"}})
+
+	_, err := suite.client.GetBalance(withToken(key.Token), &trading.GetBalanceRequest{})
+	suite.NoError(err)
+
+	_, err = suite.client.Deposit(withToken(key.Token), &trading.DepositRequest{
+		Currency: trading.Currency_CURRENCY_USD, Amount: "10",
+	})
+	suite.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = suite.client.PlaceOrder(withToken(key.Token), &trading.PlaceOrderRequest{
+		Side: trading.Side_SIDE_BUY, Quantity: "1", LimitPrice: "100",
+	})
+	suite.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func (suite *grpcTestSuite) deposit(token string, amount string) {
+	_, err := suite.client.Deposit(withToken(token), &trading.DepositRequest{
+		Currency: trading.Currency_CURRENCY_USD, Amount: amount,
+	})
+	suite.Require().NoError(err)
+}
+
+func (suite *grpcTestSuite) TestOrders() {
+	suite.deposit("111222", "50")
+	ctx := withToken("111222")
+	placed, err := suite.client.PlaceOrder(ctx, &trading.PlaceOrderRequest{
+		Side: trading.Side_SIDE_BUY, Quantity: "0.5", LimitPrice: "100", ClientOrderId: "grpc-1",
+	})
+	suite.Require().NoError(err)
+	suite.Equal("grpc-1", placed.ClientOrderId)
+	suite.Equal(trading.OrderState_ORDER_STATE_LIVE, placed.State)
+
+	_, err = suite.client.PlaceOrder(ctx, &trading.PlaceOrderRequest{
+		Side: trading.Side_SIDE_BUY, Quantity: "0.5", LimitPrice: "100", ClientOrderId: "grpc-1",
+	})
+	suite.Equal(codes.AlreadyExists, status.Code(err))
+
+	_, err = suite.client.PlaceOrder(ctx, &trading.PlaceOrderRequest{
+		Side: trading.Side_SIDE_BUY, Quantity: "-1", LimitPrice: "100",
+	})
+	suite.Equal(codes.InvalidArgument, status.Code(err))
+
+	order, err := suite.client.GetOrder(ctx, &trading.OrderRef{
+		Ref: &trading.OrderRef_ClientOrderId{ClientOrderId: "grpc-1"},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(placed.Id, order.Id)
+	suite.Equal(currency.NewBTC(0.5).String(), order.Quantity)
+	suite.Equal(currency.NewUSD(100).String(), order.LimitPrice)
+
+	list, err := suite.client.ListOrders(ctx, &trading.ListOrdersRequest{
+		States: []trading.OrderState{trading.OrderState_ORDER_STATE_LIVE},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(list.Orders, 1)
+	suite.Equal(placed.Id, list.Orders[0].Id)
+
+	ref := &trading.OrderRef{Ref: &trading.OrderRef_OrderId{OrderId: placed.Id}}
+	cancelled, err := suite.client.CancelOrder(ctx, ref)
+	suite.Require().NoError(err)
+	suite.Equal(trading.OrderState_ORDER_STATE_CANCELLED, cancelled.State)
+
+	order, err = suite.client.GetOrder(ctx, ref)
+	suite.Require().NoError(err)
+	suite.Equal(trading.OrderState_ORDER_STATE_CANCELLED, order.State)
+
+	_, err = suite.client.CancelOrder(ctx, ref)
+	suite.Equal(codes.FailedPrecondition, status.Code(err))
+}
+
+func (suite *grpcTestSuite) TestDeposit() {
+	ctx := withToken("111222")
+	response, err := suite.client.Deposit(ctx, &trading.DepositRequest{
+		Currency: trading.Currency_CURRENCY_BTC, Amount: "2",
+	})
+	suite.Require().NoError(err)
+	suite.True(response.Success)
+
+	balance, err := suite.client.GetBalance(ctx, &trading.GetBalanceRequest{})
+	suite.Require().NoError(err)
+	suite.Equal(currency.NewBTC(2).String(), balance.Btc)
+
+	_, err = suite.client.Deposit(ctx, &trading.DepositRequest{Amount: "2"})
+	suite.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (suite *grpcTestSuite) TestStreams() {
+	seller, err := suite.queries.CreateAccount(context.Background(), testqueries.CreateAccountParams{
+		Username: "Seller",
+		Token:    "333444",
+	})
+	suite.Require().NoError(err)
+	suite.setBalance(seller.ID, "BTC", currency.NewBTC(1).Internal())
+
+	suite.deposit("111222", "100")
+
+	ctx, cancel := context.WithCancel(withToken("111222"))
+	defer cancel()
+	orderEvents, err := suite.client.StreamOrderEvents(ctx, &trading.StreamOrderEventsRequest{})
+	suite.Require().NoError(err)
+	trades, err := suite.client.StreamTrades(ctx, &trading.StreamTradesRequest{})
+	suite.Require().NoError(err)
+	// the headers are sent once the subscriptions are active
+	_, err = orderEvents.Header()
+	suite.Require().NoError(err)
+	_, err = trades.Header()
+	suite.Require().NoError(err)
+
+	sell, err := suite.client.PlaceOrder(withToken("333444"), &trading.PlaceOrderRequest{
+		Side: trading.Side_SIDE_SELL, Quantity: "1", LimitPrice: "100",
+	})
+	suite.Require().NoError(err)
+	buy, err := suite.client.PlaceOrder(withToken("111222"), &trading.PlaceOrderRequest{
+		Side: trading.Side_SIDE_BUY, Quantity: "1", LimitPrice: "100", ClientOrderId: "streamed",
+	})
+	suite.Require().NoError(err)
+
+	event, err := orderEvents.Recv()
+	suite.Require().NoError(err)
+	suite.Equal(buy.Id, event.Id)
+	suite.Equal("streamed", event.ClientOrderId)
+
+	trade, err := trades.Recv()
+	suite.Require().NoError(err)
+	suite.Equal(trading.Side_SIDE_BUY, trade.TakerSide)
+	suite.Equal(currency.NewBTC(1).String(), trade.Quantity)
+	suite.Equal(currency.NewUSD(100).String(), trade.Price)
+	suite.Equal(trading.OrderState_ORDER_STATE_LIVE, sell.State)
+}
+
+func TestGRPC(t *testing.T) {
+	suite.Run(t, new(grpcTestSuite))
+}
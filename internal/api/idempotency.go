@@ -0,0 +1,183 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+const maxIdempotencyKeyLength = 255
+const maxIdempotentBodySize = 1 << 20
+
+// idempotencyKeyTTL is how long responses are kept for replays.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// bufferedResponse keeps the response body so that it can be stored before
+// it is sent. Headers are written directly to the underlying response.
+type bufferedResponse struct {
+	w      http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (response *bufferedResponse) Header() http.Header {
+	return response.w.Header()
+}
+
+func (response *bufferedResponse) Write(data []byte) (int, error) {
+	if response.status == 0 {
+		response.status = http.StatusOK
+	}
+	return response.body.Write(data)
+}
+
+func (response *bufferedResponse) WriteHeader(status int) {
+	if response.status == 0 {
+		response.status = status
+	}
+}
+
+// idempotent makes a handler safe to retry with the Idempotency-Key header.
+// The first response for a key is stored and repeated requests get it back
+// without running the handler again. Server errors and panics release the
+// key.
+func (server *Server) idempotent(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			handler(w, req)
+			return
+		}
+
+		if !isValidIdempotencyKey(key) {
+			writeValidationError(w, req, idempotencyKeyHeader, "malformed idempotency key")
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxIdempotentBodySize))
+		if err != nil {
+			writeError(w, req, http.StatusRequestEntityTooLarge, ErrorCodeRequestTooLarge, "request body too large")
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		requestHash := sha256.Sum256(body)
+
+		account := accountFromContext(req.Context())
+		endpoint := idempotencyEndpoint(req)
+		store := server.store.WithContext(req.Context())
+		existing, err := store.ReserveIdempotencyKey(
+			datastore.ReserveIdempotencyKeyParams{
+				AccountID:   account.ID,
+				Key:         key,
+				Endpoint:    endpoint,
+				RequestHash: requestHash[:],
+				NotBefore:   time.Now().Add(-idempotencyKeyTTL),
+			},
+		)
+		if err != nil {
+			writeInternalError(w, req, err)
+			return
+		}
+
+		if existing != nil {
+			replayIdempotentResponse(w, req, existing, endpoint, requestHash[:])
+			return
+		}
+
+		// the request may have been cancelled, the outcome has to be stored anyway
+		store = server.store.WithContext(context.Background())
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err := store.ReleaseIdempotencyKey(account.ID, key); err != nil {
+					log.Printf("releasing idempotency key %q failed: %v", key, err)
+				}
+				panic(recovered)
+			}
+		}()
+
+		response := &bufferedResponse{w: w}
+		handler(response, req)
+		if response.status == 0 {
+			response.status = http.StatusOK
+		}
+
+		if response.status >= http.StatusInternalServerError {
+			err = store.ReleaseIdempotencyKey(account.ID, key)
+		} else {
+			err = store.CompleteIdempotencyKey(
+				queries.CompleteIdempotencyKeyParams{
+					AccountID:      account.ID,
+					Key:            key,
+					ResponseStatus: int32(response.status),
+					ContentType:    w.Header().Get("Content-Type"),
+					ResponseBody:   response.body.Bytes(),
+				},
+			)
+		}
+		if err != nil {
+			log.Printf("storing response for idempotency key %q failed: %v", key, err)
+		}
+
+		w.WriteHeader(response.status)
+		w.Write(response.body.Bytes())
+	}
+}
+
+// idempotencyEndpoint identifies the handler of the request by its route name,
+// which is the same under every version prefix.
+func idempotencyEndpoint(req *http.Request) string {
+	if route := mux.CurrentRoute(req); route != nil && route.GetName() != "" {
+		return route.GetName()
+	}
+	return req.Method + " " + req.URL.Path
+}
+
+func replayIdempotentResponse(
+	w http.ResponseWriter,
+	req *http.Request,
+	existing *queries.IdempotencyKey,
+	endpoint string,
+	requestHash []byte,
+) {
+	if existing.Endpoint != endpoint || !bytes.Equal(existing.RequestHash, requestHash) {
+		writeError(
+			w, req, http.StatusUnprocessableEntity, ErrorCodeIdempotencyKeyReused,
+			"idempotency key was used for a different request",
+		)
+		return
+	}
+
+	if existing.ResponseStatus == 0 {
+		writeError(
+			w, req, http.StatusConflict, ErrorCodeRequestInProgress, "request with the idempotency key is in progress",
+		)
+		return
+	}
+
+	if existing.ContentType != "" {
+		w.Header().Set("Content-Type", existing.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(int(existing.ResponseStatus))
+	w.Write(existing.ResponseBody)
+}
+
+func isValidIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for _, char := range key {
+		if char < 0x21 || char > 0x7e {
+			return false
+		}
+	}
+	return true
+}
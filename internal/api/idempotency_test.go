@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type idempotencyTestSuite struct {
+	authTestSuite
+}
+
+func (suite *idempotencyTestSuite) doIdempotentRequest(
+	method, url, key string,
+	body interface{},
+) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	suite.Require().NoError(err)
+
+	request, err := http.NewRequest(method, url, bytes.NewReader(data))
+	suite.Require().NoError(err)
+	request.Header.Set("X-Token", "111222")
+	request.Header.Set(idempotencyKeyHeader, key)
+
+	recorder := httptest.NewRecorder()
+	suite.server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (suite *idempotencyTestSuite) balance() getBalanceResponse {
+	recorder := suite.doRequest(http.MethodGet, "/balance", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var response getBalanceResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	return response
+}
+
+func (suite *idempotencyTestSuite) TestDeposit() {
+	deposit := map[string]string{"currency": "usd", "topupAmount": "100"}
+
+	first := suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-1", deposit)
+	suite.Require().Equal(http.StatusOK, first.Code)
+	second := suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-1", deposit)
+	suite.Require().Equal(http.StatusOK, second.Code)
+	suite.Equal(first.Body.String(), second.Body.String())
+	suite.Equal("application/json", second.Header().Get("Content-Type"))
+	suite.Equal("true", second.Header().Get("Idempotent-Replayed"))
+	suite.Equal(currency.NewUSD(100).String(), suite.balance().USD)
+
+	suite.Equal(
+		http.StatusOK,
+		suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-2", deposit).Code,
+	)
+	suite.Equal(currency.NewUSD(200).String(), suite.balance().USD)
+
+	deposit["topupAmount"] = "50"
+	suite.Equal(
+		http.StatusUnprocessableEntity,
+		suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-1", deposit).Code,
+	)
+}
+
+func (suite *idempotencyTestSuite) TestStandingOrder() {
+	order := map[string]string{"type": "buy", "quantity": "1", "limitPrice": "100"}
+
+	first := suite.doIdempotentRequest(http.MethodPost, "/standing_orders", "order-1", order)
+	suite.Require().Equal(http.StatusOK, first.Code)
+	second := suite.doIdempotentRequest(http.MethodPost, "/standing_orders", "order-1", order)
+	suite.Require().Equal(http.StatusOK, second.Code)
+	suite.Equal(first.Body.String(), second.Body.String())
+
+	orders, err := suite.queries.GetStandingOrders(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal(1, len(orders))
+}
+
+func (suite *idempotencyTestSuite) TestVersionPrefixes() {
+	deposit := map[string]string{"currency": "usd", "topupAmount": "100"}
+
+	first := suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-1", deposit)
+	suite.Require().Equal(http.StatusOK, first.Code)
+	for _, url := range []string{"/v1/balance", "/v2/balance"} {
+		retried := suite.doIdempotentRequest(http.MethodPost, url, "deposit-1", deposit)
+		suite.Require().Equal(http.StatusOK, retried.Code, url)
+		suite.Equal("true", retried.Header().Get("Idempotent-Replayed"), url)
+	}
+	suite.Equal(currency.NewUSD(100).String(), suite.balance().USD)
+
+	order := map[string]string{"type": "buy", "quantity": "1", "limitPrice": "100"}
+	suite.Equal(
+		http.StatusUnprocessableEntity,
+		suite.doIdempotentRequest(http.MethodPost, "/v1/standing_orders", "deposit-1", order).Code,
+	)
+}
+
+func (suite *idempotencyTestSuite) TestErrorsAreReplayed() {
+	invalid := map[string]string{"currency": "usd", "topupAmount": "a lot"}
+	suite.Equal(
+		http.StatusBadRequest,
+		suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-1", invalid).Code,
+	)
+	recorder := suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-1", invalid)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal("true", recorder.Header().Get("Idempotent-Replayed"))
+
+	suite.Equal(
+		http.StatusBadRequest,
+		suite.doIdempotentRequest(http.MethodPost, "/balance", "bad key", invalid).Code,
+	)
+}
+
+func (suite *idempotencyTestSuite) TestLargeBody() {
+	deposit := map[string]string{"currency": "usd", "topupAmount": strings.Repeat("1", maxIdempotentBodySize)}
+	suite.Equal(
+		http.StatusRequestEntityTooLarge,
+		suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-1", deposit).Code,
+	)
+}
+
+func (suite *idempotencyTestSuite) TestPanicReleasesKey() {
+	account, err := suite.store.GetAccountByToken("111222")
+	suite.Require().NoError(err)
+
+	request := httptest.NewRequest(http.MethodPost, "/balance", bytes.NewReader([]byte("{}")))
+	request.Header.Set(idempotencyKeyHeader, "deposit-1")
+	request = request.WithContext(context.WithValue(request.Context(), accountContextKey{}, account))
+	handler := suite.server.idempotent(func(w http.ResponseWriter, req *http.Request) { panic("handler failed") })
+	suite.Panics(func() { handler(httptest.NewRecorder(), request) })
+
+	deposit := map[string]string{"currency": "usd", "topupAmount": "100"}
+	suite.Equal(http.StatusOK, suite.doIdempotentRequest(http.MethodPost, "/balance", "deposit-1", deposit).Code)
+}
+
+func TestIdempotency(t *testing.T) {
+	suite.Run(t, new(idempotencyTestSuite))
+}
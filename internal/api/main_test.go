@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"github.com/galcik/vlexchange/internal/datastore"
 	"github.com/galcik/vlexchange/internal/datastore/testqueries"
@@ -65,3 +66,20 @@ func (suite *TestServerSuite) BeforeTest(suiteName, testName string) {
 func (suite *TestServerSuite) AfterTest(suiteName, testName string) {
 	suite.db.Close()
 }
+
+func (suite *TestServerSuite) setBalance(accountId int32, asset string, amount int64) {
+	err := suite.queries.SetBalance(
+		context.Background(),
+		testqueries.SetBalanceParams{AccountID: accountId, Asset: asset, Amount: amount},
+	)
+	suite.Require().NoError(err)
+}
+
+func (suite *TestServerSuite) getBalance(accountId int32, asset string) int64 {
+	amount, err := suite.queries.GetBalance(
+		context.Background(),
+		testqueries.GetBalanceParams{AccountID: accountId, Asset: asset},
+	)
+	suite.Require().NoError(err)
+	return amount
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"net/http"
+	"strings"
+)
+
+type marketResponse struct {
+	Symbol         string `json:"symbol"`
+	BaseAsset      string `json:"baseAsset"`
+	BasePrecision  int    `json:"basePrecision"`
+	QuoteAsset     string `json:"quoteAsset"`
+	QuotePrecision int    `json:"quotePrecision"`
+}
+
+type getMarketsResponse struct {
+	Markets []marketResponse `json:"markets"`
+}
+
+func (server *Server) handleGetMarkets(w http.ResponseWriter, req *http.Request) {
+	markets, err := server.store.WithContext(req.Context()).GetMarkets()
+	if err != nil {
+		writeInternalError(w, req, err)
+		return
+	}
+
+	response := getMarketsResponse{Markets: make([]marketResponse, len(markets))}
+	for i, market := range markets {
+		response.Markets[i] = marketResponse{
+			Symbol:         market.Symbol,
+			BaseAsset:      market.Base.Symbol,
+			BasePrecision:  market.Base.Precision,
+			QuoteAsset:     market.Quote.Symbol,
+			QuotePrecision: market.Quote.Precision,
+		}
+	}
+	writeJSONResponse(w, response)
+}
+
+// findMarket resolves the market parameter of requests, BTC-USD when it is
+// empty. Unknown markets are reported by *FieldError.
+func (server *Server) findMarket(ctx context.Context, symbol string) (*datastore.Market, error) {
+	if symbol == "" {
+		symbol = datastore.DefaultMarket
+	}
+	symbol = strings.ToUpper(symbol)
+
+	market, err := server.store.WithContext(ctx).GetMarketBySymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if market == nil {
+		return nil, &FieldError{Field: "market", Message: fmt.Sprintf("unknown market %q", symbol)}
+	}
+	return market, nil
+}
+
+// getMarkets returns all markets by their ids.
+func (server *Server) getMarkets(ctx context.Context) (map[int32]*datastore.Market, error) {
+	markets, err := server.store.WithContext(ctx).GetMarkets()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int32]*datastore.Market, len(markets))
+	for i := range markets {
+		result[markets[i].ID] = &markets[i]
+	}
+	return result, nil
+}
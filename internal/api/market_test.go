@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type marketTestSuite struct {
+	authTestSuite
+}
+
+func (suite *marketTestSuite) TestGetMarkets() {
+	_, err := suite.store.CreateAsset("ETH", 8)
+	suite.Require().NoError(err)
+	_, err = suite.store.CreateMarket("ETH", "USD")
+	suite.Require().NoError(err)
+
+	recorder := suite.doRequest(http.MethodGet, "/markets", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var response getMarketsResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.Equal(
+		[]marketResponse{
+			{Symbol: "BTC-USD", BaseAsset: "BTC", BasePrecision: 8, QuoteAsset: "USD", QuotePrecision: 2},
+			{Symbol: "ETH-USD", BaseAsset: "ETH", BasePrecision: 8, QuoteAsset: "USD", QuotePrecision: 2},
+		},
+		response.Markets,
+	)
+
+	recorder = suite.doRequest(http.MethodPost, "/balance", "111222", map[string]string{
+		"currency": "usd", "topupAmount": "1000",
+	})
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	recorder = suite.doRequest(http.MethodPost, "/standing_orders", "111222", map[string]string{
+		"market": "eth-usd", "type": "buy", "quantity": "0.5", "limitPrice": "1000",
+	})
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+
+	recorder = suite.doRequest(http.MethodGet, "/order_book?market=ETH-USD", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.JSONEq(
+		`{"market":"ETH-USD","bids":[{"price":"1000.00","quantity":"0.50000000","orders":1}],"asks":[]}`,
+		recorder.Body.String(),
+	)
+
+	recorder = suite.doRequest(http.MethodGet, "/order_book", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.JSONEq(`{"market":"BTC-USD","bids":[],"asks":[]}`, recorder.Body.String())
+}
+
+func TestMarkets(t *testing.T) {
+	suite.Run(t, new(marketTestSuite))
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"net/http"
+	"strconv"
+)
+
+const defaultOrderBookDepth = 20
+const maxOrderBookDepth = 100
+
+type orderBookLevel struct {
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+	Orders   int32  `json:"orders"`
+}
+
+type getOrderBookResponse struct {
+	Market string           `json:"market"`
+	Bids   []orderBookLevel `json:"bids"`
+	Asks   []orderBookLevel `json:"asks"`
+}
+
+// handleGetOrderBook returns the book of the market parameter, BTC-USD by
+// default.
+func (server *Server) handleGetOrderBook(w http.ResponseWriter, req *http.Request) {
+	market, err := server.findMarket(req.Context(), req.URL.Query().Get("market"))
+	var fieldError *FieldError
+	if errors.As(err, &fieldError) {
+		writeValidationError(w, req, fieldError.Field, fieldError.Message)
+		return
+	}
+	if err != nil {
+		writeInternalError(w, req, err)
+		return
+	}
+
+	depth := defaultOrderBookDepth
+	if depthStr := req.URL.Query().Get("depth"); depthStr != "" {
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil || depth < 1 || depth > maxOrderBookDepth {
+			writeValidationError(w, req, "depth", fmt.Sprintf("depth must be between 1 and %d", maxOrderBookDepth))
+			return
+		}
+	}
+
+	book, err := server.store.WithContext(req.Context()).GetOrderBook(market.ID, int32(depth))
+	if err != nil {
+		writeInternalError(w, req, err)
+		return
+	}
+
+	writeJSONResponse(w, getOrderBookResponse{
+		Market: market.Symbol,
+		Bids:   newOrderBookLevels(market, book.Bids),
+		Asks:   newOrderBookLevels(market, book.Asks),
+	})
+}
+
+func newOrderBookLevels(market *datastore.Market, rows []queries.GetOrderBookLevelsRow) []orderBookLevel {
+	levels := make([]orderBookLevel, len(rows))
+	for i, row := range rows {
+		levels[i] = orderBookLevel{
+			Price:    market.Quote.Format(int64(row.LimitPrice)),
+			Quantity: market.Base.Format(row.Quantity),
+			Orders:   row.OrderCount,
+		}
+	}
+	return levels
+}
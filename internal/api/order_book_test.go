@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore/testqueries"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type orderBookTestSuite struct {
+	authTestSuite
+}
+
+func (suite *orderBookTestSuite) createOrder(orderType testqueries.OrderType, quantity float64, price float64) {
+	accounts, err := suite.queries.GetAccounts(context.Background())
+	suite.Require().NoError(err)
+	_, err = suite.queries.CreateStandingOrder(
+		context.Background(), testqueries.CreateStandingOrderParams{
+			AccountID:  accounts[0].ID,
+			Type:       orderType,
+			State:      testqueries.OrderStateLive,
+			Quantity:   currency.Amount(currency.NewBTC(quantity)),
+			LimitPrice: currency.Amount(currency.NewUSD(price)),
+		},
+	)
+	suite.Require().NoError(err)
+}
+
+func (suite *orderBookTestSuite) TestGetOrderBook() {
+	suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/order_book", "", nil).Code)
+
+	recorder := suite.doRequest(http.MethodGet, "/order_book", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.JSONEq(`{"market":"BTC-USD","bids":[],"asks":[]}`, recorder.Body.String())
+
+	suite.createOrder(testqueries.OrderTypeBuy, 1, 90)
+	suite.createOrder(testqueries.OrderTypeBuy, 0.5, 95)
+	suite.createOrder(testqueries.OrderTypeBuy, 0.25, 95)
+	suite.createOrder(testqueries.OrderTypeSell, 2, 100)
+
+	recorder = suite.doRequest(http.MethodGet, "/v2/order_book?depth=1", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var response getOrderBookResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.Equal(
+		[]orderBookLevel{{Price: currency.NewUSD(95).String(), Quantity: currency.NewBTC(0.75).String(), Orders: 2}},
+		response.Bids,
+	)
+	suite.Equal(
+		[]orderBookLevel{{Price: currency.NewUSD(100).String(), Quantity: currency.NewBTC(2).String(), Orders: 1}},
+		response.Asks,
+	)
+
+	suite.Equal(http.StatusBadRequest, suite.doRequest(http.MethodGet, "/order_book?depth=0", "111222", nil).Code)
+	suite.Equal(http.StatusBadRequest, suite.doRequest(http.MethodGet, "/order_book?market=XYZ", "111222", nil).Code)
+}
+
+func TestOrderBook(t *testing.T) {
+	suite.Run(t, new(orderBookTestSuite))
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/coinmarket"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"time"
+)
+
+// lastTradeMaxAge is the age of trades which no longer tell the price.
+const lastTradeMaxAge = time.Hour
+
+// lastTradeProvider quotes the price of the last trade of the BTC-USD market.
+type lastTradeProvider struct {
+	store datastore.Store
+}
+
+func (provider *lastTradeProvider) Name() string {
+	return "vlexchange"
+}
+
+func (provider *lastTradeProvider) GetBTCQuoteInUSD(ctx context.Context) (coinmarket.Quote, error) {
+	trade, err := provider.store.WithContext(ctx).GetLastTrade(datastore.DefaultMarketID)
+	if err != nil {
+		return coinmarket.Quote{}, err
+	}
+	if trade == nil || time.Since(trade.CreatedAt) > lastTradeMaxAge {
+		return coinmarket.Quote{}, coinmarket.ErrNoQuote
+	}
+	return coinmarket.Quote{Price: currency.USD(trade.Price), Timestamp: trade.CreatedAt}, nil
+}
+
+// newPriceRegistry aggregates the public price sources and the last trade.
+// CoinMarketCap is asked only with an API key.
+func newPriceRegistry(
+	store datastore.Store,
+	coinmarketApiKey string,
+	options coinmarket.RegistryOptions,
+) *coinmarket.Registry {
+	registry := coinmarket.NewRegistry(
+		options,
+		coinmarket.NewCoinbaseProvider(),
+		coinmarket.NewBitstampProvider(),
+		&lastTradeProvider{store: store},
+	)
+	if coinmarketApiKey != "" {
+		registry.Register(&coinmarket.CoinmarketServiceImpl{ApiKey: coinmarketApiKey})
+	}
+	return registry
+}
+
+// storePriceHistory keeps the reference prices in the price_history table.
+type storePriceHistory struct {
+	store datastore.Store
+}
+
+func (history *storePriceHistory) RecordBTCQuote(ctx context.Context, quote coinmarket.Quote) error {
+	return history.store.WithContext(ctx).RecordPrice(quote.Price, quote.Timestamp)
+}
+
+func (history *storePriceHistory) GetBTCQuotesAround(ctx context.Context, at time.Time) (
+	*coinmarket.Quote,
+	*coinmarket.Quote,
+	error,
+) {
+	before, after, err := history.store.WithContext(ctx).GetPricesAround(at)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newHistoryQuote(before), newHistoryQuote(after), nil
+}
+
+func newHistoryQuote(price *queries.PriceHistory) *coinmarket.Quote {
+	if price == nil {
+		return nil
+	}
+	return &coinmarket.Quote{Price: price.Price, Timestamp: price.QuotedAt}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultPriceHistoryPeriod = 24 * time.Hour
+const defaultPriceHistoryLimit = 100
+const maxPriceHistoryLimit = 1000
+
+type pricePoint struct {
+	Price     currency.USD `json:"price"`
+	Timestamp string       `json:"timestamp"`
+}
+
+type getPriceHistoryResponse struct {
+	Prices []pricePoint `json:"prices"`
+}
+
+// handleGetPriceHistory returns the recorded reference prices, by default
+// those of the last day.
+func (server *Server) handleGetPriceHistory(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	to := time.Now()
+	if toStr := query.Get("to"); toStr != "" {
+		var err error
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			writeValidationError(w, req, "to", "malformed to")
+			return
+		}
+	}
+	from := to.Add(-defaultPriceHistoryPeriod)
+	if fromStr := query.Get("from"); fromStr != "" {
+		var err error
+		if from, err = time.Parse(time.RFC3339, fromStr); err != nil {
+			writeValidationError(w, req, "from", "malformed from")
+			return
+		}
+	}
+	limit := defaultPriceHistoryLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPriceHistoryLimit {
+			writeValidationError(w, req, "limit", fmt.Sprintf("limit must be between 1 and %d", maxPriceHistoryLimit))
+			return
+		}
+	}
+
+	prices, err := server.store.WithContext(req.Context()).GetPriceHistory(from, to, int32(limit))
+	if err != nil {
+		writeInternalError(w, req, err)
+		return
+	}
+
+	response := getPriceHistoryResponse{Prices: make([]pricePoint, len(prices))}
+	for i, price := range prices {
+		response.Prices[i] = pricePoint{
+			Price:     price.Price,
+			Timestamp: price.QuotedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	writeJSONResponse(w, response)
+}
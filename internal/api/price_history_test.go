@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/galcik/vlexchange/internal/coinmarket"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type priceHistoryTestSuite struct {
+	authTestSuite
+	start time.Time
+}
+
+func (suite *priceHistoryTestSuite) BeforeTest(suiteName, testName string) {
+	suite.authTestSuite.BeforeTest(suiteName, testName)
+	suite.server.coinmarketService = coinmarket.NewHistoryService(
+		suite.server.coinmarketService, &storePriceHistory{store: suite.store}, coinmarket.DefaultHistoryOptions(),
+	)
+
+	suite.start = time.Date(2021, 2, 16, 15, 0, 0, 0, time.UTC)
+	for i, price := range []float64{40000, 60000} {
+		quotedAt := suite.start.Add(time.Duration(i) * time.Minute)
+		suite.Require().NoError(suite.store.RecordPrice(currency.NewUSD(price), quotedAt))
+	}
+}
+
+func (suite *priceHistoryTestSuite) TestGetPriceHistory() {
+	suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/price_history", "", nil).Code)
+
+	recorder := suite.doRequest(http.MethodGet, "/price_history", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.JSONEq(`{"prices":[]}`, recorder.Body.String())
+
+	recorder = suite.doRequest(
+		http.MethodGet, "/v2/price_history?from=2021-02-16T14:00:00Z&to=2021-02-16T16:00:00Z&limit=1", "111222", nil,
+	)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var response getPriceHistoryResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.Equal([]pricePoint{{Price: currency.NewUSD(40_000), Timestamp: "2021-02-16T15:00:00Z"}}, response.Prices)
+
+	suite.Equal(http.StatusBadRequest, suite.doRequest(http.MethodGet, "/price_history?limit=0", "111222", nil).Code)
+}
+
+func (suite *priceHistoryTestSuite) TestGetBalanceAt() {
+	account, err := suite.store.GetAccountByToken("111222")
+	suite.Require().NoError(err)
+	_, err = suite.store.DepositAccount(account.ID, "BTC", currency.NewBTC(0.5).Internal())
+	suite.Require().NoError(err)
+
+	recorder := suite.doRequest(http.MethodGet, "/v2/balance?at=2021-02-16T15:00:30Z", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var response getBalanceResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.Equal(currency.NewUSD(25_000), response.USDEquivalent)
+	suite.Equal("2021-02-16T15:00:30Z", response.PriceTimestamp)
+
+	recorder = suite.doRequest(http.MethodGet, "/v2/balance?at=2021-02-16T12:00:00Z", "111222", nil)
+	suite.Equal(http.StatusNotFound, recorder.Code)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	recorder = suite.doRequest(http.MethodGet, "/v2/balance?at="+future, "111222", nil)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+}
+
+func TestPriceHistory(t *testing.T) {
+	suite.Run(t, new(priceHistoryTestSuite))
+}
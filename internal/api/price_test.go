@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/coinmarket"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/mocks"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestLastTradeProvider(t *testing.T) {
+	store := &mocks.Store{}
+	store.On("WithContext", mock.Anything).Return(store)
+	provider := &lastTradeProvider{store: store}
+
+	store.On("GetLastTrade", datastore.DefaultMarketID).Return(nil, nil).Once()
+	_, err := provider.GetBTCQuoteInUSD(context.Background())
+	assert.ErrorIs(t, err, coinmarket.ErrNoQuote)
+
+	tradedAt := time.Now().Add(-time.Minute)
+	store.On("GetLastTrade", datastore.DefaultMarketID).Return(&queries.Trade{Price: currency.Amount(currency.NewUSD(49000)), CreatedAt: tradedAt}, nil).
+		Once()
+	quote, err := provider.GetBTCQuoteInUSD(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(49000), quote.Price)
+	assert.Equal(t, tradedAt, quote.Timestamp)
+
+	store.On("GetLastTrade", datastore.DefaultMarketID).Return(&queries.Trade{Price: 1, CreatedAt: time.Now().Add(-2 * lastTradeMaxAge)}, nil).
+		Once()
+	_, err = provider.GetBTCQuoteInUSD(context.Background())
+	assert.ErrorIs(t, err, coinmarket.ErrNoQuote)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"github.com/galcik/vlexchange/internal/ratelimit"
+	"github.com/gorilla/mux"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultTier = "standard"
+
+// RateLimitPolicy configures the token buckets kept per client IP and per
+// account. Accounts with a tier missing in Tiers use the default tier.
+type RateLimitPolicy struct {
+	IP    ratelimit.Limit
+	Tiers map[string]ratelimit.Limit
+}
+
+func DefaultRateLimitPolicy() RateLimitPolicy {
+	return RateLimitPolicy{
+		IP: ratelimit.Limit{Rate: 20, Burst: 40},
+		Tiers: map[string]ratelimit.Limit{
+			defaultTier: {Rate: 10, Burst: 20},
+			"pro":       {Rate: 50, Burst: 100},
+		},
+	}
+}
+
+func (policy *RateLimitPolicy) tierLimit(tier string) ratelimit.Limit {
+	if limit, ok := policy.Tiers[tier]; ok {
+		return limit
+	}
+	return policy.Tiers[defaultTier]
+}
+
+// routeCosts holds the number of tokens taken by named routes, other routes
+// cost a single token.
+var routeCosts = map[string]int{
+	"register":                      5,
+	"postBalance":                   3,
+	"postStandingOrder":             5,
+	"deleteStandingOrder":           2,
+	"deleteStandingOrderByClientId": 2,
+	"postApiKey":                    5,
+}
+
+func routeCost(req *http.Request) int {
+	route := mux.CurrentRoute(req)
+	if route == nil {
+		return 1
+	}
+	if cost, ok := routeCosts[route.GetName()]; ok {
+		return cost
+	}
+	return 1
+}
+
+// limitIP applies the per IP limit to every request.
+func (server *Server) limitIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			key := req.RemoteAddr
+			if ip := remoteIP(req); ip != nil {
+				key = ip.String()
+			}
+
+			result := server.ipLimiter.Allow(key, server.rateLimits.IP, routeCost(req))
+			if !writeRateLimit(w, req, result) {
+				return
+			}
+			next.ServeHTTP(w, req)
+		},
+	)
+}
+
+// limitAccount applies the limit of the account tier. It must be used after
+// authenticateAccount.
+func (server *Server) limitAccount(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			account := accountFromContext(req.Context())
+			result := server.accountLimiter.Allow(
+				strconv.Itoa(int(account.ID)), server.rateLimits.tierLimit(account.Tier), routeCost(req),
+			)
+			if !writeRateLimit(w, req, result) {
+				return
+			}
+			next.ServeHTTP(w, req)
+		},
+	)
+}
+
+// writeRateLimit sets the RateLimit headers and rejects the request when the
+// limit is exceeded. When several limits apply, the headers describe the one
+// with fewer remaining tokens.
+func writeRateLimit(w http.ResponseWriter, req *http.Request, result ratelimit.Result) bool {
+	header := w.Header()
+	remaining, err := strconv.Atoi(header.Get("RateLimit-Remaining"))
+	if err != nil || result.Remaining <= remaining || !result.Allowed {
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	}
+
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		writeError(w, req, http.StatusTooManyRequests, ErrorCodeRateLimited, "rate limit exceeded")
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/datastore/testqueries"
+	"github.com/galcik/vlexchange/internal/ratelimit"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type rateLimitTestSuite struct {
+	authTestSuite
+}
+
+func (suite *rateLimitTestSuite) BeforeTest(suiteName, testName string) {
+	suite.authTestSuite.BeforeTest(suiteName, testName)
+	suite.server.rateLimits = RateLimitPolicy{
+		IP: ratelimit.Limit{Rate: 0.001, Burst: 100},
+		Tiers: map[string]ratelimit.Limit{
+			defaultTier: {Rate: 0.001, Burst: 6},
+			"pro":       {Rate: 0.001, Burst: 12},
+		},
+	}
+}
+
+func (suite *rateLimitTestSuite) TestAccountLimit() {
+	recorder := suite.doRequest(http.MethodGet, "/balance", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.Equal("6", recorder.Header().Get("RateLimit-Limit"))
+	suite.Equal("5", recorder.Header().Get("RateLimit-Remaining"))
+	suite.NotEmpty(recorder.Header().Get("RateLimit-Reset"))
+
+	order := map[string]string{"type": "buy", "quantity": "1", "limitPrice": "1"}
+	recorder = suite.doRequest(http.MethodPost, "/standing_orders", "111222", order)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.Equal("0", recorder.Header().Get("RateLimit-Remaining"))
+
+	recorder = suite.doRequest(http.MethodGet, "/balance", "111222", nil)
+	suite.Equal(http.StatusTooManyRequests, recorder.Code)
+	suite.NotEmpty(recorder.Header().Get("Retry-After"))
+
+	_, err := suite.queries.CreateAccount(
+		context.Background(), testqueries.CreateAccountParams{Username: "Other", Token: "333444"},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodGet, "/balance", "333444", nil).Code)
+}
+
+func (suite *rateLimitTestSuite) TestTier() {
+	_, err := suite.db.Exec("UPDATE account SET tier = 'pro' WHERE token = '111222'")
+	suite.Require().NoError(err)
+
+	recorder := suite.doRequest(http.MethodGet, "/balance", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.Equal("12", recorder.Header().Get("RateLimit-Limit"))
+}
+
+func (suite *rateLimitTestSuite) TestIPLimit() {
+	suite.server.rateLimits.IP = ratelimit.Limit{Rate: 0.001, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/balance", "unknown", nil).Code)
+	}
+	recorder := suite.doRequest(http.MethodGet, "/balance", "111222", nil)
+	suite.Equal(http.StatusTooManyRequests, recorder.Code)
+	suite.Equal("3", recorder.Header().Get("RateLimit-Limit"))
+}
+
+func (suite *rateLimitTestSuite) TestOption() {
+	policy := DefaultRateLimitPolicy()
+	policy.IP = ratelimit.Limit{Rate: 0.001, Burst: 2}
+	server, err := NewServer(suite.store, WithRateLimits(policy))
+	suite.Require().NoError(err)
+	suite.server = server
+
+	for i := 0; i < 2; i++ {
+		suite.Equal(http.StatusUnauthorized, suite.doRequest(http.MethodGet, "/balance", "unknown", nil).Code)
+	}
+	suite.Equal(http.StatusTooManyRequests, suite.doRequest(http.MethodGet, "/balance", "unknown", nil).Code)
+}
+
+func TestRateLimits(t *testing.T) {
+	suite.Run(t, new(rateLimitTestSuite))
+}
@@ -1,10 +1,7 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
-	"github.com/galcik/vlexchange/internal/datastore/queries"
-	"github.com/google/uuid"
 	"net/http"
 )
 
@@ -22,31 +19,15 @@ func (server *Server) handleRegister(w http.ResponseWriter, req *http.Request) {
 
 	err := json.NewDecoder(req.Body).Decode(&payload)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeMalformedRequest(w, req)
 		return
 	}
 
-	token, err := uuid.NewRandom()
+	_, token, err := store.RegisterAccount(payload.Username)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, req, err)
 		return
 	}
 
-	var newAccount queries.Account
-	err = store.ExecuteTx(
-		func(ctx context.Context, q queries.Querier) error {
-			newAccount, err = q.CreateAccount(
-				ctx,
-				queries.CreateAccountParams{Username: payload.Username, Token: token.String()},
-			)
-			return err
-		},
-	)
-
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	writeJSONResponse(w, registerResponse{Token: newAccount.Token})
+	writeJSONResponse(w, registerResponse{Token: token})
 }
@@ -38,7 +38,10 @@ func (suite *registerTestSuite) TestRegister() {
 	suite.Require().NoError(json.Unmarshal(response, &jsonResponse))
 	token, ok := jsonResponse["token"]
 	suite.True(ok)
-	suite.Equal(accounts[0].Token, token)
+	key, err := suite.store.GetApiKeyByToken(token)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(key)
+	suite.Equal(accounts[0].ID, key.AccountID)
 }
 
 func TestRegistration(t *testing.T) {
@@ -1,25 +1,165 @@
 package api
 
 import (
+	"github.com/galcik/vlexchange/internal/apikey"
 	"github.com/galcik/vlexchange/internal/coinmarket"
 	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/events"
+	"github.com/galcik/vlexchange/internal/ratelimit"
+	"github.com/galcik/vlexchange/internal/webhook"
 	"github.com/gorilla/mux"
 	"net/http"
+	"sync"
 )
 
-var CoinmarketApiKey string
-
 type Server struct {
 	store             datastore.Store
 	router            *mux.Router
 	coinmarketService coinmarket.CoinmarketService
+	webhookPolicy     webhook.Policy
+	webhookClient     *webhook.Client
+	rateLimits        RateLimitPolicy
+	ipLimiter         *ratelimit.Limiter
+	accountLimiter    *ratelimit.Limiter
+	versions          []*apiVersion
+	events            *events.Broker
+	// serverSecret derives the signing keys of API keys and the webhook secrets
+	serverSecret []byte
+	// legacyTokens allows the deprecated account tokens, their first use per
+	// account is logged
+	legacyTokens        bool
+	legacyTokenAccounts sync.Map
+}
+
+type serverOptions struct {
+	coinmarketApiKey    string
+	priceService        coinmarket.CoinmarketService
+	priceCache          coinmarket.CacheOptions
+	priceRegistry       coinmarket.RegistryOptions
+	priceHistory        coinmarket.HistoryOptions
+	webhookPolicy       webhook.Policy
+	rateLimits          RateLimitPolicy
+	serverSecret        []byte
+	disableLegacyTokens bool
+}
+
+type ServerOption func(*serverOptions)
+
+// WithCoinmarketApiKey adds CoinMarketCap to the live price sources.
+func WithCoinmarketApiKey(apiKey string) ServerOption {
+	return func(options *serverOptions) {
+		options.coinmarketApiKey = apiKey
+	}
+}
+
+// WithPriceService replaces the live price sources, e.g. by an offline
+// provider. The service is used without caching.
+func WithPriceService(service coinmarket.CoinmarketService) ServerOption {
+	return func(options *serverOptions) {
+		options.priceService = service
+	}
+}
+
+// WithPriceCache sets how long the prices of the live price sources are
+// cached and when they are refreshed.
+func WithPriceCache(cacheOptions coinmarket.CacheOptions) ServerOption {
+	return func(options *serverOptions) {
+		options.priceCache = cacheOptions
+	}
+}
+
+// WithPriceRegistry sets how the prices of the live price sources are
+// aggregated.
+func WithPriceRegistry(registryOptions coinmarket.RegistryOptions) ServerOption {
+	return func(options *serverOptions) {
+		options.priceRegistry = registryOptions
+	}
+}
+
+// WithPriceHistory sets how often the BTC price is recorded and how past
+// prices are looked up.
+func WithPriceHistory(historyOptions coinmarket.HistoryOptions) ServerOption {
+	return func(options *serverOptions) {
+		options.priceHistory = historyOptions
+	}
+}
+
+// WithWebhookPolicy sets the webhook URLs accepted in orders and how webhooks
+// are delivered.
+func WithWebhookPolicy(policy webhook.Policy) ServerOption {
+	return func(options *serverOptions) {
+		options.webhookPolicy = policy
+	}
+}
+
+// WithRateLimits sets the request rate limits of addresses and account tiers.
+func WithRateLimits(policy RateLimitPolicy) ServerOption {
+	return func(options *serverOptions) {
+		options.rateLimits = policy
+	}
 }
 
-// NewServer creates a new HTTP server and set up routing.
-func NewServer(store datastore.Store) (*Server, error) {
+// WithServerSecret sets the secret the signing keys of API keys and the
+// webhook secrets of accounts are derived from. Without it a random secret is
+// used and they change when the server restarts.
+func WithServerSecret(secret []byte) ServerOption {
+	return func(options *serverOptions) {
+		options.serverSecret = secret
+	}
+}
+
+// WithLegacyTokens allows or refuses the deprecated account tokens issued
+// before API keys. They are allowed by default and keep all scopes.
+func WithLegacyTokens(allowed bool) ServerOption {
+	return func(options *serverOptions) {
+		options.disableLegacyTokens = !allowed
+	}
+}
+
+// NewServer creates a new HTTP server and set up routing. Without options
+// the BTC price is aggregated from the live price sources and the default
+// policies apply.
+func NewServer(store datastore.Store, options ...ServerOption) (*Server, error) {
+	versions, err := loadVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	serverOptions := serverOptions{
+		priceCache:    coinmarket.DefaultCacheOptions(),
+		priceRegistry: coinmarket.DefaultRegistryOptions(),
+		priceHistory:  coinmarket.DefaultHistoryOptions(),
+		webhookPolicy: webhook.DefaultPolicy(),
+		rateLimits:    DefaultRateLimitPolicy(),
+	}
+	for _, option := range options {
+		option(&serverOptions)
+	}
+	priceService := serverOptions.priceService
+	if priceService == nil {
+		registry := newPriceRegistry(store, serverOptions.coinmarketApiKey, serverOptions.priceRegistry)
+		priceService = coinmarket.NewCachedService(registry, serverOptions.priceCache)
+	}
+	priceHistory := &storePriceHistory{store: store}
+	serverSecret := serverOptions.serverSecret
+	if len(serverSecret) == 0 {
+		if serverSecret, err = apikey.GenerateServerSecret(); err != nil {
+			return nil, err
+		}
+	}
+
 	server := &Server{
 		store:             store,
-		coinmarketService: coinmarket.NewCoinmarketService(CoinmarketApiKey),
+		coinmarketService: coinmarket.NewHistoryService(priceService, priceHistory, serverOptions.priceHistory),
+		webhookPolicy:     serverOptions.webhookPolicy,
+		webhookClient:     webhook.NewClient(serverOptions.webhookPolicy),
+		rateLimits:        serverOptions.rateLimits,
+		ipLimiter:         ratelimit.NewLimiter(),
+		accountLimiter:    ratelimit.NewLimiter(),
+		versions:          versions,
+		events:            events.NewBroker(),
+		serverSecret:      serverSecret,
+		legacyTokens:      !serverOptions.disableLegacyTokens,
 	}
 	server.setupRouter()
 	return server, nil
@@ -27,24 +167,85 @@ func NewServer(store datastore.Store) (*Server, error) {
 
 func (server *Server) setupRouter() {
 	server.router = mux.NewRouter()
-	server.router.HandleFunc("/register", server.handleRegister).Methods(http.MethodPost)
-	server.router.HandleFunc("/balance", server.handleGetBalance).Methods(http.MethodGet)
-	server.router.HandleFunc("/balance", server.handlePostBalance).Methods(http.MethodPost)
-	server.router.HandleFunc("/standing_orders", server.handlePostStandingOrder).Methods(http.MethodPost)
-	server.router.HandleFunc("/standing_orders/{id:[0-9]+}", server.handlePostStandingOrder).Methods(http.MethodGet)
-	server.router.HandleFunc("/standing_orders/{id:[0-9]+}", server.handlePostStandingOrder).Methods(http.MethodDelete)
+	server.router.NotFoundHandler = assignRequestId(http.HandlerFunc(handleNotFound))
+	server.router.MethodNotAllowedHandler = assignRequestId(http.HandlerFunc(handleMethodNotAllowed))
+	server.router.Use(assignRequestId, server.limitIP, server.verifySignature)
 
 	// OpenAPI
 	fs := http.FileServer(http.Dir("./openapi/swaggerui"))
 	server.router.PathPrefix("/ui/").Handler(http.StripPrefix("/ui/", fs))
-	server.router.HandleFunc(
-		"/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
-			http.ServeFile(w, r, "./openapi/openapi.yaml")
-		},
-	)
+	for _, version := range server.versions {
+		server.router.HandleFunc(version.prefix+"/openapi.yaml", version.handleOpenAPI).Methods(http.MethodGet)
+	}
+
+	for _, version := range server.versions {
+		router := server.router.NewRoute().Subrouter()
+		if version.prefix != "" {
+			router = server.router.PathPrefix(version.prefix).Subrouter()
+		}
+		server.setupVersion(router, version)
+	}
+}
+
+func (server *Server) setupVersion(router *mux.Router, version *apiVersion) {
+	if !version.sunset.IsZero() {
+		router.Use(version.deprecate)
+	}
+
+	public := router.NewRoute().Subrouter()
+	public.Use(version.validateRequest)
+	public.HandleFunc("/register", server.handleRegister).Methods(http.MethodPost).Name("register")
+
+	getBalance := server.handleGetBalance
+	if version.name == "v1" {
+		getBalance = server.handleGetBalanceV1
+	}
+
+	authenticated := router.NewRoute().Subrouter()
+	authenticated.Use(server.authenticateAccount, server.limitAccount, version.validateRequest)
+	authenticated.HandleFunc("/balance", requireScopes(getBalance, apikey.ScopeRead)).
+		Methods(http.MethodGet)
+	authenticated.HandleFunc(
+		"/balance", requireScopes(server.idempotent(server.handlePostBalance), apikey.ScopeDeposit),
+	).Methods(http.MethodPost).Name("postBalance")
+	authenticated.HandleFunc(
+		"/standing_orders", requireScopes(server.idempotent(server.handlePostStandingOrder), apikey.ScopeTrade),
+	).Methods(http.MethodPost).Name("postStandingOrder")
+	authenticated.HandleFunc("/standing_orders", requireScopes(server.handleGetStandingOrders, apikey.ScopeRead)).
+		Methods(http.MethodGet)
+	authenticated.HandleFunc(
+		"/standing_orders/{id:[0-9]+}", requireScopes(server.handleGetStandingOrder, apikey.ScopeRead),
+	).Methods(http.MethodGet)
+	authenticated.HandleFunc(
+		"/standing_orders/{id:[0-9]+}", requireScopes(server.handleDeleteStandingOrder, apikey.ScopeTrade),
+	).Methods(http.MethodDelete).Name("deleteStandingOrder")
+	authenticated.HandleFunc(
+		"/standing_orders/by-client-id/{cid}", requireScopes(server.handleGetStandingOrder, apikey.ScopeRead),
+	).Methods(http.MethodGet)
+	authenticated.HandleFunc(
+		"/standing_orders/by-client-id/{cid}", requireScopes(server.handleDeleteStandingOrder, apikey.ScopeTrade),
+	).Methods(http.MethodDelete).Name("deleteStandingOrderByClientId")
+	authenticated.HandleFunc("/order_book", requireScopes(server.handleGetOrderBook, apikey.ScopeRead)).
+		Methods(http.MethodGet)
+	authenticated.HandleFunc("/markets", requireScopes(server.handleGetMarkets, apikey.ScopeRead)).
+		Methods(http.MethodGet)
+	authenticated.HandleFunc("/price_history", requireScopes(server.handleGetPriceHistory, apikey.ScopeRead)).
+		Methods(http.MethodGet)
+	authenticated.HandleFunc("/api_keys", requireScopes(server.handleGetApiKeys, apikey.AllScopes...)).
+		Methods(http.MethodGet)
+	authenticated.HandleFunc("/api_keys", requireScopes(server.handlePostApiKey, apikey.AllScopes...)).
+		Methods(http.MethodPost).Name("postApiKey")
+	authenticated.HandleFunc("/api_keys/{id:[0-9]+}", requireScopes(server.handleDeleteApiKey, apikey.AllScopes...)).
+		Methods(http.MethodDelete)
+	authenticated.HandleFunc("/webhook_secret", requireScopes(server.handleGetWebhookSecret, apikey.AllScopes...)).
+		Methods(http.MethodGet)
+}
+
+func (server *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	server.router.ServeHTTP(w, req)
 }
 
 func (server *Server) ListenAndServe(addr string) error {
-	httpServer := &http.Server{Addr: addr, Handler: server.router}
+	httpServer := &http.Server{Addr: addr, Handler: server}
 	return httpServer.ListenAndServe()
 }
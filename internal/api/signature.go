@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/apikey"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/galcik/vlexchange/pkg/signature"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultRecvWindow = 5 * time.Second
+const maxRecvWindow = 60 * time.Second
+const maxSignedBodySize = 1 << 20
+
+type signatureError struct {
+	status  int
+	code    ErrorCode
+	message string
+}
+
+func (err *signatureError) Error() string {
+	return err.message
+}
+
+// verifySignature authenticates requests carrying an HMAC signature. Requests
+// without the signature header are passed on unchanged.
+func (server *Server) verifySignature(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get(signature.HeaderSignature) == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			auth, err := server.authenticateSigned(req)
+			if sigErr, ok := err.(*signatureError); ok {
+				writeError(w, req, sigErr.status, sigErr.code, sigErr.message)
+				return
+			}
+			if err != nil {
+				writeInternalError(w, req, err)
+				return
+			}
+
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), authContextKey{}, auth)))
+		},
+	)
+}
+
+func (server *Server) authenticateSigned(req *http.Request) (*authInfo, error) {
+	unauthorized := func(message string) error {
+		return &signatureError{status: http.StatusUnauthorized, code: ErrorCodeInvalidSignature, message: message}
+	}
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(signature.HeaderTimestamp), 10, 64)
+	if err != nil {
+		return nil, unauthorized("malformed timestamp")
+	}
+
+	nonce := req.Header.Get(signature.HeaderNonce)
+	if nonce == "" || len(nonce) > 64 {
+		return nil, unauthorized("malformed nonce")
+	}
+
+	recvWindow := defaultRecvWindow
+	if recvWindowStr := req.Header.Get(signature.HeaderRecvWindow); recvWindowStr != "" {
+		recvWindowMs, err := strconv.ParseInt(recvWindowStr, 10, 64)
+		if err != nil || recvWindowMs <= 0 || time.Duration(recvWindowMs)*time.Millisecond > maxRecvWindow {
+			return nil, unauthorized(fmt.Sprintf("receive window must be between 1 and %d ms", maxRecvWindow.Milliseconds()))
+		}
+		recvWindow = time.Duration(recvWindowMs) * time.Millisecond
+	}
+
+	now := time.Now()
+	requestTime := time.Unix(0, timestamp*int64(time.Millisecond))
+	if requestTime.Before(now.Add(-recvWindow)) || requestTime.After(now.Add(time.Second)) {
+		return nil, unauthorized("timestamp outside of receive window")
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, req.Body, maxSignedBodySize))
+	if err != nil {
+		return nil, &signatureError{
+			status:  http.StatusRequestEntityTooLarge,
+			code:    ErrorCodeRequestTooLarge,
+			message: "request body too large",
+		}
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	store := server.store.WithContext(req.Context())
+	key, err := store.GetApiKeyByPrefix(req.Header.Get(signature.HeaderKey))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, unauthorized("unknown api key")
+	}
+
+	valid := signature.Verify(
+		server.signingKey(key),
+		req.Header.Get(signature.HeaderSignature),
+		timestamp,
+		nonce,
+		req.Method,
+		req.URL.RequestURI(),
+		body,
+	)
+	if !valid {
+		return nil, unauthorized("invalid signature")
+	}
+
+	fresh, err := store.UseRequestNonce(key.ID, nonce, now.Add(-2*maxRecvWindow))
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, unauthorized("nonce already used")
+	}
+
+	auth, err := authorizeApiKey(store, remoteIP(req), key)
+	if err != nil {
+		return nil, err
+	}
+	if auth == nil {
+		return nil, &signatureError{status: http.StatusUnauthorized, code: ErrorCodeUnauthorized, message: "unauthorized"}
+	}
+	return auth, nil
+}
+
+// signingKey returns the signing key of the API key, derived from the server
+// secret.
+func (server *Server) signingKey(key *queries.ApiKey) string {
+	return apikey.SigningKey(server.serverSecret, key.Prefix)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/galcik/vlexchange/pkg/signature"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type signatureTestSuite struct {
+	authTestSuite
+}
+
+func (suite *signatureTestSuite) signedRequest(
+	signer *signature.Signer,
+	method, url string,
+	body interface{},
+) *http.Request {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		suite.Require().NoError(err)
+	}
+
+	request, err := http.NewRequest(method, url, bytes.NewReader(data))
+	suite.Require().NoError(err)
+	request.RemoteAddr = "203.0.113.7:4321"
+	suite.Require().NoError(signer.Sign(request))
+	return request
+}
+
+func (suite *signatureTestSuite) serve(request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	suite.server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (suite *signatureTestSuite) TestSignedRequests() {
+	key := suite.createKey(map[string]interface{}{"name": "bot", "scopes": []string{"read", "deposit"}})
+	signer := &signature.Signer{KeyPrefix: key.Prefix, SigningKey: key.SigningKey}
+
+	suite.Equal(http.StatusOK, suite.serve(suite.signedRequest(signer, http.MethodGet, "/balance", nil)).Code)
+
+	deposit := map[string]string{"currency": "usd", "topupAmount": "10"}
+	suite.Equal(
+		http.StatusOK,
+		suite.serve(suite.signedRequest(signer, http.MethodPost, "/balance", deposit)).Code,
+	)
+
+	wrongKey := &signature.Signer{KeyPrefix: key.Prefix, SigningKey: "wrong"}
+	suite.Equal(
+		http.StatusUnauthorized,
+		suite.serve(suite.signedRequest(wrongKey, http.MethodGet, "/balance", nil)).Code,
+	)
+}
+
+func (suite *signatureTestSuite) TestReplayIsRejected() {
+	key := suite.createKey(map[string]interface{}{"name": "bot", "scopes": []string{"read"}})
+	signer := &signature.Signer{KeyPrefix: key.Prefix, SigningKey: key.SigningKey}
+
+	request := suite.signedRequest(signer, http.MethodGet, "/balance", nil)
+	replayed := request.Clone(request.Context())
+	suite.Equal(http.StatusOK, suite.serve(request).Code)
+	suite.Equal(http.StatusUnauthorized, suite.serve(replayed).Code)
+}
+
+func (suite *signatureTestSuite) TestReceiveWindow() {
+	key := suite.createKey(map[string]interface{}{"name": "bot", "scopes": []string{"read"}})
+
+	stale := &signature.Signer{
+		KeyPrefix:  key.Prefix,
+		SigningKey: key.SigningKey,
+		Now: func() time.Time {
+			return time.Now().Add(-10 * time.Second)
+		},
+	}
+	suite.Equal(http.StatusUnauthorized, suite.serve(suite.signedRequest(stale, http.MethodGet, "/balance", nil)).Code)
+
+	stale.RecvWindow = 20 * time.Second
+	suite.Equal(http.StatusOK, suite.serve(suite.signedRequest(stale, http.MethodGet, "/balance", nil)).Code)
+
+	stale.RecvWindow = 2 * time.Minute
+	suite.Equal(http.StatusUnauthorized, suite.serve(suite.signedRequest(stale, http.MethodGet, "/balance", nil)).Code)
+}
+
+func (suite *signatureTestSuite) TestTamperedBody() {
+	key := suite.createKey(map[string]interface{}{"name": "bot", "scopes": []string{"deposit"}})
+	signer := &signature.Signer{KeyPrefix: key.Prefix, SigningKey: key.SigningKey}
+
+	request := suite.signedRequest(
+		signer, http.MethodPost, "/balance", map[string]string{"currency": "usd", "topupAmount": "10"},
+	)
+	data, err := json.Marshal(map[string]string{"currency": "usd", "topupAmount": "1000"})
+	suite.Require().NoError(err)
+	request.Body = httptest.NewRequest(http.MethodPost, "/balance", bytes.NewReader(data)).Body
+	suite.Equal(http.StatusUnauthorized, suite.serve(request).Code)
+}
+
+func (suite *signatureTestSuite) TestSigningKeyIsDerived() {
+	var err error
+	suite.server, err = NewServer(suite.store, WithServerSecret([]byte("server secret")))
+	suite.Require().NoError(err)
+	key := suite.createKey(map[string]interface{}{"name": "bot", "scopes": []string{"read"}})
+	signer := &signature.Signer{KeyPrefix: key.Prefix, SigningKey: key.SigningKey}
+
+	// the signing key survives restarts with the same server secret
+	suite.server, err = NewServer(suite.store, WithServerSecret([]byte("server secret")))
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, suite.serve(suite.signedRequest(signer, http.MethodGet, "/balance", nil)).Code)
+
+	suite.server, err = NewServer(suite.store, WithServerSecret([]byte("other secret")))
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusUnauthorized, suite.serve(suite.signedRequest(signer, http.MethodGet, "/balance", nil)).Code)
+}
+
+func TestSignature(t *testing.T) {
+	suite.Run(t, new(signatureTestSuite))
+}
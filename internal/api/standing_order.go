@@ -1,21 +1,33 @@
 package api
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/galcik/vlexchange/internal/currency"
 	"github.com/galcik/vlexchange/internal/datastore"
 	"github.com/galcik/vlexchange/internal/datastore/queries"
 	"github.com/gorilla/mux"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
+const maxClientOrderIdLength = 64
+
 type postStandingOrderRequest struct {
-	Quantity   string `json:"quantity"`
-	Type       string `json:"type"`
-	LimitPrice string `json:"limitPrice"`
-	WebhookUrl string `json:"webhookUrl"`
+	// Market is the symbol of the market, BTC-USD when empty.
+	Market        string `json:"market"`
+	Quantity      string `json:"quantity"`
+	Type          string `json:"type"`
+	LimitPrice    string `json:"limitPrice"`
+	WebhookUrl    string `json:"webhookUrl"`
+	ClientOrderId string `json:"clientOrderId"`
 }
 
 type postStandingOrderResponse struct {
@@ -23,148 +35,447 @@ type postStandingOrderResponse struct {
 }
 
 func (server *Server) handlePostStandingOrder(w http.ResponseWriter, req *http.Request) {
-	store := server.store.WithContext(req.Context())
-	account, err := store.GetAccountByToken(req.Header.Get("X-Token"))
+	account := accountFromContext(req.Context())
+
+	var payload postStandingOrderRequest
+	err := json.NewDecoder(req.Body).Decode(&payload)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeMalformedRequest(w, req)
 		return
 	}
 
-	if account == nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	standingOrder, err := server.placeStandingOrder(req.Context(), account, payload)
+	var fieldError *FieldError
+	if errors.As(err, &fieldError) {
+		writeValidationError(w, req, fieldError.Field, fieldError.Message)
+		return
 	}
-
-	var payload postStandingOrderRequest
-	err = json.NewDecoder(req.Body).Decode(&payload)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if errors.Is(err, datastore.ErrDuplicateClientOrderID) {
+		writeError(w, req, http.StatusConflict, ErrorCodeDuplicateClientOrderId, "duplicate clientOrderId")
 		return
 	}
-	if !isValidOrderType(payload.Type) {
-		http.Error(w, "malformed order type", http.StatusBadRequest)
+	if writeRefusalError(w, req, err) {
 		return
 	}
-	orderType := queries.OrderType(strings.ToLower(payload.Type))
-	quantity, err := currency.ParseBTC(payload.Quantity)
 	if err != nil {
-		http.Error(w, "malformed quantity", http.StatusBadRequest)
+		writeInternalError(w, req, err)
 		return
 	}
-	limitPrice, err := currency.ParseUSD(payload.Quantity)
+
+	writeJSONResponse(w, postStandingOrderResponse{OrderId: standingOrder.ID})
+}
+
+// placeStandingOrder validates and places the order for the account. Invalid
+// requests are reported by *FieldError.
+func (server *Server) placeStandingOrder(
+	ctx context.Context,
+	account *queries.Account,
+	request postStandingOrderRequest,
+) (*queries.StandingOrder, error) {
+	params, err := server.standingOrderParams(ctx, account, request)
 	if err != nil {
-		http.Error(w, "malformed limitPrice", http.StatusBadRequest)
-		return
+		return nil, err
+	}
+
+	standingOrder, affectedOrderIds, err := server.store.WithContext(ctx).CreateStandingOrder(params)
+	if err != nil {
+		return nil, err
+	}
+
+	go server.notifyOrderChanges(standingOrder.ID, affectedOrderIds)
+
+	return standingOrder, nil
+}
+
+// replaceStandingOrder cancels the live order and places the replacement for
+// the account at once. The order is left untouched when the replacement is
+// invalid, which is reported by *FieldError, or fails in the store.
+func (server *Server) replaceStandingOrder(
+	ctx context.Context,
+	account *queries.Account,
+	order *queries.StandingOrder,
+	request postStandingOrderRequest,
+) (*queries.StandingOrder, error) {
+	params, err := server.standingOrderParams(ctx, account, request)
+	if err != nil {
+		return nil, err
+	}
+
+	store := server.store.WithContext(ctx)
+	replacement, affectedOrderIds, err := store.ReplaceStandingOrder(order.ID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled, err := store.GetStandingOrder(order.ID)
+	if err != nil {
+		log.Printf("loading replaced order %d failed: %v", order.ID, err)
+	} else if cancelled != nil {
+		server.events.PublishOrder(*cancelled)
+	}
+	go server.notifyOrderChanges(replacement.ID, affectedOrderIds)
+
+	return replacement, nil
+}
+
+// standingOrderParams validates the order request of the account.
+func (server *Server) standingOrderParams(
+	ctx context.Context,
+	account *queries.Account,
+	request postStandingOrderRequest,
+) (datastore.CreateStandingOrderParams, error) {
+	var params datastore.CreateStandingOrderParams
+	if !isValidOrderType(request.Type) {
+		return params, &FieldError{Field: "type", Message: "malformed order type"}
+	}
+	orderType := queries.OrderType(strings.ToLower(request.Type))
+	market, err := server.findMarket(ctx, request.Market)
+	if err != nil {
+		return params, err
+	}
+	quantity, err := market.Base.Parse(request.Quantity)
+	if err != nil {
+		return params, amountFieldError("quantity", market.Base, err, "malformed quantity")
+	}
+	limitPrice, err := market.Quote.Parse(request.LimitPrice)
+	if err != nil {
+		return params, amountFieldError("limitPrice", market.Quote, err, "malformed limitPrice")
 	}
 
 	if quantity <= 0 {
-		http.Error(w, "no quantity", http.StatusBadRequest)
-		return
+		return params, &FieldError{Field: "quantity", Message: "quantity must be positive"}
 	}
 
 	if limitPrice < 0 {
-		http.Error(w, "negative quantity", http.StatusBadRequest)
-		return
+		return params, &FieldError{Field: "limitPrice", Message: "negative limitPrice"}
 	}
 
-	standingOrder, affectedOrderIds, err := store.CreateStandingOrder(
-		datastore.CreateStandingOrderParams{
-			AccountID:  account.ID,
-			OrderType:  orderType,
-			Quantity:   quantity,
-			LimitPrice: limitPrice,
-		},
-	)
+	if _, err := market.QuoteAmount(
+		currency.Amount(quantity), currency.Amount(limitPrice), currency.RoundCeil,
+	); err != nil {
+		return params, &FieldError{Field: "quantity", Message: "order value is too large"}
+	}
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if !isValidClientOrderId(request.ClientOrderId) {
+		return params, &FieldError{Field: "clientOrderId", Message: "malformed clientOrderId"}
 	}
 
-	go server.callWebhooks(affectedOrderIds)
+	if request.WebhookUrl != "" {
+		if err := server.webhookPolicy.ValidateURL(ctx, request.WebhookUrl); err != nil {
+			return params, &FieldError{Field: "webhookUrl", Message: err.Error()}
+		}
+	}
 
-	writeJSONResponse(w, postStandingOrderResponse{OrderId: standingOrder.ID})
+	return datastore.CreateStandingOrderParams{
+		AccountID:     account.ID,
+		MarketID:      market.ID,
+		OrderType:     orderType,
+		Quantity:      currency.Amount(quantity),
+		LimitPrice:    currency.Amount(limitPrice),
+		WebhookUrl:    request.WebhookUrl,
+		ClientOrderID: request.ClientOrderId,
+	}, nil
 }
 
 type getStandingOrderResponse struct {
 	ID             int32  `json:"id"`
+	ClientOrderId  string `json:"clientOrderId,omitempty"`
+	Market         string `json:"market"`
 	Type           string `json:"type"`
 	State          string `json:"state"`
 	Quantity       string `json:"quantity"`
 	FilledQuantity string `json:"filledQuantity"`
 	LimitPrice     string `json:"limitPrice"`
 	AvgPrice       string `json:"avgPrice"`
+	CreatedAt      string `json:"createdAt"`
+	// Display holds the amounts formatted for people, it is present when the
+	// request asks for units or a language.
+	Display *orderDisplay `json:"display,omitempty"`
 }
 
-func (server *Server) handleGetStandingOrder(w http.ResponseWriter, req *http.Request) {
-	orderId, _ := strconv.Atoi(mux.Vars(req)["id"])
+type orderDisplay struct {
+	Quantity       string `json:"quantity"`
+	FilledQuantity string `json:"filledQuantity"`
+	// LimitPrice is the price of one whole unit of the base asset whatever
+	// unit the quantities are displayed in.
+	LimitPrice string `json:"limitPrice"`
+}
+
+// newStandingOrderResponse formats the amounts of the order in the assets of
+// its market. The display amounts are added when the formatter is not nil.
+func newStandingOrderResponse(
+	order *queries.StandingOrder,
+	market *datastore.Market,
+	formatter *currency.Formatter,
+) getStandingOrderResponse {
+	response := getStandingOrderResponse{
+		ID:             order.ID,
+		ClientOrderId:  order.ClientOrderID.String,
+		Market:         market.Symbol,
+		Type:           strings.ToUpper(string(order.Type)),
+		State:          strings.ToUpper(string(order.State)),
+		Quantity:       market.Base.Format(int64(order.Quantity)),
+		FilledQuantity: market.Base.Format(int64(order.FilledQuantity)),
+		LimitPrice:     market.Quote.Format(int64(order.LimitPrice)),
+		AvgPrice:       "0",
+		CreatedAt:      order.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	if formatter != nil {
+		response.Display = &orderDisplay{
+			Quantity:       formatter.Format(order.Quantity.Of(market.Base)),
+			FilledQuantity: formatter.Format(order.FilledQuantity.Of(market.Base)),
+			LimitPrice:     formatter.Format(order.LimitPrice.Of(market.Quote)),
+		}
+	}
+	return response
+}
+
+// findStandingOrder loads the order addressed by the id or the client order id
+// in the path. It writes the error response and returns nil when the order
+// does not exist or belongs to another account.
+func (server *Server) findStandingOrder(w http.ResponseWriter, req *http.Request) *queries.StandingOrder {
 	store := server.store.WithContext(req.Context())
+	account := accountFromContext(req.Context())
+	vars := mux.Vars(req)
 
-	order, err := store.GetStandingOrder(int32(orderId))
+	var order *queries.StandingOrder
+	var err error
+	if clientOrderId, ok := vars["cid"]; ok {
+		order, err = store.GetStandingOrderByClientOrderID(account.ID, clientOrderId)
+	} else {
+		orderId, _ := strconv.Atoi(vars["id"])
+		order, err = store.GetStandingOrder(int32(orderId))
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, req, err)
+		return nil
+	}
+
+	// orders of other accounts are not found so that their ids are not revealed
+	if order == nil || order.AccountID != account.ID {
+		writeError(w, req, http.StatusNotFound, ErrorCodeNotFound, "order not found")
+		return nil
+	}
+
+	return order
+}
+
+func (server *Server) handleGetStandingOrder(w http.ResponseWriter, req *http.Request) {
+	formatter, fieldErr := displayFormatter(w, req)
+	if fieldErr != nil {
+		writeValidationError(w, req, fieldErr.Field, fieldErr.Message)
 		return
 	}
 
+	order := server.findStandingOrder(w, req)
 	if order == nil {
-		http.Error(w, "order not found", http.StatusNotFound)
 		return
 	}
 
-	account, err := store.GetAccount(order.AccountID)
+	market, err := server.store.WithContext(req.Context()).GetMarket(order.MarketID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, req, err)
+		return
+	}
+
+	writeJSONResponse(w, newStandingOrderResponse(order, market, formatter))
+}
+
+func (server *Server) handleDeleteStandingOrder(w http.ResponseWriter, req *http.Request) {
+	order := server.findStandingOrder(w, req)
+	if order == nil {
 		return
 	}
 
-	if account == nil || account.Token != req.Header.Get("X-Token") {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	err := server.cancelStandingOrder(req.Context(), order)
+	if errors.Is(err, datastore.ErrOrderNotLive) {
+		writeError(w, req, http.StatusConflict, ErrorCodeOrderNotLive, "order is not live")
+		return
+	}
+	if err != nil {
+		writeInternalError(w, req, err)
 		return
 	}
 
-	writeJSONResponse(
-		w,
-		getStandingOrderResponse{
-			ID:             order.ID,
-			Type:           strings.ToUpper(string(order.Type)),
-			State:          strings.ToUpper(string(order.State)),
-			Quantity:       currency.BTC(order.Quantity).String(),
-			FilledQuantity: currency.BTC(order.FilledQuantity).String(),
-			LimitPrice:     currency.USD(order.LimitPrice).String(),
-			AvgPrice:       "0",
-		},
-	)
+	writeJSONResponse(w, map[string]bool{"success": true})
 }
 
-func (server *Server) handleDeleteStandingOrder(w http.ResponseWriter, req *http.Request) {
-	orderId, _ := strconv.Atoi(mux.Vars(req)["id"])
+// cancelStandingOrder cancels a live order and updates it. Orders which are
+// fulfilled or cancelled already fail with datastore.ErrOrderNotLive.
+func (server *Server) cancelStandingOrder(ctx context.Context, order *queries.StandingOrder) error {
+	cancelled, err := server.store.WithContext(ctx).CancelStandingOrder(order.ID)
+	if err != nil {
+		return err
+	}
+	if cancelled == nil {
+		return datastore.ErrOrderNotLive
+	}
+
+	*order = *cancelled
+	server.events.PublishOrder(*cancelled)
+	return nil
+}
+
+func isValidClientOrderId(clientOrderId string) bool {
+	if len(clientOrderId) > maxClientOrderIdLength {
+		return false
+	}
+	for _, char := range clientOrderId {
+		if char < 0x21 || char > 0x7e || char == '/' {
+			return false
+		}
+	}
+	return true
+}
+
+const defaultListLimit = 50
+const maxListLimit = 200
+
+type getStandingOrdersResponse struct {
+	Orders []getStandingOrderResponse `json:"orders"`
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+func (server *Server) handleGetStandingOrders(w http.ResponseWriter, req *http.Request) {
 	store := server.store.WithContext(req.Context())
+	account := accountFromContext(req.Context())
 
-	order, err := store.GetStandingOrder(int32(orderId))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	params, fieldErr := parseListStandingOrdersParams(req.URL.Query())
+	if fieldErr != nil {
+		writeValidationError(w, req, fieldErr.Field, fieldErr.Message)
 		return
 	}
-
-	if order == nil {
-		http.Error(w, "order not found", http.StatusNotFound)
+	params.AccountID = account.ID
+	formatter, fieldErr := displayFormatter(w, req)
+	if fieldErr != nil {
+		writeValidationError(w, req, fieldErr.Field, fieldErr.Message)
 		return
 	}
 
-	account, err := store.GetAccount(order.AccountID)
+	markets, err := server.getMarkets(req.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, req, err)
 		return
 	}
+	if symbol := req.URL.Query().Get("market"); symbol != "" {
+		market, err := server.findMarket(req.Context(), symbol)
+		var fieldError *FieldError
+		if errors.As(err, &fieldError) {
+			writeValidationError(w, req, fieldError.Field, fieldError.Message)
+			return
+		}
+		if err != nil {
+			writeInternalError(w, req, err)
+			return
+		}
+		params.MarketID = market.ID
+	}
 
-	if account == nil || account.Token != req.Header.Get("X-Token") {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	orders, cursor, err := store.ListStandingOrders(params)
+	if err != nil {
+		writeInternalError(w, req, err)
 		return
 	}
 
-	if err = store.DeleteStandingOrder(int32(orderId)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	response := getStandingOrdersResponse{Orders: make([]getStandingOrderResponse, len(orders))}
+	for i := range orders {
+		response.Orders[i] = newStandingOrderResponse(&orders[i], markets[orders[i].MarketID], formatter)
 	}
+	if cursor != nil {
+		response.NextCursor = encodeOrderCursor(cursor)
+	}
+	writeJSONResponse(w, response)
+}
 
-	writeJSONResponse(w, map[string]bool{"success": true})
+func parseListStandingOrdersParams(query url.Values) (datastore.ListStandingOrdersParams, *FieldError) {
+	params := datastore.ListStandingOrdersParams{Limit: defaultListLimit}
+
+	for _, state := range splitQueryValues(query["state"]) {
+		orderState := queries.OrderState(strings.ToLower(state))
+		if orderState != queries.OrderStateLive && orderState != queries.OrderStateFulfilled &&
+			orderState != queries.OrderStateCancelled {
+			return params, &FieldError{Field: "state", Message: fmt.Sprintf("unknown state %q", state)}
+		}
+		params.States = append(params.States, orderState)
+	}
+
+	for _, side := range splitQueryValues(query["side"]) {
+		if !isValidOrderType(side) {
+			return params, &FieldError{Field: "side", Message: fmt.Sprintf("unknown side %q", side)}
+		}
+		params.Types = append(params.Types, queries.OrderType(strings.ToLower(side)))
+	}
+
+	var err error
+	if from := query.Get("createdFrom"); from != "" {
+		if params.CreatedFrom, err = time.Parse(time.RFC3339, from); err != nil {
+			return params, &FieldError{Field: "createdFrom", Message: "malformed createdFrom"}
+		}
+	}
+	if to := query.Get("createdTo"); to != "" {
+		if params.CreatedTo, err = time.Parse(time.RFC3339, to); err != nil {
+			return params, &FieldError{Field: "createdTo", Message: "malformed createdTo"}
+		}
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			return params, &FieldError{
+				Field:   "limit",
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxListLimit),
+			}
+		}
+		params.Limit = int32(limit)
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		if params.After, err = decodeOrderCursor(cursor); err != nil {
+			return params, &FieldError{Field: "cursor", Message: err.Error()}
+		}
+	}
+
+	return params, nil
+}
+
+// splitQueryValues accepts both repeated and comma separated query values.
+func splitQueryValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+func encodeOrderCursor(cursor *datastore.StandingOrderCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + "." + strconv.Itoa(int(cursor.ID))
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeOrderCursor(cursor string) (*datastore.StandingOrderCursor, error) {
+	malformed := fmt.Errorf("malformed cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, malformed
+	}
+
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 2 {
+		return nil, malformed
+	}
+	createdAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, malformed
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return nil, malformed
+	}
+
+	return &datastore.StandingOrderCursor{CreatedAt: time.Unix(0, createdAt), ID: int32(id)}, nil
 }
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore/testqueries"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type standingOrderTestSuite struct {
+	authTestSuite
+}
+
+func (suite *standingOrderTestSuite) TestRequiresAuthentication() {
+	testCases := []struct {
+		method string
+		url    string
+	}{
+		{http.MethodGet, "/balance"},
+		{http.MethodPost, "/balance"},
+		{http.MethodPost, "/standing_orders"},
+		{http.MethodGet, "/standing_orders/1"},
+		{http.MethodDelete, "/standing_orders/1"},
+		{http.MethodGet, "/api_keys"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		suite.Equal(http.StatusUnauthorized, suite.doRequest(tc.method, tc.url, "", nil).Code, tc.url)
+		suite.Equal(http.StatusUnauthorized, suite.doRequest(tc.method, tc.url, "unknown", nil).Code, tc.url)
+	}
+}
+
+func (suite *standingOrderTestSuite) TestGetAndDelete() {
+	recorder := suite.doRequest(
+		http.MethodPost, "/standing_orders", "111222", map[string]string{
+			"type": "buy", "quantity": "0.5", "limitPrice": "100",
+		},
+	)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var postResponse postStandingOrderResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &postResponse))
+
+	url := fmt.Sprintf("/standing_orders/%d", postResponse.OrderId)
+	recorder = suite.doRequest(http.MethodGet, url, "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var getResponse getStandingOrderResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &getResponse))
+	suite.Equal(postResponse.OrderId, getResponse.ID)
+	suite.Equal("BUY", getResponse.Type)
+	suite.Equal(currency.NewUSD(100).String(), getResponse.LimitPrice)
+	suite.Equal(currency.NewBTC(0.5).String(), getResponse.Quantity)
+
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodDelete, url, "111222", nil).Code)
+
+	// cancelled orders are kept
+	recorder = suite.doRequest(http.MethodGet, url, "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &getResponse))
+	suite.Equal("CANCELLED", getResponse.State)
+	orders, err := suite.queries.GetStandingOrders(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Len(orders, 1)
+	suite.Equal(testqueries.OrderStateCancelled, orders[0].State)
+	suite.Equal(currency.Amount(0), orders[0].ReservedQuoteAmount)
+
+	// cancelling again does nothing and tells so
+	recorder = suite.doRequest(http.MethodDelete, url, "111222", nil)
+	suite.Equal(http.StatusConflict, recorder.Code)
+	var errorResponse ErrorResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &errorResponse))
+	suite.Equal(ErrorCodeOrderNotLive, errorResponse.Error.Code)
+}
+
+func (suite *standingOrderTestSuite) TestForeignOrder() {
+	other, err := suite.queries.CreateAccount(
+		context.Background(), testqueries.CreateAccountParams{Username: "Other", Token: "333444"},
+	)
+	suite.Require().NoError(err)
+	order, err := suite.queries.CreateStandingOrder(
+		context.Background(), testqueries.CreateStandingOrderParams{
+			AccountID: other.ID,
+			Type:      testqueries.OrderTypeBuy,
+			State:     testqueries.OrderStateLive,
+			Quantity:  currency.Amount(currency.NewBTC(1)),
+		},
+	)
+	suite.Require().NoError(err)
+
+	url := fmt.Sprintf("/standing_orders/%d", order.ID)
+	suite.Equal(http.StatusNotFound, suite.doRequest(http.MethodGet, url, "111222", nil).Code)
+	suite.Equal(http.StatusNotFound, suite.doRequest(http.MethodDelete, url, "111222", nil).Code)
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodGet, url, "333444", nil).Code)
+	// the order stays live
+	suite.Equal(http.StatusOK, suite.doRequest(http.MethodDelete, url, "333444", nil).Code)
+}
+
+func (suite *standingOrderTestSuite) TestClientOrderId() {
+	order := map[string]string{"type": "buy", "quantity": "0.5", "limitPrice": "100", "clientOrderId": "bot-1"}
+	recorder := suite.doRequest(http.MethodPost, "/standing_orders", "111222", order)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var postResponse postStandingOrderResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &postResponse))
+
+	suite.Equal(http.StatusConflict, suite.doRequest(http.MethodPost, "/standing_orders", "111222", order).Code)
+
+	order["clientOrderId"] = "bot/1"
+	suite.Equal(http.StatusBadRequest, suite.doRequest(http.MethodPost, "/standing_orders", "111222", order).Code)
+
+	recorder = suite.doRequest(http.MethodGet, "/standing_orders/by-client-id/bot-1", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var getResponse getStandingOrderResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &getResponse))
+	suite.Equal(postResponse.OrderId, getResponse.ID)
+	suite.Equal("bot-1", getResponse.ClientOrderId)
+
+	_, err := suite.queries.CreateAccount(
+		context.Background(), testqueries.CreateAccountParams{Username: "Other", Token: "333444"},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(
+		http.StatusNotFound,
+		suite.doRequest(http.MethodGet, "/standing_orders/by-client-id/bot-1", "333444", nil).Code,
+	)
+
+	suite.Equal(
+		http.StatusOK,
+		suite.doRequest(http.MethodDelete, "/standing_orders/by-client-id/bot-1", "111222", nil).Code,
+	)
+	recorder = suite.doRequest(http.MethodGet, "/standing_orders/by-client-id/bot-1", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &getResponse))
+	suite.Equal("CANCELLED", getResponse.State)
+}
+
+func (suite *standingOrderTestSuite) TestList() {
+	for _, orderType := range []string{"buy", "sell", "buy"} {
+		order := map[string]string{"type": orderType, "quantity": "0.5", "limitPrice": "100"}
+		suite.Require().Equal(
+			http.StatusOK,
+			suite.doRequest(http.MethodPost, "/standing_orders", "111222", order).Code,
+		)
+	}
+
+	list := func(url string) getStandingOrdersResponse {
+		recorder := suite.doRequest(http.MethodGet, url, "111222", nil)
+		suite.Require().Equal(http.StatusOK, recorder.Code)
+		var response getStandingOrdersResponse
+		suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+		return response
+	}
+
+	page := list("/standing_orders?limit=2")
+	suite.Require().Equal(2, len(page.Orders))
+	suite.Greater(page.Orders[0].ID, page.Orders[1].ID)
+	suite.Require().NotEmpty(page.NextCursor)
+	lastPage := list("/standing_orders?limit=2&cursor=" + page.NextCursor)
+	suite.Equal(1, len(lastPage.Orders))
+	suite.Empty(lastPage.NextCursor)
+
+	suite.Equal(1, len(list("/standing_orders?side=sell").Orders))
+	suite.Equal(2, len(list("/standing_orders?side=buy&state=cancelled,live").Orders))
+	suite.Equal(0, len(list("/standing_orders?createdTo=2000-01-01T00:00:00Z").Orders))
+
+	for _, url := range []string{
+		"/standing_orders?limit=0", "/standing_orders?side=short", "/standing_orders?state=open",
+		"/standing_orders?cursor=x", "/standing_orders?createdFrom=yesterday",
+	} {
+		suite.Equal(http.StatusBadRequest, suite.doRequest(http.MethodGet, url, "111222", nil).Code, url)
+	}
+}
+
+func (suite *standingOrderTestSuite) TestDisplay() {
+	account, err := suite.store.GetAccountByToken("111222")
+	suite.Require().NoError(err)
+	suite.setBalance(account.ID, "USD", currency.NewUSD(40_000).Internal())
+	suite.setBalance(account.ID, "BTC", currency.NewBTC(1.5).Internal())
+	recorder := suite.doRequest(
+		http.MethodPost, "/standing_orders", "111222", map[string]string{
+			"type": "buy", "quantity": "1.25", "limitPrice": "1234.5",
+		},
+	)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var postResponse postStandingOrderResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &postResponse))
+
+	get := func(url, acceptLanguage string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+		suite.Require().NoError(err)
+		request.Header.Set("X-Token", "111222")
+		if acceptLanguage != "" {
+			request.Header.Set("Accept-Language", acceptLanguage)
+		}
+		recorder := httptest.NewRecorder()
+		suite.server.router.ServeHTTP(recorder, request)
+		suite.requireConformingResponse(request, recorder)
+		return recorder
+	}
+
+	url := fmt.Sprintf("/v2/standing_orders/%d", postResponse.OrderId)
+	var order getStandingOrderResponse
+	suite.Require().NoError(json.Unmarshal(get(url, "").Body.Bytes(), &order))
+	suite.Nil(order.Display)
+
+	recorder = get(url+"?unit=sat", "de-DE,de;q=0.9")
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &order))
+	suite.Equal(currency.NewBTC(1.25).String(), order.Quantity)
+	suite.Equal(
+		&orderDisplay{Quantity: "125.000.000 sat", FilledQuantity: "0 sat", LimitPrice: "1.234,50 $"},
+		order.Display,
+	)
+
+	var list getStandingOrdersResponse
+	suite.Require().NoError(json.Unmarshal(get("/v2/standing_orders?unit=mbtc", "").Body.Bytes(), &list))
+	suite.Require().Equal(1, len(list.Orders))
+	suite.Equal(
+		&orderDisplay{Quantity: "1,250 mBTC", FilledQuantity: "0 mBTC", LimitPrice: "$1,234.50"},
+		list.Orders[0].Display,
+	)
+
+	recorder = get("/v2/balance", "xx, fr;q=0.5")
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var balance getBalanceResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &balance))
+	suite.Require().NotNil(balance.Display)
+	suite.Equal("1,5 BTC", balance.Display.BTC)
+	suite.Equal("40\u202f000,00 $", balance.Display.USD)
+	suite.Equal("15\u202f000,00 $", balance.Display.USDEquivalent)
+	suite.Equal(map[string]string{"BTC": "1,5 BTC", "USD": "40\u202f000,00 $"}, balance.Display.Balances)
+	suite.Contains(recorder.Header().Values("Vary"), "Accept-Language")
+
+	for _, unit := range []string{"bit", "sat,mBTC"} {
+		suite.Equal(http.StatusBadRequest, get("/v2/balance?unit="+unit, "").Code, unit)
+		suite.Equal(http.StatusBadRequest, get(url+"?unit="+unit, "").Code, unit)
+	}
+}
+
+func TestStandingOrders(t *testing.T) {
+	suite.Run(t, new(standingOrderTestSuite))
+}
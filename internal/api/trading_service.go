@@ -0,0 +1,313 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/galcik/vlexchange/proto/trading"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// tradingService implements the gRPC trading API on top of the logic shared
+// with the HTTP handlers. It trades the BTC-USD market only.
+type tradingService struct {
+	trading.UnimplementedTradingServer
+	server *Server
+}
+
+var sideOrderTypes = map[trading.Side]queries.OrderType{
+	trading.Side_SIDE_BUY:  queries.OrderTypeBuy,
+	trading.Side_SIDE_SELL: queries.OrderTypeSell,
+}
+
+var orderStates = map[trading.OrderState]queries.OrderState{
+	trading.OrderState_ORDER_STATE_LIVE:      queries.OrderStateLive,
+	trading.OrderState_ORDER_STATE_FULFILLED: queries.OrderStateFulfilled,
+	trading.OrderState_ORDER_STATE_CANCELLED: queries.OrderStateCancelled,
+}
+
+var currencyNames = map[trading.Currency]string{
+	trading.Currency_CURRENCY_USD: "usd",
+	trading.Currency_CURRENCY_BTC: "btc",
+}
+
+func (service *tradingService) PlaceOrder(
+	ctx context.Context,
+	request *trading.PlaceOrderRequest,
+) (*trading.Order, error) {
+	orderType, ok := sideOrderTypes[request.Side]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "side: unknown side")
+	}
+
+	order, err := service.server.placeStandingOrder(
+		ctx, accountFromContext(ctx), postStandingOrderRequest{
+			Quantity:      request.Quantity,
+			Type:          string(orderType),
+			LimitPrice:    request.LimitPrice,
+			WebhookUrl:    request.WebhookUrl,
+			ClientOrderId: request.ClientOrderId,
+		},
+	)
+	if errors.Is(err, datastore.ErrDuplicateClientOrderID) {
+		return nil, status.Error(codes.AlreadyExists, "duplicate clientOrderId")
+	}
+	if err != nil {
+		return nil, grpcError("PlaceOrder", err)
+	}
+
+	return newOrderMessage(order), nil
+}
+
+// findOrder loads the referenced order of the account. Orders of other
+// accounts and markets are reported as not found.
+func (service *tradingService) findOrder(ctx context.Context, ref *trading.OrderRef) (*queries.StandingOrder, error) {
+	store := service.server.store.WithContext(ctx)
+	account := accountFromContext(ctx)
+
+	var order *queries.StandingOrder
+	var err error
+	switch ref := ref.Ref.(type) {
+	case *trading.OrderRef_OrderId:
+		order, err = store.GetStandingOrder(ref.OrderId)
+	case *trading.OrderRef_ClientOrderId:
+		order, err = store.GetStandingOrderByClientOrderID(account.ID, ref.ClientOrderId)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "missing order reference")
+	}
+	if err != nil {
+		return nil, grpcInternalError("findOrder", err)
+	}
+
+	if order == nil || order.AccountID != account.ID || order.MarketID != datastore.DefaultMarketID {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	return order, nil
+}
+
+func (service *tradingService) GetOrder(ctx context.Context, ref *trading.OrderRef) (*trading.Order, error) {
+	order, err := service.findOrder(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return newOrderMessage(order), nil
+}
+
+func (service *tradingService) CancelOrder(ctx context.Context, ref *trading.OrderRef) (*trading.Order, error) {
+	order, err := service.findOrder(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.server.cancelStandingOrder(ctx, order); err != nil {
+		return nil, grpcError("CancelOrder", err)
+	}
+
+	return newOrderMessage(order), nil
+}
+
+func (service *tradingService) ListOrders(
+	ctx context.Context,
+	request *trading.ListOrdersRequest,
+) (*trading.ListOrdersResponse, error) {
+	params := datastore.ListStandingOrdersParams{
+		AccountID: accountFromContext(ctx).ID,
+		MarketID:  datastore.DefaultMarketID,
+		Limit:     defaultListLimit,
+	}
+
+	for _, state := range request.States {
+		orderState, ok := orderStates[state]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "states: unknown state %v", state)
+		}
+		params.States = append(params.States, orderState)
+	}
+	for _, side := range request.Sides {
+		orderType, ok := sideOrderTypes[side]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "sides: unknown side %v", side)
+		}
+		params.Types = append(params.Types, orderType)
+	}
+
+	if request.CreatedFrom != nil {
+		params.CreatedFrom = request.CreatedFrom.AsTime()
+	}
+	if request.CreatedTo != nil {
+		params.CreatedTo = request.CreatedTo.AsTime()
+	}
+
+	if request.Limit != 0 {
+		if request.Limit < 1 || request.Limit > maxListLimit {
+			return nil, status.Errorf(codes.InvalidArgument, "limit: limit must be between 1 and %d", maxListLimit)
+		}
+		params.Limit = request.Limit
+	}
+
+	if request.Cursor != "" {
+		after, err := decodeOrderCursor(request.Cursor)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "cursor: "+err.Error())
+		}
+		params.After = after
+	}
+
+	orders, cursor, err := service.server.store.WithContext(ctx).ListStandingOrders(params)
+	if err != nil {
+		return nil, grpcInternalError("ListOrders", err)
+	}
+
+	response := &trading.ListOrdersResponse{Orders: make([]*trading.Order, len(orders))}
+	for i := range orders {
+		response.Orders[i] = newOrderMessage(&orders[i])
+	}
+	if cursor != nil {
+		response.NextCursor = encodeOrderCursor(cursor)
+	}
+	return response, nil
+}
+
+func (service *tradingService) GetBalance(ctx context.Context, _ *trading.GetBalanceRequest) (*trading.Balance, error) {
+	balance, err := service.server.getBalance(ctx, accountFromContext(ctx))
+	if err != nil {
+		return nil, grpcInternalError("GetBalance", err)
+	}
+
+	return &trading.Balance{
+		Btc:           balance.BTC.String(),
+		Usd:           balance.USD.String(),
+		UsdEquivalent: balance.USDEquivalent.String(),
+	}, nil
+}
+
+func (service *tradingService) Deposit(
+	ctx context.Context,
+	request *trading.DepositRequest,
+) (*trading.DepositResponse, error) {
+	currencyName, ok := currencyNames[request.Currency]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "currency: unknown currency")
+	}
+
+	success, err := service.server.deposit(
+		ctx, accountFromContext(ctx), postBalanceRequest{TopupAmount: request.Amount, Currency: currencyName},
+	)
+	if err != nil {
+		return nil, grpcError("Deposit", err)
+	}
+
+	return &trading.DepositResponse{Success: success}, nil
+}
+
+// StreamOrderEvents sends every change of the orders of the account until the
+// client cancels the call. Subscribers falling behind are disconnected.
+func (service *tradingService) StreamOrderEvents(
+	_ *trading.StreamOrderEventsRequest,
+	stream trading.Trading_StreamOrderEventsServer,
+) error {
+	events := service.server.events
+	subscription := events.SubscribeOrders(accountFromContext(stream.Context()).ID)
+	defer events.UnsubscribeOrders(subscription)
+
+	// the headers tell the client that the subscription is active
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case order, ok := <-subscription.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber fell behind")
+			}
+			if order.MarketID != datastore.DefaultMarketID {
+				continue
+			}
+			if err := stream.Send(newOrderMessage(&order)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamTrades sends every BTC-USD trade of the exchange until the client
+// cancels the call. Subscribers falling behind are disconnected.
+func (service *tradingService) StreamTrades(
+	_ *trading.StreamTradesRequest,
+	stream trading.Trading_StreamTradesServer,
+) error {
+	events := service.server.events
+	subscription := events.SubscribeTrades()
+	defer events.UnsubscribeTrades(subscription)
+
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case trade, ok := <-subscription.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber fell behind")
+			}
+			if trade.MarketID != datastore.DefaultMarketID {
+				continue
+			}
+			if err := stream.Send(newTradeMessage(trade)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newOrderMessage(order *queries.StandingOrder) *trading.Order {
+	return &trading.Order{
+		Id:             order.ID,
+		ClientOrderId:  order.ClientOrderID.String,
+		Side:           orderSide(order.Type),
+		State:          orderStateMessage(order.State),
+		Quantity:       currency.BTC(order.Quantity).String(),
+		FilledQuantity: currency.BTC(order.FilledQuantity).String(),
+		LimitPrice:     currency.USD(order.LimitPrice).String(),
+		CreatedAt:      timestamppb.New(order.CreatedAt),
+	}
+}
+
+func newTradeMessage(trade queries.Trade) *trading.Trade {
+	return &trading.Trade{
+		Id:         trade.ID,
+		TakerSide:  orderSide(trade.TakerSide),
+		Quantity:   currency.BTC(trade.Quantity).String(),
+		Price:      currency.USD(trade.Price).String(),
+		ExecutedAt: timestamppb.New(trade.CreatedAt),
+	}
+}
+
+func orderSide(orderType queries.OrderType) trading.Side {
+	for side, sideType := range sideOrderTypes {
+		if sideType == orderType {
+			return side
+		}
+	}
+	return trading.Side_SIDE_UNSPECIFIED
+}
+
+func orderStateMessage(state queries.OrderState) trading.OrderState {
+	for message, orderState := range orderStates {
+		if orderState == state {
+			return message
+		}
+	}
+	panic(fmt.Sprintf("unknown order state %q", state))
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/galcik/vlexchange/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gorilla/mux"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var routeVariablePattern = regexp.MustCompile(`\{([^}:]+):[^}]*\}`)
+
+func loadSpec(version string) (*openapi3.Swagger, error) {
+	data, err := openapi.Spec(version)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := openapi3.NewSwaggerLoader().LoadSwaggerFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// specRoute returns the operation of the version spec matching a mux route,
+// or nil when the spec does not describe it.
+func (version *apiVersion) specRoute(route *mux.Route, method string) *routers.Route {
+	template, err := route.GetPathTemplate()
+	if err != nil || !strings.HasPrefix(template, version.prefix) {
+		return nil
+	}
+
+	path := routeVariablePattern.ReplaceAllString(strings.TrimPrefix(template, version.prefix), "{$1}")
+	spec := version.spec
+	pathItem := spec.Paths.Find(path)
+	if pathItem == nil {
+		return nil
+	}
+	operation := pathItem.GetOperation(method)
+	if operation == nil {
+		return nil
+	}
+
+	return &routers.Route{Swagger: spec, Path: path, PathItem: pathItem, Method: method, Operation: operation}
+}
+
+// validateRequest rejects requests not matching the version spec. It must be
+// used after authentication, which it does not check.
+func (version *apiVersion) validateRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			route := version.specRoute(mux.CurrentRoute(req), req.Method)
+			if route == nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			// bodies are JSON even when clients do not say so
+			if req.Header.Get("Content-Type") == "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			err := openapi3filter.ValidateRequest(
+				req.Context(),
+				&openapi3filter.RequestValidationInput{
+					Request:    req,
+					PathParams: mux.Vars(req),
+					Route:      route,
+					Options: &openapi3filter.Options{
+						MultiError:         true,
+						AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+					},
+				},
+			)
+			if err != nil {
+				writeSpecValidationError(w, req, err)
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		},
+	)
+}
+
+func writeSpecValidationError(w http.ResponseWriter, req *http.Request, err error) {
+	var details []FieldError
+	malformed := false
+	collectFieldErrors(err, &details, &malformed)
+
+	if malformed || len(details) == 0 {
+		writeMalformedRequest(w, req)
+		return
+	}
+
+	messages := make([]string, len(details))
+	for i := range details {
+		messages[i] = details[i].Field + ": " + details[i].Message
+	}
+	writeErrorResponse(
+		w,
+		req,
+		http.StatusBadRequest,
+		Error{Code: ErrorCodeValidationFailed, Message: strings.Join(messages, "; "), Details: details},
+	)
+}
+
+func collectFieldErrors(err error, details *[]FieldError, malformed *bool) {
+	var multiError openapi3.MultiError
+	if errors.As(err, &multiError) {
+		for _, err := range multiError {
+			collectFieldErrors(err, details, malformed)
+		}
+		return
+	}
+
+	var requestError *openapi3filter.RequestError
+	if !errors.As(err, &requestError) {
+		*malformed = true
+		return
+	}
+
+	if parameter := requestError.Parameter; parameter != nil {
+		message := requestError.Reason
+		if requestError.Err != nil {
+			message = schemaErrorReason(requestError.Err)
+		}
+		*details = append(*details, FieldError{Field: parameter.Name, Message: message})
+		return
+	}
+
+	if errors.Is(requestError.Err, openapi3filter.ErrInvalidRequired) {
+		*details = append(*details, FieldError{Field: "body", Message: "missing request body"})
+		return
+	}
+
+	var bodyErrors openapi3.MultiError
+	if errors.As(requestError.Err, &bodyErrors) {
+		for _, err := range bodyErrors {
+			collectSchemaError(err, details, malformed)
+		}
+		return
+	}
+	collectSchemaError(requestError.Err, details, malformed)
+}
+
+// collectSchemaError reports invalid properties of the body. Bodies of a wrong
+// type, undecodable ones or ones with unsupported content type are malformed.
+func collectSchemaError(err error, details *[]FieldError, malformed *bool) {
+	var schemaError *openapi3.SchemaError
+	if !errors.As(err, &schemaError) || len(schemaError.JSONPointer()) == 0 {
+		*malformed = true
+		return
+	}
+	field := strings.Join(schemaError.JSONPointer(), ".")
+	*details = append(*details, FieldError{Field: field, Message: schemaError.Reason})
+}
+
+func schemaErrorReason(err error) string {
+	var schemaError *openapi3.SchemaError
+	if errors.As(err, &schemaError) {
+		return schemaError.Reason
+	}
+	return err.Error()
+}
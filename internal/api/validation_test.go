@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type validationTestSuite struct {
+	authTestSuite
+}
+
+// routeVersion returns the version the route belongs to.
+func (server *Server) routeVersion(route *mux.Route) *apiVersion {
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return nil
+	}
+	for _, version := range server.versions {
+		if version.prefix == "" || strings.HasPrefix(template, version.prefix+"/") {
+			return version
+		}
+	}
+	return nil
+}
+
+// requireConformingResponse checks the response of a request to a routed
+// endpoint against the spec of its version.
+func (suite *authTestSuite) requireConformingResponse(request *http.Request, recorder *httptest.ResponseRecorder) {
+	var match mux.RouteMatch
+	if !suite.server.router.Match(request, &match) || match.MatchErr != nil {
+		return
+	}
+	route := suite.server.routeVersion(match.Route).specRoute(match.Route, request.Method)
+	if route == nil {
+		return
+	}
+
+	err := openapi3filter.ValidateResponse(
+		context.Background(),
+		&openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{
+				Request:    request,
+				PathParams: match.Vars,
+				Route:      route,
+			},
+			Status: recorder.Code,
+			Header: recorder.Header(),
+			Body:   ioutil.NopCloser(bytes.NewReader(recorder.Body.Bytes())),
+			Options: &openapi3filter.Options{
+				IncludeResponseStatus: true,
+			},
+		},
+	)
+	suite.Require().NoError(err, "response of %s %s does not conform to the spec", request.Method, request.URL)
+}
+
+func (suite *validationTestSuite) TestEveryRouteIsSpecified() {
+	err := suite.server.router.Walk(
+		func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+			methods, err := route.GetMethods()
+			if err != nil {
+				// path prefixes and subrouters
+				return nil
+			}
+			template, err := route.GetPathTemplate()
+			suite.Require().NoError(err)
+			if template == "/openapi.yaml" {
+				return nil
+			}
+
+			for _, method := range methods {
+				version := suite.server.routeVersion(route)
+				suite.NotNil(version.specRoute(route, method), "%s %s", method, template)
+			}
+			return nil
+		},
+	)
+	suite.Require().NoError(err)
+}
+
+func (suite *validationTestSuite) decodeError(recorder *httptest.ResponseRecorder) Error {
+	var response ErrorResponse
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	return response.Error
+}
+
+func fieldNames(apiError Error) []string {
+	fields := make([]string, len(apiError.Details))
+	for i := range apiError.Details {
+		fields[i] = apiError.Details[i].Field
+	}
+	return fields
+}
+
+func (suite *validationTestSuite) TestInvalidBody() {
+	recorder := suite.doRequest(
+		http.MethodPost, "/standing_orders", "111222", map[string]interface{}{
+			"type": "buy", "quantity": 1, "limitPrice": "100",
+		},
+	)
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	apiError := suite.decodeError(recorder)
+	suite.Equal(ErrorCodeValidationFailed, apiError.Code)
+	suite.Equal([]string{"quantity"}, fieldNames(apiError))
+
+	recorder = suite.doRequest(http.MethodPost, "/balance", "111222", map[string]string{"currency": "eur"})
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	apiError = suite.decodeError(recorder)
+	suite.Equal(ErrorCodeValidationFailed, apiError.Code)
+	suite.ElementsMatch([]string{"currency", "topupAmount"}, fieldNames(apiError))
+
+	recorder = suite.doRequest(http.MethodPost, "/register", "", map[string]string{"username": ""})
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal([]string{"username"}, fieldNames(suite.decodeError(recorder)))
+
+	recorder = suite.doRequest(http.MethodPost, "/api_keys", "111222", nil)
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal(ErrorCodeValidationFailed, suite.decodeError(recorder).Code)
+}
+
+func (suite *validationTestSuite) TestInvalidParameters() {
+	recorder := suite.doRequest(http.MethodGet, "/standing_orders?limit=500", "111222", nil)
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	apiError := suite.decodeError(recorder)
+	suite.Equal(ErrorCodeValidationFailed, apiError.Code)
+	suite.Equal([]string{"limit"}, fieldNames(apiError))
+
+	deposit := strings.NewReader(`{"currency":"usd","topupAmount":"1"}`)
+	request := httptest.NewRequest(http.MethodPost, "/balance", deposit)
+	request.Header.Set("X-Token", "111222")
+	request.Header.Set(idempotencyKeyHeader, strings.Repeat("k", 300))
+	recorder = httptest.NewRecorder()
+	suite.server.router.ServeHTTP(recorder, request)
+	suite.Require().Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal([]string{idempotencyKeyHeader}, fieldNames(suite.decodeError(recorder)))
+}
+
+func TestValidation(t *testing.T) {
+	suite.Run(t, new(validationTestSuite))
+}
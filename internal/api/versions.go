@@ -0,0 +1,118 @@
+package api
+
+import (
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"net/http"
+	"time"
+)
+
+// V1Deprecation and V1Sunset are announced to clients of the deprecated v1
+// routes, including the unversioned ones.
+var V1Deprecation = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+var V1Sunset = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+
+// apiVersion is a router tree mounted under its own path prefix and described
+// by its own OpenAPI document.
+type apiVersion struct {
+	name   string
+	prefix string
+	spec   *openapi3.Swagger
+	// deprecation and sunset are zero for supported versions
+	deprecation time.Time
+	sunset      time.Time
+}
+
+func loadVersions() ([]*apiVersion, error) {
+	v1, err := loadSpec("v1")
+	if err != nil {
+		return nil, err
+	}
+	v2, err := loadSpec("v2")
+	if err != nil {
+		return nil, err
+	}
+
+	return []*apiVersion{
+		{name: "v1", prefix: "/v1", spec: v1, deprecation: V1Deprecation, sunset: V1Sunset},
+		{name: "v2", prefix: "/v2", spec: v2},
+		// routes of the first release were not prefixed, they are kept for existing clients
+		{name: "v1", prefix: "", spec: v1, deprecation: V1Deprecation, sunset: V1Sunset},
+	}, nil
+}
+
+// deprecate announces the removal of the version in the response headers.
+func (version *apiVersion) deprecate(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			header := w.Header()
+			header.Set("Deprecation", version.deprecation.Format(http.TimeFormat))
+			header.Set("Sunset", version.sunset.Format(http.TimeFormat))
+			header.Set("Link", `</openapi.yaml?version=`+openapi.LatestVersion+`>; rel="successor-version"`)
+			next.ServeHTTP(w, req)
+		},
+	)
+}
+
+// handleOpenAPI serves the OpenAPI document of the version asked for, by
+// default the one of the version the route belongs to.
+func (version *apiVersion) handleOpenAPI(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("version")
+	if name == "" {
+		name = version.name
+	}
+
+	spec, err := openapi.Spec(name)
+	if err != nil {
+		handleNotFound(w, req)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(spec)
+}
+
+// getBalanceResponseV1 uses the uppercase keys of the first release.
+type getBalanceResponseV1 struct {
+	BTC           currency.BTC      `json:"BTC"`
+	USD           currency.USD      `json:"USD"`
+	USDEquivalent currency.USD      `json:"USDEquivalent"`
+	Display       *balanceDisplayV1 `json:"display,omitempty"`
+}
+
+type balanceDisplayV1 struct {
+	BTC           string `json:"BTC"`
+	USD           string `json:"USD"`
+	USDEquivalent string `json:"USDEquivalent"`
+}
+
+// handleGetBalanceV1 displays units like the v1 order routes. The at parameter
+// of v2 is rejected instead of silently returning the current balance.
+func (server *Server) handleGetBalanceV1(w http.ResponseWriter, req *http.Request) {
+	if _, ok := req.URL.Query()["at"]; ok {
+		writeValidationError(w, req, "at", "parameter is not supported in v1, use v2")
+		return
+	}
+
+	formatter, fieldErr := displayFormatter(w, req)
+	if fieldErr != nil {
+		writeValidationError(w, req, fieldErr.Field, fieldErr.Message)
+		return
+	}
+
+	balance, err := server.getBalance(req.Context(), accountFromContext(req.Context()))
+	if err != nil {
+		writeInternalError(w, req, err)
+		return
+	}
+
+	response := getBalanceResponseV1{BTC: balance.BTC, USD: balance.USD, USDEquivalent: balance.USDEquivalent}
+	if formatter != nil {
+		display := newBalanceDisplay(formatter, balance)
+		response.Display = &balanceDisplayV1{
+			BTC: display.BTC, USD: display.USD, USDEquivalent: display.USDEquivalent,
+		}
+	}
+	writeJSONResponse(w, response)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/galcik/vlexchange/openapi"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type versionsTestSuite struct {
+	authTestSuite
+}
+
+func (suite *versionsTestSuite) TestBalanceShapes() {
+	recorder := suite.doRequest(http.MethodGet, "/v2/balance", "111222", nil)
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	var balance map[string]string
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &balance))
+	_, err := time.Parse(time.RFC3339Nano, balance["priceTimestamp"])
+	suite.NoError(err)
+	delete(balance, "priceTimestamp")
+	suite.Equal(map[string]string{"btc": "0.00000000", "usd": "0.00", "usdEquivalent": "0.00"}, balance)
+
+	for _, url := range []string{"/v1/balance", "/balance"} {
+		recorder = suite.doRequest(http.MethodGet, url, "111222", nil)
+		suite.Require().Equal(http.StatusOK, recorder.Code, url)
+		suite.JSONEq(`{"BTC": "0.00000000", "USD": "0.00", "USDEquivalent": "0.00"}`, recorder.Body.String(), url)
+	}
+
+	// units are displayed like in v1 order responses, at is rejected
+	for _, url := range []string{"/v1/balance?unit=sat", "/balance?unit=sat"} {
+		recorder = suite.doRequest(http.MethodGet, url, "111222", nil)
+		suite.Require().Equal(http.StatusOK, recorder.Code, url)
+		var response struct{ Display map[string]string }
+		suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+		suite.Equal("0 sat", response.Display["BTC"], url)
+	}
+	for _, url := range []string{"/v1/balance?unit=xyz", "/balance?at=2026-01-02T15:04:05Z"} {
+		suite.Equal(http.StatusBadRequest, suite.doRequest(http.MethodGet, url, "111222", nil).Code, url)
+	}
+}
+
+func (suite *versionsTestSuite) TestDeprecationHeaders() {
+	for _, url := range []string{"/v1/balance", "/balance", "/v1/api_keys"} {
+		recorder := suite.doRequest(http.MethodGet, url, "111222", nil)
+		suite.Equal(V1Deprecation.Format(http.TimeFormat), recorder.Header().Get("Deprecation"), url)
+		suite.Equal(V1Sunset.Format(http.TimeFormat), recorder.Header().Get("Sunset"), url)
+		suite.Contains(recorder.Header().Get("Link"), `rel="successor-version"`, url)
+	}
+
+	// errors of deprecated routes are announced too
+	recorder := suite.doRequest(http.MethodGet, "/v1/balance", "", nil)
+	suite.Equal(http.StatusUnauthorized, recorder.Code)
+	suite.NotEmpty(recorder.Header().Get("Sunset"))
+
+	recorder = suite.doRequest(http.MethodGet, "/v2/balance", "111222", nil)
+	suite.Empty(recorder.Header().Get("Deprecation"))
+	suite.Empty(recorder.Header().Get("Sunset"))
+}
+
+func (suite *versionsTestSuite) TestRoutesOfAllVersions() {
+	order := map[string]string{"type": "buy", "quantity": "1", "limitPrice": "100", "clientOrderId": "o-1"}
+	suite.Require().Equal(http.StatusOK, suite.doRequest(http.MethodPost, "/v2/standing_orders", "111222", order).Code)
+
+	for _, url := range []string{"/v2/standing_orders/by-client-id/o-1", "/v1/standing_orders/by-client-id/o-1"} {
+		suite.Equal(http.StatusOK, suite.doRequest(http.MethodGet, url, "111222", nil).Code, url)
+	}
+	suite.Equal(http.StatusNotFound, suite.doRequest(http.MethodGet, "/v3/balance", "111222", nil).Code)
+}
+
+func (suite *versionsTestSuite) TestOpenAPI() {
+	tests := []struct {
+		url     string
+		version string
+	}{
+		{"/openapi.yaml", "v1"},
+		{"/openapi.yaml?version=v1", "v1"},
+		{"/openapi.yaml?version=v2", "v2"},
+		{"/v1/openapi.yaml", "v1"},
+		{"/v2/openapi.yaml", "v2"},
+		{"/v2/openapi.yaml?version=v1", "v1"},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, test.url, nil)
+		recorder := httptest.NewRecorder()
+		suite.server.router.ServeHTTP(recorder, request)
+		suite.Require().Equal(http.StatusOK, recorder.Code, test.url)
+
+		spec, err := openapi.Spec(test.version)
+		suite.Require().NoError(err)
+		suite.Equal(string(spec), recorder.Body.String(), test.url)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/openapi.yaml?version=v0", nil)
+	recorder := httptest.NewRecorder()
+	suite.server.router.ServeHTTP(recorder, request)
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func TestVersions(t *testing.T) {
+	suite.Run(t, new(versionsTestSuite))
+}
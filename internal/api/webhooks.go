@@ -1,44 +1,79 @@
 package api
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/galcik/vlexchange/internal/webhook"
 	"log"
 	"net/http"
 )
 
-func (server *Server) callWebhooks(orderIds []int32) {
+type getWebhookSecretResponse struct {
+	Secret string `json:"secret"`
+}
+
+// handleGetWebhookSecret returns the secret signing the webhooks of the
+// account.
+func (server *Server) handleGetWebhookSecret(w http.ResponseWriter, req *http.Request) {
+	account := accountFromContext(req.Context())
+	writeJSONResponse(w, getWebhookSecretResponse{Secret: server.webhookSecret(account.ID)})
+}
+
+// webhookSecret derives the webhook secret of the account from the server
+// secret.
+func (server *Server) webhookSecret(accountId int32) string {
+	return webhook.Secret(server.serverSecret, accountId)
+}
+
+// notifyOrderChanges publishes the orders changed by placing an order and its
+// trades to stream subscribers, then calls the webhooks of the orders.
+func (server *Server) notifyOrderChanges(placedOrderId int32, affectedOrderIds []int32) {
 	store := server.store.WithContext(context.Background())
-	orders, err := store.GetStandingOrders(orderIds)
+	orders, err := store.GetStandingOrders(affectedOrderIds)
 	if err != nil {
+		log.Printf("loading changed orders failed: %v", err)
 		return
 	}
+	trades, err := store.GetOrderTrades(placedOrderId)
+	if err != nil {
+		log.Printf("loading trades of order %d failed: %v", placedOrderId, err)
+	}
+
+	for _, order := range orders {
+		server.events.PublishOrder(order)
+	}
+	for _, trade := range trades {
+		server.events.PublishTrade(trade)
+	}
 
 	for _, order := range orders {
-		callOrderChangedWebhook(order)
+		server.callOrderChangedWebhook(order)
 	}
 }
 
-func callOrderChangedWebhook(order queries.StandingOrder) {
+func (server *Server) callOrderChangedWebhook(order queries.StandingOrder) {
 	if !order.WebhookUrl.Valid {
 		return
 	}
 
 	values := map[string]interface{}{"orderId": order.ID}
+	if order.ClientOrderID.Valid {
+		values["clientOrderId"] = order.ClientOrderID.String
+	}
 
 	callbackBody, err := json.Marshal(values)
 	if err != nil {
 		return
 	}
 
-	client := &http.Client{}
-	resp, err := client.Post(order.WebhookUrl.String, "application/json", bytes.NewBuffer(callbackBody))
+	_, err = server.webhookClient.Post(
+		context.Background(), order.WebhookUrl.String, callbackBody, server.webhookSecret(order.AccountID),
+	)
 	if err != nil {
+		log.Printf("callback to %q failed: %v", order.WebhookUrl.String, err)
 		return
 	}
-	defer resp.Body.Close()
 
 	log.Printf("callback to %q done", order.WebhookUrl.String)
 }
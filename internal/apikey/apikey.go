@@ -0,0 +1,184 @@
+package apikey
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+type Scope string
+
+const (
+	ScopeRead     Scope = "read"
+	ScopeTrade    Scope = "trade"
+	ScopeDeposit  Scope = "deposit"
+	ScopeWithdraw Scope = "withdraw"
+)
+
+var AllScopes = []Scope{ScopeRead, ScopeTrade, ScopeDeposit, ScopeWithdraw}
+
+const tokenPrefix = "vlx"
+const prefixBytes = 6
+const secretBytes = 32
+
+// Generate creates a new API key token in the form vlx_<prefix>_<secret>.
+// The prefix identifies the key, only the hash of the secret is stored.
+func Generate() (prefix string, secret string, token string, err error) {
+	prefixRaw := make([]byte, prefixBytes)
+	if _, err = rand.Read(prefixRaw); err != nil {
+		return "", "", "", err
+	}
+	secretRaw := make([]byte, secretBytes)
+	if _, err = rand.Read(secretRaw); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixRaw)
+	secret = base64.RawURLEncoding.EncodeToString(secretRaw)
+	return prefix, secret, strings.Join([]string{tokenPrefix, prefix, secret}, "_"), nil
+}
+
+// GenerateServerSecret creates a secret the signing keys can be derived from.
+func GenerateServerSecret() ([]byte, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// SigningKey derives the key used for HMAC request signing of the API key
+// with the prefix from the server secret, so that signing keys are never
+// stored.
+func SigningKey(serverSecret []byte, prefix string) string {
+	mac := hmac.New(sha256.New, serverSecret)
+	mac.Write([]byte("signing key\n" + prefix))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Parse splits a token into its prefix and secret.
+func Parse(token string) (prefix string, secret string, ok bool) {
+	parts := strings.SplitN(token, "_", 3)
+	if len(parts) != 3 || parts[0] != tokenPrefix || len(parts[1]) != 2*prefixBytes || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func Hash(secret string) []byte {
+	hash := sha256.Sum256([]byte(secret))
+	return hash[:]
+}
+
+func VerifySecret(secret string, hash []byte) bool {
+	return subtle.ConstantTimeCompare(Hash(secret), hash) == 1
+}
+
+func ParseScopes(scopes []string) ([]Scope, error) {
+	result := make([]Scope, 0, len(scopes))
+	for _, scopeStr := range scopes {
+		scope := Scope(strings.ToLower(strings.TrimSpace(scopeStr)))
+		if !isKnownScope(scope) {
+			return nil, fmt.Errorf("unknown scope %q", scopeStr)
+		}
+		if !HasScopes(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+func HasScopes(granted []Scope, required ...Scope) bool {
+	for _, requiredScope := range required {
+		found := false
+		for _, scope := range granted {
+			if scope == requiredScope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func ScopesToStrings(scopes []Scope) []string {
+	result := make([]string, len(scopes))
+	for i := range scopes {
+		result[i] = string(scopes[i])
+	}
+	return result
+}
+
+func StringsToScopes(scopes []string) []Scope {
+	result := make([]Scope, len(scopes))
+	for i := range scopes {
+		result[i] = Scope(scopes[i])
+	}
+	return result
+}
+
+// ValidateAllowedIPs checks that every entry is an IP address or a CIDR.
+func ValidateAllowedIPs(allowedIPs []string) error {
+	for _, allowed := range allowedIPs {
+		if _, err := parseNetwork(allowed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsIPAllowed reports whether ip matches the allowlist. An empty allowlist
+// allows any address.
+func IsIPAllowed(allowedIPs []string, ip net.IP) bool {
+	if len(allowedIPs) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, allowed := range allowedIPs {
+		network, err := parseNetwork(allowed)
+		if err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetwork(network string) (*net.IPNet, error) {
+	if strings.Contains(network, "/") {
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q", network)
+		}
+		return ipNet, nil
+	}
+
+	ip := net.ParseIP(network)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip address %q", network)
+	}
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+func isKnownScope(scope Scope) bool {
+	for _, known := range AllScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
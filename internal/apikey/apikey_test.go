@@ -0,0 +1,94 @@
+package apikey
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+)
+
+func TestGenerateAndParse(t *testing.T) {
+	prefix, secret, token, err := Generate()
+	require.NoError(t, err)
+
+	parsedPrefix, parsedSecret, ok := Parse(token)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsedPrefix)
+	assert.Equal(t, secret, parsedSecret)
+	assert.True(t, VerifySecret(secret, Hash(secret)))
+	assert.False(t, VerifySecret(secret+"x", Hash(secret)))
+
+	_, _, otherToken, err := Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, otherToken)
+}
+
+func TestSigningKey(t *testing.T) {
+	serverSecret, err := GenerateServerSecret()
+	require.NoError(t, err)
+	otherSecret, err := GenerateServerSecret()
+	require.NoError(t, err)
+
+	signingKey := SigningKey(serverSecret, "a1b2c3d4e5f6")
+	assert.NotEmpty(t, signingKey)
+	assert.Equal(t, signingKey, SigningKey(serverSecret, "a1b2c3d4e5f6"))
+	assert.NotEqual(t, signingKey, SigningKey(serverSecret, "a1b2c3d4e5f7"))
+	assert.NotEqual(t, signingKey, SigningKey(otherSecret, "a1b2c3d4e5f6"))
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []string{
+		"",
+		"6f1c0b3e-8a9e-4b6a-9b1e-2f0f3c1d2e3f",
+		"vlx_abc_secret",
+		"xyz_0123456789ab_secret",
+		"vlx_0123456789ab_",
+	}
+
+	for i := range testCases {
+		_, _, ok := Parse(testCases[i])
+		assert.False(t, ok, testCases[i])
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"read", "TRADE", "read"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Scope{ScopeRead, ScopeTrade}, scopes)
+
+	_, err = ParseScopes([]string{"read", "admin"})
+	assert.Error(t, err)
+}
+
+func TestHasScopes(t *testing.T) {
+	assert.True(t, HasScopes(AllScopes, ScopeRead, ScopeWithdraw))
+	assert.True(t, HasScopes([]Scope{ScopeRead}))
+	assert.False(t, HasScopes([]Scope{ScopeRead}, ScopeTrade))
+}
+
+func TestIsIPAllowed(t *testing.T) {
+	testCases := []struct {
+		allowed  []string
+		ip       string
+		expected bool
+	}{
+		{nil, "203.0.113.7", true},
+		{[]string{"203.0.113.7"}, "203.0.113.7", true},
+		{[]string{"203.0.113.7"}, "203.0.113.8", false},
+		{[]string{"203.0.113.0/24"}, "203.0.113.8", true},
+		{[]string{"2001:db8::/32"}, "2001:db8::1", true},
+		{[]string{"2001:db8::/32"}, "203.0.113.8", false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		assert.Equal(t, tc.expected, IsIPAllowed(tc.allowed, net.ParseIP(tc.ip)), tc.ip)
+	}
+	assert.False(t, IsIPAllowed([]string{"203.0.113.7"}, nil))
+}
+
+func TestValidateAllowedIPs(t *testing.T) {
+	assert.NoError(t, ValidateAllowedIPs([]string{"203.0.113.7", "10.0.0.0/8", "::1"}))
+	assert.Error(t, ValidateAllowedIPs([]string{"10.0.0.0/33"}))
+	assert.Error(t, ValidateAllowedIPs([]string{"localhost"}))
+}
@@ -0,0 +1,61 @@
+package coinmarket
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker stops querying a provider after consecutive failures. Once the
+// cooldown passes a single probe is let through, its success closes the
+// breaker and its failure opens it again.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow tells whether the provider may be queried now. Allowed queries must
+// be followed by record or release.
+func (breaker *breaker) allow(now time.Time) bool {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.failures < breaker.threshold {
+		return true
+	}
+	if breaker.probing || now.Before(breaker.openUntil) {
+		return false
+	}
+	breaker.probing = true
+	return true
+}
+
+// release ends a query which tells nothing about the provider.
+func (breaker *breaker) release() {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	breaker.probing = false
+}
+
+func (breaker *breaker) record(failed bool, now time.Time) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	breaker.probing = false
+	if !failed {
+		breaker.failures = 0
+		return
+	}
+	breaker.failures++
+	if breaker.failures >= breaker.threshold {
+		breaker.openUntil = now.Add(breaker.cooldown)
+	}
+}
@@ -0,0 +1,162 @@
+package coinmarket
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/currency"
+	"log"
+	"sync"
+	"time"
+)
+
+type CacheOptions struct {
+	// TTL is the age at which quotes are refreshed by the next request.
+	TTL time.Duration
+	// MaxAge is the age up to which quotes are served while refreshing fails.
+	MaxAge time.Duration
+	// RefreshInterval of the background refresher, zero disables it.
+	RefreshInterval time.Duration
+	// FetchTimeout limits the requests of refreshes, zero means no limit.
+	FetchTimeout time.Duration
+}
+
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		TTL:          30 * time.Second,
+		MaxAge:       5 * time.Minute,
+		FetchTimeout: 10 * time.Second,
+	}
+}
+
+// CachedService serves the quotes of the wrapped service until they expire.
+// Concurrent requests for an expired quote share a single refresh, which is
+// not cancelled when some of them give up waiting.
+type CachedService struct {
+	service CoinmarketService
+	options CacheOptions
+	now     func() time.Time
+
+	mutex     sync.Mutex
+	quote     Quote
+	fetchedAt time.Time
+	// refresh is the refresh in flight, if any
+	refresh *refresh
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type refresh struct {
+	done  chan struct{}
+	quote Quote
+	err   error
+}
+
+// NewCachedService wraps the service and starts the background refresher
+// when the options enable it. Close stops the refresher.
+func NewCachedService(service CoinmarketService, options CacheOptions) *CachedService {
+	cache := &CachedService{
+		service: service,
+		options: options,
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+	if options.RefreshInterval > 0 {
+		go cache.refreshPeriodically()
+	}
+	return cache
+}
+
+func (cache *CachedService) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	quote, err := cache.GetBTCQuoteInUSD(ctx)
+	return quote.Price, err
+}
+
+// GetBTCQuoteInUSD returns the cached quote, refreshing it once it is older
+// than the TTL. When the refresh fails, quotes younger than MaxAge are served.
+func (cache *CachedService) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	cache.mutex.Lock()
+	if cache.isCachedWithin(cache.options.TTL) {
+		quote := cache.quote
+		cache.mutex.Unlock()
+		return quote, nil
+	}
+	refresh := cache.startRefresh()
+	cache.mutex.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return Quote{}, ctx.Err()
+	case <-refresh.done:
+	}
+	if refresh.err == nil {
+		return refresh.quote, nil
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.isCachedWithin(cache.options.MaxAge) {
+		return cache.quote, nil
+	}
+	return Quote{}, refresh.err
+}
+
+func (cache *CachedService) Close() {
+	cache.stopOnce.Do(func() { close(cache.stop) })
+}
+
+// isCachedWithin tells whether a quote fetched within the age is cached. It
+// must be called with the mutex held.
+func (cache *CachedService) isCachedWithin(age time.Duration) bool {
+	return !cache.fetchedAt.IsZero() && cache.now().Sub(cache.fetchedAt) < age
+}
+
+// startRefresh joins the refresh in flight or starts a new one. It must be
+// called with the mutex held.
+func (cache *CachedService) startRefresh() *refresh {
+	if cache.refresh == nil {
+		cache.refresh = &refresh{done: make(chan struct{})}
+		go cache.fetch(cache.refresh)
+	}
+	return cache.refresh
+}
+
+func (cache *CachedService) fetch(refresh *refresh) {
+	ctx := context.Background()
+	if cache.options.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cache.options.FetchTimeout)
+		defer cancel()
+	}
+	refresh.quote, refresh.err = GetBTCQuoteInUSD(ctx, cache.service)
+
+	cache.mutex.Lock()
+	if refresh.err == nil {
+		cache.quote = refresh.quote
+		cache.fetchedAt = cache.now()
+	}
+	cache.refresh = nil
+	cache.mutex.Unlock()
+	close(refresh.done)
+}
+
+// refreshPeriodically refreshes the quote ahead of requests so that they are
+// served from the cache.
+func (cache *CachedService) refreshPeriodically() {
+	ticker := time.NewTicker(cache.options.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cache.stop:
+			return
+		case <-ticker.C:
+		}
+
+		cache.mutex.Lock()
+		refresh := cache.startRefresh()
+		cache.mutex.Unlock()
+		<-refresh.done
+		if refresh.err != nil {
+			log.Printf("refreshing BTC price failed: %v", refresh.err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package coinmarket
+
+import (
+	"context"
+	"errors"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeService returns its price, or its error when set, and counts the calls.
+// Calls block while release is set and open.
+type fakeService struct {
+	calls   int32
+	price   currency.USD
+	err     error
+	release chan struct{}
+}
+
+func (service *fakeService) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	atomic.AddInt32(&service.calls, 1)
+	if service.release != nil {
+		<-service.release
+	}
+	return service.price, service.err
+}
+
+func (service *fakeService) callCount() int {
+	return int(atomic.LoadInt32(&service.calls))
+}
+
+// newTestCache returns a cache with the clock controlled by the returned
+// function, which advances it.
+func newTestCache(service CoinmarketService) (*CachedService, func(time.Duration)) {
+	cache := NewCachedService(service, CacheOptions{TTL: time.Minute, MaxAge: 10 * time.Minute})
+	now := time.Date(2021, 2, 16, 15, 0, 0, 0, time.UTC)
+	var mutex sync.Mutex
+	cache.now = func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return now
+	}
+	return cache, func(duration time.Duration) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		now = now.Add(duration)
+	}
+}
+
+func TestCachedServiceTTL(t *testing.T) {
+	service := &fakeService{price: currency.NewUSD(100)}
+	cache, advance := newTestCache(service)
+
+	price, err := cache.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(100), price)
+
+	service.price = currency.NewUSD(200)
+	advance(30 * time.Second)
+	price, err = cache.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(100), price)
+	assert.Equal(t, 1, service.callCount())
+
+	advance(30 * time.Second)
+	price, err = cache.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(200), price)
+	assert.Equal(t, 2, service.callCount())
+}
+
+func TestCachedServiceSingleFlight(t *testing.T) {
+	service := &fakeService{price: currency.NewUSD(100), release: make(chan struct{})}
+	cache, _ := newTestCache(service)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			price, err := cache.GetBTCPriceInUSD(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, currency.NewUSD(100), price)
+		}()
+	}
+
+	// a caller giving up does not cancel the shared refresh
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cache.GetBTCPriceInUSD(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Eventually(t, func() bool { return service.callCount() == 1 }, time.Second, time.Millisecond)
+	close(service.release)
+	wg.Wait()
+	assert.Equal(t, 1, service.callCount())
+}
+
+func TestCachedServiceStaleWhileError(t *testing.T) {
+	service := &fakeService{price: currency.NewUSD(100)}
+	cache, advance := newTestCache(service)
+
+	_, err := cache.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+
+	service.err = errors.New("quota exceeded")
+	advance(5 * time.Minute)
+	price, err := cache.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(100), price)
+
+	advance(5 * time.Minute)
+	_, err = cache.GetBTCPriceInUSD(context.Background())
+	assert.EqualError(t, err, "quota exceeded")
+	assert.Equal(t, 3, service.callCount())
+}
+
+func TestCachedServiceRefresher(t *testing.T) {
+	service := &fakeService{price: currency.NewUSD(100)}
+	cache := NewCachedService(service, CacheOptions{TTL: time.Hour, MaxAge: time.Hour, RefreshInterval: time.Millisecond})
+	defer cache.Close()
+
+	assert.Eventually(t, func() bool { return service.callCount() >= 2 }, time.Second, time.Millisecond)
+
+	// requests are served by the refreshed quote
+	cache.Close()
+	time.Sleep(5 * time.Millisecond)
+	calls := service.callCount()
+	price, err := cache.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(100), price)
+	assert.Equal(t, calls, service.callCount())
+}
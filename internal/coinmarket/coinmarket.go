@@ -4,14 +4,42 @@ package coinmarket
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type CoinmarketService interface {
-	GetBTCPriceInUSD(ctx context.Context) (float64, error)
+	GetBTCPriceInUSD(ctx context.Context) (currency.USD, error)
+}
+
+// Quote is a BTC price and the time it was quoted at.
+type Quote struct {
+	Price     currency.USD
+	Timestamp time.Time
+	// Sources are the names of the providers the price was aggregated from.
+	Sources []string
+}
+
+// QuoteService is implemented by services which know the time of their prices.
+type QuoteService interface {
+	GetBTCQuoteInUSD(ctx context.Context) (Quote, error)
+}
+
+// GetBTCQuoteInUSD returns the quote of the service, or its price stamped with
+// the current time when the service does not tell the time.
+func GetBTCQuoteInUSD(ctx context.Context, service CoinmarketService) (Quote, error) {
+	if quoteService, ok := service.(QuoteService); ok {
+		return quoteService.GetBTCQuoteInUSD(ctx)
+	}
+
+	price, err := service.GetBTCPriceInUSD(ctx)
+	if err != nil {
+		return Quote{}, err
+	}
+	return Quote{Price: price, Timestamp: time.Now()}, nil
 }
 
 type CoinmarketServiceImpl struct {
@@ -22,7 +50,18 @@ func NewCoinmarketService(apiKey string) CoinmarketService {
 	return &CoinmarketServiceImpl{ApiKey: apiKey}
 }
 
-func (service *CoinmarketServiceImpl) GetBTCPriceInUSD(ctx context.Context) (float64, error) {
+func (service *CoinmarketServiceImpl) Name() string {
+	return "coinmarketcap"
+}
+
+func (service *CoinmarketServiceImpl) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	quote, err := service.GetBTCQuoteInUSD(ctx)
+	return quote.Price, err
+}
+
+// GetBTCQuoteInUSD returns the price with the time of its last update, or
+// the current time when the response does not tell it.
+func (service *CoinmarketServiceImpl) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
 	client := &http.Client{}
 	req, err := http.NewRequestWithContext(
 		ctx,
@@ -31,7 +70,7 @@ func (service *CoinmarketServiceImpl) GetBTCPriceInUSD(ctx context.Context) (flo
 		http.NoBody,
 	)
 	if err != nil {
-		return 0, err
+		return Quote{}, err
 	}
 
 	q := url.Values{}
@@ -43,21 +82,29 @@ func (service *CoinmarketServiceImpl) GetBTCPriceInUSD(ctx context.Context) (flo
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("error sending request to server: %w", err)
+		return Quote{}, fmt.Errorf("error sending request to server: %w", err)
 	}
 	defer resp.Body.Close()
 
-	var jsonResponse interface{}
-	if err = json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
-		return 0, fmt.Errorf("invalid response from server: %w", err)
+	jsonResponse, err := decodeJson(resp.Body)
+	if err != nil {
+		return Quote{}, err
 	}
 
 	btcPrice, _ := getValueFromJson(jsonResponse, "data", "BTC", "quote", "USD", "price")
-	btcPrice, ok := btcPrice.(float64)
-	if !ok {
-		return 0, fmt.Errorf("unexpected response from server: %w", err)
+	price, err := parsePrice(btcPrice)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	quote := Quote{Price: price, Timestamp: time.Now()}
+	lastUpdated, _ := getValueFromJson(jsonResponse, "data", "BTC", "quote", "USD", "last_updated")
+	if lastUpdated, ok := lastUpdated.(string); ok {
+		if timestamp, err := time.Parse(time.RFC3339, lastUpdated); err == nil {
+			quote.Timestamp = timestamp
+		}
 	}
-	return btcPrice.(float64), nil
+	return quote, nil
 }
 
 func getValueFromJson(jsonData interface{}, path ...string) (interface{}, error) {
@@ -2,10 +2,12 @@ package coinmarket
 
 import (
 	"context"
+	"github.com/galcik/vlexchange/internal/currency"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"testing"
+	"time"
 )
 
 const CoinmarketResponse = `
@@ -74,5 +76,11 @@ func TestGetBTCPriceInUSD(t *testing.T) {
 	coinmarketService := NewCoinmarketService("1111-2222")
 	btcPrice, err := coinmarketService.GetBTCPriceInUSD(context.Background())
 	assert.NoError(t, err)
-	assert.Equal(t, 49239.06561166671, btcPrice)
+	// the price is rounded to cents
+	assert.Equal(t, currency.USD(4923907), btcPrice)
+
+	quote, err := GetBTCQuoteInUSD(context.Background(), coinmarketService)
+	assert.NoError(t, err)
+	assert.Equal(t, currency.USD(4923907), quote.Price)
+	assert.Equal(t, time.Date(2021, 2, 16, 15, 38, 2, 0, time.UTC), quote.Timestamp.UTC())
 }
@@ -0,0 +1,164 @@
+package coinmarket
+
+import (
+	"context"
+	"errors"
+	"github.com/galcik/vlexchange/internal/currency"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrNoPriceHistory is returned for times without recorded prices nearby.
+var ErrNoPriceHistory = errors.New("no price history")
+
+// PriceHistory stores the quotes recorded by HistoryService.
+type PriceHistory interface {
+	RecordBTCQuote(ctx context.Context, quote Quote) error
+	// GetBTCQuotesAround returns the last quote at or before the time and
+	// the first one after it, nil when there is none.
+	GetBTCQuotesAround(ctx context.Context, at time.Time) (*Quote, *Quote, error)
+}
+
+// HistoricalService is implemented by services which know past prices.
+type HistoricalService interface {
+	GetBTCPriceAt(ctx context.Context, at time.Time) (currency.USD, error)
+}
+
+// GetBTCPriceAt returns the price of the service at the time, services
+// without history fail with ErrNoPriceHistory.
+func GetBTCPriceAt(ctx context.Context, service CoinmarketService, at time.Time) (currency.USD, error) {
+	if historicalService, ok := service.(HistoricalService); ok {
+		return historicalService.GetBTCPriceAt(ctx, at)
+	}
+	return 0, ErrNoPriceHistory
+}
+
+type HistoryOptions struct {
+	// RecordInterval is the cadence of recording the quotes of the service,
+	// zero disables recording.
+	RecordInterval time.Duration
+	// MaxGap is the distance from the time up to which recorded prices are
+	// used for it.
+	MaxGap time.Duration
+}
+
+func DefaultHistoryOptions() HistoryOptions {
+	return HistoryOptions{MaxGap: 5 * time.Minute}
+}
+
+// HistoryService serves the quotes of the wrapped service, records them to
+// the history at a fixed cadence and looks up past prices there.
+type HistoryService struct {
+	service CoinmarketService
+	history PriceHistory
+	options HistoryOptions
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewHistoryService wraps the service and starts recording its quotes when
+// the options enable it. Close stops the recording.
+func NewHistoryService(service CoinmarketService, history PriceHistory, options HistoryOptions) *HistoryService {
+	historyService := &HistoryService{
+		service: service,
+		history: history,
+		options: options,
+		stop:    make(chan struct{}),
+	}
+	if options.RecordInterval > 0 {
+		go historyService.recordPeriodically()
+	}
+	return historyService
+}
+
+func (service *HistoryService) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	return service.service.GetBTCPriceInUSD(ctx)
+}
+
+func (service *HistoryService) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	return GetBTCQuoteInUSD(ctx, service.service)
+}
+
+// GetBTCPriceAt interpolates between the recorded prices around the time.
+// Only prices recorded within MaxGap of the time are used.
+func (service *HistoryService) GetBTCPriceAt(ctx context.Context, at time.Time) (currency.USD, error) {
+	before, after, err := service.history.GetBTCQuotesAround(ctx, at)
+	if err != nil {
+		return 0, err
+	}
+	if before != nil && at.Sub(before.Timestamp) > service.options.MaxGap {
+		before = nil
+	}
+	if after != nil && after.Timestamp.Sub(at) > service.options.MaxGap {
+		after = nil
+	}
+
+	switch {
+	case before == nil && after == nil:
+		return 0, ErrNoPriceHistory
+	case after == nil:
+		return before.Price, nil
+	case before == nil:
+		return after.Price, nil
+	}
+	return interpolate(*before, *after, at)
+}
+
+func (service *HistoryService) Close() {
+	service.stopOnce.Do(func() { close(service.stop) })
+}
+
+// interpolate returns the price at the time on the line between the quotes,
+// rounded half to even.
+func interpolate(before Quote, after Quote, at time.Time) (currency.USD, error) {
+	span := after.Timestamp.Sub(before.Timestamp)
+	if span <= 0 {
+		return before.Price, nil
+	}
+	change, err := currency.MulDiv(
+		int64(after.Price-before.Price), int64(at.Sub(before.Timestamp)), int64(span), currency.RoundHalfEven,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return before.Price + currency.USD(change), nil
+}
+
+// recordPeriodically records the quotes of the service, the same quote is
+// recorded once.
+func (service *HistoryService) recordPeriodically() {
+	ticker := time.NewTicker(service.options.RecordInterval)
+	defer ticker.Stop()
+	var recorded time.Time
+	for {
+		select {
+		case <-service.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if err := service.record(&recorded); err != nil {
+			log.Printf("recording BTC price failed: %v", err)
+		}
+	}
+}
+
+func (service *HistoryService) record(recorded *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), service.options.RecordInterval)
+	defer cancel()
+
+	quote, err := GetBTCQuoteInUSD(ctx, service.service)
+	if err != nil {
+		return err
+	}
+	if !quote.Timestamp.After(*recorded) {
+		return nil
+	}
+	if err := service.history.RecordBTCQuote(ctx, quote); err != nil {
+		return err
+	}
+	*recorded = quote.Timestamp
+	return nil
+}
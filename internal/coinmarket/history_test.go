@@ -0,0 +1,120 @@
+package coinmarket
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryHistory keeps the recorded quotes sorted by their timestamps.
+type memoryHistory struct {
+	mutex  sync.Mutex
+	quotes []Quote
+}
+
+func (history *memoryHistory) RecordBTCQuote(ctx context.Context, quote Quote) error {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	history.quotes = append(history.quotes, quote)
+	sort.Slice(history.quotes, func(i, j int) bool {
+		return history.quotes[i].Timestamp.Before(history.quotes[j].Timestamp)
+	})
+	return nil
+}
+
+func (history *memoryHistory) GetBTCQuotesAround(ctx context.Context, at time.Time) (*Quote, *Quote, error) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	i := sort.Search(len(history.quotes), func(i int) bool { return history.quotes[i].Timestamp.After(at) })
+	var before, after *Quote
+	if i > 0 {
+		before = &history.quotes[i-1]
+	}
+	if i < len(history.quotes) {
+		after = &history.quotes[i]
+	}
+	return before, after, nil
+}
+
+func (history *memoryHistory) count() int {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	return len(history.quotes)
+}
+
+func TestHistoryServiceGetBTCPriceAt(t *testing.T) {
+	start := time.Date(2021, 2, 16, 15, 0, 0, 0, time.UTC)
+	history := &memoryHistory{}
+	for i, price := range []currency.USD{10000, 11000, 9000} {
+		require.NoError(t, history.RecordBTCQuote(
+			context.Background(), Quote{Price: price, Timestamp: start.Add(time.Duration(i) * time.Minute)},
+		))
+	}
+	require.NoError(t, history.RecordBTCQuote(context.Background(), Quote{Price: currency.NewUSD(200), Timestamp: start.Add(time.Hour)}))
+	service := NewHistoryService(&fakeService{}, history, DefaultHistoryOptions())
+
+	for _, step := range []struct {
+		at    time.Duration
+		price currency.USD
+	}{
+		{0, 10000},
+		{30 * time.Second, 10500},
+		{time.Minute + 15*time.Second, 10500},
+		// the interpolated price is rounded half to even
+		{150 * time.Millisecond, 10002},
+		{2 * time.Minute, 9000},
+		// the price an hour later is too far for interpolation
+		{4 * time.Minute, 9000},
+		{-time.Minute, 10000},
+		{56 * time.Minute, 20000},
+	} {
+		price, err := service.GetBTCPriceAt(context.Background(), start.Add(step.at))
+		require.NoError(t, err, step.at)
+		assert.Equal(t, step.price, price, step.at)
+	}
+
+	_, err := service.GetBTCPriceAt(context.Background(), start.Add(30*time.Minute))
+	assert.ErrorIs(t, err, ErrNoPriceHistory)
+	_, err = GetBTCPriceAt(context.Background(), &fakeService{}, start)
+	assert.ErrorIs(t, err, ErrNoPriceHistory)
+}
+
+func TestHistoryServiceRecords(t *testing.T) {
+	history := &memoryHistory{}
+	provider := NewFixedProvider(currency.NewUSD(100))
+	service := NewHistoryService(provider, history, HistoryOptions{RecordInterval: time.Millisecond, MaxGap: time.Minute})
+	defer service.Close()
+
+	assert.Eventually(t, func() bool { return history.count() >= 2 }, time.Second, time.Millisecond)
+	price, err := service.GetBTCPriceAt(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(100), price)
+
+	// the same quote is recorded once
+	var recorded time.Time
+	history = &memoryHistory{}
+	quote := Quote{Price: currency.NewUSD(100), Timestamp: time.Now()}
+	cached := NewHistoryService(fakeQuoteService{quote}, history, HistoryOptions{RecordInterval: time.Hour})
+	defer cached.Close()
+	require.NoError(t, cached.record(&recorded))
+	require.NoError(t, cached.record(&recorded))
+	assert.Equal(t, 1, history.count())
+	assert.Equal(t, quote.Timestamp, recorded)
+}
+
+type fakeQuoteService struct {
+	quote Quote
+}
+
+func (service fakeQuoteService) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	return service.quote.Price, nil
+}
+
+func (service fakeQuoteService) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	return service.quote, nil
+}
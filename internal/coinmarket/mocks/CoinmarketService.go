@@ -5,6 +5,8 @@ package mocks
 import (
 	context "context"
 
+	currency "github.com/galcik/vlexchange/internal/currency"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -14,14 +16,14 @@ type CoinmarketService struct {
 }
 
 // GetBTCPriceInUSD provides a mock function with given fields: ctx
-func (_m *CoinmarketService) GetBTCPriceInUSD(ctx context.Context) (float64, error) {
+func (_m *CoinmarketService) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
 	ret := _m.Called(ctx)
 
-	var r0 float64
-	if rf, ok := ret.Get(0).(func(context.Context) float64); ok {
+	var r0 currency.USD
+	if rf, ok := ret.Get(0).(func(context.Context) currency.USD); ok {
 		r0 = rf(ctx)
 	} else {
-		r0 = ret.Get(0).(float64)
+		r0 = ret.Get(0).(currency.USD)
 	}
 
 	var r1 error
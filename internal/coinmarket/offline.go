@@ -0,0 +1,259 @@
+package coinmarket
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRandomWalkVolatility is the relative standard deviation of the steps
+// of random walks parsed without a volatility.
+const DefaultRandomWalkVolatility = 0.001
+
+// The offline providers name themselves as the sources of their quotes.
+
+// FixedProvider quotes a constant price.
+type FixedProvider struct {
+	Price currency.USD
+}
+
+func NewFixedProvider(price currency.USD) *FixedProvider {
+	return &FixedProvider{Price: price}
+}
+
+func (provider *FixedProvider) Name() string {
+	return "fixed"
+}
+
+func (provider *FixedProvider) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	return provider.Price, nil
+}
+
+func (provider *FixedProvider) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	return Quote{Price: provider.Price, Timestamp: time.Now(), Sources: []string{provider.Name()}}, nil
+}
+
+// ReplayPoint is a price of a historical series.
+type ReplayPoint struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Price     currency.USD `json:"price"`
+}
+
+// UnmarshalJSON reads prices written as numbers or as decimal strings.
+func (point *ReplayPoint) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Timestamp time.Time   `json:"timestamp"`
+		Price     json.Number `json:"price"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	price, err := parsePrice(raw.Price)
+	if err != nil {
+		return err
+	}
+	*point = ReplayPoint{Timestamp: raw.Timestamp, Price: price}
+	return nil
+}
+
+// ReplayProvider plays back a historical series in real time from its
+// creation, starting over once the series ends. Quotes are stamped with the
+// current time.
+type ReplayProvider struct {
+	points []ReplayPoint
+	start  time.Time
+	now    func() time.Time
+}
+
+func NewReplayProvider(points []ReplayPoint) (*ReplayProvider, error) {
+	if len(points) == 0 {
+		return nil, fmt.Errorf("empty price series")
+	}
+	points = append([]ReplayPoint(nil), points...)
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	for _, point := range points {
+		if point.Price <= 0 {
+			return nil, fmt.Errorf("unexpected price %v at %v", point.Price, point.Timestamp)
+		}
+	}
+	return &ReplayProvider{points: points, start: time.Now(), now: time.Now}, nil
+}
+
+// LoadReplayProvider reads the series from a JSON file, an array of points,
+// or from a CSV file of timestamp,price rows.
+func LoadReplayProvider(path string) (*ReplayProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var points []ReplayPoint
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.NewDecoder(file).Decode(&points)
+	} else {
+		points, err = readReplayCSV(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading price series %s: %w", path, err)
+	}
+	return NewReplayProvider(points)
+}
+
+// readReplayCSV reads rows of RFC 3339 or unix timestamps and prices, the
+// first row may be a header.
+func readReplayCSV(reader io.Reader) ([]ReplayPoint, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = 2
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var points []ReplayPoint
+	for i, record := range records {
+		timestamp, timestampErr := parseTimestamp(record[0])
+		price, priceErr := parsePrice(record[1])
+		if timestampErr != nil || priceErr != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("malformed row %d: %q", i+1, strings.Join(record, ","))
+		}
+		points = append(points, ReplayPoint{Timestamp: timestamp, Price: price})
+	}
+	return points, nil
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func (provider *ReplayProvider) Name() string {
+	return "replay"
+}
+
+func (provider *ReplayProvider) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	quote, err := provider.GetBTCQuoteInUSD(ctx)
+	return quote.Price, err
+}
+
+func (provider *ReplayProvider) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	now := provider.now()
+	first := provider.points[0].Timestamp
+	elapsed := now.Sub(provider.start)
+	if length := provider.points[len(provider.points)-1].Timestamp.Sub(first); length > 0 {
+		elapsed %= length
+	} else {
+		elapsed = 0
+	}
+
+	// the last point not after the played back time
+	played := first.Add(elapsed)
+	i := sort.Search(len(provider.points), func(i int) bool { return provider.points[i].Timestamp.After(played) })
+	return Quote{Price: provider.points[i-1].Price, Timestamp: now, Sources: []string{provider.Name()}}, nil
+}
+
+// RandomWalkProvider simulates a price moving by a random relative step with
+// every quote.
+type RandomWalkProvider struct {
+	volatility float64
+
+	mutex sync.Mutex
+	price currency.USD
+	rand  *rand.Rand
+}
+
+// NewRandomWalkProvider starts the walk at the price. The volatility is the
+// standard deviation of the steps relative to the price.
+func NewRandomWalkProvider(price currency.USD, volatility float64, seed int64) *RandomWalkProvider {
+	return &RandomWalkProvider{volatility: volatility, price: price, rand: rand.New(rand.NewSource(seed))}
+}
+
+func (provider *RandomWalkProvider) Name() string {
+	return "random"
+}
+
+func (provider *RandomWalkProvider) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	quote, err := provider.GetBTCQuoteInUSD(ctx)
+	return quote.Price, err
+}
+
+func (provider *RandomWalkProvider) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	// log-normal steps keep the price positive, only the step is computed in
+	// floating point and the stepped price is read back as a decimal
+	step := math.Exp(provider.volatility * provider.rand.NormFloat64())
+	stepped := strconv.FormatFloat(provider.price.Float64()*step, 'f', -1, 64)
+	price, err := currency.AssetUSD.ParseRounded(stepped, currency.RoundHalfEven)
+	if err != nil {
+		return Quote{}, err
+	}
+	// the price does not fall below a cent
+	if price > 0 {
+		provider.price = currency.USD(price)
+	}
+	return Quote{Price: provider.price, Timestamp: time.Now(), Sources: []string{provider.Name()}}, nil
+}
+
+// ParseProvider creates an offline provider, which is a Provider as well,
+// from a specification:
+//
+//	fixed:<price>
+//	replay:<path to a .csv or .json file>
+//	random:<starting price>[/<volatility>]
+func ParseProvider(spec string) (CoinmarketService, error) {
+	parts := strings.SplitN(strings.TrimSpace(spec), ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("malformed price provider %q", spec)
+	}
+
+	switch parts[0] {
+	case "fixed":
+		price, err := parsePrice(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed price provider %q: %w", spec, err)
+		}
+		return NewFixedProvider(price), nil
+	case "replay":
+		provider, err := LoadReplayProvider(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	case "random":
+		params := strings.SplitN(parts[1], "/", 2)
+		price, err := parsePrice(params[0])
+		if err != nil {
+			return nil, fmt.Errorf("malformed price provider %q: %w", spec, err)
+		}
+		volatility := DefaultRandomWalkVolatility
+		if len(params) == 2 {
+			volatility, err = strconv.ParseFloat(params[1], 64)
+			if err != nil || volatility < 0 {
+				return nil, fmt.Errorf("malformed volatility in price provider %q", spec)
+			}
+		}
+		return NewRandomWalkProvider(price, volatility, time.Now().UnixNano()), nil
+	default:
+		return nil, fmt.Errorf("unknown price provider %q", parts[0])
+	}
+}
@@ -0,0 +1,117 @@
+package coinmarket
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTempFile(t *testing.T, name string, content string) string {
+	dir, err := ioutil.TempDir("", "coinmarket")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestFixedProvider(t *testing.T) {
+	provider, err := ParseProvider("fixed:45000.5")
+	require.NoError(t, err)
+
+	price, err := provider.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(45000.5), price)
+}
+
+func TestReplayProvider(t *testing.T) {
+	csvPath := writeTempFile(t, "prices.csv", "timestamp,price\n"+
+		"2021-02-16T15:00:00Z,100\n"+
+		"2021-02-16T15:01:00Z,110\n"+
+		"2021-02-16T15:03:00Z,105\n")
+	jsonPath := writeTempFile(t, "prices.json", `[
+		{"timestamp": "2021-02-16T15:03:00Z", "price": 105},
+		{"timestamp": "2021-02-16T15:00:00Z", "price": 100},
+		{"timestamp": "2021-02-16T15:01:00Z", "price": "110.00"}
+	]`)
+
+	for _, path := range []string{csvPath, jsonPath} {
+		provider, err := LoadReplayProvider(path)
+		require.NoError(t, err, path)
+		now := provider.start
+		provider.now = func() time.Time { return now }
+
+		for _, step := range []struct {
+			elapsed time.Duration
+			price   currency.USD
+		}{
+			{0, 10000},
+			{59 * time.Second, 10000},
+			{time.Minute, 11000},
+			{2 * time.Minute, 11000},
+			// the series starts over after three minutes
+			{3 * time.Minute, 10000},
+			{4*time.Minute + 30*time.Second, 11000},
+		} {
+			now = provider.start.Add(step.elapsed)
+			quote, err := provider.GetBTCQuoteInUSD(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, step.price, quote.Price, "%s after %v", path, step.elapsed)
+			assert.Equal(t, now, quote.Timestamp)
+		}
+	}
+}
+
+func TestReplayProviderErrors(t *testing.T) {
+	path := writeTempFile(t, "prices.csv", "1613487600,100\n1613487660,abc\n")
+	_, err := LoadReplayProvider(path)
+	assert.EqualError(t, err, "reading price series "+path+`: malformed row 2: "1613487660,abc"`)
+
+	_, err = LoadReplayProvider(writeTempFile(t, "prices.csv", "timestamp,price\n"))
+	assert.EqualError(t, err, "empty price series")
+
+	_, err = LoadReplayProvider(writeTempFile(t, "prices.json", `[{"timestamp": "2021-02-16T15:00:00Z", "price": 0}]`))
+	assert.Error(t, err)
+
+	_, err = ParseProvider("replay:/nonexistent/prices.csv")
+	assert.Error(t, err)
+}
+
+func TestRandomWalkProvider(t *testing.T) {
+	walk := NewRandomWalkProvider(currency.NewUSD(50000), 0.01, 1)
+	replayed := NewRandomWalkProvider(currency.NewUSD(50000), 0.01, 1)
+
+	previous := currency.NewUSD(50000)
+	for i := 0; i < 100; i++ {
+		price, err := walk.GetBTCPriceInUSD(context.Background())
+		require.NoError(t, err)
+		assert.Greater(t, int64(price), int64(0))
+		assert.NotEqual(t, previous, price)
+		assert.InEpsilon(t, previous.Float64(), price.Float64(), 0.1)
+		previous = price
+
+		replayedPrice, err := replayed.GetBTCPriceInUSD(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, price, replayedPrice)
+	}
+
+	provider, err := ParseProvider("random:100/0")
+	require.NoError(t, err)
+	price, err := provider.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(100), price)
+}
+
+func TestParseProviderErrors(t *testing.T) {
+	for _, spec := range []string{"", "fixed", "fixed:", "fixed:-1", "fixed:abc", "random:100/-1", "live:1"} {
+		_, err := ParseProvider(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,134 @@
+package coinmarket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrNoQuote is returned by providers which work but have no price to quote,
+// it does not count as a failure of the provider.
+var ErrNoQuote = errors.New("no quote")
+
+// Provider is a source of BTC prices of the Registry.
+type Provider interface {
+	Name() string
+	GetBTCQuoteInUSD(ctx context.Context) (Quote, error)
+}
+
+var _ Provider = (*CoinmarketServiceImpl)(nil)
+
+// CoinbaseProvider quotes the spot price of Coinbase.
+type CoinbaseProvider struct{}
+
+func NewCoinbaseProvider() *CoinbaseProvider {
+	return &CoinbaseProvider{}
+}
+
+func (provider *CoinbaseProvider) Name() string {
+	return "coinbase"
+}
+
+// GetBTCQuoteInUSD returns the spot price, which Coinbase does not timestamp.
+func (provider *CoinbaseProvider) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	jsonResponse, err := getJson(ctx, "https://api.coinbase.com/v2/prices/BTC-USD/spot")
+	if err != nil {
+		return Quote{}, err
+	}
+
+	amount, _ := getValueFromJson(jsonResponse, "data", "amount")
+	price, err := parsePrice(amount)
+	if err != nil {
+		return Quote{}, err
+	}
+	return Quote{Price: price, Timestamp: time.Now()}, nil
+}
+
+// BitstampProvider quotes the last trade of Bitstamp.
+type BitstampProvider struct{}
+
+func NewBitstampProvider() *BitstampProvider {
+	return &BitstampProvider{}
+}
+
+func (provider *BitstampProvider) Name() string {
+	return "bitstamp"
+}
+
+func (provider *BitstampProvider) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	jsonResponse, err := getJson(ctx, "https://www.bitstamp.net/api/v2/ticker/btcusd/")
+	if err != nil {
+		return Quote{}, err
+	}
+
+	last, _ := getValueFromJson(jsonResponse, "last")
+	price, err := parsePrice(last)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	quote := Quote{Price: price, Timestamp: time.Now()}
+	timestamp, _ := getValueFromJson(jsonResponse, "timestamp")
+	if timestamp, ok := timestamp.(string); ok {
+		if seconds, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+			quote.Timestamp = time.Unix(seconds, 0)
+		}
+	}
+	return quote, nil
+}
+
+func getJson(ctx context.Context, url string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accepts", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request to server: %w", err)
+	}
+	defer resp.Body.Close()
+	return decodeJson(resp.Body)
+}
+
+// decodeJson keeps the numbers of the response as json.Number, so that prices
+// are read exactly.
+func decodeJson(reader io.Reader) (interface{}, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	var jsonResponse interface{}
+	if err := decoder.Decode(&jsonResponse); err != nil {
+		return nil, fmt.Errorf("invalid response from server: %w", err)
+	}
+	return jsonResponse, nil
+}
+
+// parsePrice accepts the prices sent as numbers or as decimal strings. They
+// are rounded half to even to cents.
+func parsePrice(value interface{}) (currency.USD, error) {
+	var priceStr string
+	switch value := value.(type) {
+	case json.Number:
+		priceStr = value.String()
+	case string:
+		priceStr = value
+	default:
+		return 0, fmt.Errorf("unexpected response from server")
+	}
+
+	price, err := currency.AssetUSD.ParseRounded(priceStr, currency.RoundHalfEven)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected price %q", priceStr)
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("unexpected price %q", priceStr)
+	}
+	return currency.USD(price), nil
+}
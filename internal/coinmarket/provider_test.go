@@ -0,0 +1,55 @@
+package coinmarket
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCoinbaseProvider(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/prices/BTC-USD/spot",
+		httpmock.NewStringResponder(200, `{"data": {"base": "BTC", "currency": "USD", "amount": "49250.12"}}`),
+	)
+
+	quote, err := NewCoinbaseProvider().GetBTCQuoteInUSD(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(49250.12), quote.Price)
+	assert.False(t, quote.Timestamp.IsZero())
+}
+
+func TestBitstampProvider(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://www.bitstamp.net/api/v2/ticker/btcusd/",
+		httpmock.NewStringResponder(200, `{"last": "49228.50", "timestamp": "1613489882", "volume": "5467.4"}`),
+	)
+
+	quote, err := NewBitstampProvider().GetBTCQuoteInUSD(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(49228.5), quote.Price)
+	assert.Equal(t, time.Date(2021, 2, 16, 15, 38, 2, 0, time.UTC), quote.Timestamp.UTC())
+}
+
+func TestProviderErrors(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/prices/BTC-USD/spot",
+		httpmock.NewStringResponder(500, `{"errors": [{"id": "internal_server_error"}]}`),
+	)
+	httpmock.RegisterResponder("GET", "https://www.bitstamp.net/api/v2/ticker/btcusd/",
+		httpmock.NewStringResponder(200, `{"last": "-1"}`),
+	)
+
+	_, err := NewCoinbaseProvider().GetBTCQuoteInUSD(context.Background())
+	assert.Error(t, err)
+	_, err = NewBitstampProvider().GetBTCQuoteInUSD(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package coinmarket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/currency"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is reported for providers skipped after repeated failures.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// ErrNoPrice is returned when too few sources agree on the price.
+var ErrNoPrice = errors.New("no price available")
+
+type RegistryOptions struct {
+	// MaxDeviation is the relative distance from the median beyond which
+	// quotes are rejected as outliers.
+	MaxDeviation float64
+	// MinSources is the number of agreeing quotes required for a price.
+	MinSources int
+	// Timeout limits the queries of single providers, zero means no limit.
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failures which open the
+	// circuit breaker of a provider.
+	FailureThreshold int
+	// Cooldown is the time before a provider with an open breaker is retried.
+	Cooldown time.Duration
+}
+
+func DefaultRegistryOptions() RegistryOptions {
+	return RegistryOptions{
+		MaxDeviation:     0.05,
+		MinSources:       1,
+		Timeout:          5 * time.Second,
+		FailureThreshold: 3,
+		Cooldown:         time.Minute,
+	}
+}
+
+// SourceQuote is the quote of a single provider.
+type SourceQuote struct {
+	Source    string
+	Price     currency.USD
+	Timestamp time.Time
+}
+
+// AggregateQuote is the median of the quotes of the registered providers.
+type AggregateQuote struct {
+	Quote
+	// Used are the quotes the price is the median of.
+	Used []SourceQuote
+	// Rejected are the outliers.
+	Rejected []SourceQuote
+	// Failed are the errors of the providers which did not quote.
+	Failed map[string]error
+}
+
+// Registry queries its providers concurrently and aggregates their quotes.
+// Every provider has a circuit breaker which skips it after failures.
+type Registry struct {
+	options RegistryOptions
+	now     func() time.Time
+
+	mutex     sync.Mutex
+	providers []*registeredProvider
+}
+
+type registeredProvider struct {
+	provider Provider
+	breaker  *breaker
+}
+
+func NewRegistry(options RegistryOptions, providers ...Provider) *Registry {
+	registry := &Registry{options: options, now: time.Now}
+	for _, provider := range providers {
+		registry.Register(provider)
+	}
+	return registry
+}
+
+func (registry *Registry) Register(provider Provider) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.providers = append(registry.providers, &registeredProvider{
+		provider: provider,
+		breaker:  newBreaker(registry.options.FailureThreshold, registry.options.Cooldown),
+	})
+}
+
+func (registry *Registry) GetBTCPriceInUSD(ctx context.Context) (currency.USD, error) {
+	quote, err := registry.GetBTCQuoteInUSD(ctx)
+	return quote.Price, err
+}
+
+// GetBTCQuoteInUSD returns the aggregated price, timestamped with the oldest
+// of the used quotes.
+func (registry *Registry) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	aggregate, err := registry.Aggregate(ctx)
+	if err != nil {
+		return Quote{}, err
+	}
+	return aggregate.Quote, nil
+}
+
+// Aggregate queries the providers and returns the median of their quotes
+// without the outliers.
+func (registry *Registry) Aggregate(ctx context.Context) (*AggregateQuote, error) {
+	registry.mutex.Lock()
+	providers := append([]*registeredProvider(nil), registry.providers...)
+	registry.mutex.Unlock()
+
+	quotes := make([]Quote, len(providers))
+	errs := make([]error, len(providers))
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		if !provider.breaker.allow(registry.now()) {
+			errs[i] = ErrCircuitOpen
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, provider *registeredProvider) {
+			defer wg.Done()
+			quoteCtx := ctx
+			if registry.options.Timeout > 0 {
+				var cancel context.CancelFunc
+				quoteCtx, cancel = context.WithTimeout(ctx, registry.options.Timeout)
+				defer cancel()
+			}
+			quotes[i], errs[i] = provider.provider.GetBTCQuoteInUSD(quoteCtx)
+
+			// callers giving up do not count against the provider
+			if ctx.Err() != nil {
+				provider.breaker.release()
+				return
+			}
+			failed := errs[i] != nil && !errors.Is(errs[i], ErrNoQuote)
+			provider.breaker.record(failed, registry.now())
+		}(i, provider)
+	}
+	wg.Wait()
+
+	aggregate := &AggregateQuote{Failed: make(map[string]error)}
+	var candidates []SourceQuote
+	for i, provider := range providers {
+		if errs[i] != nil {
+			aggregate.Failed[provider.provider.Name()] = errs[i]
+			continue
+		}
+		candidates = append(candidates, SourceQuote{
+			Source:    provider.provider.Name(),
+			Price:     quotes[i].Price,
+			Timestamp: quotes[i].Timestamp,
+		})
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %d sources failed", ErrNoPrice, len(aggregate.Failed))
+	}
+
+	candidatesMedian := median(candidates)
+	for _, quote := range candidates {
+		// the deviation is a ratio of the prices, the prices stay exact
+		deviation := math.Abs(float64(quote.Price-candidatesMedian)) / float64(candidatesMedian)
+		if deviation > registry.options.MaxDeviation {
+			aggregate.Rejected = append(aggregate.Rejected, quote)
+		} else {
+			aggregate.Used = append(aggregate.Used, quote)
+		}
+	}
+	if len(aggregate.Used) == 0 || len(aggregate.Used) < registry.options.MinSources {
+		return nil, fmt.Errorf("%w: %d of %d quotes agree", ErrNoPrice, len(aggregate.Used), len(candidates))
+	}
+
+	aggregate.Price = median(aggregate.Used)
+	for _, quote := range aggregate.Used {
+		if aggregate.Timestamp.IsZero() || quote.Timestamp.Before(aggregate.Timestamp) {
+			aggregate.Timestamp = quote.Timestamp
+		}
+		aggregate.Sources = append(aggregate.Sources, quote.Source)
+	}
+	return aggregate, nil
+}
+
+// median of an even number of quotes is the mean of the middle two, rounded
+// half to even.
+func median(quotes []SourceQuote) currency.USD {
+	prices := make([]currency.USD, len(quotes))
+	for i, quote := range quotes {
+		prices[i] = quote.Price
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+
+	middle := len(prices) / 2
+	if len(prices)%2 == 0 {
+		// the distance of positive prices cannot overflow
+		distance, _ := currency.MulDiv(int64(prices[middle]-prices[middle-1]), 1, 2, currency.RoundHalfEven)
+		return prices[middle-1] + currency.USD(distance)
+	}
+	return prices[middle]
+}
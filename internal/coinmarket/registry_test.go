@@ -0,0 +1,129 @@
+package coinmarket
+
+import (
+	"context"
+	"errors"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// fakeProvider quotes its price, or fails with its error when set.
+type fakeProvider struct {
+	name  string
+	price currency.USD
+	err   error
+	calls int
+}
+
+func (provider *fakeProvider) Name() string {
+	return provider.name
+}
+
+func (provider *fakeProvider) GetBTCQuoteInUSD(ctx context.Context) (Quote, error) {
+	provider.calls++
+	if provider.err != nil {
+		return Quote{}, provider.err
+	}
+	return Quote{Price: provider.price, Timestamp: time.Unix(int64(provider.price.Float64()), 0)}, nil
+}
+
+func TestRegistryMedian(t *testing.T) {
+	registry := NewRegistry(
+		DefaultRegistryOptions(),
+		&fakeProvider{name: "a", price: currency.NewUSD(100)},
+		&fakeProvider{name: "b", price: currency.NewUSD(102)},
+		&fakeProvider{name: "c", price: currency.NewUSD(101)},
+		&fakeProvider{name: "d", price: currency.NewUSD(104)},
+	)
+
+	quote, err := registry.GetBTCQuoteInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(101.5), quote.Price)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, quote.Sources)
+	assert.Equal(t, time.Unix(100, 0), quote.Timestamp)
+}
+
+func TestRegistryRejectsOutliers(t *testing.T) {
+	registry := NewRegistry(
+		DefaultRegistryOptions(),
+		&fakeProvider{name: "a", price: currency.NewUSD(100)},
+		&fakeProvider{name: "b", price: currency.NewUSD(150)},
+		&fakeProvider{name: "c", price: currency.NewUSD(101)},
+		&fakeProvider{name: "d", price: currency.NewUSD(10), err: errors.New("timeout")},
+	)
+
+	aggregate, err := registry.Aggregate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(100.5), aggregate.Price)
+	assert.Equal(t, []string{"a", "c"}, aggregate.Sources)
+	assert.Equal(t, []SourceQuote{{Source: "b", Price: currency.NewUSD(150), Timestamp: time.Unix(150, 0)}}, aggregate.Rejected)
+	assert.EqualError(t, aggregate.Failed["d"], "timeout")
+
+	// two disagreeing sources cannot tell which one is right
+	registry = NewRegistry(
+		DefaultRegistryOptions(), &fakeProvider{name: "a", price: currency.NewUSD(100)}, &fakeProvider{name: "b", price: currency.NewUSD(150)},
+	)
+	_, err = registry.GetBTCPriceInUSD(context.Background())
+	assert.ErrorIs(t, err, ErrNoPrice)
+}
+
+func TestRegistryMinSources(t *testing.T) {
+	options := DefaultRegistryOptions()
+	options.MinSources = 2
+	failing := &fakeProvider{name: "b", err: errors.New("timeout")}
+	registry := NewRegistry(options, &fakeProvider{name: "a", price: currency.NewUSD(100)}, failing)
+
+	_, err := registry.GetBTCPriceInUSD(context.Background())
+	assert.ErrorIs(t, err, ErrNoPrice)
+
+	failing.err = nil
+	failing.price = currency.NewUSD(101)
+	price, err := registry.GetBTCPriceInUSD(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, currency.NewUSD(100.5), price)
+}
+
+func TestRegistryCircuitBreaker(t *testing.T) {
+	options := DefaultRegistryOptions()
+	options.FailureThreshold = 2
+	options.Cooldown = time.Minute
+	failing := &fakeProvider{name: "failing", err: errors.New("quota exceeded")}
+	empty := &fakeProvider{name: "empty", err: ErrNoQuote}
+	registry := NewRegistry(options, &fakeProvider{name: "working", price: currency.NewUSD(100)}, failing, empty)
+	now := time.Date(2021, 2, 16, 15, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := registry.Aggregate(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, failing.calls)
+	// providers without quotes keep being asked
+	assert.Equal(t, 3, empty.calls)
+
+	aggregate, err := registry.Aggregate(context.Background())
+	require.NoError(t, err)
+	assert.ErrorIs(t, aggregate.Failed["failing"], ErrCircuitOpen)
+
+	// a failed probe opens the breaker again
+	now = now.Add(time.Minute)
+	_, err = registry.Aggregate(context.Background())
+	require.NoError(t, err)
+	_, err = registry.Aggregate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, failing.calls)
+
+	// a successful probe closes it
+	now = now.Add(time.Minute)
+	failing.err = nil
+	failing.price = currency.NewUSD(101)
+	for i := 0; i < 2; i++ {
+		aggregate, err = registry.Aggregate(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"working", "failing"}, aggregate.Sources)
+	}
+	assert.Equal(t, 5, failing.calls)
+}
@@ -0,0 +1,71 @@
+package currency
+
+import (
+	"math"
+	"strings"
+)
+
+// Asset is a currency held in balances. Its amounts are integers counting
+// units of 10^-Precision.
+type Asset struct {
+	Symbol    string
+	Precision int
+}
+
+var AssetBTC = Asset{Symbol: "BTC", Precision: BTCPrecision}
+var AssetUSD = Asset{Symbol: "USD", Precision: USDPrecision}
+
+func (asset Asset) String() string {
+	return asset.Symbol
+}
+
+// Format returns the amount as a decimal string with all decimals of the asset.
+func (asset Asset) Format(amount int64) string {
+	return convertIntToString(amount, asset.Precision)
+}
+
+// Parse reads a decimal amount of the asset, amounts with more decimals than
+// the asset are rejected.
+func (asset Asset) Parse(amountStr string) (int64, error) {
+	return convertStringToAmount(amountStr, asset.Precision, ParseStrict)
+}
+
+// ParseWith reads a decimal amount of the asset in the notations of the mode.
+func (asset Asset) ParseWith(amountStr string, mode ParseMode) (int64, error) {
+	return convertStringToAmount(amountStr, asset.Precision, mode)
+}
+
+// ParseRounded reads a decimal amount of the asset, further decimals are
+// rounded by the mode. Prices quoted by other systems are read this way.
+func (asset Asset) ParseRounded(amountStr string, mode RoundingMode) (int64, error) {
+	intPart, fracPart := amountStr, ""
+	if pos := strings.IndexByte(amountStr, '.'); pos >= 0 {
+		intPart, fracPart = amountStr[:pos], amountStr[pos+1:]
+	}
+	// malformed amounts are reported by the strict parser
+	if len(fracPart) <= asset.Precision || !isDigits(fracPart) {
+		return asset.Parse(amountStr)
+	}
+
+	kept, dropped := fracPart[:asset.Precision], strings.TrimRight(fracPart[asset.Precision:], "0")
+	amount, err := asset.Parse(intPart + "." + kept)
+	if err != nil || dropped == "" {
+		return amount, err
+	}
+
+	cmpHalf := strings.Compare(dropped, "5")
+	negative := strings.HasPrefix(intPart, "-")
+	if !roundsAway(mode, negative, cmpHalf, amount%2 != 0) {
+		return amount, nil
+	}
+	if negative {
+		if amount == math.MinInt64 {
+			return 0, ErrOverflow
+		}
+		return amount - 1, nil
+	}
+	if amount == math.MaxInt64 {
+		return 0, ErrOverflow
+	}
+	return amount + 1, nil
+}
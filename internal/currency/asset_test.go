@@ -0,0 +1,69 @@
+package currency
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAssetFormat(t *testing.T) {
+	testCases := []struct {
+		asset    Asset
+		amount   int64
+		expected string
+	}{
+		{AssetBTC, 150000000, "1.50000000"},
+		{AssetUSD, 4000050, "40000.50"},
+		{Asset{Symbol: "JPY", Precision: 0}, 1200, "1200"},
+		{Asset{Symbol: "ETH", Precision: 18}, -5, "-0.000000000000000005"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		assert.Equal(t, tc.expected, tc.asset.Format(tc.amount))
+	}
+}
+
+func TestAssetParse(t *testing.T) {
+	jpy := Asset{Symbol: "JPY", Precision: 0}
+	amount, err := jpy.Parse("1200")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1200), amount)
+
+	amount, err = AssetUSD.Parse("12.5")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1250), amount)
+
+	_, err = AssetBTC.Parse("1-2")
+	assert.Error(t, err)
+}
+
+func TestAssetParseRounded(t *testing.T) {
+	testCases := []struct {
+		amountStr string
+		mode      RoundingMode
+		expected  int64
+	}{
+		{"49250.12", RoundHalfEven, 4925012},
+		{"49250.1", RoundHalfEven, 4925010},
+		{"49250.123456", RoundHalfEven, 4925012},
+		{"49250.125", RoundHalfEven, 4925012},
+		{"49250.135", RoundHalfEven, 4925014},
+		{"49250.1250001", RoundHalfEven, 4925013},
+		{"49250.12500", RoundHalfEven, 4925012},
+		{"49250.121", RoundCeil, 4925013},
+		{"49250.129", RoundFloor, 4925012},
+		{"-0.125", RoundHalfEven, -12},
+		{"-0.121", RoundFloor, -13},
+		{"1.999", RoundHalfEven, 200},
+	}
+	for _, testCase := range testCases {
+		amount, err := AssetUSD.ParseRounded(testCase.amountStr, testCase.mode)
+		assert.NoError(t, err, testCase.amountStr)
+		assert.Equal(t, testCase.expected, amount, testCase.amountStr)
+	}
+
+	for _, amountStr := range []string{"", "1.2.3", "1.23a", "1e3", "92233720368547758.075"} {
+		_, err := AssetUSD.ParseRounded(amountStr, RoundHalfEven)
+		assert.Error(t, err, amountStr)
+	}
+}
@@ -1,6 +1,9 @@
 package currency
 
-import "math"
+import (
+	"database/sql/driver"
+	"math"
+)
 
 type BTC int64
 
@@ -24,10 +27,45 @@ func NewBTC(amount float64) BTC {
 }
 
 func ParseBTC(amountStr string) (BTC, error) {
-	amount, err := convertStringToAmount(amountStr, BTCPrecision)
+	amount, err := convertStringToAmount(amountStr, BTCPrecision, ParseStrict)
 	return BTC(amount), err
 }
 
-func (btc BTC) USD(btcPrice float64) USD {
-	return USD(math.Round(btc.Float64() * btcPrice * 100))
+// USD values the amount at the price of one BTC, rounded half to even.
+func (btc BTC) USD(btcPrice USD) (USD, error) {
+	value, err := AssetBTC.Money(int64(btc)).Mul(AssetUSD.Money(int64(btcPrice)), RoundHalfEven)
+	return USD(value.Amount), err
+}
+
+// MarshalText formats the amount as a decimal string.
+func (btc BTC) MarshalText() ([]byte, error) {
+	return []byte(btc.String()), nil
+}
+
+func (btc *BTC) UnmarshalText(text []byte) error {
+	amount, err := ParseBTC(string(text))
+	if err != nil {
+		return err
+	}
+	*btc = amount
+	return nil
+}
+
+// MarshalJSON encodes the amount as a decimal string, numbers would lose
+// precision in JavaScript clients.
+func (btc BTC) MarshalJSON() ([]byte, error) {
+	return marshalAmountJSON(int64(btc), BTCPrecision)
+}
+
+func (btc *BTC) UnmarshalJSON(data []byte) error {
+	return unmarshalAmountJSON(data, BTCPrecision, (*int64)(btc))
+}
+
+// Scan reads the amount stored in a bigint column.
+func (btc *BTC) Scan(src interface{}) error {
+	return scanAmount(src, (*int64)(btc))
+}
+
+func (btc BTC) Value() (driver.Value, error) {
+	return int64(btc), nil
 }
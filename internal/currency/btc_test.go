@@ -1,7 +1,12 @@
 package currency
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -22,6 +27,18 @@ func TestParseBTCValid(t *testing.T) {
 		{
 			"0.01", BTC(1000000),
 		},
+		{
+			".5", BTC(50000000),
+		},
+		{
+			"+12.01", BTC(1201000000),
+		},
+		{
+			"92233720368.54775807", BTC(math.MaxInt64),
+		},
+		{
+			"-92233720368.54775808", BTC(math.MinInt64),
+		},
 	}
 
 	for i := range testCases {
@@ -46,7 +63,19 @@ func TestParseBTCInvalid(t *testing.T) {
 			".",
 		},
 		{
-			".1",
+			"- 12",
+		},
+		{
+			"12.11111111111111",
+		},
+		{
+			"1,000",
+		},
+		{
+			"1e3",
+		},
+		{
+			"99999999999999999999",
 		},
 	}
 	for i := range testCases {
@@ -108,9 +137,6 @@ func TestBTCString2String(t *testing.T) {
 		{
 			"12.01", "12.01000000",
 		},
-		{
-			"12.11111111111111", "12.11111111",
-		},
 		{
 			"-12.01", "-12.01000000",
 		},
@@ -129,3 +155,51 @@ func TestBTCString2String(t *testing.T) {
 		assert.Equal(t, tc.expected, btc.String())
 	}
 }
+
+func TestBTCJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Amount BTC `json:"amount"`
+	}{BTC(150000000)})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":"1.50000000"}`, string(data))
+
+	var decoded struct {
+		Amount BTC `json:"amount"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, BTC(150000000), decoded.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":null}`), &decoded))
+	assert.Equal(t, BTC(150000000), decoded.Amount)
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":12}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"1x"}`), &decoded))
+}
+
+func TestBTCText(t *testing.T) {
+	var amount BTC
+	var _ encoding.TextMarshaler = amount
+	var _ encoding.TextUnmarshaler = &amount
+
+	text, err := BTC(150000000).MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.50000000", string(text))
+	assert.NoError(t, amount.UnmarshalText(text))
+	assert.Equal(t, BTC(150000000), amount)
+}
+
+func TestBTCSQL(t *testing.T) {
+	var amount BTC
+	var _ sql.Scanner = &amount
+	var _ driver.Valuer = amount
+
+	assert.NoError(t, amount.Scan(int64(150)))
+	assert.Equal(t, BTC(150), amount)
+	assert.NoError(t, amount.Scan([]byte("-20")))
+	assert.Equal(t, BTC(-20), amount)
+	assert.Error(t, amount.Scan("150"))
+	assert.Error(t, amount.Scan(nil))
+
+	value, err := BTC(150).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(150), value)
+}
@@ -1,85 +1,225 @@
 package currency
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
 
+// ParseMode selects the notations accepted for decimal amounts.
+type ParseMode int
+
+const (
+	// ParseStrict accepts an optional sign followed by digits with an optional
+	// decimal point, "12", "+12.5", "-.5" and "12." are all valid. Amounts with
+	// more decimals than the asset are rejected.
+	ParseStrict ParseMode = iota
+	// ParseLenient additionally accepts thousand separators ("1,000.5"),
+	// exponent notation ("1.5e3") and zero decimals beyond the precision of the
+	// asset ("1.500000000").
+	ParseLenient
+)
+
+var (
+	ErrSyntax    = errors.New("invalid amount syntax")
+	ErrPrecision = errors.New("amount has more decimals than the asset")
+)
+
+// maxExponent bounds exponents, any larger one overflows or loses decimals
+// of a non-zero amount anyway.
+const maxExponent = 1000
+
 func convertIntToString(amount int64, decimalPlaces int) string {
 	isNegative := amount < 0
+	absAmount := uint64(amount)
 	if isNegative {
-		amount = -amount
+		absAmount = -absAmount
 	}
 
-	baseString := strconv.FormatInt(amount, 10)
+	baseString := strconv.FormatUint(absAmount, 10)
 	paddingLength := decimalPlaces + 1 - len(baseString)
 	if paddingLength > 0 {
 		baseString = strings.Repeat("0", paddingLength) + baseString
 	}
 
-	dotPos := len(baseString) - decimalPlaces
-	baseString = baseString[0:dotPos] + "." + baseString[dotPos:]
+	if decimalPlaces > 0 {
+		dotPos := len(baseString) - decimalPlaces
+		baseString = baseString[0:dotPos] + "." + baseString[dotPos:]
+	}
 	if isNegative {
 		return "-" + baseString
 	}
 	return baseString
 }
 
-func convertStringToAmount(amountStr string, decimalPlaces int) (int64, error) {
-	amountStr = strings.TrimSpace(amountStr)
+func convertStringToAmount(amountStr string, decimalPlaces int, mode ParseMode) (int64, error) {
+	amount, err := parseAmount(strings.TrimSpace(amountStr), decimalPlaces, mode)
+	if err != nil {
+		return 0, fmt.Errorf("unparsable amount %q: %w", amountStr, err)
+	}
+	return amount, nil
+}
 
+func parseAmount(s string, decimalPlaces int, mode ParseMode) (int64, error) {
 	var isNegative bool
-	if strings.HasPrefix(amountStr, "-") {
-		amountStr = strings.TrimSpace(amountStr[1:])
-		isNegative = true
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		isNegative = s[0] == '-'
+		s = s[1:]
 	}
 
-	if len(amountStr) == 0 {
-		return 0, fmt.Errorf("unparsable amount %q", amountStr)
+	exponent := 0
+	if mode == ParseLenient {
+		if pos := strings.IndexAny(s, "eE"); pos >= 0 {
+			var err error
+			if exponent, err = parseExponent(s[pos+1:]); err != nil {
+				return 0, err
+			}
+			s = s[:pos]
+		}
 	}
 
-	parsedStr := strings.SplitN(amountStr, ".", 2)
-	intPartStr := parsedStr[0]
-	var fracPartStr string
-	if len(parsedStr) == 2 {
-		fracPartStr = parsedStr[1]
+	intPartStr, fracPartStr := s, ""
+	if pos := strings.IndexByte(s, '.'); pos >= 0 {
+		intPartStr, fracPartStr = s[:pos], s[pos+1:]
+	}
+	if mode == ParseLenient && strings.IndexByte(intPartStr, ',') >= 0 {
+		var ok bool
+		if intPartStr, ok = removeThousandSeparators(intPartStr); !ok {
+			return 0, ErrSyntax
+		}
+	}
+	if intPartStr == "" && fracPartStr == "" || !isDigits(intPartStr) || !isDigits(fracPartStr) {
+		return 0, ErrSyntax
 	}
 
-	// no rounding
-	fracPartStr = fracPartStr + strings.Repeat("0", decimalPlaces)
-	fracPartStr = fracPartStr[0:decimalPlaces]
-
-	intPart, intErr := parseInt(intPartStr)
-	fracPart, fracErr := parseInt(fracPartStr)
+	// the digits count units of 10^-decimalPlaces once shifted by the scale
+	digits := strings.TrimLeft(intPartStr+fracPartStr, "0")
+	scale := decimalPlaces - len(fracPartStr) + exponent
+	if scale < 0 {
+		if mode == ParseStrict {
+			return 0, ErrPrecision
+		}
+		significant := strings.TrimRight(digits, "0")
+		if len(digits)-len(significant) < -scale && significant != "" {
+			return 0, ErrPrecision
+		}
+		if significant == "" {
+			return 0, nil
+		}
+		digits = digits[:len(digits)+scale]
+		scale = 0
+	}
+	if digits == "" {
+		return 0, nil
+	}
+	// int64 has 19 digits at most
+	if len(digits)+scale > 19 {
+		return 0, ErrOverflow
+	}
+	digits += strings.Repeat("0", scale)
 
-	if intErr != nil || fracErr != nil || fracPart < 0 {
-		return 0, fmt.Errorf("unparsable amount %q", amountStr)
+	limit := uint64(math.MaxInt64)
+	if isNegative {
+		limit++
+	}
+	amount := uint64(0)
+	for _, r := range digits {
+		amount = amount*10 + uint64(r-'0')
+	}
+	if amount > limit {
+		return 0, ErrOverflow
 	}
+	if isNegative {
+		return int64(-amount), nil
+	}
+	return int64(amount), nil
+}
 
-	for i := 0; i < decimalPlaces; i++ {
-		intPart *= 10
+// parseExponent reads a signed decimal exponent, clamped to maxExponent.
+func parseExponent(s string) (int, error) {
+	isNegative := false
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		isNegative = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "" || !isDigits(s) {
+		return 0, ErrSyntax
 	}
 
-	amount := intPart + fracPart
+	exponent := 0
+	for _, r := range s {
+		exponent = exponent*10 + int(r-'0')
+		if exponent > maxExponent {
+			exponent = maxExponent
+		}
+	}
 	if isNegative {
-		return -amount, nil
+		return -exponent, nil
 	}
-	return amount, nil
+	return exponent, nil
 }
 
-func parseInt(s string) (int64, error) {
-	if len(s) == 0 {
-		return 0, fmt.Errorf("empty string")
+// removeThousandSeparators drops commas between groups of three digits, the
+// first group may be shorter.
+func removeThousandSeparators(s string) (string, bool) {
+	groups := strings.Split(s, ",")
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return "", false
 	}
-	val := int64(0)
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return "", false
+		}
+	}
+	return strings.Join(groups, ""), true
+}
+
+func isDigits(s string) bool {
 	for _, r := range s {
 		if r < '0' || r > '9' {
-			return 0, fmt.Errorf("only digits are allowed")
+			return false
 		}
+	}
+	return true
+}
 
-		val = val*10 + int64(r-'0')
+func marshalAmountJSON(amount int64, decimalPlaces int) ([]byte, error) {
+	return json.Marshal(convertIntToString(amount, decimalPlaces))
+}
+
+// unmarshalAmountJSON reads a decimal string, null leaves the amount as it is.
+func unmarshalAmountJSON(data []byte, decimalPlaces int, amount *int64) error {
+	if string(data) == "null" {
+		return nil
 	}
 
-	return val, nil
+	var amountStr string
+	if err := json.Unmarshal(data, &amountStr); err != nil {
+		return fmt.Errorf("amount must be a decimal string: %w", err)
+	}
+	parsed, err := convertStringToAmount(amountStr, decimalPlaces, ParseStrict)
+	if err != nil {
+		return err
+	}
+	*amount = parsed
+	return nil
+}
+
+func scanAmount(src interface{}, amount *int64) error {
+	switch value := src.(type) {
+	case int64:
+		*amount = value
+	case []byte:
+		parsed, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("unsupported amount %q: %w", value, err)
+		}
+		*amount = parsed
+	default:
+		return fmt.Errorf("unsupported scan type for amount: %T", src)
+	}
+	return nil
 }
@@ -0,0 +1,62 @@
+package currency
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAmountModes(t *testing.T) {
+	testCases := []struct {
+		amountStr string
+		mode      ParseMode
+		expected  int64
+		err       error
+	}{
+		{"12.5", ParseStrict, 1250, nil},
+		{" +12.5 ", ParseStrict, 1250, nil},
+		{"-.05", ParseStrict, -5, nil},
+		{"12.", ParseStrict, 1200, nil},
+		{"12.501", ParseStrict, 0, ErrPrecision},
+		{"12.500", ParseStrict, 0, ErrPrecision},
+		{"1,000", ParseStrict, 0, ErrSyntax},
+		{"1e3", ParseStrict, 0, ErrSyntax},
+		{"+-1", ParseStrict, 0, ErrSyntax},
+		{"- 1", ParseStrict, 0, ErrSyntax},
+		{"1 000", ParseStrict, 0, ErrSyntax},
+		{"+", ParseStrict, 0, ErrSyntax},
+		{"92233720368547758.07", ParseStrict, 1<<63 - 1, nil},
+		{"-92233720368547758.08", ParseStrict, -1 << 63, nil},
+		{"92233720368547758.08", ParseStrict, 0, ErrOverflow},
+		{"100000000000000000000000", ParseStrict, 0, ErrOverflow},
+
+		{"1,000.5", ParseLenient, 100050, nil},
+		{"-12,345,678", ParseLenient, -1234567800, nil},
+		{"12.500", ParseLenient, 1250, nil},
+		{"12.501", ParseLenient, 0, ErrPrecision},
+		{"1.5e3", ParseLenient, 150000, nil},
+		{"15E-1", ParseLenient, 150, nil},
+		{"1.25e+1", ParseLenient, 1250, nil},
+		{"125e-4", ParseLenient, 0, ErrPrecision},
+		{"0e-5000", ParseLenient, 0, nil},
+		{"1e5000", ParseLenient, 0, ErrOverflow},
+		{"1e-5000", ParseLenient, 0, ErrPrecision},
+		{"1e", ParseLenient, 0, ErrSyntax},
+		{"e5", ParseLenient, 0, ErrSyntax},
+		{"1,00", ParseLenient, 0, ErrSyntax},
+		{",100", ParseLenient, 0, ErrSyntax},
+		{"1000,000", ParseLenient, 0, ErrSyntax},
+		{"1.000,5", ParseLenient, 0, ErrSyntax},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		amount, err := AssetUSD.ParseWith(tc.amountStr, tc.mode)
+		if tc.err != nil {
+			assert.True(t, errors.Is(err, tc.err), "%q: %v", tc.amountStr, err)
+			continue
+		}
+		assert.NoError(t, err, tc.amountStr)
+		assert.Equal(t, tc.expected, amount, tc.amountStr)
+	}
+}
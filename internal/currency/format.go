@@ -0,0 +1,218 @@
+package currency
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unit is a denomination amounts of an asset are displayed in. One unit is
+// 10^Exponent whole units of the asset.
+type Unit struct {
+	Name     string
+	Asset    string
+	Exponent int
+	// Sign is written next to the number instead of the name, like $.
+	Sign string
+	// MinDecimals are kept when trailing zeros are trimmed.
+	MinDecimals int
+}
+
+var (
+	UnitBTC      = Unit{Name: "BTC", Asset: "BTC"}
+	UnitMilliBTC = Unit{Name: "mBTC", Asset: "BTC", Exponent: -3}
+	UnitSatoshi  = Unit{Name: "sat", Asset: "BTC", Exponent: -8}
+	UnitUSD      = Unit{Name: "USD", Asset: "USD", Sign: "$", MinDecimals: 2}
+)
+
+// units are the known units, the first unit of an asset is its default one.
+var units = []Unit{UnitBTC, UnitMilliBTC, UnitSatoshi, UnitUSD}
+
+// LookupUnit finds a known unit by its case insensitive name.
+func LookupUnit(name string) (Unit, bool) {
+	for _, unit := range units {
+		if strings.EqualFold(unit.Name, name) {
+			return unit, true
+		}
+	}
+	return Unit{}, false
+}
+
+// DefaultUnit returns the unit amounts of the asset are displayed in unless
+// asked otherwise, assets without a known unit are displayed in whole units.
+func DefaultUnit(asset Asset) Unit {
+	for _, unit := range units {
+		if unit.Asset == asset.Symbol {
+			return unit
+		}
+	}
+	return Unit{Name: asset.Symbol, Asset: asset.Symbol}
+}
+
+// Locale holds the conventions of writing numbers in a language.
+type Locale struct {
+	Tag     string
+	Decimal string
+	Group   string
+	// SignAfter writes currency signs after the number, like 1.234,50 $.
+	SignAfter bool
+}
+
+var LocaleEnglish = Locale{Tag: "en", Decimal: ".", Group: ","}
+
+// locales are the supported locales by their lowercase language tags.
+var locales = map[string]Locale{
+	"en":    LocaleEnglish,
+	"de":    {Tag: "de", Decimal: ",", Group: ".", SignAfter: true},
+	"de-ch": {Tag: "de-CH", Decimal: ".", Group: "'", SignAfter: true},
+	"es":    {Tag: "es", Decimal: ",", Group: ".", SignAfter: true},
+	"fr":    {Tag: "fr", Decimal: ",", Group: "\u202f", SignAfter: true},
+	"it":    {Tag: "it", Decimal: ",", Group: ".", SignAfter: true},
+	"ja":    {Tag: "ja", Decimal: ".", Group: ","},
+	"pt":    {Tag: "pt", Decimal: ",", Group: ".", SignAfter: true},
+	"ru":    {Tag: "ru", Decimal: ",", Group: "\u00a0", SignAfter: true},
+	"sk":    {Tag: "sk", Decimal: ",", Group: "\u00a0", SignAfter: true},
+	"zh":    {Tag: "zh", Decimal: ".", Group: ","},
+}
+
+// LookupLocale finds a supported locale by a language tag like de-AT, falling
+// back to the language of the tag.
+func LookupLocale(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for {
+		if locale, ok := locales[tag]; ok {
+			return locale, true
+		}
+		pos := strings.LastIndexByte(tag, '-')
+		if pos < 0 {
+			return Locale{}, false
+		}
+		tag = tag[:pos]
+	}
+}
+
+// MatchLocale picks the supported locale preferred by an Accept-Language
+// header, it returns false when the header names none.
+func MatchLocale(acceptLanguage string) (Locale, bool) {
+	type weightedTag struct {
+		tag    string
+		weight float64
+	}
+	var tags []weightedTag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(part, ";")
+		tag := weightedTag{tag: strings.TrimSpace(params[0]), weight: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				weight, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					weight = 0
+				}
+				tag.weight = weight
+			}
+		}
+		if tag.tag != "" && tag.weight > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].weight > tags[j].weight })
+
+	for _, tag := range tags {
+		if tag.tag == "*" {
+			return LocaleEnglish, true
+		}
+		if locale, ok := LookupLocale(tag.tag); ok {
+			return locale, true
+		}
+	}
+	return Locale{}, false
+}
+
+// Formatter displays amounts for people in the units and conventions of a
+// locale.
+type Formatter struct {
+	Locale Locale
+	// Units by asset symbols, other assets are displayed in their default unit.
+	Units map[string]Unit
+	// TrimZeros drops trailing decimal zeros down to MinDecimals of the unit.
+	TrimZeros bool
+}
+
+// Unit returns the unit amounts of the asset are displayed in.
+func (formatter Formatter) Unit(asset Asset) Unit {
+	unit, ok := formatter.Units[asset.Symbol]
+	if !ok || unit.Asset != asset.Symbol || asset.Precision+unit.Exponent < 0 {
+		return DefaultUnit(asset)
+	}
+	return unit
+}
+
+// Format writes the money in its unit, like $1,234.50, 1.234,50 $ or
+// 150,000 sat.
+func (formatter Formatter) Format(money Money) string {
+	unit := formatter.Unit(money.Asset)
+	number := formatter.FormatNumber(money.Amount, money.Asset.Precision+unit.Exponent, unit.MinDecimals)
+
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	switch {
+	case unit.Sign == "":
+		return sign + number + " " + unit.Name
+	case formatter.Locale.SignAfter:
+		return sign + number + " " + unit.Sign
+	}
+	return sign + unit.Sign + number
+}
+
+// FormatNumber writes the amount counting units of 10^-decimalPlaces with the
+// separators of the locale. Trimming keeps at least minDecimals decimals.
+func (formatter Formatter) FormatNumber(amount int64, decimalPlaces int, minDecimals int) string {
+	number := convertIntToString(amount, decimalPlaces)
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+
+	intPart, fracPart := number, ""
+	if pos := strings.IndexByte(number, '.'); pos >= 0 {
+		intPart, fracPart = number[:pos], number[pos+1:]
+	}
+	if formatter.TrimZeros {
+		trimmed := strings.TrimRight(fracPart, "0")
+		if len(trimmed) < minDecimals && len(fracPart) >= minDecimals {
+			trimmed = fracPart[:minDecimals]
+		}
+		fracPart = trimmed
+	}
+
+	locale := formatter.Locale
+	if locale.Decimal == "" {
+		locale = LocaleEnglish
+	}
+	number = groupDigits(intPart, locale.Group)
+	if fracPart != "" {
+		number += locale.Decimal + fracPart
+	}
+	return sign + number
+}
+
+// groupDigits separates groups of three digits from the right.
+func groupDigits(digits string, separator string) string {
+	if separator == "" || len(digits) <= 3 {
+		return digits
+	}
+	var builder strings.Builder
+	first := len(digits) % 3
+	if first == 0 {
+		first = 3
+	}
+	builder.WriteString(digits[:first])
+	for pos := first; pos < len(digits); pos += 3 {
+		builder.WriteString(separator)
+		builder.WriteString(digits[pos : pos+3])
+	}
+	return builder.String()
+}
@@ -0,0 +1,84 @@
+package currency
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestFormatterFormat(t *testing.T) {
+	german, _ := LookupLocale("de")
+	french, _ := LookupLocale("fr")
+	eth := Asset{Symbol: "ETH", Precision: 18}
+	testCases := []struct {
+		formatter Formatter
+		money     Money
+		expected  string
+	}{
+		{Formatter{Locale: LocaleEnglish}, AssetUSD.Money(123450), "$1,234.50"},
+		{Formatter{Locale: LocaleEnglish}, AssetUSD.Money(-5), "-$0.05"},
+		{Formatter{Locale: german}, AssetUSD.Money(123450), "1.234,50 $"},
+		{Formatter{Locale: french}, AssetUSD.Money(-123456789), "-1\u202f234\u202f567,89 $"},
+		{Formatter{Locale: LocaleEnglish, TrimZeros: true}, AssetUSD.Money(100000), "$1,000.00"},
+		{Formatter{Locale: LocaleEnglish}, AssetBTC.Money(150000000), "1.50000000 BTC"},
+		{Formatter{Locale: LocaleEnglish, TrimZeros: true}, AssetBTC.Money(150000000), "1.5 BTC"},
+		{Formatter{Locale: LocaleEnglish, TrimZeros: true}, AssetBTC.Money(200000000), "2 BTC"},
+		{
+			Formatter{Locale: german, Units: map[string]Unit{"BTC": UnitMilliBTC}, TrimZeros: true},
+			AssetBTC.Money(123456789000), "1.234.567,89 mBTC",
+		},
+		{
+			Formatter{Locale: LocaleEnglish, Units: map[string]Unit{"BTC": UnitSatoshi}},
+			AssetBTC.Money(-150000000), "-150,000,000 sat",
+		},
+		{
+			Formatter{Locale: LocaleEnglish, Units: map[string]Unit{"BTC": UnitSatoshi}},
+			AssetBTC.Money(math.MinInt64), "-9,223,372,036,854,775,808 sat",
+		},
+		{Formatter{Locale: LocaleEnglish, TrimZeros: true}, eth.Money(1500), "0.0000000000000015 ETH"},
+		// units of other assets are ignored
+		{Formatter{Units: map[string]Unit{"USD": UnitSatoshi}}, AssetUSD.Money(1), "$0.01"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		assert.Equal(t, tc.expected, tc.formatter.Format(tc.money))
+	}
+}
+
+func TestLookupUnit(t *testing.T) {
+	unit, ok := LookupUnit("MBTC")
+	assert.True(t, ok)
+	assert.Equal(t, UnitMilliBTC, unit)
+
+	_, ok = LookupUnit("bit")
+	assert.False(t, ok)
+
+	assert.Equal(t, UnitBTC, DefaultUnit(AssetBTC))
+	assert.Equal(t, Unit{Name: "ETH", Asset: "ETH"}, DefaultUnit(Asset{Symbol: "ETH", Precision: 18}))
+}
+
+func TestMatchLocale(t *testing.T) {
+	testCases := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"de-AT", "de"},
+		{"de-CH,de;q=0.9", "de-CH"},
+		{"xx, fr;q=0.5, de;q=0.8", "de"},
+		{"en-GB;q=0.1, sk", "sk"},
+		{"xx, *;q=0.1", "en"},
+		{"de;q=0, fr;q=abc, ja", "ja"},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+		locale, ok := MatchLocale(tc.acceptLanguage)
+		assert.True(t, ok, tc.acceptLanguage)
+		assert.Equal(t, tc.expected, locale.Tag, tc.acceptLanguage)
+	}
+
+	_, ok := MatchLocale("xx-YY")
+	assert.False(t, ok)
+	_, ok = MatchLocale("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,61 @@
+//go:build go1.18
+// +build go1.18
+
+// fuzz tests need go 1.18 while the module still supports go 1.16
+
+package currency
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func FuzzBTCStringParse(f *testing.F) {
+	for _, amount := range []int64{0, 1, -1, 1201000000, math.MaxInt64, math.MinInt64} {
+		f.Add(amount)
+	}
+	f.Fuzz(func(t *testing.T, amount int64) {
+		btc, err := ParseBTC(BTC(amount).String())
+		assert.NoError(t, err)
+		assert.Equal(t, BTC(amount), btc)
+	})
+}
+
+func FuzzUSDStringParse(f *testing.F) {
+	for _, amount := range []int64{0, 1, -1, 1201, 1<<63 - 1, -1 << 63} {
+		f.Add(amount)
+	}
+	f.Fuzz(func(t *testing.T, amount int64) {
+		usd, err := ParseUSD(USD(amount).String())
+		assert.NoError(t, err)
+		assert.Equal(t, USD(amount), usd)
+	})
+}
+
+func FuzzParseAmount(f *testing.F) {
+	for _, seed := range []string{"12.5", "-.05", "1,000.5", "1.5e3", "0e-5000", "92233720368547758.07"} {
+		f.Add(seed, 2)
+		f.Add(seed, 8)
+	}
+	f.Fuzz(func(t *testing.T, amountStr string, precision int) {
+		if precision < 0 || precision > MaxPrecision {
+			return
+		}
+		asset := Asset{Symbol: "XYZ", Precision: precision}
+		amount, err := asset.ParseWith(amountStr, ParseLenient)
+		if err != nil {
+			_, strictErr := asset.Parse(amountStr)
+			assert.Error(t, strictErr, "%q is strict but not lenient", amountStr)
+			return
+		}
+		if strict, err := asset.Parse(amountStr); err == nil {
+			assert.Equal(t, amount, strict, amountStr)
+		}
+
+		// every parsed amount is formatted back to the same amount
+		parsed, err := asset.Parse(asset.Format(amount))
+		assert.NoError(t, err)
+		assert.Equal(t, amount, parsed)
+	})
+}
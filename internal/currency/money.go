@@ -0,0 +1,254 @@
+package currency
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	ErrOverflow       = errors.New("amount overflows int64")
+	ErrDivisionByZero = errors.New("division by zero")
+	ErrAssetMismatch  = errors.New("amounts of different assets")
+)
+
+// MaxPrecision is the largest precision whose unit fits in int64.
+const MaxPrecision = 18
+
+// RoundingMode decides how results between two amounts are rounded.
+type RoundingMode int
+
+const (
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor RoundingMode = iota
+	// RoundCeil rounds towards positive infinity.
+	RoundCeil
+	// RoundHalfEven rounds to the nearest amount, ties to the even one.
+	RoundHalfEven
+)
+
+func (mode RoundingMode) String() string {
+	switch mode {
+	case RoundFloor:
+		return "floor"
+	case RoundCeil:
+		return "ceil"
+	case RoundHalfEven:
+		return "half-even"
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(mode))
+}
+
+// Pow10 returns 10^precision, the number of amounts in one unit of an asset
+// with the precision.
+func Pow10(precision int) (int64, error) {
+	if precision < 0 || precision > MaxPrecision {
+		return 0, fmt.Errorf("precision %d out of range: %w", precision, ErrOverflow)
+	}
+	result := int64(1)
+	for i := 0; i < precision; i++ {
+		result *= 10
+	}
+	return result, nil
+}
+
+// MulDiv returns x*y/z rounded by the mode. The product is exact, only the
+// final result has to fit in int64.
+func MulDiv(x, y, z int64, mode RoundingMode) (int64, error) {
+	if z == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	// the product of amounts below 2^31 cannot overflow
+	if x > -1<<31 && x < 1<<31 && y > -1<<31 && y < 1<<31 {
+		return divRound(x*y, z, mode), nil
+	}
+
+	product := new(big.Int).Mul(big.NewInt(x), big.NewInt(y))
+	quotient, remainder := new(big.Int).QuoRem(product, big.NewInt(z), new(big.Int))
+	if remainder.Sign() != 0 {
+		negative := product.Sign() != big.NewInt(z).Sign()
+		doubled := new(big.Int).Abs(remainder)
+		doubled.Lsh(doubled, 1)
+		if roundsAway(mode, negative, doubled.Cmp(new(big.Int).Abs(big.NewInt(z))), quotient.Bit(0) == 1) {
+			if negative {
+				quotient.Sub(quotient, big.NewInt(1))
+			} else {
+				quotient.Add(quotient, big.NewInt(1))
+			}
+		}
+	}
+	if !quotient.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quotient.Int64(), nil
+}
+
+// divRound divides the product of two amounts below 2^31, z must not be
+// zero.
+func divRound(x, z int64, mode RoundingMode) int64 {
+	quotient, remainder := x/z, x%z
+	if remainder == 0 {
+		return quotient
+	}
+
+	negative := (x < 0) != (z < 0)
+	absRemainder, absZ := uint64(remainder), uint64(z)
+	if remainder < 0 {
+		absRemainder = uint64(-remainder)
+	}
+	if z < 0 {
+		absZ = -absZ
+	}
+	cmpHalf := 0
+	if doubled := 2 * absRemainder; doubled < absZ {
+		cmpHalf = -1
+	} else if doubled > absZ {
+		cmpHalf = 1
+	}
+	if roundsAway(mode, negative, cmpHalf, quotient%2 != 0) {
+		if negative {
+			return quotient - 1
+		}
+		return quotient + 1
+	}
+	return quotient
+}
+
+// roundsAway tells whether a truncated quotient has to move away from zero.
+// cmpHalf compares the dropped remainder with one half.
+func roundsAway(mode RoundingMode, negative bool, cmpHalf int, odd bool) bool {
+	switch mode {
+	case RoundFloor:
+		return negative
+	case RoundCeil:
+		return !negative
+	default:
+		return cmpHalf > 0 || cmpHalf == 0 && odd
+	}
+}
+
+// Money is an amount of an asset. Its arithmetic is exact, results that
+// cannot be represented are rounded by an explicit mode and overflows are
+// reported instead of wrapping.
+type Money struct {
+	Amount int64
+	Asset  Asset
+}
+
+// Money returns the amount of the asset.
+func (asset Asset) Money(amount int64) Money {
+	return Money{Amount: amount, Asset: asset}
+}
+
+// Amount is a number of the smallest units of an asset known from the context,
+// such as the base or the quote asset of the market of an order. It is stored
+// in bigint columns.
+type Amount int64
+
+// Of returns the amount of the asset.
+func (amount Amount) Of(asset Asset) Money {
+	return asset.Money(int64(amount))
+}
+
+// Scan reads the amount stored in a bigint column.
+func (amount *Amount) Scan(src interface{}) error {
+	return scanAmount(src, (*int64)(amount))
+}
+
+func (amount Amount) Value() (driver.Value, error) {
+	return int64(amount), nil
+}
+
+// String formats the amount with all decimals of the asset followed by its
+// symbol.
+func (money Money) String() string {
+	return money.Asset.Format(money.Amount) + " " + money.Asset.Symbol
+}
+
+func (money Money) IsZero() bool {
+	return money.Amount == 0
+}
+
+func (money Money) Add(other Money) (Money, error) {
+	if money.Asset != other.Asset {
+		return Money{}, ErrAssetMismatch
+	}
+	sum := money.Amount + other.Amount
+	if (other.Amount > 0 && sum < money.Amount) || (other.Amount < 0 && sum > money.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return money.Asset.Money(sum), nil
+}
+
+func (money Money) Sub(other Money) (Money, error) {
+	if money.Asset != other.Asset {
+		return Money{}, ErrAssetMismatch
+	}
+	difference := money.Amount - other.Amount
+	if (other.Amount > 0 && difference > money.Amount) || (other.Amount < 0 && difference < money.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return money.Asset.Money(difference), nil
+}
+
+// Cmp compares amounts of the same asset, -1 when money is less than other, 0
+// when equal and +1 when greater.
+func (money Money) Cmp(other Money) (int, error) {
+	if money.Asset != other.Asset {
+		return 0, ErrAssetMismatch
+	}
+	switch {
+	case money.Amount < other.Amount:
+		return -1, nil
+	case money.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Mul values the money at the price of one unit of its asset. The result is
+// in the asset of the price.
+func (money Money) Mul(price Money, mode RoundingMode) (Money, error) {
+	unit, err := Pow10(money.Asset.Precision)
+	if err != nil {
+		return Money{}, err
+	}
+	amount, err := MulDiv(money.Amount, price.Amount, unit, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return price.Asset.Money(amount), nil
+}
+
+// Div returns the amount of the asset worth the money at the price of one
+// unit of the asset. The price has to be in the asset of the money.
+func (money Money) Div(price Money, asset Asset, mode RoundingMode) (Money, error) {
+	if money.Asset != price.Asset {
+		return Money{}, ErrAssetMismatch
+	}
+	unit, err := Pow10(asset.Precision)
+	if err != nil {
+		return Money{}, err
+	}
+	amount, err := MulDiv(money.Amount, unit, price.Amount, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return asset.Money(amount), nil
+}
+
+// Per returns the price of one unit of the asset of the quantity, the inverse
+// of Mul.
+func (money Money) Per(quantity Money, mode RoundingMode) (Money, error) {
+	unit, err := Pow10(quantity.Asset.Precision)
+	if err != nil {
+		return Money{}, err
+	}
+	amount, err := MulDiv(money.Amount, unit, quantity.Amount, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return money.Asset.Money(amount), nil
+}
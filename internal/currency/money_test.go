@@ -0,0 +1,136 @@
+package currency
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestMulDiv(t *testing.T) {
+	testCases := []struct {
+		x, y, z  int64
+		mode     RoundingMode
+		expected int64
+	}{
+		{7, 3, 2, RoundFloor, 10},
+		{7, 3, 2, RoundCeil, 11},
+		{7, 3, 2, RoundHalfEven, 10},
+		{5, 3, 2, RoundHalfEven, 8},
+		{-7, 3, 2, RoundFloor, -11},
+		{-7, 3, 2, RoundCeil, -10},
+		{-7, 3, 2, RoundHalfEven, -10},
+		{7, 3, -2, RoundFloor, -11},
+		{10, 1, 3, RoundHalfEven, 3},
+		{20, 1, 3, RoundHalfEven, 7},
+		{6, 4, 3, RoundCeil, 8},
+		// the product exceeds int64, the quotient does not
+		{math.MaxInt64, 10, 20, RoundFloor, math.MaxInt64 / 2},
+		{math.MaxInt64, 10, 20, RoundCeil, math.MaxInt64/2 + 1},
+		{math.MaxInt64, 10, 20, RoundHalfEven, math.MaxInt64/2 + 1},
+		{math.MaxInt64 - 1, 10, 20, RoundHalfEven, (math.MaxInt64 - 1) / 2},
+		{math.MinInt64, 3, 6, RoundHalfEven, math.MinInt64 / 2},
+		{-math.MaxInt64, 10, 20, RoundFloor, -math.MaxInt64/2 - 1},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		result, err := MulDiv(tc.x, tc.y, tc.z, tc.mode)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, result, "%d*%d/%d %s", tc.x, tc.y, tc.z, tc.mode)
+	}
+}
+
+func TestMulDivErrors(t *testing.T) {
+	_, err := MulDiv(math.MaxInt64, 2, 1, RoundFloor)
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	_, err = MulDiv(math.MinInt64, -1, 1, RoundFloor)
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	_, err = MulDiv(1, 1, 0, RoundFloor)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := AssetUSD.Money(150).Add(AssetUSD.Money(-50))
+	assert.NoError(t, err)
+	assert.Equal(t, AssetUSD.Money(100), sum)
+
+	difference, err := AssetUSD.Money(150).Sub(AssetUSD.Money(200))
+	assert.NoError(t, err)
+	assert.Equal(t, AssetUSD.Money(-50), difference)
+	assert.Equal(t, "-0.50 USD", difference.String())
+
+	_, err = AssetUSD.Money(math.MaxInt64).Add(AssetUSD.Money(1))
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = AssetUSD.Money(math.MinInt64).Sub(AssetUSD.Money(1))
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = AssetUSD.Money(1).Add(AssetBTC.Money(1))
+	assert.ErrorIs(t, err, ErrAssetMismatch)
+
+	cmp, err := AssetBTC.Money(1).Cmp(AssetBTC.Money(2))
+	assert.NoError(t, err)
+	assert.Equal(t, -1, cmp)
+}
+
+func TestMoneyPrices(t *testing.T) {
+	price := AssetUSD.Money(NewUSD(49_999.99).Internal())
+
+	// 0.00000001 BTC is worth 0.0004999999 USD
+	value, err := AssetBTC.Money(1).Mul(price, RoundHalfEven)
+	assert.NoError(t, err)
+	assert.Equal(t, AssetUSD.Money(0), value)
+	value, err = AssetBTC.Money(1).Mul(price, RoundCeil)
+	assert.NoError(t, err)
+	assert.Equal(t, AssetUSD.Money(1), value)
+
+	value, err = AssetBTC.Money(NewBTC(0.3).Internal()).Mul(price, RoundHalfEven)
+	assert.NoError(t, err)
+	assert.Equal(t, AssetUSD.Money(NewUSD(15_000).Internal()), value)
+
+	// 100 USD buys 0.0020000004 BTC
+	quantity, err := AssetUSD.Money(NewUSD(100).Internal()).Div(price, AssetBTC, RoundFloor)
+	assert.NoError(t, err)
+	assert.Equal(t, AssetBTC.Money(200000), quantity)
+	_, err = AssetBTC.Money(1).Div(price, AssetBTC, RoundFloor)
+	assert.ErrorIs(t, err, ErrAssetMismatch)
+
+	average, err := AssetUSD.Money(NewUSD(15_000).Internal()).Per(AssetBTC.Money(NewBTC(0.3).Internal()), RoundHalfEven)
+	assert.NoError(t, err)
+	assert.Equal(t, AssetUSD.Money(NewUSD(50_000).Internal()), average)
+
+	_, err = Asset{Symbol: "XYZ", Precision: 19}.Money(1).Mul(price, RoundFloor)
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestAmount(t *testing.T) {
+	var amount Amount
+	var _ sql.Scanner = &amount
+	var _ driver.Valuer = amount
+
+	assert.NoError(t, amount.Scan(int64(150)))
+	assert.Equal(t, Amount(150), amount)
+	assert.NoError(t, amount.Scan([]byte("-20")))
+	assert.Equal(t, Amount(-20), amount)
+	assert.Error(t, amount.Scan(nil))
+
+	value, err := Amount(150).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(150), value)
+
+	assert.Equal(t, AssetUSD.Money(150), Amount(150).Of(AssetUSD))
+	assert.Equal(t, "0.00000150 BTC", Amount(150).Of(AssetBTC).String())
+}
+
+func TestBTCValue(t *testing.T) {
+	value, err := NewBTC(1.5).USD(NewUSD(10_000.01))
+	assert.NoError(t, err)
+	assert.Equal(t, NewUSD(15_000.02), value)
+
+	// 0.12345678 BTC at 0.10 USD is worth 0.012345678 USD
+	value, err = NewBTC(0.12345678).USD(NewUSD(0.1))
+	assert.NoError(t, err)
+	assert.Equal(t, NewUSD(0.01), value)
+}
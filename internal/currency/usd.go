@@ -1,6 +1,9 @@
 package currency
 
-import "math"
+import (
+	"database/sql/driver"
+	"math"
+)
 
 type USD int64
 
@@ -24,6 +27,39 @@ func NewUSD(amount float64) USD {
 }
 
 func ParseUSD(amountStr string) (USD, error) {
-	amount, err := convertStringToAmount(amountStr, USDPrecision)
+	amount, err := convertStringToAmount(amountStr, USDPrecision, ParseStrict)
 	return USD(amount), err
 }
+
+// MarshalText formats the amount as a decimal string.
+func (usd USD) MarshalText() ([]byte, error) {
+	return []byte(usd.String()), nil
+}
+
+func (usd *USD) UnmarshalText(text []byte) error {
+	amount, err := ParseUSD(string(text))
+	if err != nil {
+		return err
+	}
+	*usd = amount
+	return nil
+}
+
+// MarshalJSON encodes the amount as a decimal string, numbers would lose
+// precision in JavaScript clients.
+func (usd USD) MarshalJSON() ([]byte, error) {
+	return marshalAmountJSON(int64(usd), USDPrecision)
+}
+
+func (usd *USD) UnmarshalJSON(data []byte) error {
+	return unmarshalAmountJSON(data, USDPrecision, (*int64)(usd))
+}
+
+// Scan reads the amount stored in a bigint column.
+func (usd *USD) Scan(src interface{}) error {
+	return scanAmount(src, (*int64)(usd))
+}
+
+func (usd USD) Value() (driver.Value, error) {
+	return int64(usd), nil
+}
@@ -1,6 +1,10 @@
 package currency
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -22,6 +26,12 @@ func TestParseUSDValid(t *testing.T) {
 		{
 			"0.01", USD(1),
 		},
+		{
+			".5", USD(50),
+		},
+		{
+			"+12.01", USD(1201),
+		},
 	}
 
 	for i := range testCases {
@@ -46,7 +56,19 @@ func TestParseUSDInvalid(t *testing.T) {
 			".",
 		},
 		{
-			".1",
+			"- 12",
+		},
+		{
+			"12.11111111111111",
+		},
+		{
+			"1,000",
+		},
+		{
+			"1e3",
+		},
+		{
+			"99999999999999999999",
 		},
 	}
 	for i := range testCases {
@@ -108,9 +130,6 @@ func TestUSDString2String(t *testing.T) {
 		{
 			"12.01", "12.01",
 		},
-		{
-			"12.11111111111111", "12.11",
-		},
 		{
 			"-12.01", "-12.01",
 		},
@@ -129,3 +148,51 @@ func TestUSDString2String(t *testing.T) {
 		assert.Equal(t, tc.expected, usd.String())
 	}
 }
+
+func TestUSDJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Amount USD `json:"amount"`
+	}{USD(-1250)})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":"-12.50"}`, string(data))
+
+	var decoded struct {
+		Amount USD `json:"amount"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, USD(-1250), decoded.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":null}`), &decoded))
+	assert.Equal(t, USD(-1250), decoded.Amount)
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":12}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"1x"}`), &decoded))
+}
+
+func TestUSDText(t *testing.T) {
+	var amount USD
+	var _ encoding.TextMarshaler = amount
+	var _ encoding.TextUnmarshaler = &amount
+
+	text, err := USD(-1250).MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "-12.50", string(text))
+	assert.NoError(t, amount.UnmarshalText(text))
+	assert.Equal(t, USD(-1250), amount)
+}
+
+func TestUSDSQL(t *testing.T) {
+	var amount USD
+	var _ sql.Scanner = &amount
+	var _ driver.Valuer = amount
+
+	assert.NoError(t, amount.Scan(int64(150)))
+	assert.Equal(t, USD(150), amount)
+	assert.NoError(t, amount.Scan([]byte("-20")))
+	assert.Equal(t, USD(-20), amount)
+	assert.Error(t, amount.Scan("150"))
+	assert.Error(t, amount.Scan(nil))
+
+	value, err := USD(150).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(150), value)
+}
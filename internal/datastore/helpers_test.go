@@ -22,19 +22,26 @@ func newDBHelper(t *testing.T, db *sql.DB) *dbHelper {
 
 func (helper *dbHelper) createAccount(accountSpec queries.Account) *tq.Account {
 	createdAccount, err := helper.queries.CreateAccount(helper.context, tq.CreateAccountParams{
-		Username:  accountSpec.Username,
-		Token:     accountSpec.Token,
-		UsdAmount: accountSpec.UsdAmount,
-		BtcAmount: accountSpec.BtcAmount,
+		Username: accountSpec.Username,
+		Token:    accountSpec.Token,
 	})
 	require.NoError(helper.t, err, "unable to create test account")
 	require.Equal(helper.t, accountSpec.Username, createdAccount.Username)
 	require.Equal(helper.t, accountSpec.Token, createdAccount.Token)
-	require.Equal(helper.t, accountSpec.UsdAmount, createdAccount.UsdAmount)
-	require.Equal(helper.t, accountSpec.BtcAmount, createdAccount.BtcAmount)
 	return &createdAccount
 }
 
+func (helper *dbHelper) setBalance(accountId int32, asset string, amount int64) {
+	err := helper.queries.SetBalance(helper.context, tq.SetBalanceParams{AccountID: accountId, Asset: asset, Amount: amount})
+	require.NoError(helper.t, err, "unable to set test balance")
+}
+
+func (helper *dbHelper) getBalance(accountId int32, asset string) int64 {
+	amount, err := helper.queries.GetBalance(helper.context, tq.GetBalanceParams{AccountID: accountId, Asset: asset})
+	require.NoError(helper.t, err, "unable to get test balance")
+	return amount
+}
+
 func (helper *dbHelper) getAccounts() map[int32]*tq.Account {
 	accounts, err := helper.queries.GetAccounts(helper.context)
 	require.NoError(helper.t, err, "unable to get accounts")
@@ -48,16 +55,16 @@ func (helper *dbHelper) getAccounts() map[int32]*tq.Account {
 func (helper *dbHelper) createStandingOrder(orderSpec queries.StandingOrder) *tq.StandingOrder {
 	createdOrder, err := helper.queries.CreateStandingOrder(helper.context,
 		tq.CreateStandingOrderParams{
-			AccountID:         orderSpec.AccountID,
-			Type:              tq.OrderType(orderSpec.Type),
-			State:             tq.OrderState(orderSpec.State),
-			Quantity:          orderSpec.Quantity,
-			FilledQuantity:    orderSpec.FilledQuantity,
-			FilledPrice:       orderSpec.FilledPrice,
-			LimitPrice:        orderSpec.LimitPrice,
-			ReservedUsdAmount: orderSpec.ReservedUsdAmount,
-			ReservedBtcAmount: orderSpec.ReservedBtcAmount,
-			WebhookUrl:        orderSpec.WebhookUrl,
+			AccountID:           orderSpec.AccountID,
+			Type:                tq.OrderType(orderSpec.Type),
+			State:               tq.OrderState(orderSpec.State),
+			Quantity:            orderSpec.Quantity,
+			FilledQuantity:      orderSpec.FilledQuantity,
+			FilledPrice:         orderSpec.FilledPrice,
+			LimitPrice:          orderSpec.LimitPrice,
+			ReservedQuoteAmount: orderSpec.ReservedQuoteAmount,
+			ReservedBaseAmount:  orderSpec.ReservedBaseAmount,
+			WebhookUrl:          orderSpec.WebhookUrl,
 		})
 	require.NoError(helper.t, err, "unable to create test account")
 	return &createdOrder
@@ -0,0 +1,6 @@
+-- deposits required the withdraw scope before the deposit scope existed, keys
+-- with the withdraw scope keep depositing and managing API keys
+UPDATE api_key
+SET scopes = array_append(scopes, 'deposit')
+WHERE 'withdraw' = ANY (scopes)
+  AND NOT 'deposit' = ANY (scopes);
@@ -0,0 +1,5 @@
+-- signing keys are derived from the server secret instead of being stored,
+-- the signing keys handed out before differ and their API keys have to be
+-- replaced by the holders
+ALTER TABLE api_key
+    DROP COLUMN IF EXISTS signing_key;
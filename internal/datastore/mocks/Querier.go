@@ -7,27 +7,697 @@ import (
 
 	queries "github.com/galcik/vlexchange/internal/datastore/queries"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
-// Querier is an autogenerated mock type for the Querier type
-type Querier struct {
-	mock.Mock
+// Querier is an autogenerated mock type for the Querier type
+type Querier struct {
+	mock.Mock
+}
+
+// CancelStandingOrder provides a mock function with given fields: ctx, id
+func (_m *Querier) CancelStandingOrder(ctx context.Context, id int32) (queries.StandingOrder, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.StandingOrder); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(queries.StandingOrder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompleteIdempotencyKey provides a mock function with given fields: ctx, arg
+func (_m *Querier) CompleteIdempotencyKey(ctx context.Context, arg queries.CompleteIdempotencyKeyParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CompleteIdempotencyKeyParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAccount provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateAccount(ctx context.Context, arg queries.CreateAccountParams) (queries.Account, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.Account
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateAccountParams) queries.Account); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateAccountParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateApiKey provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateApiKey(ctx context.Context, arg queries.CreateApiKeyParams) (queries.ApiKey, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.ApiKey
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateApiKeyParams) queries.ApiKey); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.ApiKey)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateApiKeyParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAsset provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateAsset(ctx context.Context, arg queries.CreateAssetParams) (queries.Asset, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.Asset
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateAssetParams) queries.Asset); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.Asset)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateAssetParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateBalance provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateBalance(ctx context.Context, arg queries.CreateBalanceParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateBalanceParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateBalanceJournalEntry provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateBalanceJournalEntry(ctx context.Context, arg queries.CreateBalanceJournalEntryParams) (queries.BalanceJournal, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.BalanceJournal
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateBalanceJournalEntryParams) queries.BalanceJournal); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.BalanceJournal)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateBalanceJournalEntryParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFixMessage provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateFixMessage(ctx context.Context, arg queries.CreateFixMessageParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateFixMessageParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateIdempotencyKey provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateIdempotencyKey(ctx context.Context, arg queries.CreateIdempotencyKeyParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateIdempotencyKeyParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateIdempotencyKeyParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateMarket provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateMarket(ctx context.Context, arg queries.CreateMarketParams) (queries.Market, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.Market
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateMarketParams) queries.Market); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.Market)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateMarketParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePriceHistoryEntry provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreatePriceHistoryEntry(ctx context.Context, arg queries.CreatePriceHistoryEntryParams) (queries.PriceHistory, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.PriceHistory
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreatePriceHistoryEntryParams) queries.PriceHistory); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.PriceHistory)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreatePriceHistoryEntryParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateStandingOrder provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateStandingOrder(ctx context.Context, arg queries.CreateStandingOrderParams) (queries.StandingOrder, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateStandingOrderParams) queries.StandingOrder); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.StandingOrder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateStandingOrderParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateTrade provides a mock function with given fields: ctx, arg
+func (_m *Querier) CreateTrade(ctx context.Context, arg queries.CreateTradeParams) (queries.Trade, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.Trade
+	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateTradeParams) queries.Trade); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.Trade)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateTradeParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteApiKey provides a mock function with given fields: ctx, arg
+func (_m *Querier) DeleteApiKey(ctx context.Context, arg queries.DeleteApiKeyParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, queries.DeleteApiKeyParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.DeleteApiKeyParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExpiredIdempotencyKeys provides a mock function with given fields: ctx, arg
+func (_m *Querier) DeleteExpiredIdempotencyKeys(ctx context.Context, arg queries.DeleteExpiredIdempotencyKeysParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, queries.DeleteExpiredIdempotencyKeysParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredNonces provides a mock function with given fields: ctx, arg
+func (_m *Querier) DeleteExpiredNonces(ctx context.Context, arg queries.DeleteExpiredNoncesParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, queries.DeleteExpiredNoncesParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteFixMessages provides a mock function with given fields: ctx, sessionID
+func (_m *Querier) DeleteFixMessages(ctx context.Context, sessionID int32) error {
+	ret := _m.Called(ctx, sessionID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int32) error); ok {
+		r0 = rf(ctx, sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteIdempotencyKey provides a mock function with given fields: ctx, arg
+func (_m *Querier) DeleteIdempotencyKey(ctx context.Context, arg queries.DeleteIdempotencyKeyParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, queries.DeleteIdempotencyKeyParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteStandingOrder provides a mock function with given fields: ctx, id
+func (_m *Querier) DeleteStandingOrder(ctx context.Context, id int32) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int32) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAccountById provides a mock function with given fields: ctx, id
+func (_m *Querier) GetAccountById(ctx context.Context, id int32) (queries.Account, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 queries.Account
+	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.Account); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(queries.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountByToken provides a mock function with given fields: ctx, token
+func (_m *Querier) GetAccountByToken(ctx context.Context, token string) (queries.Account, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 queries.Account
+	if rf, ok := ret.Get(0).(func(context.Context, string) queries.Account); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(queries.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApiKeyByPrefix provides a mock function with given fields: ctx, prefix
+func (_m *Querier) GetApiKeyByPrefix(ctx context.Context, prefix string) (queries.ApiKey, error) {
+	ret := _m.Called(ctx, prefix)
+
+	var r0 queries.ApiKey
+	if rf, ok := ret.Get(0).(func(context.Context, string) queries.ApiKey); ok {
+		r0 = rf(ctx, prefix)
+	} else {
+		r0 = ret.Get(0).(queries.ApiKey)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApiKeys provides a mock function with given fields: ctx, accountID
+func (_m *Querier) GetApiKeys(ctx context.Context, accountID int32) ([]queries.ApiKey, error) {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 []queries.ApiKey
+	if rf, ok := ret.Get(0).(func(context.Context, int32) []queries.ApiKey); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAsset provides a mock function with given fields: ctx, symbol
+func (_m *Querier) GetAsset(ctx context.Context, symbol string) (queries.Asset, error) {
+	ret := _m.Called(ctx, symbol)
+
+	var r0 queries.Asset
+	if rf, ok := ret.Get(0).(func(context.Context, string) queries.Asset); ok {
+		r0 = rf(ctx, symbol)
+	} else {
+		r0 = ret.Get(0).(queries.Asset)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, symbol)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAssets provides a mock function with given fields: ctx
+func (_m *Querier) GetAssets(ctx context.Context) ([]queries.Asset, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []queries.Asset
+	if rf, ok := ret.Get(0).(func(context.Context) []queries.Asset); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.Asset)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBalance provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetBalance(ctx context.Context, arg queries.GetBalanceParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetBalanceParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetBalanceParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBalanceJournal provides a mock function with given fields: ctx, accountID
+func (_m *Querier) GetBalanceJournal(ctx context.Context, accountID int32) ([]queries.BalanceJournal, error) {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 []queries.BalanceJournal
+	if rf, ok := ret.Get(0).(func(context.Context, int32) []queries.BalanceJournal); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.BalanceJournal)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBalances provides a mock function with given fields: ctx, accountID
+func (_m *Querier) GetBalances(ctx context.Context, accountID int32) ([]queries.GetBalancesRow, error) {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 []queries.GetBalancesRow
+	if rf, ok := ret.Get(0).(func(context.Context, int32) []queries.GetBalancesRow); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.GetBalancesRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBestBuyer provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetBestBuyer(ctx context.Context, arg queries.GetBestBuyerParams) (queries.StandingOrder, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetBestBuyerParams) queries.StandingOrder); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.StandingOrder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetBestBuyerParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBestMarketBuyer provides a mock function with given fields: ctx, marketID
+func (_m *Querier) GetBestMarketBuyer(ctx context.Context, marketID int32) (queries.StandingOrder, error) {
+	ret := _m.Called(ctx, marketID)
+
+	var r0 queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.StandingOrder); ok {
+		r0 = rf(ctx, marketID)
+	} else {
+		r0 = ret.Get(0).(queries.StandingOrder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, marketID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBestMarketSeller provides a mock function with given fields: ctx, marketID
+func (_m *Querier) GetBestMarketSeller(ctx context.Context, marketID int32) (queries.StandingOrder, error) {
+	ret := _m.Called(ctx, marketID)
+
+	var r0 queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.StandingOrder); ok {
+		r0 = rf(ctx, marketID)
+	} else {
+		r0 = ret.Get(0).(queries.StandingOrder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, marketID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBestSeller provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetBestSeller(ctx context.Context, arg queries.GetBestSellerParams) (queries.StandingOrder, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetBestSellerParams) queries.StandingOrder); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.StandingOrder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetBestSellerParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFixMessages provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetFixMessages(ctx context.Context, arg queries.GetFixMessagesParams) ([]queries.FixMessage, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []queries.FixMessage
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetFixMessagesParams) []queries.FixMessage); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.FixMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetFixMessagesParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIdempotencyKey provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetIdempotencyKey(ctx context.Context, arg queries.GetIdempotencyKeyParams) (queries.IdempotencyKey, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.IdempotencyKey
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetIdempotencyKeyParams) queries.IdempotencyKey); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.IdempotencyKey)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetIdempotencyKeyParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// CreateAccount provides a mock function with given fields: ctx, arg
-func (_m *Querier) CreateAccount(ctx context.Context, arg queries.CreateAccountParams) (queries.Account, error) {
-	ret := _m.Called(ctx, arg)
+// GetLastTrade provides a mock function with given fields: ctx, marketID
+func (_m *Querier) GetLastTrade(ctx context.Context, marketID int32) (queries.Trade, error) {
+	ret := _m.Called(ctx, marketID)
 
-	var r0 queries.Account
-	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateAccountParams) queries.Account); ok {
-		r0 = rf(ctx, arg)
+	var r0 queries.Trade
+	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.Trade); ok {
+		r0 = rf(ctx, marketID)
 	} else {
-		r0 = ret.Get(0).(queries.Account)
+		r0 = ret.Get(0).(queries.Trade)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateAccountParams) error); ok {
-		r1 = rf(ctx, arg)
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, marketID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,19 +705,63 @@ func (_m *Querier) CreateAccount(ctx context.Context, arg queries.CreateAccountP
 	return r0, r1
 }
 
-// CreateStandingOrder provides a mock function with given fields: ctx, arg
-func (_m *Querier) CreateStandingOrder(ctx context.Context, arg queries.CreateStandingOrderParams) (queries.StandingOrder, error) {
+// GetMarket provides a mock function with given fields: ctx, id
+func (_m *Querier) GetMarket(ctx context.Context, id int32) (queries.GetMarketRow, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 queries.GetMarketRow
+	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.GetMarketRow); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(queries.GetMarketRow)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMarkets provides a mock function with given fields: ctx
+func (_m *Querier) GetMarkets(ctx context.Context) ([]queries.GetMarketsRow, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []queries.GetMarketsRow
+	if rf, ok := ret.Get(0).(func(context.Context) []queries.GetMarketsRow); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.GetMarketsRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrCreateFixSession provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetOrCreateFixSession(ctx context.Context, arg queries.GetOrCreateFixSessionParams) (queries.FixSession, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 queries.StandingOrder
-	if rf, ok := ret.Get(0).(func(context.Context, queries.CreateStandingOrderParams) queries.StandingOrder); ok {
+	var r0 queries.FixSession
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetOrCreateFixSessionParams) queries.FixSession); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(queries.StandingOrder)
+		r0 = ret.Get(0).(queries.FixSession)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, queries.CreateStandingOrderParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetOrCreateFixSessionParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -56,34 +770,45 @@ func (_m *Querier) CreateStandingOrder(ctx context.Context, arg queries.CreateSt
 	return r0, r1
 }
 
-// DeleteStandingOrder provides a mock function with given fields: ctx, id
-func (_m *Querier) DeleteStandingOrder(ctx context.Context, id int32) error {
-	ret := _m.Called(ctx, id)
+// GetOrderBookLevels provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetOrderBookLevels(ctx context.Context, arg queries.GetOrderBookLevelsParams) ([]queries.GetOrderBookLevelsRow, error) {
+	ret := _m.Called(ctx, arg)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int32) error); ok {
-		r0 = rf(ctx, id)
+	var r0 []queries.GetOrderBookLevelsRow
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetOrderBookLevelsParams) []queries.GetOrderBookLevelsRow); ok {
+		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.GetOrderBookLevelsRow)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetOrderBookLevelsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// GetAccountById provides a mock function with given fields: ctx, id
-func (_m *Querier) GetAccountById(ctx context.Context, id int32) (queries.Account, error) {
-	ret := _m.Called(ctx, id)
+// GetOrderTrades provides a mock function with given fields: ctx, orderID
+func (_m *Querier) GetOrderTrades(ctx context.Context, orderID int32) ([]queries.Trade, error) {
+	ret := _m.Called(ctx, orderID)
 
-	var r0 queries.Account
-	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.Account); ok {
-		r0 = rf(ctx, id)
+	var r0 []queries.Trade
+	if rf, ok := ret.Get(0).(func(context.Context, int32) []queries.Trade); ok {
+		r0 = rf(ctx, orderID)
 	} else {
-		r0 = ret.Get(0).(queries.Account)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.Trade)
+		}
 	}
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
-		r1 = rf(ctx, id)
+		r1 = rf(ctx, orderID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -91,20 +816,20 @@ func (_m *Querier) GetAccountById(ctx context.Context, id int32) (queries.Accoun
 	return r0, r1
 }
 
-// GetAccountByToken provides a mock function with given fields: ctx, token
-func (_m *Querier) GetAccountByToken(ctx context.Context, token string) (queries.Account, error) {
-	ret := _m.Called(ctx, token)
+// GetPriceAfter provides a mock function with given fields: ctx, quotedAt
+func (_m *Querier) GetPriceAfter(ctx context.Context, quotedAt time.Time) (queries.PriceHistory, error) {
+	ret := _m.Called(ctx, quotedAt)
 
-	var r0 queries.Account
-	if rf, ok := ret.Get(0).(func(context.Context, string) queries.Account); ok {
-		r0 = rf(ctx, token)
+	var r0 queries.PriceHistory
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) queries.PriceHistory); ok {
+		r0 = rf(ctx, quotedAt)
 	} else {
-		r0 = ret.Get(0).(queries.Account)
+		r0 = ret.Get(0).(queries.PriceHistory)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, token)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, quotedAt)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -112,20 +837,20 @@ func (_m *Querier) GetAccountByToken(ctx context.Context, token string) (queries
 	return r0, r1
 }
 
-// GetBestBuyer provides a mock function with given fields: ctx, limitPrice
-func (_m *Querier) GetBestBuyer(ctx context.Context, limitPrice int64) (queries.StandingOrder, error) {
-	ret := _m.Called(ctx, limitPrice)
+// GetPriceAtOrBefore provides a mock function with given fields: ctx, quotedAt
+func (_m *Querier) GetPriceAtOrBefore(ctx context.Context, quotedAt time.Time) (queries.PriceHistory, error) {
+	ret := _m.Called(ctx, quotedAt)
 
-	var r0 queries.StandingOrder
-	if rf, ok := ret.Get(0).(func(context.Context, int64) queries.StandingOrder); ok {
-		r0 = rf(ctx, limitPrice)
+	var r0 queries.PriceHistory
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) queries.PriceHistory); ok {
+		r0 = rf(ctx, quotedAt)
 	} else {
-		r0 = ret.Get(0).(queries.StandingOrder)
+		r0 = ret.Get(0).(queries.PriceHistory)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, limitPrice)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, quotedAt)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -133,20 +858,22 @@ func (_m *Querier) GetBestBuyer(ctx context.Context, limitPrice int64) (queries.
 	return r0, r1
 }
 
-// GetBestMarketBuyer provides a mock function with given fields: ctx
-func (_m *Querier) GetBestMarketBuyer(ctx context.Context) (queries.StandingOrder, error) {
-	ret := _m.Called(ctx)
+// GetPriceHistory provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetPriceHistory(ctx context.Context, arg queries.GetPriceHistoryParams) ([]queries.PriceHistory, error) {
+	ret := _m.Called(ctx, arg)
 
-	var r0 queries.StandingOrder
-	if rf, ok := ret.Get(0).(func(context.Context) queries.StandingOrder); ok {
-		r0 = rf(ctx)
+	var r0 []queries.PriceHistory
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetPriceHistoryParams) []queries.PriceHistory); ok {
+		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(queries.StandingOrder)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.PriceHistory)
+		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetPriceHistoryParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -154,15 +881,17 @@ func (_m *Querier) GetBestMarketBuyer(ctx context.Context) (queries.StandingOrde
 	return r0, r1
 }
 
-// GetBestMarketSeller provides a mock function with given fields: ctx
-func (_m *Querier) GetBestMarketSeller(ctx context.Context) (queries.StandingOrder, error) {
+// GetReconciliation provides a mock function with given fields: ctx
+func (_m *Querier) GetReconciliation(ctx context.Context) ([]queries.GetReconciliationRow, error) {
 	ret := _m.Called(ctx)
 
-	var r0 queries.StandingOrder
-	if rf, ok := ret.Get(0).(func(context.Context) queries.StandingOrder); ok {
+	var r0 []queries.GetReconciliationRow
+	if rf, ok := ret.Get(0).(func(context.Context) []queries.GetReconciliationRow); ok {
 		r0 = rf(ctx)
 	} else {
-		r0 = ret.Get(0).(queries.StandingOrder)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.GetReconciliationRow)
+		}
 	}
 
 	var r1 error
@@ -175,20 +904,20 @@ func (_m *Querier) GetBestMarketSeller(ctx context.Context) (queries.StandingOrd
 	return r0, r1
 }
 
-// GetBestSeller provides a mock function with given fields: ctx, limitPrice
-func (_m *Querier) GetBestSeller(ctx context.Context, limitPrice int64) (queries.StandingOrder, error) {
-	ret := _m.Called(ctx, limitPrice)
+// GetReservedAmount provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetReservedAmount(ctx context.Context, arg queries.GetReservedAmountParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
 
-	var r0 queries.StandingOrder
-	if rf, ok := ret.Get(0).(func(context.Context, int64) queries.StandingOrder); ok {
-		r0 = rf(ctx, limitPrice)
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetReservedAmountParams) int64); ok {
+		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(queries.StandingOrder)
+		r0 = ret.Get(0).(int64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, limitPrice)
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetReservedAmountParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -196,20 +925,20 @@ func (_m *Querier) GetBestSeller(ctx context.Context, limitPrice int64) (queries
 	return r0, r1
 }
 
-// GetReservedAmounts provides a mock function with given fields: ctx, accountID
-func (_m *Querier) GetReservedAmounts(ctx context.Context, accountID int32) (queries.GetReservedAmountsRow, error) {
-	ret := _m.Called(ctx, accountID)
+// GetStandingOrder provides a mock function with given fields: ctx, id
+func (_m *Querier) GetStandingOrder(ctx context.Context, id int32) (queries.StandingOrder, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 queries.GetReservedAmountsRow
-	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.GetReservedAmountsRow); ok {
-		r0 = rf(ctx, accountID)
+	var r0 queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.StandingOrder); ok {
+		r0 = rf(ctx, id)
 	} else {
-		r0 = ret.Get(0).(queries.GetReservedAmountsRow)
+		r0 = ret.Get(0).(queries.StandingOrder)
 	}
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
-		r1 = rf(ctx, accountID)
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -217,20 +946,20 @@ func (_m *Querier) GetReservedAmounts(ctx context.Context, accountID int32) (que
 	return r0, r1
 }
 
-// GetStandingOrder provides a mock function with given fields: ctx, id
-func (_m *Querier) GetStandingOrder(ctx context.Context, id int32) (queries.StandingOrder, error) {
-	ret := _m.Called(ctx, id)
+// GetStandingOrderByClientOrderId provides a mock function with given fields: ctx, arg
+func (_m *Querier) GetStandingOrderByClientOrderId(ctx context.Context, arg queries.GetStandingOrderByClientOrderIdParams) (queries.StandingOrder, error) {
+	ret := _m.Called(ctx, arg)
 
 	var r0 queries.StandingOrder
-	if rf, ok := ret.Get(0).(func(context.Context, int32) queries.StandingOrder); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, queries.GetStandingOrderByClientOrderIdParams) queries.StandingOrder); ok {
+		r0 = rf(ctx, arg)
 	} else {
 		r0 = ret.Get(0).(queries.StandingOrder)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, queries.GetStandingOrderByClientOrderIdParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -261,6 +990,50 @@ func (_m *Querier) GetStandingOrders(ctx context.Context, orderIds []int32) ([]q
 	return r0, r1
 }
 
+// GetTradingStatus provides a mock function with given fields: ctx
+func (_m *Querier) GetTradingStatus(ctx context.Context) (queries.TradingStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 queries.TradingStatus
+	if rf, ok := ret.Get(0).(func(context.Context) queries.TradingStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(queries.TradingStatus)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListStandingOrders provides a mock function with given fields: ctx, arg
+func (_m *Querier) ListStandingOrders(ctx context.Context, arg queries.ListStandingOrdersParams) ([]queries.StandingOrder, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(context.Context, queries.ListStandingOrdersParams) []queries.StandingOrder); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.StandingOrder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.ListStandingOrdersParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SatisfyOrder provides a mock function with given fields: ctx, arg
 func (_m *Querier) SatisfyOrder(ctx context.Context, arg queries.SatisfyOrderParams) (queries.StandingOrder, error) {
 	ret := _m.Called(ctx, arg)
@@ -282,19 +1055,124 @@ func (_m *Querier) SatisfyOrder(ctx context.Context, arg queries.SatisfyOrderPar
 	return r0, r1
 }
 
-// TransferAmounts provides a mock function with given fields: ctx, arg
-func (_m *Querier) TransferAmounts(ctx context.Context, arg queries.TransferAmountsParams) (int64, error) {
+// SetAccountFrozen provides a mock function with given fields: ctx, arg
+func (_m *Querier) SetAccountFrozen(ctx context.Context, arg queries.SetAccountFrozenParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, queries.SetAccountFrozenParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.SetAccountFrozenParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetFixSenderSeqNum provides a mock function with given fields: ctx, arg
+func (_m *Querier) SetFixSenderSeqNum(ctx context.Context, arg queries.SetFixSenderSeqNumParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, queries.SetFixSenderSeqNumParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetFixTargetSeqNum provides a mock function with given fields: ctx, arg
+func (_m *Querier) SetFixTargetSeqNum(ctx context.Context, arg queries.SetFixTargetSeqNumParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, queries.SetFixTargetSeqNumParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetTradingStatus provides a mock function with given fields: ctx, arg
+func (_m *Querier) SetTradingStatus(ctx context.Context, arg queries.SetTradingStatusParams) (queries.TradingStatus, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 queries.TradingStatus
+	if rf, ok := ret.Get(0).(func(context.Context, queries.SetTradingStatusParams) queries.TradingStatus); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(queries.TradingStatus)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.SetTradingStatusParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TouchApiKey provides a mock function with given fields: ctx, id
+func (_m *Querier) TouchApiKey(ctx context.Context, id int32) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int32) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TransferBalance provides a mock function with given fields: ctx, arg
+func (_m *Querier) TransferBalance(ctx context.Context, arg queries.TransferBalanceParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, queries.TransferBalanceParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, queries.TransferBalanceParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseNonce provides a mock function with given fields: ctx, arg
+func (_m *Querier) UseNonce(ctx context.Context, arg queries.UseNonceParams) (int64, error) {
 	ret := _m.Called(ctx, arg)
 
 	var r0 int64
-	if rf, ok := ret.Get(0).(func(context.Context, queries.TransferAmountsParams) int64); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, queries.UseNonceParams) int64); ok {
 		r0 = rf(ctx, arg)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, queries.TransferAmountsParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, queries.UseNonceParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -11,6 +11,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	queries "github.com/galcik/vlexchange/internal/datastore/queries"
+
+	time "time"
 )
 
 // Store is an autogenerated mock type for the Store type
@@ -18,6 +20,140 @@ type Store struct {
 	mock.Mock
 }
 
+// AdjustBalance provides a mock function with given fields: params
+func (_m *Store) AdjustBalance(params datastore.AdjustBalanceParams) (bool, error) {
+	ret := _m.Called(params)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(datastore.AdjustBalanceParams) bool); ok {
+		r0 = rf(params)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.AdjustBalanceParams) error); ok {
+		r1 = rf(params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CancelStandingOrder provides a mock function with given fields: orderId
+func (_m *Store) CancelStandingOrder(orderId int32) (*queries.StandingOrder, error) {
+	ret := _m.Called(orderId)
+
+	var r0 *queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(int32) *queries.StandingOrder); ok {
+		r0 = rf(orderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.StandingOrder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompleteIdempotencyKey provides a mock function with given fields: params
+func (_m *Store) CompleteIdempotencyKey(params queries.CompleteIdempotencyKeyParams) error {
+	ret := _m.Called(params)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(queries.CompleteIdempotencyKeyParams) error); ok {
+		r0 = rf(params)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateApiKey provides a mock function with given fields: params
+func (_m *Store) CreateApiKey(params datastore.CreateApiKeyParams) (*queries.ApiKey, string, error) {
+	ret := _m.Called(params)
+
+	var r0 *queries.ApiKey
+	if rf, ok := ret.Get(0).(func(datastore.CreateApiKeyParams) *queries.ApiKey); ok {
+		r0 = rf(params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.ApiKey)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(datastore.CreateApiKeyParams) string); ok {
+		r1 = rf(params)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(datastore.CreateApiKeyParams) error); ok {
+		r2 = rf(params)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// CreateAsset provides a mock function with given fields: symbol, precision
+func (_m *Store) CreateAsset(symbol string, precision int32) (*queries.Asset, error) {
+	ret := _m.Called(symbol, precision)
+
+	var r0 *queries.Asset
+	if rf, ok := ret.Get(0).(func(string, int32) *queries.Asset); ok {
+		r0 = rf(symbol, precision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.Asset)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int32) error); ok {
+		r1 = rf(symbol, precision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateMarket provides a mock function with given fields: baseAsset, quoteAsset
+func (_m *Store) CreateMarket(baseAsset string, quoteAsset string) (*datastore.Market, error) {
+	ret := _m.Called(baseAsset, quoteAsset)
+
+	var r0 *datastore.Market
+	if rf, ok := ret.Get(0).(func(string, string) *datastore.Market); ok {
+		r0 = rf(baseAsset, quoteAsset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*datastore.Market)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(baseAsset, quoteAsset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateStandingOrder provides a mock function with given fields: params
 func (_m *Store) CreateStandingOrder(params datastore.CreateStandingOrderParams) (*queries.StandingOrder, []int32, error) {
 	ret := _m.Called(params)
@@ -50,34 +186,41 @@ func (_m *Store) CreateStandingOrder(params datastore.CreateStandingOrderParams)
 	return r0, r1, r2
 }
 
-// DeleteStandingOrder provides a mock function with given fields: orderId
-func (_m *Store) DeleteStandingOrder(orderId int32) error {
-	ret := _m.Called(orderId)
+// DeleteApiKey provides a mock function with given fields: accountId, keyId
+func (_m *Store) DeleteApiKey(accountId int32, keyId int32) (bool, error) {
+	ret := _m.Called(accountId, keyId)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(int32) error); ok {
-		r0 = rf(orderId)
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(int32, int32) bool); ok {
+		r0 = rf(accountId, keyId)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32, int32) error); ok {
+		r1 = rf(accountId, keyId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// DepositAccount provides a mock function with given fields: accountId, btcAmount, usdAmount
-func (_m *Store) DepositAccount(accountId int32, btcAmount currency.BTC, usdAmount currency.USD) (bool, error) {
-	ret := _m.Called(accountId, btcAmount, usdAmount)
+// DepositAccount provides a mock function with given fields: accountId, asset, amount
+func (_m *Store) DepositAccount(accountId int32, asset string, amount int64) (bool, error) {
+	ret := _m.Called(accountId, asset, amount)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(int32, currency.BTC, currency.USD) bool); ok {
-		r0 = rf(accountId, btcAmount, usdAmount)
+	if rf, ok := ret.Get(0).(func(int32, string, int64) bool); ok {
+		r0 = rf(accountId, asset, amount)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int32, currency.BTC, currency.USD) error); ok {
-		r1 = rf(accountId, btcAmount, usdAmount)
+	if rf, ok := ret.Get(1).(func(int32, string, int64) error); ok {
+		r1 = rf(accountId, asset, amount)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -175,22 +318,68 @@ func (_m *Store) GetAccountByToken(token string) (*queries.Account, error) {
 	return r0, r1
 }
 
-// GetStandingOrder provides a mock function with given fields: orderId
-func (_m *Store) GetStandingOrder(orderId int32) (*queries.StandingOrder, error) {
-	ret := _m.Called(orderId)
+// GetApiKeyByPrefix provides a mock function with given fields: prefix
+func (_m *Store) GetApiKeyByPrefix(prefix string) (*queries.ApiKey, error) {
+	ret := _m.Called(prefix)
 
-	var r0 *queries.StandingOrder
-	if rf, ok := ret.Get(0).(func(int32) *queries.StandingOrder); ok {
-		r0 = rf(orderId)
+	var r0 *queries.ApiKey
+	if rf, ok := ret.Get(0).(func(string) *queries.ApiKey); ok {
+		r0 = rf(prefix)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*queries.StandingOrder)
+			r0 = ret.Get(0).(*queries.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApiKeyByToken provides a mock function with given fields: token
+func (_m *Store) GetApiKeyByToken(token string) (*queries.ApiKey, error) {
+	ret := _m.Called(token)
+
+	var r0 *queries.ApiKey
+	if rf, ok := ret.Get(0).(func(string) *queries.ApiKey); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApiKeys provides a mock function with given fields: accountId
+func (_m *Store) GetApiKeys(accountId int32) ([]queries.ApiKey, error) {
+	ret := _m.Called(accountId)
+
+	var r0 []queries.ApiKey
+	if rf, ok := ret.Get(0).(func(int32) []queries.ApiKey); ok {
+		r0 = rf(accountId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.ApiKey)
 		}
 	}
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(int32) error); ok {
-		r1 = rf(orderId)
+		r1 = rf(accountId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -198,22 +387,711 @@ func (_m *Store) GetStandingOrder(orderId int32) (*queries.StandingOrder, error)
 	return r0, r1
 }
 
-// GetStandingOrders provides a mock function with given fields: orderIds
-func (_m *Store) GetStandingOrders(orderIds []int32) ([]queries.StandingOrder, error) {
-	ret := _m.Called(orderIds)
+// GetAsset provides a mock function with given fields: symbol
+func (_m *Store) GetAsset(symbol string) (*queries.Asset, error) {
+	ret := _m.Called(symbol)
 
-	var r0 []queries.StandingOrder
-	if rf, ok := ret.Get(0).(func([]int32) []queries.StandingOrder); ok {
-		r0 = rf(orderIds)
+	var r0 *queries.Asset
+	if rf, ok := ret.Get(0).(func(string) *queries.Asset); ok {
+		r0 = rf(symbol)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]queries.StandingOrder)
+			r0 = ret.Get(0).(*queries.Asset)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func([]int32) error); ok {
-		r1 = rf(orderIds)
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(symbol)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAssets provides a mock function with given fields: 
+func (_m *Store) GetAssets() ([]queries.Asset, error) {
+	ret := _m.Called()
+
+	var r0 []queries.Asset
+	if rf, ok := ret.Get(0).(func() []queries.Asset); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.Asset)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBalanceJournal provides a mock function with given fields: accountId
+func (_m *Store) GetBalanceJournal(accountId int32) ([]queries.BalanceJournal, error) {
+	ret := _m.Called(accountId)
+
+	var r0 []queries.BalanceJournal
+	if rf, ok := ret.Get(0).(func(int32) []queries.BalanceJournal); ok {
+		r0 = rf(accountId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.BalanceJournal)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(accountId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBalances provides a mock function with given fields: accountId
+func (_m *Store) GetBalances(accountId int32) ([]queries.GetBalancesRow, error) {
+	ret := _m.Called(accountId)
+
+	var r0 []queries.GetBalancesRow
+	if rf, ok := ret.Get(0).(func(int32) []queries.GetBalancesRow); ok {
+		r0 = rf(accountId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.GetBalancesRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(accountId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFixMessages provides a mock function with given fields: sessionId, fromSeqNum, toSeqNum
+func (_m *Store) GetFixMessages(sessionId int32, fromSeqNum int32, toSeqNum int32) ([]queries.FixMessage, error) {
+	ret := _m.Called(sessionId, fromSeqNum, toSeqNum)
+
+	var r0 []queries.FixMessage
+	if rf, ok := ret.Get(0).(func(int32, int32, int32) []queries.FixMessage); ok {
+		r0 = rf(sessionId, fromSeqNum, toSeqNum)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.FixMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32, int32, int32) error); ok {
+		r1 = rf(sessionId, fromSeqNum, toSeqNum)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFixSession provides a mock function with given fields: senderCompId, targetCompId
+func (_m *Store) GetFixSession(senderCompId string, targetCompId string) (*queries.FixSession, error) {
+	ret := _m.Called(senderCompId, targetCompId)
+
+	var r0 *queries.FixSession
+	if rf, ok := ret.Get(0).(func(string, string) *queries.FixSession); ok {
+		r0 = rf(senderCompId, targetCompId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.FixSession)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(senderCompId, targetCompId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLastTrade provides a mock function with given fields: marketId
+func (_m *Store) GetLastTrade(marketId int32) (*queries.Trade, error) {
+	ret := _m.Called(marketId)
+
+	var r0 *queries.Trade
+	if rf, ok := ret.Get(0).(func(int32) *queries.Trade); ok {
+		r0 = rf(marketId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.Trade)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(marketId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMarket provides a mock function with given fields: marketId
+func (_m *Store) GetMarket(marketId int32) (*datastore.Market, error) {
+	ret := _m.Called(marketId)
+
+	var r0 *datastore.Market
+	if rf, ok := ret.Get(0).(func(int32) *datastore.Market); ok {
+		r0 = rf(marketId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*datastore.Market)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(marketId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMarketBySymbol provides a mock function with given fields: symbol
+func (_m *Store) GetMarketBySymbol(symbol string) (*datastore.Market, error) {
+	ret := _m.Called(symbol)
+
+	var r0 *datastore.Market
+	if rf, ok := ret.Get(0).(func(string) *datastore.Market); ok {
+		r0 = rf(symbol)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*datastore.Market)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(symbol)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMarkets provides a mock function with given fields: 
+func (_m *Store) GetMarkets() ([]datastore.Market, error) {
+	ret := _m.Called()
+
+	var r0 []datastore.Market
+	if rf, ok := ret.Get(0).(func() []datastore.Market); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]datastore.Market)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderBook provides a mock function with given fields: marketId, depth
+func (_m *Store) GetOrderBook(marketId int32, depth int32) (*datastore.OrderBook, error) {
+	ret := _m.Called(marketId, depth)
+
+	var r0 *datastore.OrderBook
+	if rf, ok := ret.Get(0).(func(int32, int32) *datastore.OrderBook); ok {
+		r0 = rf(marketId, depth)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*datastore.OrderBook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32, int32) error); ok {
+		r1 = rf(marketId, depth)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderTrades provides a mock function with given fields: orderId
+func (_m *Store) GetOrderTrades(orderId int32) ([]queries.Trade, error) {
+	ret := _m.Called(orderId)
+
+	var r0 []queries.Trade
+	if rf, ok := ret.Get(0).(func(int32) []queries.Trade); ok {
+		r0 = rf(orderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.Trade)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPriceHistory provides a mock function with given fields: from, to, maxRows
+func (_m *Store) GetPriceHistory(from time.Time, to time.Time, maxRows int32) ([]queries.PriceHistory, error) {
+	ret := _m.Called(from, to, maxRows)
+
+	var r0 []queries.PriceHistory
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time, int32) []queries.PriceHistory); ok {
+		r0 = rf(from, to, maxRows)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.PriceHistory)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time, time.Time, int32) error); ok {
+		r1 = rf(from, to, maxRows)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPricesAround provides a mock function with given fields: at
+func (_m *Store) GetPricesAround(at time.Time) (*queries.PriceHistory, *queries.PriceHistory, error) {
+	ret := _m.Called(at)
+
+	var r0 *queries.PriceHistory
+	if rf, ok := ret.Get(0).(func(time.Time) *queries.PriceHistory); ok {
+		r0 = rf(at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.PriceHistory)
+		}
+	}
+
+	var r1 *queries.PriceHistory
+	if rf, ok := ret.Get(1).(func(time.Time) *queries.PriceHistory); ok {
+		r1 = rf(at)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*queries.PriceHistory)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(time.Time) error); ok {
+		r2 = rf(at)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetReconciliation provides a mock function with given fields: 
+func (_m *Store) GetReconciliation() ([]queries.GetReconciliationRow, error) {
+	ret := _m.Called()
+
+	var r0 []queries.GetReconciliationRow
+	if rf, ok := ret.Get(0).(func() []queries.GetReconciliationRow); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.GetReconciliationRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStandingOrder provides a mock function with given fields: orderId
+func (_m *Store) GetStandingOrder(orderId int32) (*queries.StandingOrder, error) {
+	ret := _m.Called(orderId)
+
+	var r0 *queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(int32) *queries.StandingOrder); ok {
+		r0 = rf(orderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.StandingOrder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStandingOrderByClientOrderID provides a mock function with given fields: accountId, clientOrderId
+func (_m *Store) GetStandingOrderByClientOrderID(accountId int32, clientOrderId string) (*queries.StandingOrder, error) {
+	ret := _m.Called(accountId, clientOrderId)
+
+	var r0 *queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(int32, string) *queries.StandingOrder); ok {
+		r0 = rf(accountId, clientOrderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.StandingOrder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32, string) error); ok {
+		r1 = rf(accountId, clientOrderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStandingOrders provides a mock function with given fields: orderIds
+func (_m *Store) GetStandingOrders(orderIds []int32) ([]queries.StandingOrder, error) {
+	ret := _m.Called(orderIds)
+
+	var r0 []queries.StandingOrder
+	if rf, ok := ret.Get(0).(func([]int32) []queries.StandingOrder); ok {
+		r0 = rf(orderIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.StandingOrder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]int32) error); ok {
+		r1 = rf(orderIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTradingStatus provides a mock function with given fields: 
+func (_m *Store) GetTradingStatus() (*queries.TradingStatus, error) {
+	ret := _m.Called()
+
+	var r0 *queries.TradingStatus
+	if rf, ok := ret.Get(0).(func() *queries.TradingStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.TradingStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListStandingOrders provides a mock function with given fields: params
+func (_m *Store) ListStandingOrders(params datastore.ListStandingOrdersParams) ([]queries.StandingOrder, *datastore.StandingOrderCursor, error) {
+	ret := _m.Called(params)
+
+	var r0 []queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(datastore.ListStandingOrdersParams) []queries.StandingOrder); ok {
+		r0 = rf(params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.StandingOrder)
+		}
+	}
+
+	var r1 *datastore.StandingOrderCursor
+	if rf, ok := ret.Get(1).(func(datastore.ListStandingOrdersParams) *datastore.StandingOrderCursor); ok {
+		r1 = rf(params)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*datastore.StandingOrderCursor)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(datastore.ListStandingOrdersParams) error); ok {
+		r2 = rf(params)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RecordPrice provides a mock function with given fields: price, quotedAt
+func (_m *Store) RecordPrice(price currency.USD, quotedAt time.Time) error {
+	ret := _m.Called(price, quotedAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(currency.USD, time.Time) error); ok {
+		r0 = rf(price, quotedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RegisterAccount provides a mock function with given fields: username
+func (_m *Store) RegisterAccount(username string) (*queries.Account, string, error) {
+	ret := _m.Called(username)
+
+	var r0 *queries.Account
+	if rf, ok := ret.Get(0).(func(string) *queries.Account); ok {
+		r0 = rf(username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.Account)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(string) string); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(username)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ReleaseIdempotencyKey provides a mock function with given fields: accountId, key
+func (_m *Store) ReleaseIdempotencyKey(accountId int32, key string) error {
+	ret := _m.Called(accountId, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int32, string) error); ok {
+		r0 = rf(accountId, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceStandingOrder provides a mock function with given fields: orderId, params
+func (_m *Store) ReplaceStandingOrder(orderId int32, params datastore.CreateStandingOrderParams) (*queries.StandingOrder, []int32, error) {
+	ret := _m.Called(orderId, params)
+
+	var r0 *queries.StandingOrder
+	if rf, ok := ret.Get(0).(func(int32, datastore.CreateStandingOrderParams) *queries.StandingOrder); ok {
+		r0 = rf(orderId, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.StandingOrder)
+		}
+	}
+
+	var r1 []int32
+	if rf, ok := ret.Get(1).(func(int32, datastore.CreateStandingOrderParams) []int32); ok {
+		r1 = rf(orderId, params)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]int32)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int32, datastore.CreateStandingOrderParams) error); ok {
+		r2 = rf(orderId, params)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ReserveIdempotencyKey provides a mock function with given fields: params
+func (_m *Store) ReserveIdempotencyKey(params datastore.ReserveIdempotencyKeyParams) (*queries.IdempotencyKey, error) {
+	ret := _m.Called(params)
+
+	var r0 *queries.IdempotencyKey
+	if rf, ok := ret.Get(0).(func(datastore.ReserveIdempotencyKeyParams) *queries.IdempotencyKey); ok {
+		r0 = rf(params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.IdempotencyKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.ReserveIdempotencyKeyParams) error); ok {
+		r1 = rf(params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetFixSession provides a mock function with given fields: sessionId
+func (_m *Store) ResetFixSession(sessionId int32) error {
+	ret := _m.Called(sessionId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int32) error); ok {
+		r0 = rf(sessionId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveFixMessage provides a mock function with given fields: sessionId, seqNum, message
+func (_m *Store) SaveFixMessage(sessionId int32, seqNum int32, message []byte) error {
+	ret := _m.Called(sessionId, seqNum, message)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int32, int32, []byte) error); ok {
+		r0 = rf(sessionId, seqNum, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetAccountFrozen provides a mock function with given fields: accountId, frozen
+func (_m *Store) SetAccountFrozen(accountId int32, frozen bool) (bool, error) {
+	ret := _m.Called(accountId, frozen)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(int32, bool) bool); ok {
+		r0 = rf(accountId, frozen)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32, bool) error); ok {
+		r1 = rf(accountId, frozen)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetFixTargetSeqNum provides a mock function with given fields: sessionId, seqNum
+func (_m *Store) SetFixTargetSeqNum(sessionId int32, seqNum int32) error {
+	ret := _m.Called(sessionId, seqNum)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int32, int32) error); ok {
+		r0 = rf(sessionId, seqNum)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetTradingStatus provides a mock function with given fields: halted, reason
+func (_m *Store) SetTradingStatus(halted bool, reason string) (*queries.TradingStatus, error) {
+	ret := _m.Called(halted, reason)
+
+	var r0 *queries.TradingStatus
+	if rf, ok := ret.Get(0).(func(bool, string) *queries.TradingStatus); ok {
+		r0 = rf(halted, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*queries.TradingStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(bool, string) error); ok {
+		r1 = rf(halted, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TouchApiKey provides a mock function with given fields: keyId
+func (_m *Store) TouchApiKey(keyId int32) error {
+	ret := _m.Called(keyId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int32) error); ok {
+		r0 = rf(keyId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UseRequestNonce provides a mock function with given fields: keyId, nonce, notBefore
+func (_m *Store) UseRequestNonce(keyId int32, nonce string, notBefore time.Time) (bool, error) {
+	ret := _m.Called(keyId, nonce, notBefore)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(int32, string, time.Time) bool); ok {
+		r0 = rf(keyId, nonce, notBefore)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32, string, time.Time) error); ok {
+		r1 = rf(keyId, nonce, notBefore)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -9,7 +9,7 @@ import (
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO account (username, token)
-VALUES ($1, $2) RETURNING id, username, token, usd_amount, btc_amount
+VALUES ($1, $2) RETURNING id, username, token, tier, frozen
 `
 
 type CreateAccountParams struct {
@@ -24,14 +24,14 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.ID,
 		&i.Username,
 		&i.Token,
-		&i.UsdAmount,
-		&i.BtcAmount,
+		&i.Tier,
+		&i.Frozen,
 	)
 	return i, err
 }
 
 const getAccountById = `-- name: GetAccountById :one
-SELECT id, username, token, usd_amount, btc_amount
+SELECT id, username, token, tier, frozen
 FROM account
 WHERE id = $1 LIMIT 1
 `
@@ -43,14 +43,14 @@ func (q *Queries) GetAccountById(ctx context.Context, id int32) (Account, error)
 		&i.ID,
 		&i.Username,
 		&i.Token,
-		&i.UsdAmount,
-		&i.BtcAmount,
+		&i.Tier,
+		&i.Frozen,
 	)
 	return i, err
 }
 
 const getAccountByToken = `-- name: GetAccountByToken :one
-SELECT id, username, token, usd_amount, btc_amount
+SELECT id, username, token, tier, frozen
 FROM account
 WHERE token = $1 LIMIT 1
 `
@@ -62,29 +62,93 @@ func (q *Queries) GetAccountByToken(ctx context.Context, token string) (Account,
 		&i.ID,
 		&i.Username,
 		&i.Token,
-		&i.UsdAmount,
-		&i.BtcAmount,
+		&i.Tier,
+		&i.Frozen,
 	)
 	return i, err
 }
 
-const transferAmounts = `-- name: TransferAmounts :execrows
+const getReconciliation = `-- name: GetReconciliation :many
+SELECT account.id,
+       account.username,
+       account.frozen,
+       asset.symbol AS asset,
+       asset.precision,
+       COALESCE(balance.amount, 0)::bigint AS amount,
+       (SELECT COALESCE(SUM(CASE
+                                WHEN market.base_asset = asset.symbol THEN reserved_base_amount
+                                ELSE reserved_quote_amount END), 0)
+        FROM standing_order
+                 JOIN market ON market.id = standing_order.market_id
+        WHERE standing_order.account_id = account.id
+          AND standing_order.state = 'live'
+          AND asset.symbol IN (market.base_asset, market.quote_asset))::bigint AS reserved_amount,
+       (SELECT COALESCE(SUM(balance_journal.amount), 0)
+        FROM balance_journal
+        WHERE balance_journal.account_id = account.id
+          AND balance_journal.asset = asset.symbol)::bigint AS journal_amount
+FROM account
+         CROSS JOIN asset
+         LEFT JOIN balance ON balance.account_id = account.id AND balance.asset = asset.symbol
+ORDER BY account.id, asset.symbol
+`
+
+type GetReconciliationRow struct {
+	ID             int32
+	Username       string
+	Frozen         bool
+	Asset          string
+	Precision      int32
+	Amount         int64
+	ReservedAmount int64
+	JournalAmount  int64
+}
+
+func (q *Queries) GetReconciliation(ctx context.Context) ([]GetReconciliationRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReconciliation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReconciliationRow
+	for rows.Next() {
+		var i GetReconciliationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Frozen,
+			&i.Asset,
+			&i.Precision,
+			&i.Amount,
+			&i.ReservedAmount,
+			&i.JournalAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAccountFrozen = `-- name: SetAccountFrozen :execrows
 UPDATE account
-SET btc_amount = btc_amount + $2,
-    usd_amount = usd_amount + $3
+SET frozen = $2
 WHERE id = $1
-  AND btc_amount + $2 >= 0
-  AND usd_amount + $3 >= 0
 `
 
-type TransferAmountsParams struct {
-	ID        int32
-	BtcAmount int64
-	UsdAmount int64
+type SetAccountFrozenParams struct {
+	ID     int32
+	Frozen bool
 }
 
-func (q *Queries) TransferAmounts(ctx context.Context, arg TransferAmountsParams) (int64, error) {
-	result, err := q.db.ExecContext(ctx, transferAmounts, arg.ID, arg.BtcAmount, arg.UsdAmount)
+func (q *Queries) SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setAccountFrozen, arg.ID, arg.Frozen)
 	if err != nil {
 		return 0, err
 	}
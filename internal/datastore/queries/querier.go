@@ -4,23 +4,68 @@ package queries
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	CancelStandingOrder(ctx context.Context, id int32) (StandingOrder, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateAsset(ctx context.Context, arg CreateAssetParams) (Asset, error)
+	CreateBalance(ctx context.Context, arg CreateBalanceParams) error
+	CreateBalanceJournalEntry(ctx context.Context, arg CreateBalanceJournalEntryParams) (BalanceJournal, error)
+	CreateFixMessage(ctx context.Context, arg CreateFixMessageParams) error
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error)
+	CreateMarket(ctx context.Context, arg CreateMarketParams) (Market, error)
+	CreatePriceHistoryEntry(ctx context.Context, arg CreatePriceHistoryEntryParams) (PriceHistory, error)
 	CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error)
+	CreateTrade(ctx context.Context, arg CreateTradeParams) (Trade, error)
+	DeleteApiKey(ctx context.Context, arg DeleteApiKeyParams) (int64, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context, arg DeleteExpiredIdempotencyKeysParams) error
+	DeleteExpiredNonces(ctx context.Context, arg DeleteExpiredNoncesParams) error
+	DeleteFixMessages(ctx context.Context, sessionID int32) error
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
 	DeleteStandingOrder(ctx context.Context, id int32) error
 	GetAccountById(ctx context.Context, id int32) (Account, error)
 	GetAccountByToken(ctx context.Context, token string) (Account, error)
-	GetBestBuyer(ctx context.Context, limitPrice int64) (StandingOrder, error)
-	GetBestMarketBuyer(ctx context.Context) (StandingOrder, error)
-	GetBestMarketSeller(ctx context.Context) (StandingOrder, error)
-	GetBestSeller(ctx context.Context, limitPrice int64) (StandingOrder, error)
-	GetReservedAmounts(ctx context.Context, accountID int32) (GetReservedAmountsRow, error)
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetApiKeys(ctx context.Context, accountID int32) ([]ApiKey, error)
+	GetAsset(ctx context.Context, symbol string) (Asset, error)
+	GetAssets(ctx context.Context) ([]Asset, error)
+	GetBalance(ctx context.Context, arg GetBalanceParams) (int64, error)
+	GetBalanceJournal(ctx context.Context, accountID int32) ([]BalanceJournal, error)
+	GetBalances(ctx context.Context, accountID int32) ([]GetBalancesRow, error)
+	GetBestBuyer(ctx context.Context, arg GetBestBuyerParams) (StandingOrder, error)
+	GetBestMarketBuyer(ctx context.Context, marketID int32) (StandingOrder, error)
+	GetBestMarketSeller(ctx context.Context, marketID int32) (StandingOrder, error)
+	GetBestSeller(ctx context.Context, arg GetBestSellerParams) (StandingOrder, error)
+	GetFixMessages(ctx context.Context, arg GetFixMessagesParams) ([]FixMessage, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLastTrade(ctx context.Context, marketID int32) (Trade, error)
+	GetMarket(ctx context.Context, id int32) (GetMarketRow, error)
+	GetMarkets(ctx context.Context) ([]GetMarketsRow, error)
+	GetOrCreateFixSession(ctx context.Context, arg GetOrCreateFixSessionParams) (FixSession, error)
+	GetOrderBookLevels(ctx context.Context, arg GetOrderBookLevelsParams) ([]GetOrderBookLevelsRow, error)
+	GetOrderTrades(ctx context.Context, orderID int32) ([]Trade, error)
+	GetPriceAfter(ctx context.Context, quotedAt time.Time) (PriceHistory, error)
+	GetPriceAtOrBefore(ctx context.Context, quotedAt time.Time) (PriceHistory, error)
+	GetPriceHistory(ctx context.Context, arg GetPriceHistoryParams) ([]PriceHistory, error)
+	GetReconciliation(ctx context.Context) ([]GetReconciliationRow, error)
+	GetReservedAmount(ctx context.Context, arg GetReservedAmountParams) (int64, error)
 	GetStandingOrder(ctx context.Context, id int32) (StandingOrder, error)
+	GetStandingOrderByClientOrderId(ctx context.Context, arg GetStandingOrderByClientOrderIdParams) (StandingOrder, error)
 	GetStandingOrders(ctx context.Context, orderIds []int32) ([]StandingOrder, error)
+	GetTradingStatus(ctx context.Context) (TradingStatus, error)
+	ListStandingOrders(ctx context.Context, arg ListStandingOrdersParams) ([]StandingOrder, error)
 	SatisfyOrder(ctx context.Context, arg SatisfyOrderParams) (StandingOrder, error)
-	TransferAmounts(ctx context.Context, arg TransferAmountsParams) (int64, error)
+	SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (int64, error)
+	SetFixSenderSeqNum(ctx context.Context, arg SetFixSenderSeqNumParams) error
+	SetFixTargetSeqNum(ctx context.Context, arg SetFixTargetSeqNumParams) error
+	SetTradingStatus(ctx context.Context, arg SetTradingStatusParams) (TradingStatus, error)
+	TouchApiKey(ctx context.Context, id int32) error
+	TransferBalance(ctx context.Context, arg TransferBalanceParams) (int64, error)
+	UseNonce(ctx context.Context, arg UseNonceParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,10 +12,31 @@ WHERE id = $1 LIMIT 1;
 INSERT INTO account (username, token)
 VALUES ($1, $2) RETURNING *;
 
--- name: TransferAmounts :execrows
+-- name: SetAccountFrozen :execrows
 UPDATE account
-SET btc_amount = btc_amount + $2,
-    usd_amount = usd_amount + $3
-WHERE id = $1
-  AND btc_amount + $2 >= 0
-  AND usd_amount + $3 >= 0;
+SET frozen = $2
+WHERE id = $1;
+
+-- name: GetReconciliation :many
+SELECT account.id,
+       account.username,
+       account.frozen,
+       asset.symbol AS asset,
+       asset.precision,
+       COALESCE(balance.amount, 0)::bigint AS amount,
+       (SELECT COALESCE(SUM(CASE
+                                WHEN market.base_asset = asset.symbol THEN reserved_base_amount
+                                ELSE reserved_quote_amount END), 0)
+        FROM standing_order
+                 JOIN market ON market.id = standing_order.market_id
+        WHERE standing_order.account_id = account.id
+          AND standing_order.state = 'live'
+          AND asset.symbol IN (market.base_asset, market.quote_asset))::bigint AS reserved_amount,
+       (SELECT COALESCE(SUM(balance_journal.amount), 0)
+        FROM balance_journal
+        WHERE balance_journal.account_id = account.id
+          AND balance_journal.asset = asset.symbol)::bigint AS journal_amount
+FROM account
+         CROSS JOIN asset
+         LEFT JOIN balance ON balance.account_id = account.id AND balance.asset = asset.symbol
+ORDER BY account.id, asset.symbol;
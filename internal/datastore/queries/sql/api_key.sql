@@ -0,0 +1,37 @@
+-- name: CreateApiKey :one
+INSERT INTO api_key (account_id, name, prefix, secret_hash, scopes, allowed_ips, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: GetApiKeyByPrefix :one
+SELECT *
+FROM api_key
+WHERE prefix = $1 LIMIT 1;
+
+-- name: GetApiKeys :many
+SELECT *
+FROM api_key
+WHERE account_id = $1
+ORDER BY id;
+
+-- name: TouchApiKey :exec
+UPDATE api_key
+SET last_used_at = now()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
+
+-- name: DeleteApiKey :execrows
+DELETE
+FROM api_key
+WHERE id = $1
+  AND account_id = $2;
+
+-- name: DeleteExpiredNonces :exec
+DELETE
+FROM request_nonce
+WHERE api_key_id = $1
+  AND created_at < $2;
+
+-- name: UseNonce :execrows
+INSERT INTO request_nonce (api_key_id, nonce)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,13 @@
+-- name: GetAssets :many
+SELECT *
+FROM asset
+ORDER BY symbol;
+
+-- name: GetAsset :one
+SELECT *
+FROM asset
+WHERE symbol = $1 LIMIT 1;
+
+-- name: CreateAsset :one
+INSERT INTO asset (symbol, precision)
+VALUES ($1, $2) RETURNING *;
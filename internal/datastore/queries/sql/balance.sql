@@ -0,0 +1,33 @@
+-- name: GetBalances :many
+SELECT balance.asset, balance.amount, asset.precision
+FROM balance
+         JOIN asset ON asset.symbol = balance.asset
+WHERE balance.account_id = $1
+ORDER BY balance.asset;
+
+-- name: GetBalance :one
+SELECT amount
+FROM balance
+WHERE account_id = $1
+  AND asset = $2 LIMIT 1;
+
+-- name: CreateBalance :exec
+INSERT INTO balance (account_id, asset)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: TransferBalance :execrows
+UPDATE balance
+SET amount = amount + $3
+WHERE account_id = $1
+  AND asset = $2
+  AND amount + $3 >= 0;
+
+-- name: GetReservedAmount :one
+SELECT COALESCE(SUM(CASE WHEN market.base_asset = @asset THEN reserved_base_amount ELSE reserved_quote_amount END),
+                0)::bigint AS amount
+FROM standing_order
+         JOIN market ON market.id = standing_order.market_id
+WHERE standing_order.account_id = @account_id
+  AND standing_order.state = 'live'
+  AND @asset IN (market.base_asset, market.quote_asset);
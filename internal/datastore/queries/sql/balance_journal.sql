@@ -0,0 +1,9 @@
+-- name: CreateBalanceJournalEntry :one
+INSERT INTO balance_journal (account_id, asset, amount, reason, operator)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetBalanceJournal :many
+SELECT *
+FROM balance_journal
+WHERE account_id = $1
+ORDER BY id;
@@ -0,0 +1,32 @@
+-- name: GetOrCreateFixSession :one
+INSERT INTO fix_session (sender_comp_id, target_comp_id)
+VALUES ($1, $2) ON CONFLICT (sender_comp_id, target_comp_id) DO
+UPDATE SET sender_comp_id = EXCLUDED.sender_comp_id RETURNING *;
+
+-- name: CreateFixMessage :exec
+INSERT INTO fix_message (session_id, seq_num, message)
+VALUES ($1, $2, $3) ON CONFLICT (session_id, seq_num) DO
+UPDATE SET message = EXCLUDED.message, created_at = now();
+
+-- name: SetFixSenderSeqNum :exec
+UPDATE fix_session
+SET next_sender_seq_num = $2
+WHERE id = $1;
+
+-- name: SetFixTargetSeqNum :exec
+UPDATE fix_session
+SET next_target_seq_num = $2
+WHERE id = $1;
+
+-- name: GetFixMessages :many
+SELECT *
+FROM fix_message
+WHERE session_id = @session_id
+  AND seq_num >= @from_seq_num
+  AND seq_num <= @to_seq_num
+ORDER BY seq_num;
+
+-- name: DeleteFixMessages :exec
+DELETE
+FROM fix_message
+WHERE session_id = $1;
@@ -0,0 +1,29 @@
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_key (account_id, key, endpoint, request_hash)
+VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING;
+
+-- name: GetIdempotencyKey :one
+SELECT *
+FROM idempotency_key
+WHERE account_id = $1
+  AND key = $2 LIMIT 1;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_key
+SET response_status = $3,
+    content_type    = $4,
+    response_body   = $5
+WHERE account_id = $1
+  AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE
+FROM idempotency_key
+WHERE account_id = $1
+  AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE
+FROM idempotency_key
+WHERE account_id = $1
+  AND created_at < $2;
@@ -0,0 +1,27 @@
+-- name: GetMarkets :many
+SELECT market.id,
+       market.symbol,
+       market.base_asset,
+       base.precision  AS base_precision,
+       market.quote_asset,
+       quote.precision AS quote_precision
+FROM market
+         JOIN asset base ON base.symbol = market.base_asset
+         JOIN asset quote ON quote.symbol = market.quote_asset
+ORDER BY market.id;
+
+-- name: GetMarket :one
+SELECT market.id,
+       market.symbol,
+       market.base_asset,
+       base.precision  AS base_precision,
+       market.quote_asset,
+       quote.precision AS quote_precision
+FROM market
+         JOIN asset base ON base.symbol = market.base_asset
+         JOIN asset quote ON quote.symbol = market.quote_asset
+WHERE market.id = $1 LIMIT 1;
+
+-- name: CreateMarket :one
+INSERT INTO market (symbol, base_asset, quote_asset)
+VALUES ($1, $2, $3) RETURNING *;
@@ -0,0 +1,22 @@
+-- name: CreatePriceHistoryEntry :one
+INSERT INTO price_history (price, quoted_at)
+VALUES ($1, $2) RETURNING *;
+
+-- name: GetPriceAtOrBefore :one
+SELECT *
+FROM price_history
+WHERE quoted_at <= $1
+ORDER BY quoted_at DESC LIMIT 1;
+
+-- name: GetPriceAfter :one
+SELECT *
+FROM price_history
+WHERE quoted_at > $1
+ORDER BY quoted_at LIMIT 1;
+
+-- name: GetPriceHistory :many
+SELECT *
+FROM price_history
+WHERE quoted_at >= @quoted_from
+  AND quoted_at < @quoted_to
+ORDER BY quoted_at LIMIT @max_rows;
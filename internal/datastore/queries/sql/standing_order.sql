@@ -1,49 +1,81 @@
 -- name: CreateStandingOrder :one
-INSERT INTO standing_order (account_id, type, state, quantity, limit_price, reserved_btc_amount, reserved_usd_amount,
-                            webhook_url)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+INSERT INTO standing_order (account_id, market_id, type, state, quantity, limit_price, reserved_base_amount,
+                            reserved_quote_amount, webhook_url, client_order_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
 
 -- name: GetStandingOrder :one
 SELECT *
 FROM standing_order
 WHERE id = $1 LIMIT 1;
 
+-- name: GetStandingOrderByClientOrderId :one
+SELECT *
+FROM standing_order
+WHERE account_id = $1
+  AND client_order_id = $2 LIMIT 1;
+
 -- name: GetStandingOrders :many
 SELECT *
 FROM standing_order
-WHERE id IN (@order_ids::integer[]);
+WHERE id = ANY (@order_ids::integer[]);
+
+-- name: ListStandingOrders :many
+SELECT *
+FROM standing_order
+WHERE account_id = @account_id
+  AND (@market_id::integer = 0 OR market_id = @market_id)
+  AND (cardinality(@states::varchar[]) = 0 OR state::varchar = ANY (@states::varchar[]))
+  AND (cardinality(@types::varchar[]) = 0 OR type::varchar = ANY (@types::varchar[]))
+  AND created_at >= @created_from
+  AND created_at < @created_to
+  AND (created_at, id) < (@cursor_created_at::timestamptz, @cursor_id::integer)
+ORDER BY created_at DESC, id DESC LIMIT @max_rows;
+
+-- name: GetOrderBookLevels :many
+SELECT limit_price, SUM(quantity)::bigint AS quantity, COUNT(*)::integer AS order_count
+FROM standing_order
+WHERE state = 'live'
+  AND market_id = @market_id
+  AND type = @type
+GROUP BY limit_price
+ORDER BY CASE WHEN @type = 'buy' THEN -limit_price ELSE limit_price END LIMIT @max_rows;
 
 -- name: DeleteStandingOrder :exec
 DELETE
 FROM standing_order
 WHERE id = $1;
 
--- name: GetReservedAmounts :one
-SELECT COALESCE(SUM(reserved_usd_amount), 0)::bigint as usd_amount, COALESCE(SUM(reserved_btc_amount), 0) ::bigint as btc_amount
-FROM standing_order
-WHERE account_id = $1
-  AND state = 'live';
+-- name: CancelStandingOrder :one
+UPDATE standing_order
+SET state                 = 'cancelled',
+    reserved_quote_amount = 0,
+    reserved_base_amount  = 0
+WHERE id = $1
+  AND state = 'live' RETURNING *;
 
 -- name: GetBestBuyer :one
 SELECT *
 FROM standing_order
 WHERE state = 'live'
+  AND market_id = $1
   AND type = 'buy'
-  AND limit_price >= $1
+  AND limit_price >= $2
 ORDER BY limit_price DESC LIMIT 1;
 
 -- name: GetBestSeller :one
 SELECT *
 FROM standing_order
 WHERE state = 'live'
+  AND market_id = $1
   AND type = 'sell'
-  AND limit_price <= $1
+  AND limit_price <= $2
 ORDER BY limit_price ASC LIMIT 1;
 
 -- name: GetBestMarketBuyer :one
 SELECT *
 FROM standing_order
 WHERE state = 'live'
+  AND market_id = $1
   AND type = 'buy'
 ORDER BY limit_price DESC LIMIT 1;
 
@@ -51,20 +83,21 @@ ORDER BY limit_price DESC LIMIT 1;
 SELECT *
 FROM standing_order
 WHERE state = 'live'
+  AND market_id = $1
   AND type = 'sell'
 ORDER BY limit_price ASC LIMIT 1;
 
 -- name: SatisfyOrder :one
 UPDATE standing_order
-SET quantity            = quantity - $2,
-    filled_quantity     = filled_quantity + $2,
-    filled_price        = filled_price + $3,
-    state               = CASE
-                              WHEN quantity - $2 = 0 THEN 'fulfilled'
-                              ELSE state
+SET quantity              = quantity - $2,
+    filled_quantity       = filled_quantity + $2,
+    filled_price          = filled_price + $3,
+    state                 = CASE
+                                WHEN quantity - $2 = 0 THEN 'fulfilled'
+                                ELSE state
         END,
-    reserved_usd_amount = reserved_usd_amount - $4,
-    reserved_btc_amount = reserved_btc_amount - $5
+    reserved_quote_amount = reserved_quote_amount - $4,
+    reserved_base_amount  = reserved_base_amount - $5
 WHERE id = $1
   AND quantity - $2 >= 0 RETURNING *;
 
@@ -0,0 +1,16 @@
+-- name: CreateTrade :one
+INSERT INTO trade (market_id, buy_order_id, sell_order_id, taker_side, quantity, price)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetOrderTrades :many
+SELECT *
+FROM trade
+WHERE buy_order_id = @order_id
+   OR sell_order_id = @order_id
+ORDER BY id;
+
+-- name: GetLastTrade :one
+SELECT *
+FROM trade
+WHERE market_id = $1
+ORDER BY id DESC LIMIT 1;
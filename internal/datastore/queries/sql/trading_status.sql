@@ -0,0 +1,9 @@
+-- name: GetTradingStatus :one
+SELECT *
+FROM trading_status LIMIT 1;
+
+-- name: SetTradingStatus :one
+UPDATE trading_status
+SET halted     = $1,
+    reason     = $2,
+    updated_at = now() RETURNING *;
@@ -6,51 +6,91 @@ package queries
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/galcik/vlexchange/internal/currency"
 	"github.com/lib/pq"
 )
 
+const cancelStandingOrder = `-- name: CancelStandingOrder :one
+UPDATE standing_order
+SET state                 = 'cancelled',
+    reserved_quote_amount = 0,
+    reserved_base_amount  = 0
+WHERE id = $1
+  AND state = 'live' RETURNING id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
+`
+
+func (q *Queries) CancelStandingOrder(ctx context.Context, id int32) (StandingOrder, error) {
+	row := q.db.QueryRowContext(ctx, cancelStandingOrder, id)
+	var i StandingOrder
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.MarketID,
+		&i.Type,
+		&i.State,
+		&i.Quantity,
+		&i.FilledQuantity,
+		&i.FilledPrice,
+		&i.LimitPrice,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
+		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createStandingOrder = `-- name: CreateStandingOrder :one
-INSERT INTO standing_order (account_id, type, state, quantity, limit_price, reserved_btc_amount, reserved_usd_amount,
-                            webhook_url)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+INSERT INTO standing_order (account_id, market_id, type, state, quantity, limit_price, reserved_base_amount,
+                            reserved_quote_amount, webhook_url, client_order_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 `
 
 type CreateStandingOrderParams struct {
-	AccountID         int32
-	Type              OrderType
-	State             OrderState
-	Quantity          int64
-	LimitPrice        int64
-	ReservedBtcAmount int64
-	ReservedUsdAmount int64
-	WebhookUrl        sql.NullString
+	AccountID           int32
+	MarketID            int32
+	Type                OrderType
+	State               OrderState
+	Quantity            currency.Amount
+	LimitPrice          currency.Amount
+	ReservedBaseAmount  currency.Amount
+	ReservedQuoteAmount currency.Amount
+	WebhookUrl          sql.NullString
+	ClientOrderID       sql.NullString
 }
 
 func (q *Queries) CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error) {
 	row := q.db.QueryRowContext(ctx, createStandingOrder,
 		arg.AccountID,
+		arg.MarketID,
 		arg.Type,
 		arg.State,
 		arg.Quantity,
 		arg.LimitPrice,
-		arg.ReservedBtcAmount,
-		arg.ReservedUsdAmount,
+		arg.ReservedBaseAmount,
+		arg.ReservedQuoteAmount,
 		arg.WebhookUrl,
+		arg.ClientOrderID,
 	)
 	var i StandingOrder
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
+		&i.MarketID,
 		&i.Type,
 		&i.State,
 		&i.Quantity,
 		&i.FilledQuantity,
 		&i.FilledPrice,
 		&i.LimitPrice,
-		&i.ReservedUsdAmount,
-		&i.ReservedBtcAmount,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
 		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -67,136 +107,188 @@ func (q *Queries) DeleteStandingOrder(ctx context.Context, id int32) error {
 }
 
 const getBestBuyer = `-- name: GetBestBuyer :one
-SELECT id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 FROM standing_order
 WHERE state = 'live'
+  AND market_id = $1
   AND type = 'buy'
-  AND limit_price >= $1
+  AND limit_price >= $2
 ORDER BY limit_price DESC LIMIT 1
 `
 
-func (q *Queries) GetBestBuyer(ctx context.Context, limitPrice int64) (StandingOrder, error) {
-	row := q.db.QueryRowContext(ctx, getBestBuyer, limitPrice)
+type GetBestBuyerParams struct {
+	MarketID   int32
+	LimitPrice currency.Amount
+}
+
+func (q *Queries) GetBestBuyer(ctx context.Context, arg GetBestBuyerParams) (StandingOrder, error) {
+	row := q.db.QueryRowContext(ctx, getBestBuyer, arg.MarketID, arg.LimitPrice)
 	var i StandingOrder
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
+		&i.MarketID,
 		&i.Type,
 		&i.State,
 		&i.Quantity,
 		&i.FilledQuantity,
 		&i.FilledPrice,
 		&i.LimitPrice,
-		&i.ReservedUsdAmount,
-		&i.ReservedBtcAmount,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
 		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getBestMarketBuyer = `-- name: GetBestMarketBuyer :one
-SELECT id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 FROM standing_order
 WHERE state = 'live'
+  AND market_id = $1
   AND type = 'buy'
 ORDER BY limit_price DESC LIMIT 1
 `
 
-func (q *Queries) GetBestMarketBuyer(ctx context.Context) (StandingOrder, error) {
-	row := q.db.QueryRowContext(ctx, getBestMarketBuyer)
+func (q *Queries) GetBestMarketBuyer(ctx context.Context, marketID int32) (StandingOrder, error) {
+	row := q.db.QueryRowContext(ctx, getBestMarketBuyer, marketID)
 	var i StandingOrder
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
+		&i.MarketID,
 		&i.Type,
 		&i.State,
 		&i.Quantity,
 		&i.FilledQuantity,
 		&i.FilledPrice,
 		&i.LimitPrice,
-		&i.ReservedUsdAmount,
-		&i.ReservedBtcAmount,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
 		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getBestMarketSeller = `-- name: GetBestMarketSeller :one
-SELECT id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 FROM standing_order
 WHERE state = 'live'
+  AND market_id = $1
   AND type = 'sell'
 ORDER BY limit_price ASC LIMIT 1
 `
 
-func (q *Queries) GetBestMarketSeller(ctx context.Context) (StandingOrder, error) {
-	row := q.db.QueryRowContext(ctx, getBestMarketSeller)
+func (q *Queries) GetBestMarketSeller(ctx context.Context, marketID int32) (StandingOrder, error) {
+	row := q.db.QueryRowContext(ctx, getBestMarketSeller, marketID)
 	var i StandingOrder
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
+		&i.MarketID,
 		&i.Type,
 		&i.State,
 		&i.Quantity,
 		&i.FilledQuantity,
 		&i.FilledPrice,
 		&i.LimitPrice,
-		&i.ReservedUsdAmount,
-		&i.ReservedBtcAmount,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
 		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getBestSeller = `-- name: GetBestSeller :one
-SELECT id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 FROM standing_order
 WHERE state = 'live'
+  AND market_id = $1
   AND type = 'sell'
-  AND limit_price <= $1
+  AND limit_price <= $2
 ORDER BY limit_price ASC LIMIT 1
 `
 
-func (q *Queries) GetBestSeller(ctx context.Context, limitPrice int64) (StandingOrder, error) {
-	row := q.db.QueryRowContext(ctx, getBestSeller, limitPrice)
+type GetBestSellerParams struct {
+	MarketID   int32
+	LimitPrice currency.Amount
+}
+
+func (q *Queries) GetBestSeller(ctx context.Context, arg GetBestSellerParams) (StandingOrder, error) {
+	row := q.db.QueryRowContext(ctx, getBestSeller, arg.MarketID, arg.LimitPrice)
 	var i StandingOrder
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
+		&i.MarketID,
 		&i.Type,
 		&i.State,
 		&i.Quantity,
 		&i.FilledQuantity,
 		&i.FilledPrice,
 		&i.LimitPrice,
-		&i.ReservedUsdAmount,
-		&i.ReservedBtcAmount,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
 		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getReservedAmounts = `-- name: GetReservedAmounts :one
-SELECT COALESCE(SUM(reserved_usd_amount), 0)::bigint as usd_amount, COALESCE(SUM(reserved_btc_amount), 0) ::bigint as btc_amount
+const getOrderBookLevels = `-- name: GetOrderBookLevels :many
+SELECT limit_price, SUM(quantity)::bigint AS quantity, COUNT(*)::integer AS order_count
 FROM standing_order
-WHERE account_id = $1
-  AND state = 'live'
+WHERE state = 'live'
+  AND market_id = $1
+  AND type = $2
+GROUP BY limit_price
+ORDER BY CASE WHEN $2 = 'buy' THEN -limit_price ELSE limit_price END LIMIT $3
 `
 
-type GetReservedAmountsRow struct {
-	UsdAmount int64
-	BtcAmount int64
+type GetOrderBookLevelsParams struct {
+	MarketID int32
+	Type     OrderType
+	MaxRows  int32
 }
 
-func (q *Queries) GetReservedAmounts(ctx context.Context, accountID int32) (GetReservedAmountsRow, error) {
-	row := q.db.QueryRowContext(ctx, getReservedAmounts, accountID)
-	var i GetReservedAmountsRow
-	err := row.Scan(&i.UsdAmount, &i.BtcAmount)
-	return i, err
+type GetOrderBookLevelsRow struct {
+	LimitPrice currency.Amount
+	Quantity   int64
+	OrderCount int32
+}
+
+func (q *Queries) GetOrderBookLevels(ctx context.Context, arg GetOrderBookLevelsParams) ([]GetOrderBookLevelsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderBookLevels, arg.MarketID, arg.Type, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderBookLevelsRow
+	for rows.Next() {
+		var i GetOrderBookLevelsRow
+		if err := rows.Scan(&i.LimitPrice, &i.Quantity, &i.OrderCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getStandingOrder = `-- name: GetStandingOrder :one
-SELECT id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 FROM standing_order
 WHERE id = $1 LIMIT 1
 `
@@ -207,23 +299,60 @@ func (q *Queries) GetStandingOrder(ctx context.Context, id int32) (StandingOrder
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
+		&i.MarketID,
+		&i.Type,
+		&i.State,
+		&i.Quantity,
+		&i.FilledQuantity,
+		&i.FilledPrice,
+		&i.LimitPrice,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
+		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getStandingOrderByClientOrderId = `-- name: GetStandingOrderByClientOrderId :one
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
+FROM standing_order
+WHERE account_id = $1
+  AND client_order_id = $2 LIMIT 1
+`
+
+type GetStandingOrderByClientOrderIdParams struct {
+	AccountID     int32
+	ClientOrderID sql.NullString
+}
+
+func (q *Queries) GetStandingOrderByClientOrderId(ctx context.Context, arg GetStandingOrderByClientOrderIdParams) (StandingOrder, error) {
+	row := q.db.QueryRowContext(ctx, getStandingOrderByClientOrderId, arg.AccountID, arg.ClientOrderID)
+	var i StandingOrder
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.MarketID,
 		&i.Type,
 		&i.State,
 		&i.Quantity,
 		&i.FilledQuantity,
 		&i.FilledPrice,
 		&i.LimitPrice,
-		&i.ReservedUsdAmount,
-		&i.ReservedBtcAmount,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
 		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getStandingOrders = `-- name: GetStandingOrders :many
-SELECT id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 FROM standing_order
-WHERE id IN ($1::integer[])
+WHERE id = ANY ($1::integer[])
 `
 
 func (q *Queries) GetStandingOrders(ctx context.Context, orderIds []int32) ([]StandingOrder, error) {
@@ -238,15 +367,91 @@ func (q *Queries) GetStandingOrders(ctx context.Context, orderIds []int32) ([]St
 		if err := rows.Scan(
 			&i.ID,
 			&i.AccountID,
+			&i.MarketID,
+			&i.Type,
+			&i.State,
+			&i.Quantity,
+			&i.FilledQuantity,
+			&i.FilledPrice,
+			&i.LimitPrice,
+			&i.ReservedQuoteAmount,
+			&i.ReservedBaseAmount,
+			&i.WebhookUrl,
+			&i.ClientOrderID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStandingOrders = `-- name: ListStandingOrders :many
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
+FROM standing_order
+WHERE account_id = $1
+  AND ($2::integer = 0 OR market_id = $2)
+  AND (cardinality($3::varchar[]) = 0 OR state::varchar = ANY ($3::varchar[]))
+  AND (cardinality($4::varchar[]) = 0 OR type::varchar = ANY ($4::varchar[]))
+  AND created_at >= $5
+  AND created_at < $6
+  AND (created_at, id) < ($7::timestamptz, $8::integer)
+ORDER BY created_at DESC, id DESC LIMIT $9
+`
+
+type ListStandingOrdersParams struct {
+	AccountID       int32
+	MarketID        int32
+	States          []string
+	Types           []string
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	CursorCreatedAt time.Time
+	CursorID        int32
+	MaxRows         int32
+}
+
+func (q *Queries) ListStandingOrders(ctx context.Context, arg ListStandingOrdersParams) ([]StandingOrder, error) {
+	rows, err := q.db.QueryContext(ctx, listStandingOrders,
+		arg.AccountID,
+		arg.MarketID,
+		pq.Array(arg.States),
+		pq.Array(arg.Types),
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StandingOrder
+	for rows.Next() {
+		var i StandingOrder
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.MarketID,
 			&i.Type,
 			&i.State,
 			&i.Quantity,
 			&i.FilledQuantity,
 			&i.FilledPrice,
 			&i.LimitPrice,
-			&i.ReservedUsdAmount,
-			&i.ReservedBtcAmount,
+			&i.ReservedQuoteAmount,
+			&i.ReservedBaseAmount,
 			&i.WebhookUrl,
+			&i.ClientOrderID,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -263,25 +468,25 @@ func (q *Queries) GetStandingOrders(ctx context.Context, orderIds []int32) ([]St
 
 const satisfyOrder = `-- name: SatisfyOrder :one
 UPDATE standing_order
-SET quantity            = quantity - $2,
-    filled_quantity     = filled_quantity + $2,
-    filled_price        = filled_price + $3,
-    state               = CASE
-                              WHEN quantity - $2 = 0 THEN 'fulfilled'
-                              ELSE state
+SET quantity              = quantity - $2,
+    filled_quantity       = filled_quantity + $2,
+    filled_price          = filled_price + $3,
+    state                 = CASE
+                                WHEN quantity - $2 = 0 THEN 'fulfilled'
+                                ELSE state
         END,
-    reserved_usd_amount = reserved_usd_amount - $4,
-    reserved_btc_amount = reserved_btc_amount - $5
+    reserved_quote_amount = reserved_quote_amount - $4,
+    reserved_base_amount  = reserved_base_amount - $5
 WHERE id = $1
-  AND quantity - $2 >= 0 RETURNING id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+  AND quantity - $2 >= 0 RETURNING id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 `
 
 type SatisfyOrderParams struct {
-	ID                int32
-	Quantity          int64
-	FilledPrice       int64
-	ReservedUsdAmount int64
-	ReservedBtcAmount int64
+	ID                  int32
+	Quantity            currency.Amount
+	FilledPrice         currency.Amount
+	ReservedQuoteAmount currency.Amount
+	ReservedBaseAmount  currency.Amount
 }
 
 func (q *Queries) SatisfyOrder(ctx context.Context, arg SatisfyOrderParams) (StandingOrder, error) {
@@ -289,22 +494,25 @@ func (q *Queries) SatisfyOrder(ctx context.Context, arg SatisfyOrderParams) (Sta
 		arg.ID,
 		arg.Quantity,
 		arg.FilledPrice,
-		arg.ReservedUsdAmount,
-		arg.ReservedBtcAmount,
+		arg.ReservedQuoteAmount,
+		arg.ReservedBaseAmount,
 	)
 	var i StandingOrder
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
+		&i.MarketID,
 		&i.Type,
 		&i.State,
 		&i.Quantity,
 		&i.FilledQuantity,
 		&i.FilledPrice,
 		&i.LimitPrice,
-		&i.ReservedUsdAmount,
-		&i.ReservedBtcAmount,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
 		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -3,27 +3,192 @@ CREATE TABLE account
     id         SERIAL PRIMARY KEY,
     username   varchar          NOT NULL,
     token      varchar(50)      NOT NULL UNIQUE,
-    usd_amount bigint DEFAULT 0 NOT NULL,
-    btc_amount bigint DEFAULT 0 NOT NULL
+    tier       varchar          NOT NULL DEFAULT 'standard',
+    -- frozen accounts cannot trade or deposit
+    frozen     boolean          NOT NULL DEFAULT false
+);
+
+-- amounts of an asset are integers in units of 10^-precision
+CREATE TABLE asset
+(
+    symbol    varchar(16) PRIMARY KEY,
+    precision integer NOT NULL CHECK (precision BETWEEN 0 AND 18)
+);
+
+INSERT INTO asset (symbol, precision)
+VALUES ('BTC', 8),
+       ('USD', 2);
+
+-- markets trade the base asset for the quote asset, prices are amounts of the
+-- quote asset per one base asset
+CREATE TABLE market
+(
+    id          SERIAL PRIMARY KEY,
+    symbol      varchar(33) NOT NULL UNIQUE,
+    base_asset  varchar(16) NOT NULL REFERENCES asset (symbol),
+    quote_asset varchar(16) NOT NULL REFERENCES asset (symbol),
+    CHECK (base_asset <> quote_asset)
+);
+
+INSERT INTO market (symbol, base_asset, quote_asset)
+VALUES ('BTC-USD', 'BTC', 'USD');
+
+CREATE TABLE balance
+(
+    account_id integer          NOT NULL REFERENCES account (id),
+    asset      varchar(16)      NOT NULL REFERENCES asset (symbol),
+    amount     bigint DEFAULT 0 NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (account_id, asset)
 );
 
 CREATE TYPE order_type AS ENUM ('buy', 'sell');
 CREATE TYPE order_state AS ENUM ('live', 'fulfilled', 'cancelled');
 
 CREATE TABLE standing_order
+(
+    id                    SERIAL PRIMARY KEY,
+    account_id            integer                    NOT NULL REFERENCES account (id),
+    -- orders default to the BTC-USD market
+    market_id             integer     DEFAULT 1      NOT NULL REFERENCES market (id),
+    type                  order_type                 NOT NULL,
+    state                 order_state DEFAULT 'live' NOT NULL,
+    quantity              bigint      DEFAULT 0      NOT NULL,
+    filled_quantity       bigint      DEFAULT 0      NOT NULL,
+    filled_price          bigint      DEFAULT 0      NOT NULL,
+    limit_price           bigint      DEFAULT 0      NOT NULL,
+    -- amounts of the quote and base assets held for the order
+    reserved_quote_amount bigint      DEFAULT 0      NOT NULL,
+    reserved_base_amount  bigint      DEFAULT 0      NOT NULL,
+    webhook_url           text,
+    client_order_id       varchar(64),
+    created_at            timestamptz DEFAULT now()  NOT NULL
+);
+
+-- serves account order listings, newest first
+CREATE
+    INDEX standing_order_account_id_created_at_idx ON standing_order (account_id, created_at DESC, id DESC);
+
+CREATE UNIQUE
+    INDEX standing_order_client_order_id_idx ON standing_order (account_id, client_order_id);
+
+CREATE TABLE api_key
+(
+    id           SERIAL PRIMARY KEY,
+    account_id   integer                 NOT NULL REFERENCES account (id),
+    name         varchar                 NOT NULL,
+    prefix       varchar(16)             NOT NULL UNIQUE,
+    secret_hash  bytea                   NOT NULL,
+    scopes       varchar[]               NOT NULL,
+    allowed_ips  varchar[] DEFAULT '{}'  NOT NULL,
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    created_at   timestamptz DEFAULT now() NOT NULL
+);
+
+CREATE
+    INDEX api_key_account_id_idx ON api_key (account_id);
+
+
+CREATE TABLE request_nonce
+(
+    api_key_id integer                   NOT NULL REFERENCES api_key (id) ON DELETE CASCADE,
+    nonce      varchar(64)               NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    PRIMARY KEY (api_key_id, nonce)
+);
+CREATE TABLE idempotency_key
+(
+    account_id      integer                   NOT NULL REFERENCES account (id),
+    key             varchar(255)              NOT NULL,
+    endpoint        varchar                   NOT NULL,
+    request_hash    bytea                     NOT NULL,
+    -- zero while the original request is in progress
+    response_status integer     DEFAULT 0     NOT NULL,
+    content_type    varchar     DEFAULT ''    NOT NULL,
+    response_body   bytea,
+    created_at      timestamptz DEFAULT now() NOT NULL,
+    PRIMARY KEY (account_id, key)
+);
+
+-- market orders are deleted once executed, trades keep their ids without references
+CREATE TABLE trade
+(
+    id            BIGSERIAL PRIMARY KEY,
+    market_id     integer                   NOT NULL REFERENCES market (id),
+    buy_order_id  integer                   NOT NULL,
+    sell_order_id integer                   NOT NULL,
+    taker_side    order_type                NOT NULL,
+    quantity      bigint                    NOT NULL,
+    -- quote asset per base asset
+    price         bigint                    NOT NULL,
+    created_at    timestamptz DEFAULT now() NOT NULL
+);
+
+CREATE
+    INDEX trade_market_id_idx ON trade (market_id, id DESC);
+
+CREATE
+    INDEX trade_buy_order_id_idx ON trade (buy_order_id);
+
+CREATE
+    INDEX trade_sell_order_id_idx ON trade (sell_order_id);
+
+-- sessions of the FIX gateway, comp ids are seen from the gateway
+CREATE TABLE fix_session
 (
     id                  SERIAL PRIMARY KEY,
-    account_id          integer                    NOT NULL REFERENCES account (id),
-    type                order_type                 NOT NULL,
-    state               order_state DEFAULT 'live' NOT NULL,
-    quantity            bigint      DEFAULT 0      NOT NULL,
-    filled_quantity     bigint      DEFAULT 0      NOT NULL,
-    filled_price        bigint      DEFAULT 0      NOT NULL,
-    limit_price         bigint      DEFAULT 0      NOT NULL,
-    reserved_usd_amount bigint      DEFAULT 0      NOT NULL,
-    reserved_btc_amount bigint      DEFAULT 0      NOT NULL,
-    webhook_url         text
+    sender_comp_id      varchar(64)   NOT NULL,
+    target_comp_id      varchar(64)   NOT NULL,
+    next_sender_seq_num integer DEFAULT 1 NOT NULL,
+    next_target_seq_num integer DEFAULT 1 NOT NULL,
+    UNIQUE (sender_comp_id, target_comp_id)
+);
+
+-- sent messages are kept for resend requests
+CREATE TABLE fix_message
+(
+    session_id integer                   NOT NULL REFERENCES fix_session (id) ON DELETE CASCADE,
+    seq_num    integer                   NOT NULL,
+    message    bytea                     NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    PRIMARY KEY (session_id, seq_num)
+);
+
+-- deposits and operator adjustments of balances, trades only move amounts
+-- between accounts so the balances add up to the journal
+CREATE TABLE balance_journal
+(
+    id         BIGSERIAL PRIMARY KEY,
+    account_id integer                   NOT NULL REFERENCES account (id),
+    asset      varchar(16)               NOT NULL REFERENCES asset (symbol),
+    amount     bigint                    NOT NULL,
+    reason     varchar                   NOT NULL,
+    -- empty for deposits of the account holder
+    operator   varchar     DEFAULT ''    NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL
+);
+
+CREATE
+    INDEX balance_journal_account_id_idx ON balance_journal (account_id);
+
+-- the single row holds the trading halt set by operators
+CREATE TABLE trading_status
+(
+    id         boolean     DEFAULT true  PRIMARY KEY CHECK (id),
+    halted     boolean     DEFAULT false NOT NULL,
+    reason     varchar     DEFAULT ''    NOT NULL,
+    updated_at timestamptz DEFAULT now() NOT NULL
+);
+
+INSERT INTO trading_status DEFAULT VALUES;
+
+-- reference BTC prices recorded by the price refresher
+CREATE TABLE price_history
+(
+    id        BIGSERIAL PRIMARY KEY,
+    price     bigint      NOT NULL,
+    quoted_at timestamptz NOT NULL
 );
 
 CREATE
-    INDEX standing_order_account_id_idx ON standing_order (account_id);
\ No newline at end of file
+    INDEX price_history_quoted_at_idx ON price_history (quoted_at);
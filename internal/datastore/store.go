@@ -7,18 +7,86 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/galcik/vlexchange/internal/apikey"
 	"github.com/galcik/vlexchange/internal/currency"
 	"github.com/galcik/vlexchange/internal/datastore/queries"
-	_ "github.com/lib/pq"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"math"
+	"time"
 )
 
+// ErrDuplicateClientOrderID is returned when the account already has an order
+// with the client order id.
+var ErrDuplicateClientOrderID = errors.New("duplicate client order id")
+
+// ErrAccountFrozen is returned for orders and deposits of frozen accounts.
+var ErrAccountFrozen = errors.New("account frozen")
+
+// ErrTradingHalted is returned for orders placed while trading is halted.
+var ErrTradingHalted = errors.New("trading halted")
+
+// ErrUnknownAsset is returned for balance changes of unregistered assets.
+var ErrUnknownAsset = errors.New("unknown asset")
+
+// ErrUnknownMarket is returned for orders in markets which do not exist.
+var ErrUnknownMarket = errors.New("unknown market")
+
+// ErrOrderNotLive is returned for changes of orders which are fulfilled or
+// cancelled.
+var ErrOrderNotLive = errors.New("order is not live")
+
+// ErrInsufficientFunds is returned for replacements of orders which are not
+// covered by the balances.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// DefaultMarketID is the BTC-USD market created with the schema.
+const DefaultMarketID int32 = 1
+
+// DefaultMarket is the symbol of the market used when clients name none.
+const DefaultMarket = "BTC-USD"
+
+// depositReason is the journal reason of deposits made by account holders.
+const depositReason = "deposit"
+
+const uniqueViolation = "23505"
+
 type Store interface {
 	WithContext(ctx context.Context) Store
 	ExecuteTx(transaction func(context.Context, queries.Querier) error) error
 
+	RegisterAccount(username string) (*queries.Account, string, error)
 	GetAccountByToken(token string) (*queries.Account, error)
 	GetAccount(accountId int32) (*queries.Account, error)
-	DepositAccount(accountId int32, btcAmount currency.BTC, usdAmount currency.USD) (bool, error)
+	DepositAccount(accountId int32, asset string, amount int64) (bool, error)
+	AdjustBalance(params AdjustBalanceParams) (bool, error)
+	GetBalances(accountId int32) ([]queries.GetBalancesRow, error)
+	GetBalanceJournal(accountId int32) ([]queries.BalanceJournal, error)
+	SetAccountFrozen(accountId int32, frozen bool) (bool, error)
+	GetReconciliation() ([]queries.GetReconciliationRow, error)
+
+	GetAssets() ([]queries.Asset, error)
+	GetAsset(symbol string) (*queries.Asset, error)
+	CreateAsset(symbol string, precision int32) (*queries.Asset, error)
+	GetMarkets() ([]Market, error)
+	GetMarket(marketId int32) (*Market, error)
+	GetMarketBySymbol(symbol string) (*Market, error)
+	CreateMarket(baseAsset string, quoteAsset string) (*Market, error)
+
+	GetTradingStatus() (*queries.TradingStatus, error)
+	SetTradingStatus(halted bool, reason string) (*queries.TradingStatus, error)
+
+	CreateApiKey(params CreateApiKeyParams) (*queries.ApiKey, string, error)
+	GetApiKeyByToken(token string) (*queries.ApiKey, error)
+	GetApiKeys(accountId int32) ([]queries.ApiKey, error)
+	TouchApiKey(keyId int32) error
+	DeleteApiKey(accountId int32, keyId int32) (bool, error)
+	GetApiKeyByPrefix(prefix string) (*queries.ApiKey, error)
+	UseRequestNonce(keyId int32, nonce string, notBefore time.Time) (bool, error)
+
+	ReserveIdempotencyKey(params ReserveIdempotencyKeyParams) (*queries.IdempotencyKey, error)
+	CompleteIdempotencyKey(params queries.CompleteIdempotencyKeyParams) error
+	ReleaseIdempotencyKey(accountId int32, key string) error
 
 	ExecuteMarketOrder(params CreateMarketOrderParams) (
 		CreateMarketOrderResult,
@@ -32,8 +100,29 @@ type Store interface {
 		error,
 	)
 	GetStandingOrder(orderId int32) (*queries.StandingOrder, error)
+	GetStandingOrderByClientOrderID(accountId int32, clientOrderId string) (*queries.StandingOrder, error)
 	GetStandingOrders(orderIds []int32) ([]queries.StandingOrder, error)
-	DeleteStandingOrder(orderId int32) error
+	ListStandingOrders(params ListStandingOrdersParams) ([]queries.StandingOrder, *StandingOrderCursor, error)
+	CancelStandingOrder(orderId int32) (*queries.StandingOrder, error)
+	ReplaceStandingOrder(orderId int32, params CreateStandingOrderParams) (
+		*queries.StandingOrder,
+		[]int32,
+		error,
+	)
+	GetOrderBook(marketId int32, depth int32) (*OrderBook, error)
+
+	GetOrderTrades(orderId int32) ([]queries.Trade, error)
+	GetLastTrade(marketId int32) (*queries.Trade, error)
+
+	RecordPrice(price currency.USD, quotedAt time.Time) error
+	GetPricesAround(at time.Time) (*queries.PriceHistory, *queries.PriceHistory, error)
+	GetPriceHistory(from time.Time, to time.Time, maxRows int32) ([]queries.PriceHistory, error)
+
+	GetFixSession(senderCompId string, targetCompId string) (*queries.FixSession, error)
+	SaveFixMessage(sessionId int32, seqNum int32, message []byte) error
+	GetFixMessages(sessionId int32, fromSeqNum int32, toSeqNum int32) ([]queries.FixMessage, error)
+	SetFixTargetSeqNum(sessionId int32, seqNum int32) error
+	ResetFixSession(sessionId int32) error
 }
 
 type DbStore struct {
@@ -79,33 +168,48 @@ func (store *DbStore) ExecuteTx(transaction func(context.Context, queries.Querie
 	return tx.Commit()
 }
 
-func (store *DbStore) GetAccountByToken(token string) (*queries.Account, error) {
+// RegisterAccount creates a new account together with its default API key
+// holding all scopes. The key token is returned only here.
+func (store *DbStore) RegisterAccount(username string) (*queries.Account, string, error) {
+	legacyToken, err := uuid.NewRandom()
+	if err != nil {
+		return nil, "", err
+	}
+
 	var account queries.Account
-	var err error
+	var token string
 	err = store.ExecuteTx(
 		func(ctx context.Context, q queries.Querier) error {
-			account, err = q.GetAccountByToken(ctx, token)
+			account, err = q.CreateAccount(
+				ctx,
+				queries.CreateAccountParams{Username: username, Token: legacyToken.String()},
+			)
+			if err != nil {
+				return err
+			}
+
+			_, token, err = createApiKey(
+				ctx,
+				q,
+				CreateApiKeyParams{AccountID: account.ID, Name: "default", Scopes: apikey.AllScopes},
+			)
 			return err
 		},
 	)
 
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil
-	}
-
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return &account, nil
+	return &account, token, nil
 }
 
-func (store *DbStore) GetAccount(accountId int32) (*queries.Account, error) {
+func (store *DbStore) GetAccountByToken(token string) (*queries.Account, error) {
 	var account queries.Account
 	var err error
 	err = store.ExecuteTx(
 		func(ctx context.Context, q queries.Querier) error {
-			account, err = q.GetAccountById(ctx, accountId)
+			account, err = q.GetAccountByToken(ctx, token)
 			return err
 		},
 	)
@@ -121,32 +225,12 @@ func (store *DbStore) GetAccount(accountId int32) (*queries.Account, error) {
 	return &account, nil
 }
 
-func (store *DbStore) DepositAccount(accountId int32, btcAmount currency.BTC, usdAmount currency.USD) (bool, error) {
-	var err error
-	var success bool
-	err = store.ExecuteTx(
-		func(ctx context.Context, q queries.Querier) error {
-			rowCount, err := q.TransferAmounts(
-				ctx,
-				queries.TransferAmountsParams{
-					ID:        accountId,
-					UsdAmount: usdAmount.Internal(),
-					BtcAmount: btcAmount.Internal(),
-				},
-			)
-			success = rowCount == 1
-			return err
-		},
-	)
-	return success, err
-}
-
-func (store *DbStore) GetStandingOrder(orderId int32) (*queries.StandingOrder, error) {
-	var order queries.StandingOrder
+func (store *DbStore) GetAccount(accountId int32) (*queries.Account, error) {
+	var account queries.Account
 	var err error
 	err = store.ExecuteTx(
 		func(ctx context.Context, q queries.Querier) error {
-			order, err = q.GetStandingOrder(ctx, orderId)
+			account, err = q.GetAccountById(ctx, accountId)
 			return err
 		},
 	)
@@ -159,289 +243,1473 @@ func (store *DbStore) GetStandingOrder(orderId int32) (*queries.StandingOrder, e
 		return nil, err
 	}
 
-	return &order, nil
-}
-
-func (store *DbStore) GetStandingOrders(orderIds []int32) ([]queries.StandingOrder, error) {
-	var err error
-	var orders []queries.StandingOrder
-	err = store.ExecuteTx(
-		func(ctx context.Context, q queries.Querier) error {
-			orders, err = q.GetStandingOrders(ctx, orderIds)
-			return err
-		},
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return orders, nil
-}
-
-type CreateMarketOrderParams struct {
-	AccountID int32
-	OrderType queries.OrderType
-	Quantity  currency.BTC
-}
-
-type CreateMarketOrderResult struct {
-	Quantity currency.BTC
-	Price    currency.USD
+	return &account, nil
 }
 
-func (store *DbStore) ExecuteMarketOrder(params CreateMarketOrderParams) (
-	CreateMarketOrderResult,
-	[]int32,
-	error,
-) {
-	var result CreateMarketOrderResult
-	var affectedOrderIds []int32
+// DepositAccount adds the amount of the asset to the balance and journals the
+// deposit. Deposits to frozen accounts fail with ErrAccountFrozen.
+func (store *DbStore) DepositAccount(accountId int32, asset string, amount int64) (bool, error) {
+	var success bool
 	err := store.ExecuteTx(
 		func(ctx context.Context, q queries.Querier) error {
-			standingOrder, err := q.CreateStandingOrder(
-				ctx,
-				queries.CreateStandingOrderParams{
-					AccountID:         params.AccountID,
-					Type:              params.OrderType,
-					State:             queries.OrderStateLive,
-					Quantity:          params.Quantity.Internal(),
-					LimitPrice:        0,
-					ReservedBtcAmount: 0,
-					ReservedUsdAmount: 0,
-				},
-			)
+			account, err := q.GetAccountById(ctx, accountId)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
 			if err != nil {
 				return err
 			}
 
-			for standingOrder.Quantity > 0 {
-				var account queries.Account
-				account, err = q.GetAccountById(ctx, params.AccountID)
-				if err != nil {
-					return err
-				}
-
-				if params.OrderType == queries.OrderTypeBuy {
-					sellOrder, err := q.GetBestMarketSeller(ctx)
-					if errors.Is(err, sql.ErrNoRows) {
-						break
-					}
-
-					affectedOrderIds = append(affectedOrderIds, sellOrder.ID)
-					btcPrice := sellOrder.LimitPrice
-					maxBuyQuantity := currency.NewBTC(float64(account.UsdAmount) / float64(btcPrice)).Internal()
-					quantity := minQuantity(sellOrder.Quantity, maxBuyQuantity, standingOrder.Quantity)
-					if err := processDeal(ctx, q, &sellOrder, &standingOrder, quantity, btcPrice); err != nil {
-						return err
-					}
-				} else {
-					buyOrder, err := q.GetBestMarketBuyer(ctx)
-					if errors.Is(err, sql.ErrNoRows) {
-						break
-					}
-
-					affectedOrderIds = append(affectedOrderIds, buyOrder.ID)
-					btcPrice := buyOrder.LimitPrice
-					quantity := minQuantity(standingOrder.Quantity, buyOrder.Quantity)
-					if err := processDeal(ctx, q, &standingOrder, &buyOrder, quantity, btcPrice); err != nil {
-						return err
-					}
-				}
-			}
-
-			result.Quantity = currency.BTC(standingOrder.FilledQuantity)
-			result.Price = currency.USD(standingOrder.FilledPrice)
-
-			err = q.DeleteStandingOrder(ctx, standingOrder.ID)
-			if err != nil {
-				return err
+			if account.Frozen {
+				return ErrAccountFrozen
 			}
 
-			return nil
+			success, err = transferJournaled(
+				ctx,
+				q,
+				AdjustBalanceParams{
+					AccountID: accountId,
+					Asset:     asset,
+					Amount:    amount,
+					Reason:    depositReason,
+				},
+			)
+			return err
 		},
 	)
-
-	return result, affectedOrderIds, err
+	return success, err
 }
 
-type CreateStandingOrderParams struct {
-	AccountID  int32
-	OrderType  queries.OrderType
-	Quantity   currency.BTC
-	LimitPrice currency.USD
+type AdjustBalanceParams struct {
+	AccountID int32
+	Asset     string
+	Amount    int64
+	Reason    string
+	// Operator is empty for deposits of the account holder.
+	Operator string
 }
 
-func (store *DbStore) CreateStandingOrder(params CreateStandingOrderParams) (
-	*queries.StandingOrder,
-	[]int32,
-	error,
-) {
-	reservedUSD := currency.USD(0)
-	reservedBTC := currency.BTC(0)
-	if params.OrderType == queries.OrderTypeBuy {
-		reservedUSD = params.Quantity.USD(params.LimitPrice.Float64())
-	} else {
-		reservedBTC = params.Quantity
-	}
-
-	var standingOrder queries.StandingOrder
-	var affectedOrderIds []int32
+// AdjustBalance changes the balance on behalf of an operator and journals the
+// change with its reason. Balances cannot drop below the amounts reserved by
+// live orders, false is returned then and for unknown accounts.
+func (store *DbStore) AdjustBalance(params AdjustBalanceParams) (bool, error) {
+	var success bool
 	err := store.ExecuteTx(
 		func(ctx context.Context, q queries.Querier) error {
 			account, err := q.GetAccountById(ctx, params.AccountID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
 			if err != nil {
 				return err
 			}
 
-			reservedAmounts, err := q.GetReservedAmounts(ctx, params.AccountID)
+			balance, err := getBalance(ctx, q, account.ID, params.Asset)
 			if err != nil {
 				return err
 			}
 
-			sufficientAmounts := (reservedAmounts.UsdAmount+reservedUSD.Internal() <= account.UsdAmount) &&
-				(reservedAmounts.BtcAmount+reservedBTC.Internal() <= account.BtcAmount)
-			state := queries.OrderStateLive
-			if !sufficientAmounts {
-				state = queries.OrderStateCancelled
-				reservedBTC = 0
-				reservedUSD = 0
-			}
-			standingOrder, err = q.CreateStandingOrder(
-				ctx,
-				queries.CreateStandingOrderParams{
-					AccountID:         params.AccountID,
-					Type:              params.OrderType,
-					State:             state,
-					Quantity:          params.Quantity.Internal(),
-					LimitPrice:        params.LimitPrice.Internal(),
-					ReservedBtcAmount: reservedBTC.Internal(),
-					ReservedUsdAmount: reservedUSD.Internal(),
-				},
+			reservedAmount, err := q.GetReservedAmount(
+				ctx, queries.GetReservedAmountParams{Asset: params.Asset, AccountID: account.ID},
 			)
-
 			if err != nil {
 				return err
 			}
 
-			affectedOrderIds = append(affectedOrderIds, standingOrder.ID)
-
-			if state != queries.OrderStateLive {
+			if balance+params.Amount < reservedAmount {
 				return nil
 			}
 
-			for standingOrder.State != queries.OrderStateFulfilled {
-				if params.OrderType == queries.OrderTypeBuy {
-					sellOrder, err := q.GetBestSeller(ctx, params.LimitPrice.Internal())
-					if errors.Is(err, sql.ErrNoRows) {
-						return nil
-					}
-
-					affectedOrderIds = append(affectedOrderIds, sellOrder.ID)
-					quantity := minQuantity(standingOrder.Quantity, sellOrder.Quantity)
-					btcPrice := sellOrder.LimitPrice
-					if err := processDeal(ctx, q, &sellOrder, &standingOrder, quantity, btcPrice); err != nil {
-						return err
-					}
-				} else {
-					buyOrder, err := q.GetBestBuyer(ctx, params.LimitPrice.Internal())
-					if errors.Is(err, sql.ErrNoRows) {
-						return nil
-					}
-
-					affectedOrderIds = append(affectedOrderIds, buyOrder.ID)
-					quantity := minQuantity(standingOrder.Quantity, buyOrder.Quantity)
-					btcPrice := buyOrder.LimitPrice
-					if err := processDeal(ctx, q, &standingOrder, &buyOrder, quantity, btcPrice); err != nil {
-						return err
-					}
-				}
-			}
-
-			return nil
+			success, err = transferJournaled(ctx, q, params)
+			return err
 		},
 	)
-
-	return &standingOrder, affectedOrderIds, err
-}
-
-type dealProcessingResult struct {
-	USDAmount currency.USD
+	return success, err
 }
 
-func processDeal(
-	ctx context.Context,
-	q queries.Querier,
-	sellOrder *queries.StandingOrder,
-	buyOrder *queries.StandingOrder,
-	quantity int64,
-	btcPrice int64,
-) error {
-	var result dealProcessingResult
-	dealPrice := currency.BTC(quantity).USD(currency.USD(btcPrice).Float64()).Internal()
-	updatedRows, err := q.TransferAmounts(
-		ctx,
-		queries.TransferAmountsParams{ID: sellOrder.AccountID, UsdAmount: dealPrice, BtcAmount: -quantity},
-	)
+// transferJournaled changes the balance unless it would become negative and
+// records the change in the balance journal. Unregistered assets fail with
+// ErrUnknownAsset.
+func transferJournaled(ctx context.Context, q queries.Querier, params AdjustBalanceParams) (bool, error) {
+	_, err := q.GetAsset(ctx, params.Asset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrUnknownAsset
+	}
 	if err != nil {
-		return err
+		return false, err
 	}
-	if updatedRows != 1 {
-		return fmt.Errorf("invalid transfer from seller")
+
+	success, err := transferBalance(ctx, q, params.AccountID, params.Asset, params.Amount)
+	if err != nil || !success {
+		return false, err
 	}
 
-	updatedRows, err = q.TransferAmounts(
+	_, err = q.CreateBalanceJournalEntry(
 		ctx,
-		queries.TransferAmountsParams{ID: buyOrder.AccountID, UsdAmount: -dealPrice, BtcAmount: quantity},
+		queries.CreateBalanceJournalEntryParams{
+			AccountID: params.AccountID,
+			Asset:     params.Asset,
+			Amount:    params.Amount,
+			Reason:    params.Reason,
+			Operator:  params.Operator,
+		},
 	)
+	return err == nil, err
+}
+
+// transferBalance changes the balance of the asset unless it would become
+// negative.
+func transferBalance(ctx context.Context, q queries.Querier, accountId int32, asset string, amount int64) (
+	bool,
+	error,
+) {
+	err := q.CreateBalance(ctx, queries.CreateBalanceParams{AccountID: accountId, Asset: asset})
 	if err != nil {
-		return err
+		return false, err
 	}
-	if updatedRows != 1 {
-		return fmt.Errorf("invalid transfer to buy")
+
+	rowCount, err := q.TransferBalance(
+		ctx, queries.TransferBalanceParams{AccountID: accountId, Asset: asset, Amount: amount},
+	)
+	return rowCount == 1, err
+}
+
+// getBalance returns the balance of the asset, zero when the account never
+// held it.
+func getBalance(ctx context.Context, q queries.Querier, accountId int32, asset string) (int64, error) {
+	amount, err := q.GetBalance(ctx, queries.GetBalanceParams{AccountID: accountId, Asset: asset})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
 	}
+	return amount, err
+}
 
-	reservedBtcChange := quantity
-	*sellOrder, err = q.SatisfyOrder(
-		ctx,
-		queries.SatisfyOrderParams{
-			ID:                sellOrder.ID,
-			Quantity:          quantity,
-			FilledPrice:       dealPrice,
-			ReservedBtcAmount: reservedBtcChange,
+// GetBalances returns the balances of all assets the account held.
+func (store *DbStore) GetBalances(accountId int32) ([]queries.GetBalancesRow, error) {
+	var balances []queries.GetBalancesRow
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			balances, err = q.GetBalances(ctx, accountId)
+			return err
 		},
 	)
-	if err != nil {
-		return err
-	}
+	return balances, err
+}
 
-	reservedUsdChange := currency.BTC(quantity).USD(currency.USD(buyOrder.LimitPrice).Float64()).Internal()
-	*buyOrder, err = q.SatisfyOrder(
-		ctx,
-		queries.SatisfyOrderParams{
-			ID:                buyOrder.ID,
-			Quantity:          quantity,
-			FilledPrice:       dealPrice,
-			ReservedUsdAmount: reservedUsdChange,
+// GetBalanceJournal returns the journal of the account, oldest first.
+func (store *DbStore) GetBalanceJournal(accountId int32) ([]queries.BalanceJournal, error) {
+	var entries []queries.BalanceJournal
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			entries, err = q.GetBalanceJournal(ctx, accountId)
+			return err
 		},
 	)
-	if err != nil {
-		return err
-	}
+	return entries, err
+}
 
-	result.USDAmount = currency.USD(dealPrice)
-	return nil
+// SetAccountFrozen freezes or unfreezes the account. It returns false for
+// unknown accounts.
+func (store *DbStore) SetAccountFrozen(accountId int32, frozen bool) (bool, error) {
+	var success bool
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			rowCount, err := q.SetAccountFrozen(ctx, queries.SetAccountFrozenParams{ID: accountId, Frozen: frozen})
+			success = rowCount == 1
+			return err
+		},
+	)
+	return success, err
 }
 
-func (store *DbStore) DeleteStandingOrder(orderId int32) error {
-	return store.ExecuteTx(
+// GetReconciliation returns the balances, the reserved amounts and the
+// journaled amounts of every account and asset.
+func (store *DbStore) GetReconciliation() ([]queries.GetReconciliationRow, error) {
+	var rows []queries.GetReconciliationRow
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			rows, err = q.GetReconciliation(ctx)
+			return err
+		},
+	)
+	return rows, err
+}
+
+func (store *DbStore) GetAssets() ([]queries.Asset, error) {
+	var assets []queries.Asset
+	err := store.ExecuteTx(
 		func(ctx context.Context, q queries.Querier) error {
-			return q.DeleteStandingOrder(ctx, orderId)
+			var err error
+			assets, err = q.GetAssets(ctx)
+			return err
+		},
+	)
+	return assets, err
+}
+
+// GetAsset returns the registered asset, or nil when there is none with the
+// symbol.
+func (store *DbStore) GetAsset(symbol string) (*queries.Asset, error) {
+	var asset queries.Asset
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			asset, err = q.GetAsset(ctx, symbol)
+			return err
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+// CreateAsset registers an asset with amounts of the precision.
+func (store *DbStore) CreateAsset(symbol string, precision int32) (*queries.Asset, error) {
+	var asset queries.Asset
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			asset, err = q.CreateAsset(ctx, queries.CreateAssetParams{Symbol: symbol, Precision: precision})
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// Market trades its base asset for the quote asset. Order quantities are
+// amounts of the base asset, prices amounts of the quote asset per one base
+// asset.
+type Market struct {
+	ID     int32
+	Symbol string
+	Base   currency.Asset
+	Quote  currency.Asset
+}
+
+func newMarket(row queries.GetMarketsRow) Market {
+	return Market{
+		ID:     row.ID,
+		Symbol: row.Symbol,
+		Base:   currency.Asset{Symbol: row.BaseAsset, Precision: int(row.BasePrecision)},
+		Quote:  currency.Asset{Symbol: row.QuoteAsset, Precision: int(row.QuotePrecision)},
+	}
+}
+
+// QuoteAmount is the amount of the quote asset paid for the quantity at the
+// price.
+func (market *Market) QuoteAmount(quantity currency.Amount, price currency.Amount, mode currency.RoundingMode) (
+	currency.Amount,
+	error,
+) {
+	amount, err := quantity.Of(market.Base).Mul(price.Of(market.Quote), mode)
+	return currency.Amount(amount.Amount), err
+}
+
+// BaseQuantity is the quantity bought for the amount of the quote asset at
+// the price.
+func (market *Market) BaseQuantity(quoteAmount currency.Amount, price currency.Amount, mode currency.RoundingMode) (
+	currency.Amount,
+	error,
+) {
+	quantity, err := quoteAmount.Of(market.Quote).Div(price.Of(market.Quote), market.Base, mode)
+	return currency.Amount(quantity.Amount), err
+}
+
+func (store *DbStore) GetMarkets() ([]Market, error) {
+	var rows []queries.GetMarketsRow
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			rows, err = q.GetMarkets(ctx)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, len(rows))
+	for i := range rows {
+		markets[i] = newMarket(rows[i])
+	}
+	return markets, nil
+}
+
+// GetMarket returns the market, or nil when there is none with the id.
+func (store *DbStore) GetMarket(marketId int32) (*Market, error) {
+	var market Market
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			market, err = getMarket(ctx, q, marketId)
+			return err
+		},
+	)
+
+	if errors.Is(err, ErrUnknownMarket) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &market, nil
+}
+
+// GetMarketBySymbol returns the market, or nil when there is none with the
+// symbol.
+func (store *DbStore) GetMarketBySymbol(symbol string) (*Market, error) {
+	markets, err := store.GetMarkets()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range markets {
+		if markets[i].Symbol == symbol {
+			return &markets[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateMarket opens a market trading the registered base asset for the quote
+// asset. Its symbol joins the asset symbols with a dash.
+func (store *DbStore) CreateMarket(baseAsset string, quoteAsset string) (*Market, error) {
+	var market Market
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			created, err := q.CreateMarket(
+				ctx,
+				queries.CreateMarketParams{
+					Symbol:     baseAsset + "-" + quoteAsset,
+					BaseAsset:  baseAsset,
+					QuoteAsset: quoteAsset,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			market, err = getMarket(ctx, q, created.ID)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &market, nil
+}
+
+// getMarket loads the market with the precisions of its assets, unknown
+// markets fail with ErrUnknownMarket.
+func getMarket(ctx context.Context, q queries.Querier, marketId int32) (Market, error) {
+	row, err := q.GetMarket(ctx, marketId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Market{}, ErrUnknownMarket
+	}
+	if err != nil {
+		return Market{}, err
+	}
+	return newMarket(queries.GetMarketsRow(row)), nil
+}
+
+func (store *DbStore) GetTradingStatus() (*queries.TradingStatus, error) {
+	var status queries.TradingStatus
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			status, err = q.GetTradingStatus(ctx)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetTradingStatus halts or resumes trading. Orders placed while trading is
+// halted fail with ErrTradingHalted, live orders stay in the book.
+func (store *DbStore) SetTradingStatus(halted bool, reason string) (*queries.TradingStatus, error) {
+	var status queries.TradingStatus
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			status, err = q.SetTradingStatus(ctx, queries.SetTradingStatusParams{Halted: halted, Reason: reason})
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// checkTradingAllowed fails when the account is frozen or trading is halted.
+func checkTradingAllowed(ctx context.Context, q queries.Querier, account queries.Account) error {
+	if account.Frozen {
+		return ErrAccountFrozen
+	}
+
+	status, err := q.GetTradingStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Halted {
+		return ErrTradingHalted
+	}
+	return nil
+}
+
+type CreateApiKeyParams struct {
+	AccountID  int32
+	Name       string
+	Scopes     []apikey.Scope
+	AllowedIPs []string
+	ExpiresAt  time.Time
+}
+
+func (store *DbStore) CreateApiKey(params CreateApiKeyParams) (*queries.ApiKey, string, error) {
+	var key queries.ApiKey
+	var token string
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			key, token, err = createApiKey(ctx, q, params)
+			return err
+		},
+	)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &key, token, nil
+}
+
+func createApiKey(ctx context.Context, q queries.Querier, params CreateApiKeyParams) (queries.ApiKey, string, error) {
+	prefix, secret, token, err := apikey.Generate()
+	if err != nil {
+		return queries.ApiKey{}, "", err
+	}
+
+	allowedIPs := params.AllowedIPs
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+
+	key, err := q.CreateApiKey(
+		ctx,
+		queries.CreateApiKeyParams{
+			AccountID:  params.AccountID,
+			Name:       params.Name,
+			Prefix:     prefix,
+			SecretHash: apikey.Hash(secret),
+			Scopes:     apikey.ScopesToStrings(params.Scopes),
+			AllowedIps: allowedIPs,
+			ExpiresAt:  sql.NullTime{Time: params.ExpiresAt, Valid: !params.ExpiresAt.IsZero()},
+		},
+	)
+	return key, token, err
+}
+
+// GetApiKeyByToken returns the key matching the token, or nil when the token
+// is malformed, unknown or its secret does not match.
+func (store *DbStore) GetApiKeyByToken(token string) (*queries.ApiKey, error) {
+	prefix, secret, ok := apikey.Parse(token)
+	if !ok {
+		return nil, nil
+	}
+
+	var key queries.ApiKey
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			key, err = q.GetApiKeyByPrefix(ctx, prefix)
+			return err
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !apikey.VerifySecret(secret, key.SecretHash) {
+		return nil, nil
+	}
+
+	return &key, nil
+}
+
+func (store *DbStore) GetApiKeyByPrefix(prefix string) (*queries.ApiKey, error) {
+	var key queries.ApiKey
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			key, err = q.GetApiKeyByPrefix(ctx, prefix)
+			return err
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// UseRequestNonce records the nonce for the key and reports false when it was
+// already used. Nonces recorded before notBefore are discarded, requests that
+// old are rejected by their timestamp anyway.
+func (store *DbStore) UseRequestNonce(keyId int32, nonce string, notBefore time.Time) (bool, error) {
+	var fresh bool
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			err := q.DeleteExpiredNonces(ctx, queries.DeleteExpiredNoncesParams{ApiKeyID: keyId, CreatedAt: notBefore})
+			if err != nil {
+				return err
+			}
+
+			rowCount, err := q.UseNonce(ctx, queries.UseNonceParams{ApiKeyID: keyId, Nonce: nonce})
+			fresh = rowCount == 1
+			return err
+		},
+	)
+	return fresh, err
+}
+
+func (store *DbStore) GetApiKeys(accountId int32) ([]queries.ApiKey, error) {
+	var keys []queries.ApiKey
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			keys, err = q.GetApiKeys(ctx, accountId)
+			return err
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (store *DbStore) TouchApiKey(keyId int32) error {
+	return store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			return q.TouchApiKey(ctx, keyId)
+		},
+	)
+}
+
+func (store *DbStore) DeleteApiKey(accountId int32, keyId int32) (bool, error) {
+	var deleted bool
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			rowCount, err := q.DeleteApiKey(ctx, queries.DeleteApiKeyParams{ID: keyId, AccountID: accountId})
+			deleted = rowCount == 1
+			return err
+		},
+	)
+	return deleted, err
+}
+
+type ReserveIdempotencyKeyParams struct {
+	AccountID   int32
+	Key         string
+	Endpoint    string
+	RequestHash []byte
+	// Keys created before NotBefore are discarded and can be used again.
+	NotBefore time.Time
+}
+
+// ReserveIdempotencyKey marks the key as used by a request in progress. It
+// returns nil when the key was reserved, otherwise the existing record.
+func (store *DbStore) ReserveIdempotencyKey(params ReserveIdempotencyKeyParams) (*queries.IdempotencyKey, error) {
+	var existing *queries.IdempotencyKey
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			existing = nil
+			err := q.DeleteExpiredIdempotencyKeys(
+				ctx,
+				queries.DeleteExpiredIdempotencyKeysParams{AccountID: params.AccountID, CreatedAt: params.NotBefore},
+			)
+			if err != nil {
+				return err
+			}
+
+			rowCount, err := q.CreateIdempotencyKey(
+				ctx,
+				queries.CreateIdempotencyKeyParams{
+					AccountID:   params.AccountID,
+					Key:         params.Key,
+					Endpoint:    params.Endpoint,
+					RequestHash: params.RequestHash,
+				},
+			)
+			if err != nil || rowCount == 1 {
+				return err
+			}
+
+			key, err := q.GetIdempotencyKey(
+				ctx, queries.GetIdempotencyKeyParams{AccountID: params.AccountID, Key: params.Key},
+			)
+			existing = &key
+			return err
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request holding the key.
+func (store *DbStore) CompleteIdempotencyKey(params queries.CompleteIdempotencyKeyParams) error {
+	return store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			return q.CompleteIdempotencyKey(ctx, params)
+		},
+	)
+}
+
+// ReleaseIdempotencyKey deletes a reservation so that the request can be retried.
+func (store *DbStore) ReleaseIdempotencyKey(accountId int32, key string) error {
+	return store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			return q.DeleteIdempotencyKey(ctx, queries.DeleteIdempotencyKeyParams{AccountID: accountId, Key: key})
+		},
+	)
+}
+
+func (store *DbStore) GetStandingOrder(orderId int32) (*queries.StandingOrder, error) {
+	var order queries.StandingOrder
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			order, err = q.GetStandingOrder(ctx, orderId)
+			return err
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (store *DbStore) GetStandingOrderByClientOrderID(accountId int32, clientOrderId string) (
+	*queries.StandingOrder,
+	error,
+) {
+	var order queries.StandingOrder
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			order, err = q.GetStandingOrderByClientOrderId(
+				ctx,
+				queries.GetStandingOrderByClientOrderIdParams{
+					AccountID:     accountId,
+					ClientOrderID: sql.NullString{String: clientOrderId, Valid: true},
+				},
+			)
+			return err
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (store *DbStore) GetStandingOrders(orderIds []int32) ([]queries.StandingOrder, error) {
+	var err error
+	var orders []queries.StandingOrder
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			orders, err = q.GetStandingOrders(ctx, orderIds)
+			return err
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// StandingOrderCursor points to the last order of a listed page.
+type StandingOrderCursor struct {
+	CreatedAt time.Time
+	ID        int32
+}
+
+type ListStandingOrdersParams struct {
+	AccountID int32
+	// MarketID filters the orders when not zero.
+	MarketID int32
+	// States and Types filter the orders when not empty.
+	States []queries.OrderState
+	Types  []queries.OrderType
+	// CreatedFrom is inclusive, CreatedTo exclusive, zero values are unbounded.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// After continues the listing behind the cursor of the previous page.
+	After *StandingOrderCursor
+	Limit int32
+}
+
+var maxListTime = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ListStandingOrders returns the orders of an account, newest first. The
+// returned cursor is nil on the last page.
+func (store *DbStore) ListStandingOrders(params ListStandingOrdersParams) (
+	[]queries.StandingOrder,
+	*StandingOrderCursor,
+	error,
+) {
+	queryParams := queries.ListStandingOrdersParams{
+		AccountID:       params.AccountID,
+		MarketID:        params.MarketID,
+		States:          make([]string, len(params.States)),
+		Types:           make([]string, len(params.Types)),
+		CreatedFrom:     params.CreatedFrom,
+		CreatedTo:       params.CreatedTo,
+		CursorCreatedAt: maxListTime,
+		CursorID:        math.MaxInt32,
+		// one more row tells whether there is a next page
+		MaxRows: params.Limit + 1,
+	}
+	for i := range params.States {
+		queryParams.States[i] = string(params.States[i])
+	}
+	for i := range params.Types {
+		queryParams.Types[i] = string(params.Types[i])
+	}
+	if queryParams.CreatedTo.IsZero() {
+		queryParams.CreatedTo = maxListTime
+	}
+	if params.After != nil {
+		queryParams.CursorCreatedAt = params.After.CreatedAt
+		queryParams.CursorID = params.After.ID
+	}
+
+	var orders []queries.StandingOrder
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			orders, err = q.ListStandingOrders(ctx, queryParams)
+			return err
+		},
+	)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(orders) <= int(params.Limit) {
+		return orders, nil, nil
+	}
+
+	orders = orders[:params.Limit]
+	last := orders[len(orders)-1]
+	return orders, &StandingOrderCursor{CreatedAt: last.CreatedAt, ID: last.ID}, nil
+}
+
+// OrderBook aggregates the live orders by their limit price, the best prices
+// come first.
+type OrderBook struct {
+	Bids []queries.GetOrderBookLevelsRow
+	Asks []queries.GetOrderBookLevelsRow
+}
+
+// GetOrderBook returns at most depth price levels of each side of the market.
+func (store *DbStore) GetOrderBook(marketId int32, depth int32) (*OrderBook, error) {
+	var book OrderBook
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			book.Bids, err = q.GetOrderBookLevels(
+				ctx,
+				queries.GetOrderBookLevelsParams{MarketID: marketId, Type: queries.OrderTypeBuy, MaxRows: depth},
+			)
+			if err != nil {
+				return err
+			}
+			book.Asks, err = q.GetOrderBookLevels(
+				ctx,
+				queries.GetOrderBookLevelsParams{MarketID: marketId, Type: queries.OrderTypeSell, MaxRows: depth},
+			)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+type CreateMarketOrderParams struct {
+	AccountID int32
+	MarketID  int32
+	OrderType queries.OrderType
+	// Quantity is an amount of the base asset of the market.
+	Quantity currency.Amount
+}
+
+type CreateMarketOrderResult struct {
+	Quantity currency.Amount
+	// Price is the total amount of the quote asset paid.
+	Price currency.Amount
+}
+
+func (store *DbStore) ExecuteMarketOrder(params CreateMarketOrderParams) (
+	CreateMarketOrderResult,
+	[]int32,
+	error,
+) {
+	var result CreateMarketOrderResult
+	var affectedOrderIds []int32
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			account, err := q.GetAccountById(ctx, params.AccountID)
+			if err != nil {
+				return err
+			}
+
+			if err := checkTradingAllowed(ctx, q, account); err != nil {
+				return err
+			}
+
+			market, err := getMarket(ctx, q, params.MarketID)
+			if err != nil {
+				return err
+			}
+
+			standingOrder, err := q.CreateStandingOrder(
+				ctx,
+				queries.CreateStandingOrderParams{
+					AccountID:           params.AccountID,
+					MarketID:            market.ID,
+					Type:                params.OrderType,
+					State:               queries.OrderStateLive,
+					Quantity:            params.Quantity,
+					LimitPrice:          0,
+					ReservedBaseAmount:  0,
+					ReservedQuoteAmount: 0,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			for standingOrder.Quantity > 0 {
+				if params.OrderType == queries.OrderTypeBuy {
+					sellOrder, err := q.GetBestMarketSeller(ctx, market.ID)
+					if errors.Is(err, sql.ErrNoRows) {
+						break
+					}
+
+					quoteBalance, err := getBalance(ctx, q, params.AccountID, market.Quote.Symbol)
+					if err != nil {
+						return err
+					}
+
+					affectedOrderIds = append(affectedOrderIds, sellOrder.ID)
+					price := sellOrder.LimitPrice
+					// the deal amount of the quantity rounds to at most the balance
+					maxBuyQuantity, err := market.BaseQuantity(currency.Amount(quoteBalance), price, currency.RoundFloor)
+					if err != nil {
+						return err
+					}
+					quantity := minQuantity(sellOrder.Quantity, maxBuyQuantity, standingOrder.Quantity)
+					if quantity == 0 {
+						break
+					}
+					err = processDeal(ctx, q, &market, &sellOrder, &standingOrder, params.OrderType, quantity, price)
+					if err != nil {
+						return err
+					}
+				} else {
+					buyOrder, err := q.GetBestMarketBuyer(ctx, market.ID)
+					if errors.Is(err, sql.ErrNoRows) {
+						break
+					}
+
+					affectedOrderIds = append(affectedOrderIds, buyOrder.ID)
+					price := buyOrder.LimitPrice
+					quantity := minQuantity(standingOrder.Quantity, buyOrder.Quantity)
+					err = processDeal(ctx, q, &market, &standingOrder, &buyOrder, params.OrderType, quantity, price)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			result.Quantity = standingOrder.FilledQuantity
+			result.Price = standingOrder.FilledPrice
+
+			err = q.DeleteStandingOrder(ctx, standingOrder.ID)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		},
+	)
+
+	return result, affectedOrderIds, err
+}
+
+type CreateStandingOrderParams struct {
+	AccountID int32
+	MarketID  int32
+	OrderType queries.OrderType
+	// Quantity is an amount of the base asset, LimitPrice an amount of the
+	// quote asset per one base asset.
+	Quantity   currency.Amount
+	LimitPrice currency.Amount
+	WebhookUrl string
+	// ClientOrderID is optional and unique per account.
+	ClientOrderID string
+}
+
+// CreateStandingOrder places the order in its market and matches it against
+// the book. Orders not covered by the balances are stored cancelled, unknown
+// markets fail with ErrUnknownMarket.
+func (store *DbStore) CreateStandingOrder(params CreateStandingOrderParams) (
+	*queries.StandingOrder,
+	[]int32,
+	error,
+) {
+	var standingOrder queries.StandingOrder
+	var affectedOrderIds []int32
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			standingOrder, affectedOrderIds, err = createStandingOrder(ctx, q, params)
+			return err
+		},
+	)
+
+	return &standingOrder, affectedOrderIds, err
+}
+
+// ReplaceStandingOrder cancels the live order and places the replacement in
+// one transaction. Nothing changes when the order is not live, which fails
+// with ErrOrderNotLive, or when the replacement is not covered by the
+// balances, which fails with ErrInsufficientFunds.
+func (store *DbStore) ReplaceStandingOrder(orderId int32, params CreateStandingOrderParams) (
+	*queries.StandingOrder,
+	[]int32,
+	error,
+) {
+	var standingOrder queries.StandingOrder
+	var affectedOrderIds []int32
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			_, err := q.CancelStandingOrder(ctx, orderId)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrOrderNotLive
+			}
+			if err != nil {
+				return err
+			}
+
+			standingOrder, affectedOrderIds, err = createStandingOrder(ctx, q, params)
+			if err != nil {
+				return err
+			}
+			if standingOrder.State == queries.OrderStateCancelled {
+				return ErrInsufficientFunds
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &standingOrder, affectedOrderIds, nil
+}
+
+// createStandingOrder stores the order and matches it in the transaction.
+func createStandingOrder(ctx context.Context, q queries.Querier, params CreateStandingOrderParams) (
+	queries.StandingOrder,
+	[]int32,
+	error,
+) {
+	var standingOrder queries.StandingOrder
+	var affectedOrderIds []int32
+	account, err := q.GetAccountById(ctx, params.AccountID)
+	if err != nil {
+		return standingOrder, affectedOrderIds, err
+	}
+
+	if err := checkTradingAllowed(ctx, q, account); err != nil {
+		return standingOrder, affectedOrderIds, err
+	}
+
+	market, err := getMarket(ctx, q, params.MarketID)
+	if err != nil {
+		return standingOrder, affectedOrderIds, err
+	}
+
+	if params.ClientOrderID != "" {
+		_, err = q.GetStandingOrderByClientOrderId(
+			ctx,
+			queries.GetStandingOrderByClientOrderIdParams{
+				AccountID:     params.AccountID,
+				ClientOrderID: sql.NullString{String: params.ClientOrderID, Valid: true},
+			},
+		)
+		if err == nil {
+			return standingOrder, affectedOrderIds, ErrDuplicateClientOrderID
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return standingOrder, affectedOrderIds, err
+		}
+	}
+
+	var reservedQuote, reservedBase currency.Amount
+	reservedAsset := market.Base.Symbol
+	if params.OrderType == queries.OrderTypeBuy {
+		reservedQuote, err = market.QuoteAmount(params.Quantity, params.LimitPrice, currency.RoundCeil)
+		if err != nil {
+			return standingOrder, affectedOrderIds, err
+		}
+		reservedAsset = market.Quote.Symbol
+	} else {
+		reservedBase = params.Quantity
+	}
+
+	balance, err := getBalance(ctx, q, params.AccountID, reservedAsset)
+	if err != nil {
+		return standingOrder, affectedOrderIds, err
+	}
+
+	reservedAmount, err := q.GetReservedAmount(
+		ctx, queries.GetReservedAmountParams{Asset: reservedAsset, AccountID: params.AccountID},
+	)
+	if err != nil {
+		return standingOrder, affectedOrderIds, err
+	}
+
+	state := queries.OrderStateLive
+	if reservedAmount+int64(reservedQuote+reservedBase) > balance {
+		state = queries.OrderStateCancelled
+		reservedQuote = 0
+		reservedBase = 0
+	}
+	standingOrder, err = q.CreateStandingOrder(
+		ctx,
+		queries.CreateStandingOrderParams{
+			AccountID:           params.AccountID,
+			MarketID:            market.ID,
+			Type:                params.OrderType,
+			State:               state,
+			Quantity:            params.Quantity,
+			LimitPrice:          params.LimitPrice,
+			ReservedBaseAmount:  reservedBase,
+			ReservedQuoteAmount: reservedQuote,
+			WebhookUrl:          sql.NullString{String: params.WebhookUrl, Valid: params.WebhookUrl != ""},
+			ClientOrderID:       sql.NullString{String: params.ClientOrderID, Valid: params.ClientOrderID != ""},
+		},
+	)
+
+	// concurrent inserts with the same client order id are caught by the index
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation &&
+		pqErr.Constraint == "standing_order_client_order_id_idx" {
+		return standingOrder, affectedOrderIds, ErrDuplicateClientOrderID
+	}
+	if err != nil {
+		return standingOrder, affectedOrderIds, err
+	}
+
+	affectedOrderIds = append(affectedOrderIds, standingOrder.ID)
+
+	if state != queries.OrderStateLive {
+		return standingOrder, affectedOrderIds, nil
+	}
+
+	for standingOrder.State != queries.OrderStateFulfilled {
+		if params.OrderType == queries.OrderTypeBuy {
+			sellOrder, err := q.GetBestSeller(
+				ctx, queries.GetBestSellerParams{MarketID: market.ID, LimitPrice: params.LimitPrice},
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return standingOrder, affectedOrderIds, nil
+			}
+
+			affectedOrderIds = append(affectedOrderIds, sellOrder.ID)
+			quantity := minQuantity(standingOrder.Quantity, sellOrder.Quantity)
+			price := sellOrder.LimitPrice
+			err = processDeal(ctx, q, &market, &sellOrder, &standingOrder, params.OrderType, quantity, price)
+			if err != nil {
+				return standingOrder, affectedOrderIds, err
+			}
+		} else {
+			buyOrder, err := q.GetBestBuyer(
+				ctx, queries.GetBestBuyerParams{MarketID: market.ID, LimitPrice: params.LimitPrice},
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return standingOrder, affectedOrderIds, nil
+			}
+
+			affectedOrderIds = append(affectedOrderIds, buyOrder.ID)
+			quantity := minQuantity(standingOrder.Quantity, buyOrder.Quantity)
+			price := buyOrder.LimitPrice
+			err = processDeal(ctx, q, &market, &standingOrder, &buyOrder, params.OrderType, quantity, price)
+			if err != nil {
+				return standingOrder, affectedOrderIds, err
+			}
+		}
+	}
+
+	return standingOrder, affectedOrderIds, nil
+}
+
+// processDeal moves the quantity of the base asset from the seller to the
+// buyer and its price in the quote asset back.
+func processDeal(
+	ctx context.Context,
+	q queries.Querier,
+	market *Market,
+	sellOrder *queries.StandingOrder,
+	buyOrder *queries.StandingOrder,
+	takerSide queries.OrderType,
+	quantity currency.Amount,
+	price currency.Amount,
+) error {
+	dealAmount, err := market.QuoteAmount(quantity, price, currency.RoundHalfEven)
+	if err != nil {
+		return err
+	}
+
+	// the reservation was rounded up for the whole quantity, partial fills
+	// release it rounded up too and the last fill releases the rest
+	releasedQuote, err := market.QuoteAmount(quantity, buyOrder.LimitPrice, currency.RoundCeil)
+	if err != nil {
+		return err
+	}
+	if releasedQuote > buyOrder.ReservedQuoteAmount || quantity == buyOrder.Quantity {
+		releasedQuote = buyOrder.ReservedQuoteAmount
+	}
+
+	for _, transfer := range []struct {
+		accountId int32
+		asset     string
+		amount    int64
+	}{
+		{sellOrder.AccountID, market.Base.Symbol, -int64(quantity)},
+		{sellOrder.AccountID, market.Quote.Symbol, int64(dealAmount)},
+		{buyOrder.AccountID, market.Quote.Symbol, -int64(dealAmount)},
+		{buyOrder.AccountID, market.Base.Symbol, int64(quantity)},
+	} {
+		success, err := transferBalance(ctx, q, transfer.accountId, transfer.asset, transfer.amount)
+		if err != nil {
+			return err
+		}
+		if !success {
+			return fmt.Errorf("invalid transfer of %s for account %d", transfer.asset, transfer.accountId)
+		}
+	}
+
+	*sellOrder, err = q.SatisfyOrder(
+		ctx,
+		queries.SatisfyOrderParams{
+			ID:                 sellOrder.ID,
+			Quantity:           quantity,
+			FilledPrice:        dealAmount,
+			ReservedBaseAmount: quantity,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	*buyOrder, err = q.SatisfyOrder(
+		ctx,
+		queries.SatisfyOrderParams{
+			ID:                  buyOrder.ID,
+			Quantity:            quantity,
+			FilledPrice:         dealAmount,
+			ReservedQuoteAmount: releasedQuote,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateTrade(
+		ctx,
+		queries.CreateTradeParams{
+			MarketID:    market.ID,
+			BuyOrderID:  buyOrder.ID,
+			SellOrderID: sellOrder.ID,
+			TakerSide:   takerSide,
+			Quantity:    quantity,
+			Price:       price,
+		},
+	)
+	return err
+}
+
+// CancelStandingOrder marks a live order cancelled and releases its
+// reservations. It returns nil when the order is not live.
+func (store *DbStore) CancelStandingOrder(orderId int32) (*queries.StandingOrder, error) {
+	var order queries.StandingOrder
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			order, err = q.CancelStandingOrder(ctx, orderId)
+			return err
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// GetOrderTrades returns the trades of the order, oldest first.
+func (store *DbStore) GetOrderTrades(orderId int32) ([]queries.Trade, error) {
+	var trades []queries.Trade
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			trades, err = q.GetOrderTrades(ctx, orderId)
+			return err
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+// GetLastTrade returns the latest trade of the market, or nil when there was
+// no trade yet.
+func (store *DbStore) GetLastTrade(marketId int32) (*queries.Trade, error) {
+	var trade queries.Trade
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			trade, err = q.GetLastTrade(ctx, marketId)
+			return err
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &trade, nil
+}
+
+// RecordPrice adds the BTC price quoted at the time to the price history.
+func (store *DbStore) RecordPrice(price currency.USD, quotedAt time.Time) error {
+	return store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			_, err := q.CreatePriceHistoryEntry(
+				ctx, queries.CreatePriceHistoryEntryParams{Price: price, QuotedAt: quotedAt},
+			)
+			return err
+		},
+	)
+}
+
+// GetPricesAround returns the last recorded price quoted at or before the
+// time and the first one quoted after it, nil when there is none.
+func (store *DbStore) GetPricesAround(at time.Time) (*queries.PriceHistory, *queries.PriceHistory, error) {
+	var before, after *queries.PriceHistory
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			price, err := q.GetPriceAtOrBefore(ctx, at)
+			if err == nil {
+				before = &price
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			price, err = q.GetPriceAfter(ctx, at)
+			if err == nil {
+				after = &price
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// GetPriceHistory returns at most maxRows prices quoted from the time up to
+// but excluding the other, the oldest first.
+func (store *DbStore) GetPriceHistory(from time.Time, to time.Time, maxRows int32) ([]queries.PriceHistory, error) {
+	var prices []queries.PriceHistory
+	err := store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			var err error
+			prices, err = q.GetPriceHistory(
+				ctx, queries.GetPriceHistoryParams{QuotedFrom: from, QuotedTo: to, MaxRows: maxRows},
+			)
+			return err
+		},
+	)
+	return prices, err
+}
+
+// GetFixSession returns the sequence numbers of the FIX session, new sessions
+// start at one.
+func (store *DbStore) GetFixSession(senderCompId string, targetCompId string) (*queries.FixSession, error) {
+	var session queries.FixSession
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			session, err = q.GetOrCreateFixSession(
+				ctx,
+				queries.GetOrCreateFixSessionParams{SenderCompID: senderCompId, TargetCompID: targetCompId},
+			)
+			return err
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// SaveFixMessage keeps the sent message for resend requests and advances the
+// next sender sequence number past it.
+func (store *DbStore) SaveFixMessage(sessionId int32, seqNum int32, message []byte) error {
+	return store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			err := q.CreateFixMessage(
+				ctx,
+				queries.CreateFixMessageParams{SessionID: sessionId, SeqNum: seqNum, Message: message},
+			)
+			if err != nil {
+				return err
+			}
+
+			return q.SetFixSenderSeqNum(
+				ctx,
+				queries.SetFixSenderSeqNumParams{ID: sessionId, NextSenderSeqNum: seqNum + 1},
+			)
+		},
+	)
+}
+
+func (store *DbStore) GetFixMessages(sessionId int32, fromSeqNum int32, toSeqNum int32) (
+	[]queries.FixMessage,
+	error,
+) {
+	var messages []queries.FixMessage
+	var err error
+	err = store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			messages, err = q.GetFixMessages(
+				ctx,
+				queries.GetFixMessagesParams{SessionID: sessionId, FromSeqNum: fromSeqNum, ToSeqNum: toSeqNum},
+			)
+			return err
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (store *DbStore) SetFixTargetSeqNum(sessionId int32, seqNum int32) error {
+	return store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			return q.SetFixTargetSeqNum(
+				ctx,
+				queries.SetFixTargetSeqNumParams{ID: sessionId, NextTargetSeqNum: seqNum},
+			)
+		},
+	)
+}
+
+// ResetFixSession starts both sequences of the session at one again and drops
+// the sent messages.
+func (store *DbStore) ResetFixSession(sessionId int32) error {
+	return store.ExecuteTx(
+		func(ctx context.Context, q queries.Querier) error {
+			if err := q.DeleteFixMessages(ctx, sessionId); err != nil {
+				return err
+			}
+
+			err := q.SetFixSenderSeqNum(ctx, queries.SetFixSenderSeqNumParams{ID: sessionId, NextSenderSeqNum: 1})
+			if err != nil {
+				return err
+			}
+
+			return q.SetFixTargetSeqNum(ctx, queries.SetFixTargetSeqNumParams{ID: sessionId, NextTargetSeqNum: 1})
 		},
 	)
 }
 
-func minQuantity(amounts ...int64) int64 {
+func minQuantity(amounts ...currency.Amount) currency.Amount {
 	result := amounts[0]
 	for _, amount := range amounts {
 		if amount < result {
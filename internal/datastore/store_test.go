@@ -1,11 +1,14 @@
 package datastore
 
 import (
+	"github.com/galcik/vlexchange/internal/apikey"
 	"github.com/galcik/vlexchange/internal/currency"
 	"github.com/galcik/vlexchange/internal/datastore/queries"
 	"github.com/galcik/vlexchange/internal/datastore/testqueries"
 	"github.com/stretchr/testify/suite"
+	"io/ioutil"
 	"testing"
+	"time"
 )
 
 type TestStoreSuite struct {
@@ -87,43 +90,45 @@ func (suite *TestStoreSuite) TestComplexScenario() {
 	var success bool
 	var err error
 
-	success, err = suite.store.DepositAccount(userA.ID, currency.NewBTC(1), currency.USD(0))
+	success, err = suite.store.DepositAccount(userA.ID, "BTC", currency.NewBTC(1).Internal())
 	suite.Equal(true, success)
 	suite.NoError(err)
 
-	success, err = suite.store.DepositAccount(userB.ID, currency.NewBTC(10), currency.USD(0))
+	success, err = suite.store.DepositAccount(userB.ID, "BTC", currency.NewBTC(10).Internal())
 	suite.Equal(true, success)
 	suite.NoError(err)
 
-	success, err = suite.store.DepositAccount(userC.ID, currency.BTC(0), currency.NewUSD(250_000))
+	success, err = suite.store.DepositAccount(userC.ID, "USD", currency.NewUSD(250_000).Internal())
 	suite.Equal(true, success)
 	suite.NoError(err)
 
-	success, err = suite.store.DepositAccount(userD.ID, currency.BTC(0), currency.NewUSD(300_000))
+	success, err = suite.store.DepositAccount(userD.ID, "USD", currency.NewUSD(300_000).Internal())
 	suite.Equal(true, success)
 	suite.NoError(err)
 
 	var affectedOrderIds []int32
 	order, affectedOrderIds, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
 		AccountID:  userA.ID,
+		MarketID:   DefaultMarketID,
 		OrderType:  queries.OrderTypeSell,
-		Quantity:   currency.NewBTC(10),
-		LimitPrice: currency.NewUSD(10_000),
+		Quantity:   currency.Amount(currency.NewBTC(10)),
+		LimitPrice: currency.Amount(currency.NewUSD(10_000)),
 	})
 	suite.NoError(err)
 	suite.NotNil(order)
 	suite.Equal(queries.OrderStateCancelled, order.State)
 	suite.Equal(len(affectedOrderIds), 1)
 
-	success, err = suite.store.DepositAccount(userA.ID, currency.NewBTC(9), currency.USD(0))
+	success, err = suite.store.DepositAccount(userA.ID, "BTC", currency.NewBTC(9).Internal())
 	suite.Equal(true, success)
 	suite.Nil(err)
 
 	order1, affectedOrderIds, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
 		AccountID:  userA.ID,
+		MarketID:   DefaultMarketID,
 		OrderType:  queries.OrderTypeSell,
-		Quantity:   currency.NewBTC(10),
-		LimitPrice: currency.NewUSD(10_000),
+		Quantity:   currency.Amount(currency.NewBTC(10)),
+		LimitPrice: currency.Amount(currency.NewUSD(10_000)),
 	})
 	suite.NoError(err)
 	suite.NotNil(order1)
@@ -132,9 +137,10 @@ func (suite *TestStoreSuite) TestComplexScenario() {
 
 	order2, affectedOrderIds, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
 		AccountID:  userB.ID,
+		MarketID:   DefaultMarketID,
 		OrderType:  queries.OrderTypeSell,
-		Quantity:   currency.NewBTC(10),
-		LimitPrice: currency.NewUSD(20_000),
+		Quantity:   currency.Amount(currency.NewBTC(10)),
+		LimitPrice: currency.Amount(currency.NewUSD(20_000)),
 	})
 	suite.NoError(err)
 	suite.NotNil(order2)
@@ -143,17 +149,17 @@ func (suite *TestStoreSuite) TestComplexScenario() {
 
 	orderResult, affectedOrderIds, err := suite.store.ExecuteMarketOrder(CreateMarketOrderParams{
 		AccountID: userC.ID,
+		MarketID:  DefaultMarketID,
 		OrderType: queries.OrderTypeBuy,
-		Quantity:  currency.NewBTC(15),
+		Quantity:  currency.Amount(currency.NewBTC(15)),
 	})
 	suite.NoError(err)
 	suite.NotNil(order)
-	suite.Equal(currency.NewBTC(15), orderResult.Quantity)
-	suite.Equal(currency.NewUSD(200_000), orderResult.Price)
+	suite.Equal(currency.Amount(currency.NewBTC(15)), orderResult.Quantity)
+	suite.Equal(currency.Amount(currency.NewUSD(200_000)), orderResult.Price)
 	suite.ElementsMatch([]int32{order1.ID, order2.ID}, affectedOrderIds)
-	userC = suite.dbHelper.getAccounts()[userC.ID]
-	suite.Equal(currency.NewBTC(15), currency.BTC(userC.BtcAmount))
-	suite.Equal(currency.NewUSD(50_000), currency.USD(userC.UsdAmount))
+	suite.Equal(currency.NewBTC(15).Internal(), suite.dbHelper.getBalance(userC.ID, "BTC"))
+	suite.Equal(currency.NewUSD(50_000).Internal(), suite.dbHelper.getBalance(userC.ID, "USD"))
 	orders := suite.dbHelper.getStandingOrders()
 	suite.Equal(3, len(orders))
 	dbOrder1 := orders[order1.ID]
@@ -169,9 +175,10 @@ func (suite *TestStoreSuite) TestComplexScenario() {
 
 	order3, affectedOrderIds, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
 		AccountID:  userD.ID,
+		MarketID:   DefaultMarketID,
 		OrderType:  queries.OrderTypeBuy,
-		Quantity:   currency.NewBTC(20),
-		LimitPrice: currency.NewUSD(10_000),
+		Quantity:   currency.Amount(currency.NewBTC(20)),
+		LimitPrice: currency.Amount(currency.NewUSD(10_000)),
 	})
 	suite.NoError(err)
 	suite.NotNil(order3)
@@ -182,9 +189,10 @@ func (suite *TestStoreSuite) TestComplexScenario() {
 
 	order4, affectedOrderIds, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
 		AccountID:  userD.ID,
+		MarketID:   DefaultMarketID,
 		OrderType:  queries.OrderTypeBuy,
-		Quantity:   currency.NewBTC(10),
-		LimitPrice: currency.NewUSD(25_000),
+		Quantity:   currency.Amount(currency.NewBTC(10)),
+		LimitPrice: currency.Amount(currency.NewUSD(25_000)),
 	})
 	suite.NoError(err)
 	suite.NotNil(order4)
@@ -193,16 +201,27 @@ func (suite *TestStoreSuite) TestComplexScenario() {
 	orders = suite.dbHelper.getStandingOrders()
 	suite.Equal(5, len(orders))
 
-	err = suite.store.DeleteStandingOrder(order3.ID)
-	suite.Nil(err)
+	cancelled, err := suite.store.CancelStandingOrder(order3.ID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(cancelled)
+	suite.Equal(queries.OrderStateCancelled, cancelled.State)
 	orders = suite.dbHelper.getStandingOrders()
-	suite.Equal(4, len(orders))
+	suite.Equal(5, len(orders))
+	suite.Equal(testqueries.OrderStateCancelled, orders[order3.ID].State)
+	suite.Equal(currency.Amount(0), orders[order3.ID].ReservedBaseAmount)
+	suite.Equal(currency.Amount(0), orders[order3.ID].ReservedQuoteAmount)
+
+	// only live orders can be cancelled
+	cancelled, err = suite.store.CancelStandingOrder(order3.ID)
+	suite.NoError(err)
+	suite.Nil(cancelled)
 
 	order5, affectedOrderIds, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
 		AccountID:  userD.ID,
+		MarketID:   DefaultMarketID,
 		OrderType:  queries.OrderTypeBuy,
-		Quantity:   currency.NewBTC(10),
-		LimitPrice: currency.NewUSD(25_000),
+		Quantity:   currency.Amount(currency.NewBTC(10)),
+		LimitPrice: currency.Amount(currency.NewUSD(25_000)),
 	})
 	suite.NoError(err)
 	suite.NotNil(order5)
@@ -213,8 +232,784 @@ func (suite *TestStoreSuite) TestComplexScenario() {
 
 	suite.ElementsMatch([]int32{order5.ID, order2.ID}, affectedOrderIds)
 	orders = suite.dbHelper.getStandingOrders()
-	suite.Equal(5, len(orders))
+	suite.Equal(6, len(orders))
 	suite.Equal(testqueries.OrderStateFulfilled, orders[order2.ID].State)
+
+	trades, err := suite.store.GetOrderTrades(order5.ID)
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, len(trades))
+	suite.Equal(order5.ID, trades[0].BuyOrderID)
+	suite.Equal(order2.ID, trades[0].SellOrderID)
+	suite.Equal(queries.OrderTypeBuy, trades[0].TakerSide)
+	suite.Equal(currency.Amount(currency.NewBTC(5)), trades[0].Quantity)
+	suite.Equal(currency.Amount(currency.NewUSD(20_000)), trades[0].Price)
+
+	trades, err = suite.store.GetOrderTrades(order2.ID)
+	suite.Require().NoError(err)
+	suite.Equal(2, len(trades))
+}
+
+func (suite *TestStoreSuite) TestRegisterAccount() {
+	account, token, err := suite.store.RegisterAccount("tester")
+	suite.Require().NoError(err)
+	suite.Equal("tester", account.Username)
+
+	key, err := suite.store.GetApiKeyByToken(token)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(key)
+	suite.Equal(account.ID, key.AccountID)
+	suite.ElementsMatch(apikey.ScopesToStrings(apikey.AllScopes), key.Scopes)
+}
+
+func (suite *TestStoreSuite) TestApiKeys() {
+	testAccount1 := suite.dbHelper.createAccount(queries.Account{Username: "tester1", Token: "111111"})
+	testAccount2 := suite.dbHelper.createAccount(queries.Account{Username: "tester2", Token: "222222"})
+
+	key, token, err := suite.store.CreateApiKey(CreateApiKeyParams{
+		AccountID:  testAccount1.ID,
+		Name:       "bot",
+		Scopes:     []apikey.Scope{apikey.ScopeRead},
+		AllowedIPs: []string{"203.0.113.0/24"},
+	})
+	suite.Require().NoError(err)
+	suite.Equal([]string{"read"}, key.Scopes)
+	suite.Equal([]string{"203.0.113.0/24"}, key.AllowedIps)
+	suite.False(key.ExpiresAt.Valid)
+	suite.NotContains(string(key.SecretHash), token)
+
+	foundKey, err := suite.store.GetApiKeyByToken(token)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(foundKey)
+	suite.Equal(key.ID, foundKey.ID)
+	suite.False(foundKey.LastUsedAt.Valid)
+
+	suite.Require().NoError(suite.store.TouchApiKey(key.ID))
+	foundKey, err = suite.store.GetApiKeyByToken(token)
+	suite.Require().NoError(err)
+	suite.True(foundKey.LastUsedAt.Valid)
+
+	prefix, _, _ := apikey.Parse(token)
+	foundKey, err = suite.store.GetApiKeyByToken("vlx_" + prefix + "_wrongsecret")
+	suite.NoError(err)
+	suite.Nil(foundKey)
+
+	keys, err := suite.store.GetApiKeys(testAccount1.ID)
+	suite.NoError(err)
+	suite.Equal(1, len(keys))
+
+	deleted, err := suite.store.DeleteApiKey(testAccount2.ID, key.ID)
+	suite.NoError(err)
+	suite.False(deleted)
+
+	deleted, err = suite.store.DeleteApiKey(testAccount1.ID, key.ID)
+	suite.NoError(err)
+	suite.True(deleted)
+
+	foundKey, err = suite.store.GetApiKeyByToken(token)
+	suite.NoError(err)
+	suite.Nil(foundKey)
+}
+
+func (suite *TestStoreSuite) TestDepositScopeMigration() {
+	testAccount := suite.dbHelper.createAccount(queries.Account{Username: "tester1", Token: "111111"})
+	_, withdrawToken, err := suite.store.CreateApiKey(CreateApiKeyParams{
+		AccountID: testAccount.ID,
+		Name:      "treasury",
+		Scopes:    []apikey.Scope{apikey.ScopeRead, apikey.ScopeWithdraw},
+	})
+	suite.Require().NoError(err)
+	_, readToken, err := suite.store.CreateApiKey(CreateApiKeyParams{
+		AccountID: testAccount.ID,
+		Name:      "monitor",
+		Scopes:    []apikey.Scope{apikey.ScopeRead},
+	})
+	suite.Require().NoError(err)
+
+	migration, err := ioutil.ReadFile("./migrations/0001_api_key_deposit_scope.sql")
+	suite.Require().NoError(err)
+	// applying the migration twice adds the scope once
+	for i := 0; i < 2; i++ {
+		_, err = suite.db.Exec(string(migration))
+		suite.Require().NoError(err)
+	}
+
+	foundKey, err := suite.store.GetApiKeyByToken(withdrawToken)
+	suite.Require().NoError(err)
+	suite.Equal([]string{"read", "withdraw", "deposit"}, foundKey.Scopes)
+	foundKey, err = suite.store.GetApiKeyByToken(readToken)
+	suite.Require().NoError(err)
+	suite.Equal([]string{"read"}, foundKey.Scopes)
+}
+
+func (suite *TestStoreSuite) TestUseRequestNonce() {
+	testAccount := suite.dbHelper.createAccount(queries.Account{Username: "tester1", Token: "111111"})
+	key, _, err := suite.store.CreateApiKey(CreateApiKeyParams{
+		AccountID: testAccount.ID,
+		Name:      "bot",
+		Scopes:    apikey.AllScopes,
+	})
+	suite.Require().NoError(err)
+
+	foundKey, err := suite.store.GetApiKeyByPrefix(key.Prefix)
+	suite.Require().NoError(err)
+	suite.Equal(key.ID, foundKey.ID)
+
+	fresh, err := suite.store.UseRequestNonce(key.ID, "nonce-1", time.Now().Add(-time.Minute))
+	suite.NoError(err)
+	suite.True(fresh)
+
+	fresh, err = suite.store.UseRequestNonce(key.ID, "nonce-1", time.Now().Add(-time.Minute))
+	suite.NoError(err)
+	suite.False(fresh)
+
+	fresh, err = suite.store.UseRequestNonce(key.ID, "nonce-2", time.Now().Add(-time.Minute))
+	suite.NoError(err)
+	suite.True(fresh)
+
+	// expired nonces are discarded
+	fresh, err = suite.store.UseRequestNonce(key.ID, "nonce-1", time.Now().Add(time.Minute))
+	suite.NoError(err)
+	suite.True(fresh)
+}
+
+func (suite *TestStoreSuite) TestIdempotencyKeys() {
+	testAccount := suite.dbHelper.createAccount(queries.Account{Username: "tester1", Token: "111111"})
+	params := ReserveIdempotencyKeyParams{
+		AccountID:   testAccount.ID,
+		Key:         "key-1",
+		Endpoint:    "POST /balance",
+		RequestHash: []byte{1, 2, 3},
+		NotBefore:   time.Now().Add(-time.Hour),
+	}
+
+	existing, err := suite.store.ReserveIdempotencyKey(params)
+	suite.Require().NoError(err)
+	suite.Nil(existing)
+
+	existing, err = suite.store.ReserveIdempotencyKey(params)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(existing)
+	suite.Equal(int32(0), existing.ResponseStatus)
+	suite.Equal([]byte{1, 2, 3}, existing.RequestHash)
+
+	suite.Require().NoError(suite.store.CompleteIdempotencyKey(queries.CompleteIdempotencyKeyParams{
+		AccountID:      testAccount.ID,
+		Key:            "key-1",
+		ResponseStatus: 200,
+		ContentType:    "application/json",
+		ResponseBody:   []byte(`{"success":true}`),
+	}))
+	existing, err = suite.store.ReserveIdempotencyKey(params)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(existing)
+	suite.Equal(int32(200), existing.ResponseStatus)
+	suite.Equal(`{"success":true}`, string(existing.ResponseBody))
+
+	suite.Require().NoError(suite.store.ReleaseIdempotencyKey(testAccount.ID, "key-1"))
+	existing, err = suite.store.ReserveIdempotencyKey(params)
+	suite.Require().NoError(err)
+	suite.Nil(existing)
+
+	// expired keys can be used again
+	params.NotBefore = time.Now().Add(time.Minute)
+	existing, err = suite.store.ReserveIdempotencyKey(params)
+	suite.Require().NoError(err)
+	suite.Nil(existing)
+}
+
+func (suite *TestStoreSuite) TestClientOrderId() {
+	testAccount1 := suite.dbHelper.createAccount(queries.Account{Username: "tester1", Token: "111111"})
+	testAccount2 := suite.dbHelper.createAccount(queries.Account{Username: "tester2", Token: "222222"})
+	params := CreateStandingOrderParams{
+		AccountID:     testAccount1.ID,
+		MarketID:      DefaultMarketID,
+		OrderType:     queries.OrderTypeSell,
+		Quantity:      currency.Amount(currency.NewBTC(1)),
+		LimitPrice:    currency.Amount(currency.NewUSD(100)),
+		ClientOrderID: "order-1",
+	}
+
+	order, _, err := suite.store.CreateStandingOrder(params)
+	suite.Require().NoError(err)
+	suite.Equal("order-1", order.ClientOrderID.String)
+
+	_, _, err = suite.store.CreateStandingOrder(params)
+	suite.ErrorIs(err, ErrDuplicateClientOrderID)
+
+	params.AccountID = testAccount2.ID
+	_, _, err = suite.store.CreateStandingOrder(params)
+	suite.NoError(err)
+
+	found, err := suite.store.GetStandingOrderByClientOrderID(testAccount1.ID, "order-1")
+	suite.Require().NoError(err)
+	suite.Equal(order.ID, found.ID)
+
+	found, err = suite.store.GetStandingOrderByClientOrderID(testAccount1.ID, "order-2")
+	suite.NoError(err)
+	suite.Nil(found)
+
+	orders, err := suite.store.GetStandingOrders([]int32{order.ID})
+	suite.Require().NoError(err)
+	suite.Equal(1, len(orders))
+}
+
+func (suite *TestStoreSuite) TestReplaceStandingOrder() {
+	account := suite.dbHelper.createAccount(queries.Account{Username: "tester", Token: "111111"})
+	suite.dbHelper.setBalance(account.ID, "USD", currency.NewUSD(100).Internal())
+	params := CreateStandingOrderParams{
+		AccountID:     account.ID,
+		MarketID:      DefaultMarketID,
+		OrderType:     queries.OrderTypeBuy,
+		Quantity:      currency.Amount(currency.NewBTC(0.5)),
+		LimitPrice:    currency.Amount(currency.NewUSD(100)),
+		ClientOrderID: "order-1",
+	}
+	original, _, err := suite.store.CreateStandingOrder(params)
+	suite.Require().NoError(err)
+
+	// the reservation of the original order is released for the replacement
+	params.Quantity = currency.Amount(currency.NewBTC(0.8))
+	params.LimitPrice = currency.Amount(currency.NewUSD(120))
+	params.ClientOrderID = "order-2"
+	replacement, _, err := suite.store.ReplaceStandingOrder(original.ID, params)
+	suite.Require().NoError(err)
+	suite.Equal(queries.OrderStateLive, replacement.State)
+	suite.Equal(currency.Amount(currency.NewUSD(96)), replacement.ReservedQuoteAmount)
+	orders := suite.dbHelper.getStandingOrders()
+	suite.Equal(testqueries.OrderStateCancelled, orders[original.ID].State)
+	suite.Equal(currency.Amount(0), orders[original.ID].ReservedQuoteAmount)
+
+	_, _, err = suite.store.ReplaceStandingOrder(original.ID, params)
+	suite.ErrorIs(err, ErrOrderNotLive)
+
+	// rejected replacements leave the order untouched
+	params.Quantity = currency.Amount(currency.NewBTC(1))
+	params.LimitPrice = currency.Amount(currency.NewUSD(150))
+	params.ClientOrderID = "order-3"
+	_, _, err = suite.store.ReplaceStandingOrder(replacement.ID, params)
+	suite.ErrorIs(err, ErrInsufficientFunds)
+	orders = suite.dbHelper.getStandingOrders()
+	suite.Equal(2, len(orders))
+	suite.Equal(testqueries.OrderStateLive, orders[replacement.ID].State)
+	suite.Equal(currency.Amount(currency.NewUSD(96)), orders[replacement.ID].ReservedQuoteAmount)
+}
+
+func (suite *TestStoreSuite) TestListStandingOrders() {
+	testAccount1 := suite.dbHelper.createAccount(queries.Account{Username: "tester1", Token: "111111"})
+	testAccount2 := suite.dbHelper.createAccount(queries.Account{Username: "tester2", Token: "222222"})
+
+	var orderIds []int32
+	specs := []queries.StandingOrder{
+		{AccountID: testAccount1.ID, Type: queries.OrderTypeBuy, State: queries.OrderStateLive},
+		{AccountID: testAccount1.ID, Type: queries.OrderTypeSell, State: queries.OrderStateLive},
+		{AccountID: testAccount1.ID, Type: queries.OrderTypeSell, State: queries.OrderStateCancelled},
+		{AccountID: testAccount1.ID, Type: queries.OrderTypeBuy, State: queries.OrderStateFulfilled},
+		{AccountID: testAccount2.ID, Type: queries.OrderTypeBuy, State: queries.OrderStateLive},
+	}
+	for i, spec := range specs {
+		order := suite.dbHelper.createStandingOrder(spec)
+		orderIds = append(orderIds, order.ID)
+		_, err := suite.db.Exec(
+			"UPDATE standing_order SET created_at = $1 WHERE id = $2",
+			time.Date(2021, 3, 1+i, 0, 0, 0, 0, time.UTC),
+			order.ID,
+		)
+		suite.Require().NoError(err)
+	}
+
+	listIds := func(params ListStandingOrdersParams) ([]int32, *StandingOrderCursor) {
+		orders, cursor, err := suite.store.ListStandingOrders(params)
+		suite.Require().NoError(err)
+		ids := []int32{}
+		for _, order := range orders {
+			ids = append(ids, order.ID)
+		}
+		return ids, cursor
+	}
+
+	ids, cursor := listIds(ListStandingOrdersParams{AccountID: testAccount1.ID, Limit: 10})
+	suite.Equal([]int32{orderIds[3], orderIds[2], orderIds[1], orderIds[0]}, ids)
+	suite.Nil(cursor)
+
+	ids, cursor = listIds(ListStandingOrdersParams{AccountID: testAccount1.ID, Limit: 3})
+	suite.Equal([]int32{orderIds[3], orderIds[2], orderIds[1]}, ids)
+	suite.Require().NotNil(cursor)
+	ids, cursor = listIds(ListStandingOrdersParams{AccountID: testAccount1.ID, Limit: 3, After: cursor})
+	suite.Equal([]int32{orderIds[0]}, ids)
+	suite.Nil(cursor)
+
+	ids, _ = listIds(ListStandingOrdersParams{
+		AccountID: testAccount1.ID,
+		States:    []queries.OrderState{queries.OrderStateLive},
+		Types:     []queries.OrderType{queries.OrderTypeSell},
+		Limit:     10,
+	})
+	suite.Equal([]int32{orderIds[1]}, ids)
+
+	ids, _ = listIds(ListStandingOrdersParams{
+		AccountID:   testAccount1.ID,
+		CreatedFrom: time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+		CreatedTo:   time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC),
+		Limit:       10,
+	})
+	suite.Equal([]int32{orderIds[2], orderIds[1]}, ids)
+}
+
+func (suite *TestStoreSuite) TestGetOrderBook() {
+	account := suite.dbHelper.createAccount(queries.Account{Username: "tester", Token: "111111"})
+	specs := []queries.StandingOrder{
+		{Type: queries.OrderTypeBuy, State: queries.OrderStateLive, LimitPrice: 90, Quantity: 1},
+		{Type: queries.OrderTypeBuy, State: queries.OrderStateLive, LimitPrice: 95, Quantity: 2},
+		{Type: queries.OrderTypeBuy, State: queries.OrderStateLive, LimitPrice: 95, Quantity: 3},
+		{Type: queries.OrderTypeBuy, State: queries.OrderStateCancelled, LimitPrice: 99, Quantity: 4},
+		{Type: queries.OrderTypeSell, State: queries.OrderStateLive, LimitPrice: 110, Quantity: 5},
+		{Type: queries.OrderTypeSell, State: queries.OrderStateLive, LimitPrice: 100, Quantity: 6},
+		{Type: queries.OrderTypeSell, State: queries.OrderStateFulfilled, LimitPrice: 100, Quantity: 0},
+	}
+	for _, spec := range specs {
+		spec.AccountID = account.ID
+		suite.dbHelper.createStandingOrder(spec)
+	}
+
+	book, err := suite.store.GetOrderBook(DefaultMarketID, 10)
+	suite.Require().NoError(err)
+	suite.Equal(
+		[]queries.GetOrderBookLevelsRow{
+			{LimitPrice: 95, Quantity: 5, OrderCount: 2},
+			{LimitPrice: 90, Quantity: 1, OrderCount: 1},
+		},
+		book.Bids,
+	)
+	suite.Equal(
+		[]queries.GetOrderBookLevelsRow{
+			{LimitPrice: 100, Quantity: 6, OrderCount: 1},
+			{LimitPrice: 110, Quantity: 5, OrderCount: 1},
+		},
+		book.Asks,
+	)
+
+	book, err = suite.store.GetOrderBook(DefaultMarketID, 1)
+	suite.Require().NoError(err)
+	suite.Len(book.Bids, 1)
+	suite.Len(book.Asks, 1)
+}
+
+func (suite *TestStoreSuite) TestGetLastTrade() {
+	trade, err := suite.store.GetLastTrade(DefaultMarketID)
+	suite.Require().NoError(err)
+	suite.Nil(trade)
+
+	seller := suite.dbHelper.createAccount(queries.Account{Username: "seller", Token: "111111"})
+	suite.dbHelper.setBalance(seller.ID, "BTC", currency.NewBTC(2).Internal())
+	buyer := suite.dbHelper.createAccount(queries.Account{Username: "buyer", Token: "222222"})
+	suite.dbHelper.setBalance(buyer.ID, "USD", currency.NewUSD(1000).Internal())
+	for _, price := range []float64{100, 200} {
+		_, _, err = suite.store.CreateStandingOrder(CreateStandingOrderParams{
+			AccountID:  seller.ID,
+			MarketID:   DefaultMarketID,
+			OrderType:  queries.OrderTypeSell,
+			Quantity:   currency.Amount(currency.NewBTC(1)),
+			LimitPrice: currency.Amount(currency.NewUSD(price)),
+		})
+		suite.Require().NoError(err)
+		_, _, err = suite.store.CreateStandingOrder(CreateStandingOrderParams{
+			AccountID:  buyer.ID,
+			MarketID:   DefaultMarketID,
+			OrderType:  queries.OrderTypeBuy,
+			Quantity:   currency.Amount(currency.NewBTC(1)),
+			LimitPrice: currency.Amount(currency.NewUSD(price)),
+		})
+		suite.Require().NoError(err)
+	}
+
+	trade, err = suite.store.GetLastTrade(DefaultMarketID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(trade)
+	suite.Equal(currency.Amount(currency.NewUSD(200)), trade.Price)
+}
+
+func (suite *TestStoreSuite) TestPriceHistory() {
+	start := time.Date(2021, 2, 16, 15, 0, 0, 0, time.UTC)
+	before, after, err := suite.store.GetPricesAround(start)
+	suite.Require().NoError(err)
+	suite.Nil(before)
+	suite.Nil(after)
+
+	for i, price := range []float64{100, 110, 90} {
+		suite.Require().NoError(suite.store.RecordPrice(currency.NewUSD(price), start.Add(time.Duration(i)*time.Minute)))
+	}
+
+	before, after, err = suite.store.GetPricesAround(start.Add(90 * time.Second))
+	suite.Require().NoError(err)
+	suite.Require().NotNil(before)
+	suite.Require().NotNil(after)
+	suite.Equal(currency.NewUSD(110), before.Price)
+	suite.True(start.Add(time.Minute).Equal(before.QuotedAt))
+	suite.Equal(currency.NewUSD(90), after.Price)
+
+	before, after, err = suite.store.GetPricesAround(start.Add(2 * time.Minute))
+	suite.Require().NoError(err)
+	suite.Equal(currency.NewUSD(90), before.Price)
+	suite.Nil(after)
+
+	prices, err := suite.store.GetPriceHistory(start, start.Add(2*time.Minute), 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(prices, 2)
+	suite.Equal(currency.NewUSD(100), prices[0].Price)
+	suite.Equal(currency.NewUSD(110), prices[1].Price)
+
+	prices, err = suite.store.GetPriceHistory(start, start.Add(time.Hour), 1)
+	suite.Require().NoError(err)
+	suite.Len(prices, 1)
+}
+
+func (suite *TestStoreSuite) TestAdjustBalance() {
+	account := suite.dbHelper.createAccount(queries.Account{Username: "tester", Token: "111111"})
+
+	success, err := suite.store.DepositAccount(account.ID, "USD", currency.NewUSD(100).Internal())
+	suite.Require().NoError(err)
+	suite.True(success)
+
+	success, err = suite.store.AdjustBalance(AdjustBalanceParams{
+		AccountID: account.ID,
+		Asset:     "USD",
+		Amount:    currency.NewUSD(-30).Internal(),
+		Reason:    "correction",
+		Operator:  "admin",
+	})
+	suite.Require().NoError(err)
+	suite.True(success)
+
+	success, err = suite.store.AdjustBalance(AdjustBalanceParams{
+		AccountID: account.ID,
+		Asset:     "BTC",
+		Amount:    currency.NewBTC(1).Internal(),
+		Reason:    "correction",
+		Operator:  "admin",
+	})
+	suite.Require().NoError(err)
+	suite.True(success)
+
+	order, _, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
+		AccountID:  account.ID,
+		MarketID:   DefaultMarketID,
+		OrderType:  queries.OrderTypeSell,
+		Quantity:   currency.Amount(currency.NewBTC(0.6)),
+		LimitPrice: currency.Amount(currency.NewUSD(1000)),
+	})
+	suite.Require().NoError(err)
+	suite.Equal(queries.OrderStateLive, order.State)
+
+	// the balance cannot drop below the reserved amounts
+	success, err = suite.store.AdjustBalance(AdjustBalanceParams{
+		AccountID: account.ID,
+		Asset:     "BTC",
+		Amount:    currency.NewBTC(-0.5).Internal(),
+		Reason:    "correction",
+		Operator:  "admin",
+	})
+	suite.Require().NoError(err)
+	suite.False(success)
+
+	success, err = suite.store.AdjustBalance(AdjustBalanceParams{AccountID: account.ID + 1, Asset: "USD", Reason: "unknown"})
+	suite.Require().NoError(err)
+	suite.False(success)
+
+	_, err = suite.store.AdjustBalance(AdjustBalanceParams{AccountID: account.ID, Asset: "XYZ", Reason: "unknown"})
+	suite.ErrorIs(err, ErrUnknownAsset)
+
+	suite.Equal(currency.NewUSD(70).Internal(), suite.dbHelper.getBalance(account.ID, "USD"))
+	suite.Equal(currency.NewBTC(1).Internal(), suite.dbHelper.getBalance(account.ID, "BTC"))
+
+	journal, err := suite.store.GetBalanceJournal(account.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(journal, 3)
+	suite.Equal("deposit", journal[0].Reason)
+	suite.Equal("", journal[0].Operator)
+	suite.Equal("USD", journal[0].Asset)
+	suite.Equal(currency.NewUSD(100).Internal(), journal[0].Amount)
+	suite.Equal("correction", journal[1].Reason)
+	suite.Equal("admin", journal[1].Operator)
+	suite.Equal(currency.NewUSD(-30).Internal(), journal[1].Amount)
+	suite.Equal("BTC", journal[2].Asset)
+	suite.Equal(currency.NewBTC(1).Internal(), journal[2].Amount)
+
+	rows, err := suite.store.GetReconciliation()
+	suite.Require().NoError(err)
+	suite.Equal(
+		[]queries.GetReconciliationRow{
+			{
+				ID:             account.ID,
+				Username:       "tester",
+				Asset:          "BTC",
+				Precision:      8,
+				Amount:         currency.NewBTC(1).Internal(),
+				ReservedAmount: currency.NewBTC(0.6).Internal(),
+				JournalAmount:  currency.NewBTC(1).Internal(),
+			},
+			{
+				ID:            account.ID,
+				Username:      "tester",
+				Asset:         "USD",
+				Precision:     2,
+				Amount:        currency.NewUSD(70).Internal(),
+				JournalAmount: currency.NewUSD(70).Internal(),
+			},
+		},
+		rows,
+	)
+}
+
+func (suite *TestStoreSuite) TestFrozenAccount() {
+	account := suite.dbHelper.createAccount(queries.Account{Username: "tester", Token: "111111"})
+	suite.dbHelper.setBalance(account.ID, "USD", currency.NewUSD(100).Internal())
+
+	success, err := suite.store.SetAccountFrozen(account.ID, true)
+	suite.Require().NoError(err)
+	suite.True(success)
+
+	_, err = suite.store.DepositAccount(account.ID, "USD", currency.NewUSD(100).Internal())
+	suite.ErrorIs(err, ErrAccountFrozen)
+
+	orderParams := CreateStandingOrderParams{
+		AccountID:  account.ID,
+		MarketID:   DefaultMarketID,
+		OrderType:  queries.OrderTypeBuy,
+		Quantity:   currency.Amount(currency.NewBTC(0.1)),
+		LimitPrice: currency.Amount(currency.NewUSD(100)),
+	}
+	_, _, err = suite.store.CreateStandingOrder(orderParams)
+	suite.ErrorIs(err, ErrAccountFrozen)
+
+	success, err = suite.store.SetAccountFrozen(account.ID, false)
+	suite.Require().NoError(err)
+	suite.True(success)
+
+	_, _, err = suite.store.CreateStandingOrder(orderParams)
+	suite.NoError(err)
+
+	success, err = suite.store.SetAccountFrozen(account.ID+1, true)
+	suite.Require().NoError(err)
+	suite.False(success)
+}
+
+func (suite *TestStoreSuite) TestTradingHalt() {
+	account := suite.dbHelper.createAccount(queries.Account{Username: "tester", Token: "111111"})
+	suite.dbHelper.setBalance(account.ID, "USD", currency.NewUSD(100).Internal())
+
+	status, err := suite.store.GetTradingStatus()
+	suite.Require().NoError(err)
+	suite.False(status.Halted)
+
+	status, err = suite.store.SetTradingStatus(true, "maintenance")
+	suite.Require().NoError(err)
+	suite.True(status.Halted)
+	suite.Equal("maintenance", status.Reason)
+
+	orderParams := CreateStandingOrderParams{
+		AccountID:  account.ID,
+		MarketID:   DefaultMarketID,
+		OrderType:  queries.OrderTypeBuy,
+		Quantity:   currency.Amount(currency.NewBTC(0.1)),
+		LimitPrice: currency.Amount(currency.NewUSD(100)),
+	}
+	_, _, err = suite.store.CreateStandingOrder(orderParams)
+	suite.ErrorIs(err, ErrTradingHalted)
+
+	_, _, err = suite.store.ExecuteMarketOrder(CreateMarketOrderParams{
+		AccountID: account.ID,
+		MarketID:  DefaultMarketID,
+		OrderType: queries.OrderTypeBuy,
+		Quantity:  currency.Amount(currency.NewBTC(0.1)),
+	})
+	suite.ErrorIs(err, ErrTradingHalted)
+
+	// deposits are not affected by the halt
+	success, err := suite.store.DepositAccount(account.ID, "USD", currency.NewUSD(100).Internal())
+	suite.Require().NoError(err)
+	suite.True(success)
+
+	status, err = suite.store.SetTradingStatus(false, "")
+	suite.Require().NoError(err)
+	suite.False(status.Halted)
+
+	_, _, err = suite.store.CreateStandingOrder(orderParams)
+	suite.NoError(err)
+}
+
+func (suite *TestStoreSuite) TestMarkets() {
+	markets, err := suite.store.GetMarkets()
+	suite.Require().NoError(err)
+	suite.Require().Len(markets, 1)
+	suite.Equal(DefaultMarketID, markets[0].ID)
+	suite.Equal(DefaultMarket, markets[0].Symbol)
+	suite.Equal(currency.AssetBTC, markets[0].Base)
+	suite.Equal(currency.AssetUSD, markets[0].Quote)
+
+	_, err = suite.store.CreateAsset("ETH", 8)
+	suite.Require().NoError(err)
+	market, err := suite.store.CreateMarket("ETH", "USD")
+	suite.Require().NoError(err)
+	suite.Equal("ETH-USD", market.Symbol)
+
+	found, err := suite.store.GetMarketBySymbol("ETH-USD")
+	suite.Require().NoError(err)
+	suite.Equal(market, found)
+
+	seller := suite.dbHelper.createAccount(queries.Account{Username: "seller", Token: "111111"})
+	suite.dbHelper.setBalance(seller.ID, "ETH", 10_00000000)
+	buyer := suite.dbHelper.createAccount(queries.Account{Username: "buyer", Token: "222222"})
+	suite.dbHelper.setBalance(buyer.ID, "USD", currency.NewUSD(10_000).Internal())
+
+	sellOrder, _, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
+		AccountID:  seller.ID,
+		MarketID:   market.ID,
+		OrderType:  queries.OrderTypeSell,
+		Quantity:   2_00000000,
+		LimitPrice: currency.Amount(currency.NewUSD(1000)),
+	})
+	suite.Require().NoError(err)
+	suite.Equal(queries.OrderStateLive, sellOrder.State)
+
+	// orders of other markets do not match
+	buyOrder, _, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
+		AccountID:  buyer.ID,
+		MarketID:   DefaultMarketID,
+		OrderType:  queries.OrderTypeBuy,
+		Quantity:   currency.Amount(currency.NewBTC(2)),
+		LimitPrice: currency.Amount(currency.NewUSD(1000)),
+	})
+	suite.Require().NoError(err)
+	suite.Equal(queries.OrderStateLive, buyOrder.State)
+	cancelled, err := suite.store.CancelStandingOrder(buyOrder.ID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(cancelled)
+
+	book, err := suite.store.GetOrderBook(market.ID, 10)
+	suite.Require().NoError(err)
+	suite.Empty(book.Bids)
+	suite.Len(book.Asks, 1)
+
+	buyOrder, _, err = suite.store.CreateStandingOrder(CreateStandingOrderParams{
+		AccountID:  buyer.ID,
+		MarketID:   market.ID,
+		OrderType:  queries.OrderTypeBuy,
+		Quantity:   1_00000000,
+		LimitPrice: currency.Amount(currency.NewUSD(1000)),
+	})
+	suite.Require().NoError(err)
+	suite.Equal(queries.OrderStateFulfilled, buyOrder.State)
+
+	suite.Equal(int64(1_00000000), suite.dbHelper.getBalance(buyer.ID, "ETH"))
+	suite.Equal(currency.NewUSD(9000).Internal(), suite.dbHelper.getBalance(buyer.ID, "USD"))
+	suite.Equal(int64(9_00000000), suite.dbHelper.getBalance(seller.ID, "ETH"))
+	suite.Equal(currency.NewUSD(1000).Internal(), suite.dbHelper.getBalance(seller.ID, "USD"))
+
+	trade, err := suite.store.GetLastTrade(market.ID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(trade)
+	suite.Equal(market.ID, trade.MarketID)
+	trade, err = suite.store.GetLastTrade(DefaultMarketID)
+	suite.Require().NoError(err)
+	suite.Nil(trade)
+
+	_, _, err = suite.store.CreateStandingOrder(CreateStandingOrderParams{
+		AccountID:  buyer.ID,
+		MarketID:   market.ID + 1,
+		OrderType:  queries.OrderTypeBuy,
+		Quantity:   1_00000000,
+		LimitPrice: currency.Amount(currency.NewUSD(1000)),
+	})
+	suite.ErrorIs(err, ErrUnknownMarket)
+}
+
+func (suite *TestStoreSuite) TestRounding() {
+	seller := suite.dbHelper.createAccount(queries.Account{Username: "seller", Token: "111111"})
+	suite.dbHelper.setBalance(seller.ID, "BTC", currency.NewBTC(10).Internal())
+	buyer := suite.dbHelper.createAccount(queries.Account{Username: "buyer", Token: "222222"})
+	suite.dbHelper.setBalance(buyer.ID, "USD", currency.NewUSD(0.1).Internal())
+
+	sellOrder, _, err := suite.store.CreateStandingOrder(CreateStandingOrderParams{
+		AccountID:  seller.ID,
+		MarketID:   DefaultMarketID,
+		OrderType:  queries.OrderTypeSell,
+		Quantity:   currency.Amount(currency.NewBTC(10)),
+		LimitPrice: currency.Amount(currency.NewUSD(0.03)),
+	})
+	suite.Require().NoError(err)
+
+	// 3.33333333 BTC at 0.03 USD cost 0.0999999999 USD, rounded to 0.10 USD
+	result, _, err := suite.store.ExecuteMarketOrder(CreateMarketOrderParams{
+		AccountID: buyer.ID,
+		MarketID:  DefaultMarketID,
+		OrderType: queries.OrderTypeBuy,
+		Quantity:  currency.Amount(currency.NewBTC(5)),
+	})
+	suite.Require().NoError(err)
+	suite.Equal(currency.Amount(currency.NewBTC(3.33333333)), result.Quantity)
+	suite.Equal(currency.Amount(currency.NewUSD(0.1)), result.Price)
+	suite.Equal(int64(0), suite.dbHelper.getBalance(buyer.ID, "USD"))
+	suite.Equal(currency.NewBTC(3.33333333).Internal(), suite.dbHelper.getBalance(buyer.ID, "BTC"))
+
+	// the reservation of 0.5 BTC at 0.03 USD is rounded up to 0.02 USD
+	suite.dbHelper.setBalance(buyer.ID, "USD", currency.NewUSD(0.01).Internal())
+	buyParams := CreateStandingOrderParams{
+		AccountID:  buyer.ID,
+		MarketID:   DefaultMarketID,
+		OrderType:  queries.OrderTypeBuy,
+		Quantity:   currency.Amount(currency.NewBTC(0.5)),
+		LimitPrice: currency.Amount(currency.NewUSD(0.03)),
+	}
+	buyOrder, _, err := suite.store.CreateStandingOrder(buyParams)
+	suite.Require().NoError(err)
+	suite.Equal(queries.OrderStateCancelled, buyOrder.State)
+
+	suite.dbHelper.setBalance(buyer.ID, "USD", currency.NewUSD(0.02).Internal())
+	buyOrder, _, err = suite.store.CreateStandingOrder(buyParams)
+	suite.Require().NoError(err)
+	suite.Equal(queries.OrderStateFulfilled, buyOrder.State)
+	suite.Equal(currency.Amount(0), buyOrder.ReservedQuoteAmount)
+	// 0.015 USD is rounded half to even
+	suite.Equal(currency.Amount(currency.NewUSD(0.02)), buyOrder.FilledPrice)
+
+	orders := suite.dbHelper.getStandingOrders()
+	suite.Equal(currency.Amount(currency.NewUSD(0.12)), orders[sellOrder.ID].FilledPrice)
+	suite.Equal(currency.Amount(0), orders[sellOrder.ID].ReservedQuoteAmount)
+}
+
+func (suite *TestStoreSuite) TestFixSession() {
+	session, err := suite.store.GetFixSession("VLEX", "CLIENT")
+	suite.Require().NoError(err)
+	suite.Equal(int32(1), session.NextSenderSeqNum)
+	suite.Equal(int32(1), session.NextTargetSeqNum)
+
+	suite.Require().NoError(suite.store.SaveFixMessage(session.ID, 1, []byte("first")))
+	suite.Require().NoError(suite.store.SaveFixMessage(session.ID, 2, []byte("second")))
+	suite.Require().NoError(suite.store.SetFixTargetSeqNum(session.ID, 5))
+
+	loaded, err := suite.store.GetFixSession("VLEX", "CLIENT")
+	suite.Require().NoError(err)
+	suite.Equal(session.ID, loaded.ID)
+	suite.Equal(int32(3), loaded.NextSenderSeqNum)
+	suite.Equal(int32(5), loaded.NextTargetSeqNum)
+
+	messages, err := suite.store.GetFixMessages(session.ID, 2, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(messages, 1)
+	suite.Equal([]byte("second"), messages[0].Message)
+
+	other, err := suite.store.GetFixSession("VLEX", "OTHER")
+	suite.Require().NoError(err)
+	suite.NotEqual(session.ID, other.ID)
+
+	suite.Require().NoError(suite.store.ResetFixSession(session.ID))
+	loaded, err = suite.store.GetFixSession("VLEX", "CLIENT")
+	suite.Require().NoError(err)
+	suite.Equal(int32(1), loaded.NextSenderSeqNum)
+	suite.Equal(int32(1), loaded.NextTargetSeqNum)
+	messages, err = suite.store.GetFixMessages(session.ID, 1, 10)
+	suite.Require().NoError(err)
+	suite.Empty(messages)
 }
 
 func TestStoreTestSuite(t *testing.T) {
@@ -5,6 +5,9 @@ package testqueries
 import (
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/galcik/vlexchange/internal/currency"
 )
 
 type OrderState string
@@ -47,23 +50,123 @@ func (e *OrderType) Scan(src interface{}) error {
 }
 
 type Account struct {
-	ID        int32
-	Username  string
-	Token     string
-	UsdAmount int64
-	BtcAmount int64
+	ID       int32
+	Username string
+	Token    string
+	Tier     string
+	Frozen   bool
+}
+
+type ApiKey struct {
+	ID         int32
+	AccountID  int32
+	Name       string
+	Prefix     string
+	SecretHash []byte
+	Scopes     []string
+	AllowedIps []string
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+type Asset struct {
+	Symbol    string
+	Precision int32
+}
+
+type Balance struct {
+	AccountID int32
+	Asset     string
+	Amount    int64
+}
+
+type BalanceJournal struct {
+	ID        int64
+	AccountID int32
+	Asset     string
+	Amount    int64
+	Reason    string
+	Operator  string
+	CreatedAt time.Time
+}
+
+type FixMessage struct {
+	SessionID int32
+	SeqNum    int32
+	Message   []byte
+	CreatedAt time.Time
+}
+
+type FixSession struct {
+	ID               int32
+	SenderCompID     string
+	TargetCompID     string
+	NextSenderSeqNum int32
+	NextTargetSeqNum int32
+}
+
+type IdempotencyKey struct {
+	AccountID      int32
+	Key            string
+	Endpoint       string
+	RequestHash    []byte
+	ResponseStatus int32
+	ContentType    string
+	ResponseBody   []byte
+	CreatedAt      time.Time
+}
+
+type Market struct {
+	ID         int32
+	Symbol     string
+	BaseAsset  string
+	QuoteAsset string
+}
+
+type PriceHistory struct {
+	ID       int64
+	Price    currency.USD
+	QuotedAt time.Time
+}
+
+type RequestNonce struct {
+	ApiKeyID  int32
+	Nonce     string
+	CreatedAt time.Time
 }
 
 type StandingOrder struct {
-	ID                int32
-	AccountID         int32
-	Type              OrderType
-	State             OrderState
-	Quantity          int64
-	FilledQuantity    int64
-	FilledPrice       int64
-	LimitPrice        int64
-	ReservedUsdAmount int64
-	ReservedBtcAmount int64
-	WebhookUrl        sql.NullString
+	ID                  int32
+	AccountID           int32
+	MarketID            int32
+	Type                OrderType
+	State               OrderState
+	Quantity            currency.Amount
+	FilledQuantity      currency.Amount
+	FilledPrice         currency.Amount
+	LimitPrice          currency.Amount
+	ReservedQuoteAmount currency.Amount
+	ReservedBaseAmount  currency.Amount
+	WebhookUrl          sql.NullString
+	ClientOrderID       sql.NullString
+	CreatedAt           time.Time
+}
+
+type Trade struct {
+	ID          int64
+	MarketID    int32
+	BuyOrderID  int32
+	SellOrderID int32
+	TakerSide   OrderType
+	Quantity    currency.Amount
+	Price       currency.Amount
+	CreatedAt   time.Time
+}
+
+type TradingStatus struct {
+	ID        bool
+	Halted    bool
+	Reason    string
+	UpdatedAt time.Time
 }
@@ -3,17 +3,25 @@ SELECT *
 FROM account;
 
 -- name: CreateAccount :one
-INSERT INTO account (username, token, usd_amount, btc_amount)
-VALUES ($1, $2, $3, $4)
+INSERT INTO account (username, token)
+VALUES ($1, $2)
 RETURNING *;
 
+-- name: SetBalance :exec
+INSERT INTO balance (account_id, asset, amount)
+VALUES ($1, $2, $3)
+ON CONFLICT (account_id, asset) DO UPDATE SET amount = excluded.amount;
+
+-- name: GetBalance :one
+SELECT COALESCE((SELECT amount FROM balance WHERE account_id = $1 AND asset = $2), 0)::bigint AS amount;
+
 -- name: GetStandingOrders :many
 SELECT *
 FROM standing_order;
 
 -- name: CreateStandingOrder :one
 INSERT INTO standing_order (account_id, type, state, quantity, filled_quantity, filled_price, limit_price,
-                            reserved_btc_amount, reserved_usd_amount,
+                            reserved_base_amount, reserved_quote_amount,
                             webhook_url)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
@@ -6,58 +6,53 @@ package testqueries
 import (
 	"context"
 	"database/sql"
+
+	"github.com/galcik/vlexchange/internal/currency"
 )
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO account (username, token, usd_amount, btc_amount)
-VALUES ($1, $2, $3, $4)
-RETURNING id, username, token, usd_amount, btc_amount
+INSERT INTO account (username, token)
+VALUES ($1, $2)
+RETURNING id, username, token, tier, frozen
 `
 
 type CreateAccountParams struct {
-	Username  string
-	Token     string
-	UsdAmount int64
-	BtcAmount int64
+	Username string
+	Token    string
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount,
-		arg.Username,
-		arg.Token,
-		arg.UsdAmount,
-		arg.BtcAmount,
-	)
+	row := q.db.QueryRowContext(ctx, createAccount, arg.Username, arg.Token)
 	var i Account
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
 		&i.Token,
-		&i.UsdAmount,
-		&i.BtcAmount,
+		&i.Tier,
+		&i.Frozen,
 	)
 	return i, err
 }
 
 const createStandingOrder = `-- name: CreateStandingOrder :one
 INSERT INTO standing_order (account_id, type, state, quantity, filled_quantity, filled_price, limit_price,
-                            reserved_btc_amount, reserved_usd_amount,
+                            reserved_base_amount, reserved_quote_amount,
                             webhook_url)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-RETURNING id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+RETURNING id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 `
 
 type CreateStandingOrderParams struct {
-	AccountID         int32
-	Type              OrderType
-	State             OrderState
-	Quantity          int64
-	FilledQuantity    int64
-	FilledPrice       int64
-	LimitPrice        int64
-	ReservedBtcAmount int64
-	ReservedUsdAmount int64
-	WebhookUrl        sql.NullString
+	AccountID           int32
+	Type                OrderType
+	State               OrderState
+	Quantity            currency.Amount
+	FilledQuantity      currency.Amount
+	FilledPrice         currency.Amount
+	LimitPrice          currency.Amount
+	ReservedBaseAmount  currency.Amount
+	ReservedQuoteAmount currency.Amount
+	WebhookUrl          sql.NullString
 }
 
 func (q *Queries) CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error) {
@@ -69,29 +64,32 @@ func (q *Queries) CreateStandingOrder(ctx context.Context, arg CreateStandingOrd
 		arg.FilledQuantity,
 		arg.FilledPrice,
 		arg.LimitPrice,
-		arg.ReservedBtcAmount,
-		arg.ReservedUsdAmount,
+		arg.ReservedBaseAmount,
+		arg.ReservedQuoteAmount,
 		arg.WebhookUrl,
 	)
 	var i StandingOrder
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
+		&i.MarketID,
 		&i.Type,
 		&i.State,
 		&i.Quantity,
 		&i.FilledQuantity,
 		&i.FilledPrice,
 		&i.LimitPrice,
-		&i.ReservedUsdAmount,
-		&i.ReservedBtcAmount,
+		&i.ReservedQuoteAmount,
+		&i.ReservedBaseAmount,
 		&i.WebhookUrl,
+		&i.ClientOrderID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getAccounts = `-- name: GetAccounts :many
-SELECT id, username, token, usd_amount, btc_amount
+SELECT id, username, token, tier, frozen
 FROM account
 `
 
@@ -108,8 +106,8 @@ func (q *Queries) GetAccounts(ctx context.Context) ([]Account, error) {
 			&i.ID,
 			&i.Username,
 			&i.Token,
-			&i.UsdAmount,
-			&i.BtcAmount,
+			&i.Tier,
+			&i.Frozen,
 		); err != nil {
 			return nil, err
 		}
@@ -124,8 +122,24 @@ func (q *Queries) GetAccounts(ctx context.Context) ([]Account, error) {
 	return items, nil
 }
 
+const getBalance = `-- name: GetBalance :one
+SELECT COALESCE((SELECT amount FROM balance WHERE account_id = $1 AND asset = $2), 0)::bigint AS amount
+`
+
+type GetBalanceParams struct {
+	AccountID int32
+	Asset     string
+}
+
+func (q *Queries) GetBalance(ctx context.Context, arg GetBalanceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getBalance, arg.AccountID, arg.Asset)
+	var amount int64
+	err := row.Scan(&amount)
+	return amount, err
+}
+
 const getStandingOrders = `-- name: GetStandingOrders :many
-SELECT id, account_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_usd_amount, reserved_btc_amount, webhook_url
+SELECT id, account_id, market_id, type, state, quantity, filled_quantity, filled_price, limit_price, reserved_quote_amount, reserved_base_amount, webhook_url, client_order_id, created_at
 FROM standing_order
 `
 
@@ -141,15 +155,18 @@ func (q *Queries) GetStandingOrders(ctx context.Context) ([]StandingOrder, error
 		if err := rows.Scan(
 			&i.ID,
 			&i.AccountID,
+			&i.MarketID,
 			&i.Type,
 			&i.State,
 			&i.Quantity,
 			&i.FilledQuantity,
 			&i.FilledPrice,
 			&i.LimitPrice,
-			&i.ReservedUsdAmount,
-			&i.ReservedBtcAmount,
+			&i.ReservedQuoteAmount,
+			&i.ReservedBaseAmount,
 			&i.WebhookUrl,
+			&i.ClientOrderID,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -163,3 +180,20 @@ func (q *Queries) GetStandingOrders(ctx context.Context) ([]StandingOrder, error
 	}
 	return items, nil
 }
+
+const setBalance = `-- name: SetBalance :exec
+INSERT INTO balance (account_id, asset, amount)
+VALUES ($1, $2, $3)
+ON CONFLICT (account_id, asset) DO UPDATE SET amount = excluded.amount
+`
+
+type SetBalanceParams struct {
+	AccountID int32
+	Asset     string
+	Amount    int64
+}
+
+func (q *Queries) SetBalance(ctx context.Context, arg SetBalanceParams) error {
+	_, err := q.db.ExecContext(ctx, setBalance, arg.AccountID, arg.Asset, arg.Amount)
+	return err
+}
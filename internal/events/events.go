@@ -0,0 +1,111 @@
+// Package events fans out order changes and trades to subscribers of the
+// running server.
+package events
+
+import (
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"sync"
+)
+
+// BufferSize is the number of events a subscriber may fall behind. Slower
+// subscribers are dropped instead of blocking the publisher.
+const BufferSize = 256
+
+// OrderSubscription receives changes of the orders of one account. C is closed
+// when the subscription is cancelled or dropped.
+type OrderSubscription struct {
+	C         <-chan queries.StandingOrder
+	c         chan queries.StandingOrder
+	accountId int32
+}
+
+// TradeSubscription receives all trades. C is closed when the subscription is
+// cancelled or dropped.
+type TradeSubscription struct {
+	C <-chan queries.Trade
+	c chan queries.Trade
+}
+
+type Broker struct {
+	mutex              sync.Mutex
+	orderSubscriptions map[*OrderSubscription]struct{}
+	tradeSubscriptions map[*TradeSubscription]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		orderSubscriptions: make(map[*OrderSubscription]struct{}),
+		tradeSubscriptions: make(map[*TradeSubscription]struct{}),
+	}
+}
+
+func (broker *Broker) SubscribeOrders(accountId int32) *OrderSubscription {
+	c := make(chan queries.StandingOrder, BufferSize)
+	subscription := &OrderSubscription{C: c, c: c, accountId: accountId}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.orderSubscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+func (broker *Broker) SubscribeTrades() *TradeSubscription {
+	c := make(chan queries.Trade, BufferSize)
+	subscription := &TradeSubscription{C: c, c: c}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.tradeSubscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// UnsubscribeOrders cancels the subscription, it is safe to call it for
+// dropped subscriptions.
+func (broker *Broker) UnsubscribeOrders(subscription *OrderSubscription) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if _, ok := broker.orderSubscriptions[subscription]; ok {
+		delete(broker.orderSubscriptions, subscription)
+		close(subscription.c)
+	}
+}
+
+// UnsubscribeTrades cancels the subscription, it is safe to call it for
+// dropped subscriptions.
+func (broker *Broker) UnsubscribeTrades(subscription *TradeSubscription) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if _, ok := broker.tradeSubscriptions[subscription]; ok {
+		delete(broker.tradeSubscriptions, subscription)
+		close(subscription.c)
+	}
+}
+
+func (broker *Broker) PublishOrder(order queries.StandingOrder) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	for subscription := range broker.orderSubscriptions {
+		if subscription.accountId != order.AccountID {
+			continue
+		}
+		select {
+		case subscription.c <- order:
+		default:
+			delete(broker.orderSubscriptions, subscription)
+			close(subscription.c)
+		}
+	}
+}
+
+func (broker *Broker) PublishTrade(trade queries.Trade) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	for subscription := range broker.tradeSubscriptions {
+		select {
+		case subscription.c <- trade:
+		default:
+			delete(broker.tradeSubscriptions, subscription)
+			close(subscription.c)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOrdersOfAccount(t *testing.T) {
+	broker := NewBroker()
+	subscription := broker.SubscribeOrders(1)
+	other := broker.SubscribeOrders(2)
+
+	broker.PublishOrder(queries.StandingOrder{ID: 10, AccountID: 1})
+	broker.PublishOrder(queries.StandingOrder{ID: 11, AccountID: 2})
+
+	require.Equal(t, 1, len(subscription.C))
+	assert.Equal(t, int32(10), (<-subscription.C).ID)
+	require.Equal(t, 1, len(other.C))
+	assert.Equal(t, int32(11), (<-other.C).ID)
+
+	broker.UnsubscribeOrders(subscription)
+	_, ok := <-subscription.C
+	assert.False(t, ok)
+	broker.UnsubscribeOrders(subscription)
+	broker.PublishOrder(queries.StandingOrder{ID: 12, AccountID: 1})
+}
+
+func TestTrades(t *testing.T) {
+	broker := NewBroker()
+	first := broker.SubscribeTrades()
+	second := broker.SubscribeTrades()
+
+	broker.PublishTrade(queries.Trade{ID: 1})
+	assert.Equal(t, int64(1), (<-first.C).ID)
+	assert.Equal(t, int64(1), (<-second.C).ID)
+
+	broker.UnsubscribeTrades(first)
+	broker.PublishTrade(queries.Trade{ID: 2})
+	_, ok := <-first.C
+	assert.False(t, ok)
+	assert.Equal(t, int64(2), (<-second.C).ID)
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	broker := NewBroker()
+	slow := broker.SubscribeTrades()
+
+	for i := 0; i <= BufferSize; i++ {
+		broker.PublishTrade(queries.Trade{ID: int64(i)})
+	}
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	assert.Equal(t, BufferSize, received)
+	broker.UnsubscribeTrades(slow)
+}
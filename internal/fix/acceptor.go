@@ -0,0 +1,200 @@
+package fix
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+const DefaultLogonTimeout = 10 * time.Second
+
+// Acceptor accepts FIX sessions of initiators addressing CompID. Only one
+// session per initiator may be logged on at a time.
+type Acceptor struct {
+	CompID       string
+	LogonTimeout time.Duration
+
+	store Store
+	app   Application
+
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	sessions  map[string]net.Conn
+}
+
+func NewAcceptor(compID string, store Store, app Application) *Acceptor {
+	return &Acceptor{
+		CompID:       compID,
+		LogonTimeout: DefaultLogonTimeout,
+		store:        store,
+		app:          app,
+		listeners:    make(map[net.Listener]struct{}),
+		sessions:     make(map[string]net.Conn),
+	}
+}
+
+func (acceptor *Acceptor) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return acceptor.Serve(listener)
+}
+
+// Serve accepts connections until the listener is closed.
+func (acceptor *Acceptor) Serve(listener net.Listener) error {
+	acceptor.mutex.Lock()
+	acceptor.listeners[listener] = struct{}{}
+	acceptor.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			acceptor.mutex.Lock()
+			delete(acceptor.listeners, listener)
+			acceptor.mutex.Unlock()
+			return err
+		}
+		go acceptor.handle(conn)
+	}
+}
+
+// Close stops the listeners and disconnects the sessions.
+func (acceptor *Acceptor) Close() {
+	acceptor.mutex.Lock()
+	defer acceptor.mutex.Unlock()
+	for listener := range acceptor.listeners {
+		listener.Close()
+	}
+	for _, conn := range acceptor.sessions {
+		conn.Close()
+	}
+}
+
+func (acceptor *Acceptor) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(acceptor.LogonTimeout))
+	data, err := ReadMessage(reader)
+	if err != nil {
+		return
+	}
+	logon, err := Parse(data)
+	if err != nil || logon.Type() != MsgTypeLogon || logon.Get(TagTargetCompID) != acceptor.CompID {
+		log.Printf("fix: connection from %s did not log on", conn.RemoteAddr())
+		return
+	}
+
+	targetCompID := logon.Get(TagSenderCompID)
+	heartBtInt, err := logon.GetInt(TagHeartBtInt)
+	if err != nil || heartBtInt <= 0 {
+		log.Printf("fix: logon of %s without HeartBtInt", targetCompID)
+		return
+	}
+	seqNum, err := logon.GetInt(TagMsgSeqNum)
+	if err != nil {
+		return
+	}
+
+	if !acceptor.register(targetCompID, conn) {
+		log.Printf("fix: %s is logged on already", targetCompID)
+		return
+	}
+	defer acceptor.unregister(targetCompID, conn)
+
+	session, err := acceptor.openSession(targetCompID, logon.Get(TagResetSeqNumFlag) == "Y")
+	if err != nil {
+		log.Printf("fix: session of %s failed: %v", targetCompID, err)
+		return
+	}
+	session.conn = conn
+	session.reader = reader
+	session.HeartBtInt = time.Duration(heartBtInt) * time.Second
+
+	handler, err := acceptor.app.Logon(session, logon)
+	if err != nil {
+		session.logout(err.Error())
+		return
+	}
+	defer handler.OnLogout()
+
+	if seqNum < session.nextTargetSeqNum {
+		session.logout(fmt.Sprintf("MsgSeqNum too low, expecting %d but received %d", session.nextTargetSeqNum, seqNum))
+		return
+	}
+
+	response := NewMessage(MsgTypeLogon).Set(TagEncryptMethod, "0").SetInt(TagHeartBtInt, heartBtInt)
+	if logon.Get(TagResetSeqNumFlag) == "Y" {
+		response.Set(TagResetSeqNumFlag, "Y")
+	}
+	if err := session.Send(response); err != nil {
+		log.Printf("fix: session of %s failed: %v", targetCompID, err)
+		return
+	}
+
+	if seqNum > session.nextTargetSeqNum {
+		session.resendUntil = seqNum
+		resendRequest := NewMessage(MsgTypeResendRequest).
+			SetInt(TagBeginSeqNo, session.nextTargetSeqNum).
+			SetInt(TagEndSeqNo, 0)
+		if err := session.Send(resendRequest); err != nil {
+			return
+		}
+	} else if !session.setNextTargetSeqNum(seqNum + 1) {
+		return
+	}
+
+	handler.OnLogon()
+	session.run(handler)
+
+	// the initiator may log on again as soon as it receives the response
+	acceptor.unregister(targetCompID, conn)
+	if session.logoutReceived {
+		session.logout("")
+	}
+}
+
+func (acceptor *Acceptor) openSession(targetCompID string, reset bool) (*Session, error) {
+	record, err := acceptor.store.GetFixSession(acceptor.CompID, targetCompID)
+	if err != nil {
+		return nil, err
+	}
+	if reset {
+		if err := acceptor.store.ResetFixSession(record.ID); err != nil {
+			return nil, err
+		}
+		record.NextSenderSeqNum = 1
+		record.NextTargetSeqNum = 1
+	}
+
+	return &Session{
+		SenderCompID:     acceptor.CompID,
+		TargetCompID:     targetCompID,
+		id:               record.ID,
+		store:            acceptor.store,
+		nextSenderSeqNum: int(record.NextSenderSeqNum),
+		nextTargetSeqNum: int(record.NextTargetSeqNum),
+	}, nil
+}
+
+func (acceptor *Acceptor) register(targetCompID string, conn net.Conn) bool {
+	acceptor.mutex.Lock()
+	defer acceptor.mutex.Unlock()
+	if _, ok := acceptor.sessions[targetCompID]; ok {
+		return false
+	}
+	acceptor.sessions[targetCompID] = conn
+	return true
+}
+
+func (acceptor *Acceptor) unregister(targetCompID string, conn net.Conn) {
+	acceptor.mutex.Lock()
+	defer acceptor.mutex.Unlock()
+	if acceptor.sessions[targetCompID] == conn {
+		delete(acceptor.sessions, targetCompID)
+	}
+}
@@ -0,0 +1,288 @@
+package fix
+
+import (
+	"errors"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"github.com/stretchr/testify/suite"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+const receiveTimeout = 5 * time.Second
+
+// memoryStore keeps the sessions in memory instead of the database.
+type memoryStore struct {
+	mutex    sync.Mutex
+	sessions []queries.FixSession
+	messages map[int32]map[int32][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{messages: make(map[int32]map[int32][]byte)}
+}
+
+func (store *memoryStore) GetFixSession(senderCompId string, targetCompId string) (*queries.FixSession, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	for _, session := range store.sessions {
+		if session.SenderCompID == senderCompId && session.TargetCompID == targetCompId {
+			return &session, nil
+		}
+	}
+	session := queries.FixSession{
+		ID:               int32(len(store.sessions) + 1),
+		SenderCompID:     senderCompId,
+		TargetCompID:     targetCompId,
+		NextSenderSeqNum: 1,
+		NextTargetSeqNum: 1,
+	}
+	store.sessions = append(store.sessions, session)
+	store.messages[session.ID] = make(map[int32][]byte)
+	return &session, nil
+}
+
+func (store *memoryStore) SaveFixMessage(sessionId int32, seqNum int32, message []byte) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.messages[sessionId][seqNum] = message
+	store.sessions[sessionId-1].NextSenderSeqNum = seqNum + 1
+	return nil
+}
+
+func (store *memoryStore) GetFixMessages(sessionId int32, fromSeqNum int32, toSeqNum int32) (
+	[]queries.FixMessage,
+	error,
+) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	var messages []queries.FixMessage
+	for seqNum, message := range store.messages[sessionId] {
+		if seqNum >= fromSeqNum && seqNum <= toSeqNum {
+			messages = append(messages, queries.FixMessage{SessionID: sessionId, SeqNum: seqNum, Message: message})
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].SeqNum < messages[j].SeqNum })
+	return messages, nil
+}
+
+func (store *memoryStore) SetFixTargetSeqNum(sessionId int32, seqNum int32) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.sessions[sessionId-1].NextTargetSeqNum = seqNum
+	return nil
+}
+
+func (store *memoryStore) ResetFixSession(sessionId int32) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.sessions[sessionId-1].NextSenderSeqNum = 1
+	store.sessions[sessionId-1].NextTargetSeqNum = 1
+	store.messages[sessionId] = make(map[int32][]byte)
+	return nil
+}
+
+// echoApplication acknowledges every NewOrderSingle by an ExecutionReport.
+type echoApplication struct{}
+
+type echoHandler struct {
+	session *Session
+}
+
+func (echoApplication) Logon(session *Session, logon *Message) (Handler, error) {
+	if logon.Get(TagPassword) != "secret" {
+		return nil, errors.New("invalid password")
+	}
+	return &echoHandler{session: session}, nil
+}
+
+func (handler *echoHandler) OnLogon() {}
+
+func (handler *echoHandler) OnLogout() {}
+
+func (handler *echoHandler) FromApp(message *Message) error {
+	if message.Type() != MsgTypeNewOrderSingle {
+		return ErrUnsupportedMessageType
+	}
+	return handler.session.Send(
+		NewMessage(MsgTypeExecutionReport).
+			Set(TagClOrdID, message.Get(TagClOrdID)).
+			Set(TagExecType, ExecTypeNew),
+	)
+}
+
+type acceptorTestSuite struct {
+	suite.Suite
+	store    *memoryStore
+	acceptor *Acceptor
+	addr     string
+}
+
+func (suite *acceptorTestSuite) SetupTest() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	suite.addr = listener.Addr().String()
+	suite.store = newMemoryStore()
+	suite.acceptor = NewAcceptor("VLEX", suite.store, echoApplication{})
+	go suite.acceptor.Serve(listener)
+}
+
+func (suite *acceptorTestSuite) TearDownTest() {
+	suite.acceptor.Close()
+}
+
+func (suite *acceptorTestSuite) dial() *Initiator {
+	initiator, err := Dial(suite.addr, "CLIENT", "VLEX")
+	suite.Require().NoError(err)
+	return initiator
+}
+
+func (suite *acceptorTestSuite) logon(initiator *Initiator, resetSeqNum bool) *Message {
+	response, err := initiator.Logon("secret", 30, resetSeqNum)
+	suite.Require().NoError(err)
+	return response
+}
+
+func (suite *acceptorTestSuite) receive(initiator *Initiator, msgType string) *Message {
+	message, err := initiator.Receive(receiveTimeout)
+	suite.Require().NoError(err)
+	suite.Require().Equal(msgType, message.Type(), message.String())
+	return message
+}
+
+func (suite *acceptorTestSuite) sendOrder(initiator *Initiator, clOrdID string) {
+	suite.Require().NoError(initiator.Send(NewMessage(MsgTypeNewOrderSingle).Set(TagClOrdID, clOrdID)))
+}
+
+func (suite *acceptorTestSuite) TestLogon() {
+	initiator := suite.dial()
+	defer initiator.Close()
+	response, err := initiator.Logon("wrong", 30, false)
+	suite.Error(err)
+	suite.Equal(MsgTypeLogout, response.Type())
+	suite.Equal("invalid password", response.Get(TagText))
+
+	initiator = suite.dial()
+	defer initiator.Close()
+	response = suite.logon(initiator, true)
+	suite.Equal("30", response.Get(TagHeartBtInt))
+	suite.Equal("Y", response.Get(TagResetSeqNumFlag))
+	suite.Equal("1", response.Get(TagMsgSeqNum))
+	suite.Equal("VLEX", response.Get(TagSenderCompID))
+
+	// a second session of the same initiator is refused
+	second := suite.dial()
+	defer second.Close()
+	_, err = second.Logon("secret", 30, false)
+	suite.Error(err)
+
+	suite.Require().NoError(initiator.Send(NewMessage(MsgTypeTestRequest).Set(TagTestReqID, "ping")))
+	heartbeat := suite.receive(initiator, MsgTypeHeartbeat)
+	suite.Equal("ping", heartbeat.Get(TagTestReqID))
+
+	suite.sendOrder(initiator, "order-1")
+	report := suite.receive(initiator, MsgTypeExecutionReport)
+	suite.Equal("order-1", report.Get(TagClOrdID))
+
+	suite.Require().NoError(initiator.Send(NewMessage(MsgTypeOrderCancelRequest)))
+	reject := suite.receive(initiator, MsgTypeBusinessMessageReject)
+	suite.Equal(BusinessRejectReasonUnsupported, reject.Get(TagBusinessRejectReason))
+	suite.Equal(MsgTypeOrderCancelRequest, reject.Get(TagRefMsgType))
+
+	suite.Require().NoError(initiator.Send(NewMessage(MsgTypeLogout)))
+	suite.receive(initiator, MsgTypeLogout)
+}
+
+func (suite *acceptorTestSuite) TestSequenceNumbersArePersisted() {
+	initiator := suite.dial()
+	suite.logon(initiator, true)
+	suite.sendOrder(initiator, "order-1")
+	suite.receive(initiator, MsgTypeExecutionReport)
+	suite.Require().NoError(initiator.Send(NewMessage(MsgTypeLogout)))
+	logout := suite.receive(initiator, MsgTypeLogout)
+	suite.Equal("3", logout.Get(TagMsgSeqNum))
+	initiator.Close()
+
+	reconnected := suite.dial()
+	defer reconnected.Close()
+	reconnected.NextSeqNum = initiator.NextSeqNum
+	response := suite.logon(reconnected, false)
+	suite.Equal("4", response.Get(TagMsgSeqNum))
+
+	// messages below the expected sequence number end the session
+	reconnected.NextSeqNum = 2
+	suite.sendOrder(reconnected, "order-2")
+	logout = suite.receive(reconnected, MsgTypeLogout)
+	suite.Contains(logout.Get(TagText), "MsgSeqNum too low, expecting 5")
+}
+
+func (suite *acceptorTestSuite) TestResendRequest() {
+	initiator := suite.dial()
+	defer initiator.Close()
+	suite.logon(initiator, true)
+	suite.sendOrder(initiator, "order-1")
+	suite.receive(initiator, MsgTypeExecutionReport)
+	suite.sendOrder(initiator, "order-2")
+	suite.receive(initiator, MsgTypeExecutionReport)
+
+	suite.Require().NoError(initiator.Send(
+		NewMessage(MsgTypeResendRequest).SetInt(TagBeginSeqNo, 1).SetInt(TagEndSeqNo, 0),
+	))
+
+	// the Logon is replaced by a gap fill, the reports are sent again
+	gapFill := suite.receive(initiator, MsgTypeSequenceReset)
+	suite.Equal("1", gapFill.Get(TagMsgSeqNum))
+	suite.Equal("Y", gapFill.Get(TagGapFillFlag))
+	suite.Equal("2", gapFill.Get(TagNewSeqNo))
+
+	for i, clOrdID := range []string{"order-1", "order-2"} {
+		report := suite.receive(initiator, MsgTypeExecutionReport)
+		suite.Equal(clOrdID, report.Get(TagClOrdID))
+		suite.Equal(i+2, mustInt(report, TagMsgSeqNum))
+		suite.Equal("Y", report.Get(TagPossDupFlag))
+		suite.NotEmpty(report.Get(TagOrigSendingTime))
+	}
+
+	// new messages continue the sequence
+	suite.sendOrder(initiator, "order-3")
+	report := suite.receive(initiator, MsgTypeExecutionReport)
+	suite.Equal(4, mustInt(report, TagMsgSeqNum))
+}
+
+func (suite *acceptorTestSuite) TestSequenceGap() {
+	initiator := suite.dial()
+	defer initiator.Close()
+	suite.logon(initiator, true)
+
+	initiator.NextSeqNum = 4
+	suite.sendOrder(initiator, "order-4")
+	resendRequest := suite.receive(initiator, MsgTypeResendRequest)
+	suite.Equal("2", resendRequest.Get(TagBeginSeqNo))
+	suite.Equal("0", resendRequest.Get(TagEndSeqNo))
+
+	// the missing messages are skipped and the order is sent again
+	initiator.NextSeqNum = 2
+	suite.Require().NoError(initiator.Send(
+		NewMessage(MsgTypeSequenceReset).Set(TagGapFillFlag, "Y").SetInt(TagNewSeqNo, 4).Set(TagPossDupFlag, "Y"),
+	))
+	initiator.NextSeqNum = 4
+	suite.Require().NoError(initiator.Send(
+		NewMessage(MsgTypeNewOrderSingle).Set(TagClOrdID, "order-4").Set(TagPossDupFlag, "Y"),
+	))
+	report := suite.receive(initiator, MsgTypeExecutionReport)
+	suite.Equal("order-4", report.Get(TagClOrdID))
+}
+
+func mustInt(message *Message, tag int) int {
+	value, err := message.GetInt(tag)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func TestAcceptor(t *testing.T) {
+	suite.Run(t, new(acceptorTestSuite))
+}
@@ -0,0 +1,119 @@
+package fix
+
+// Tags of the session and order entry messages of FIX 4.4.
+const (
+	TagAvgPx                = 6
+	TagBeginSeqNo           = 7
+	TagBeginString          = 8
+	TagBodyLength           = 9
+	TagCheckSum             = 10
+	TagClOrdID              = 11
+	TagCumQty               = 14
+	TagEndSeqNo             = 16
+	TagExecID               = 17
+	TagLastPx               = 31
+	TagLastQty              = 32
+	TagMsgSeqNum            = 34
+	TagMsgType              = 35
+	TagNewSeqNo             = 36
+	TagOrderID              = 37
+	TagOrderQty             = 38
+	TagOrdStatus            = 39
+	TagOrdType              = 40
+	TagOrigClOrdID          = 41
+	TagPossDupFlag          = 43
+	TagPrice                = 44
+	TagRefSeqNum            = 45
+	TagSenderCompID         = 49
+	TagSendingTime          = 52
+	TagSide                 = 54
+	TagSymbol               = 55
+	TagTargetCompID         = 56
+	TagText                 = 58
+	TagTransactTime         = 60
+	TagEncryptMethod        = 98
+	TagCxlRejReason         = 102
+	TagOrdRejReason         = 103
+	TagHeartBtInt           = 108
+	TagTestReqID            = 112
+	TagOrigSendingTime      = 122
+	TagGapFillFlag          = 123
+	TagResetSeqNumFlag      = 141
+	TagExecType             = 150
+	TagLeavesQty            = 151
+	TagRefTagID             = 371
+	TagRefMsgType           = 372
+	TagSessionRejectReason  = 373
+	TagBusinessRejectReason = 380
+	TagCxlRejResponseTo     = 434
+	TagUsername             = 553
+	TagPassword             = 554
+)
+
+// Message types.
+const (
+	MsgTypeHeartbeat                 = "0"
+	MsgTypeTestRequest               = "1"
+	MsgTypeResendRequest             = "2"
+	MsgTypeReject                    = "3"
+	MsgTypeSequenceReset             = "4"
+	MsgTypeLogout                    = "5"
+	MsgTypeExecutionReport           = "8"
+	MsgTypeOrderCancelReject         = "9"
+	MsgTypeLogon                     = "A"
+	MsgTypeNewOrderSingle            = "D"
+	MsgTypeOrderCancelRequest        = "F"
+	MsgTypeOrderCancelReplaceRequest = "G"
+	MsgTypeBusinessMessageReject     = "j"
+)
+
+// isAdminMessage tells the session level messages, they are not resent but
+// replaced by gap fills.
+func isAdminMessage(msgType string) bool {
+	switch msgType {
+	case MsgTypeHeartbeat, MsgTypeTestRequest, MsgTypeResendRequest, MsgTypeReject, MsgTypeSequenceReset,
+		MsgTypeLogout, MsgTypeLogon:
+		return true
+	}
+	return false
+}
+
+// Values of the order entry fields.
+const (
+	SideBuy  = "1"
+	SideSell = "2"
+
+	OrdTypeLimit = "2"
+
+	ExecTypeNew      = "0"
+	ExecTypeCanceled = "4"
+	ExecTypeReplaced = "5"
+	ExecTypeRejected = "8"
+	ExecTypeTrade    = "F"
+
+	OrdStatusNew             = "0"
+	OrdStatusPartiallyFilled = "1"
+	OrdStatusFilled          = "2"
+	OrdStatusCanceled        = "4"
+	OrdStatusRejected        = "8"
+
+	OrdRejReasonUnknownSymbol    = "1"
+	OrdRejReasonExchangeClosed   = "2"
+	OrdRejReasonExceedsLimit     = "3"
+	OrdRejReasonDuplicateOrder   = "6"
+	OrdRejReasonUnsupportedOrder = "11"
+	OrdRejReasonOther            = "99"
+
+	CxlRejReasonTooLate      = "0"
+	CxlRejReasonUnknownOrder = "1"
+	CxlRejReasonOther        = "99"
+
+	CxlRejResponseToCancel        = "1"
+	CxlRejResponseToCancelReplace = "2"
+
+	BusinessRejectReasonOther       = "0"
+	BusinessRejectReasonUnsupported = "3"
+)
+
+// SendingTime and TransactTime use UTC timestamps with milliseconds.
+const TimestampFormat = "20060102-15:04:05.000"
@@ -0,0 +1,94 @@
+package fix
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Initiator is a minimal FIX client keeping its sequence numbers in memory. It
+// neither answers test requests nor serves resend requests and is meant for
+// tests and tools.
+type Initiator struct {
+	SenderCompID string
+	TargetCompID string
+	// NextSeqNum is the sequence number of the next sent message.
+	NextSeqNum int
+
+	conn   net.Conn
+	reader *bufio.Reader
+	mutex  sync.Mutex
+}
+
+func Dial(addr string, senderCompID string, targetCompID string) (*Initiator, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewInitiator(conn, senderCompID, targetCompID), nil
+}
+
+func NewInitiator(conn net.Conn, senderCompID string, targetCompID string) *Initiator {
+	return &Initiator{
+		SenderCompID: senderCompID,
+		TargetCompID: targetCompID,
+		NextSeqNum:   1,
+		conn:         conn,
+		reader:       bufio.NewReader(conn),
+	}
+}
+
+func (initiator *Initiator) Send(message *Message) error {
+	initiator.mutex.Lock()
+	defer initiator.mutex.Unlock()
+
+	message.Set(TagSenderCompID, initiator.SenderCompID)
+	message.Set(TagTargetCompID, initiator.TargetCompID)
+	message.SetInt(TagMsgSeqNum, initiator.NextSeqNum)
+	message.Set(TagSendingTime, time.Now().UTC().Format(TimestampFormat))
+	if _, err := initiator.conn.Write(message.Bytes()); err != nil {
+		return err
+	}
+	initiator.NextSeqNum++
+	return nil
+}
+
+// Receive waits for the next message at most for the timeout.
+func (initiator *Initiator) Receive(timeout time.Duration) (*Message, error) {
+	initiator.conn.SetReadDeadline(time.Now().Add(timeout))
+	data, err := ReadMessage(initiator.reader)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Logon authenticates by the password and waits for the Logon response.
+func (initiator *Initiator) Logon(password string, heartBtInt int, resetSeqNum bool) (*Message, error) {
+	logon := NewMessage(MsgTypeLogon).
+		Set(TagEncryptMethod, "0").
+		SetInt(TagHeartBtInt, heartBtInt).
+		Set(TagPassword, password)
+	if resetSeqNum {
+		logon.Set(TagResetSeqNumFlag, "Y")
+		initiator.NextSeqNum = 1
+	}
+	if err := initiator.Send(logon); err != nil {
+		return nil, err
+	}
+
+	response, err := initiator.Receive(DefaultLogonTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if response.Type() != MsgTypeLogon {
+		return response, fmt.Errorf("logon rejected: %s", response.Get(TagText))
+	}
+	return response, nil
+}
+
+func (initiator *Initiator) Close() error {
+	return initiator.conn.Close()
+}
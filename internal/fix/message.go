@@ -0,0 +1,229 @@
+// Package fix implements the session layer of FIX 4.4 over TCP. The acceptor
+// keeps the sequence numbers and the sent messages of its sessions in a store so
+// that resend requests can be served across reconnects.
+package fix
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const BeginString = "FIX.4.4"
+
+const soh = '\x01'
+
+const maxBodyLength = 1 << 16
+
+// ErrGarbled is returned for messages which cannot be framed or fail the
+// BodyLength or CheckSum validation.
+var ErrGarbled = errors.New("garbled message")
+
+type Field struct {
+	Tag   int
+	Value string
+}
+
+// Message holds the fields of a message in their order. BeginString,
+// BodyLength and CheckSum are added by Bytes and are not part of the fields.
+type Message struct {
+	Fields []Field
+}
+
+// headerTags are written first, in this order, regardless of when they were set.
+var headerTags = []int{
+	TagMsgType, TagSenderCompID, TagTargetCompID, TagMsgSeqNum, TagPossDupFlag, TagSendingTime, TagOrigSendingTime,
+}
+
+func NewMessage(msgType string) *Message {
+	return &Message{Fields: []Field{{Tag: TagMsgType, Value: msgType}}}
+}
+
+func (message *Message) Type() string {
+	return message.Get(TagMsgType)
+}
+
+func (message *Message) Lookup(tag int) (string, bool) {
+	for _, field := range message.Fields {
+		if field.Tag == tag {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the value of the tag, or an empty string when it is missing.
+func (message *Message) Get(tag int) string {
+	value, _ := message.Lookup(tag)
+	return value
+}
+
+func (message *Message) GetInt(tag int) (int, error) {
+	value, ok := message.Lookup(tag)
+	if !ok {
+		return 0, fmt.Errorf("missing tag %d", tag)
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("malformed tag %d", tag)
+	}
+	return result, nil
+}
+
+// Set replaces the value of the tag or appends the field.
+func (message *Message) Set(tag int, value string) *Message {
+	for i := range message.Fields {
+		if message.Fields[i].Tag == tag {
+			message.Fields[i].Value = value
+			return message
+		}
+	}
+	message.Fields = append(message.Fields, Field{Tag: tag, Value: value})
+	return message
+}
+
+func (message *Message) SetInt(tag int, value int) *Message {
+	return message.Set(tag, strconv.Itoa(value))
+}
+
+// Bytes encodes the message with the header fields first.
+func (message *Message) Bytes() []byte {
+	var body bytes.Buffer
+	writeField := func(tag int, value string) {
+		body.WriteString(strconv.Itoa(tag))
+		body.WriteByte('=')
+		body.WriteString(value)
+		body.WriteByte(soh)
+	}
+
+	for _, tag := range headerTags {
+		if value, ok := message.Lookup(tag); ok {
+			writeField(tag, value)
+		}
+	}
+	for _, field := range message.Fields {
+		if !isHeaderTag(field.Tag) {
+			writeField(field.Tag, field.Value)
+		}
+	}
+
+	var result bytes.Buffer
+	result.WriteString("8=" + BeginString + string(soh))
+	result.WriteString("9=" + strconv.Itoa(body.Len()) + string(soh))
+	result.Write(body.Bytes())
+	result.WriteString(fmt.Sprintf("10=%03d%c", checksum(result.Bytes()), soh))
+	return result.Bytes()
+}
+
+func (message *Message) String() string {
+	return string(bytes.ReplaceAll(message.Bytes(), []byte{soh}, []byte{'|'}))
+}
+
+func isHeaderTag(tag int) bool {
+	for _, headerTag := range headerTags {
+		if tag == headerTag {
+			return true
+		}
+	}
+	return tag == TagBeginString || tag == TagBodyLength || tag == TagCheckSum
+}
+
+func checksum(data []byte) int {
+	sum := 0
+	for _, b := range data {
+		sum += int(b)
+	}
+	return sum % 256
+}
+
+// Parse decodes a complete message and validates its BodyLength and CheckSum.
+func Parse(data []byte) (*Message, error) {
+	fields := bytes.Split(data, []byte{soh})
+	if len(fields) < 4 || len(fields[len(fields)-1]) != 0 {
+		return nil, ErrGarbled
+	}
+	fields = fields[:len(fields)-1]
+
+	if string(fields[0]) != "8="+BeginString || !bytes.HasPrefix(fields[1], []byte("9=")) {
+		return nil, ErrGarbled
+	}
+	bodyLength, err := strconv.Atoi(string(fields[1][2:]))
+	if err != nil {
+		return nil, ErrGarbled
+	}
+	bodyStart := len(fields[0]) + len(fields[1]) + 2
+	trailerStart := len(data) - len(fields[len(fields)-1]) - 1
+	if trailerStart-bodyStart != bodyLength {
+		return nil, ErrGarbled
+	}
+
+	trailer := fields[len(fields)-1]
+	if len(trailer) != 6 || !bytes.HasPrefix(trailer, []byte("10=")) {
+		return nil, ErrGarbled
+	}
+	sum, err := strconv.Atoi(string(trailer[3:]))
+	if err != nil || sum != checksum(data[:trailerStart]) {
+		return nil, ErrGarbled
+	}
+
+	message := &Message{}
+	for _, field := range fields[2 : len(fields)-1] {
+		separator := bytes.IndexByte(field, '=')
+		if separator <= 0 {
+			return nil, ErrGarbled
+		}
+		tag, err := strconv.Atoi(string(field[:separator]))
+		if err != nil {
+			return nil, ErrGarbled
+		}
+		message.Fields = append(message.Fields, Field{Tag: tag, Value: string(field[separator+1:])})
+	}
+
+	if len(message.Fields) == 0 || message.Fields[0].Tag != TagMsgType {
+		return nil, ErrGarbled
+	}
+	return message, nil
+}
+
+// ReadMessage reads the raw bytes of the next message, framed by its
+// BodyLength. The content is validated by Parse.
+func ReadMessage(reader *bufio.Reader) ([]byte, error) {
+	beginString, err := reader.ReadBytes(soh)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(beginString, []byte("8=")) {
+		return nil, ErrGarbled
+	}
+
+	bodyLengthField, err := reader.ReadBytes(soh)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bodyLengthField, []byte("9=")) {
+		return nil, ErrGarbled
+	}
+	bodyLength, err := strconv.Atoi(string(bodyLengthField[2 : len(bodyLengthField)-1]))
+	if err != nil || bodyLength < 0 || bodyLength > maxBodyLength {
+		return nil, ErrGarbled
+	}
+
+	body := make([]byte, bodyLength)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, err
+	}
+
+	trailer, err := reader.ReadBytes(soh)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, len(beginString)+len(bodyLengthField)+bodyLength+len(trailer))
+	data = append(data, beginString...)
+	data = append(data, bodyLengthField...)
+	data = append(data, body...)
+	return append(data, trailer...), nil
+}
@@ -0,0 +1,66 @@
+package fix
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestMessageBytes(t *testing.T) {
+	message := NewMessage(MsgTypeHeartbeat).
+		Set(TagTestReqID, "1").
+		Set(TagSenderCompID, "A").
+		Set(TagTargetCompID, "B").
+		SetInt(TagMsgSeqNum, 2)
+
+	// the header fields come first regardless of the order they were set in
+	assert.Equal(t, "8=FIX.4.4|9=26|35=0|49=A|56=B|34=2|112=1|10=135|", message.String())
+
+	parsed, err := Parse(message.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, MsgTypeHeartbeat, parsed.Type())
+	assert.Equal(t, "1", parsed.Get(TagTestReqID))
+	seqNum, err := parsed.GetInt(TagMsgSeqNum)
+	require.NoError(t, err)
+	assert.Equal(t, 2, seqNum)
+}
+
+func TestParseRejectsGarbledMessages(t *testing.T) {
+	valid := NewMessage(MsgTypeHeartbeat).Set(TagTestReqID, "1").String()
+
+	testCases := []string{
+		strings.Replace(valid, "10=", "10=0", 1),
+		strings.Replace(valid, "9=", "9=1", 1),
+		strings.Replace(valid, "FIX.4.4", "FIX.4.2", 1),
+		strings.Replace(valid, "112=1", "112=2", 1),
+		strings.TrimSuffix(valid, "|"),
+		"",
+	}
+
+	for _, tc := range testCases {
+		_, err := Parse([]byte(strings.ReplaceAll(tc, "|", "\x01")))
+		assert.ErrorIs(t, err, ErrGarbled, tc)
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	first := NewMessage(MsgTypeHeartbeat).Bytes()
+	second := NewMessage(MsgTypeTestRequest).Set(TagTestReqID, "a=b").Bytes()
+	reader := bufio.NewReader(bytes.NewReader(append(append([]byte{}, first...), second...)))
+
+	data, err := ReadMessage(reader)
+	require.NoError(t, err)
+	assert.Equal(t, first, data)
+
+	data, err = ReadMessage(reader)
+	require.NoError(t, err)
+	message, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, "a=b", message.Get(TagTestReqID))
+
+	_, err = ReadMessage(bufio.NewReader(strings.NewReader("garbage\x01")))
+	assert.ErrorIs(t, err, ErrGarbled)
+}
@@ -0,0 +1,376 @@
+package fix
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/galcik/vlexchange/internal/datastore/queries"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrUnsupportedMessageType is returned by handlers for business messages they
+// do not handle.
+var ErrUnsupportedMessageType = errors.New("unsupported message type")
+
+const writeTimeout = 10 * time.Second
+
+// Store persists the sequence numbers and the sent messages of sessions,
+// datastore.Store implements it.
+type Store interface {
+	GetFixSession(senderCompId string, targetCompId string) (*queries.FixSession, error)
+	SaveFixMessage(sessionId int32, seqNum int32, message []byte) error
+	GetFixMessages(sessionId int32, fromSeqNum int32, toSeqNum int32) ([]queries.FixMessage, error)
+	SetFixTargetSeqNum(sessionId int32, seqNum int32) error
+	ResetFixSession(sessionId int32) error
+}
+
+// Application authenticates the Logon messages of new sessions. The returned
+// handler receives the business messages of the session. An error rejects the
+// logon and its text is sent in the Logout message.
+type Application interface {
+	Logon(session *Session, logon *Message) (Handler, error)
+}
+
+type Handler interface {
+	// OnLogon is called once the Logon response is sent.
+	OnLogon()
+	// FromApp handles a business message. Errors are answered by a
+	// BusinessMessageReject.
+	FromApp(message *Message) error
+	// OnLogout is called once the session is disconnected.
+	OnLogout()
+}
+
+// Session is a logged on FIX session. Messages are sent by Send, which is safe
+// for concurrent use.
+type Session struct {
+	SenderCompID string
+	TargetCompID string
+	HeartBtInt   time.Duration
+
+	id     int32
+	conn   net.Conn
+	reader *bufio.Reader
+	store  Store
+
+	mutex            sync.Mutex
+	nextSenderSeqNum int
+	lastSent         time.Time
+
+	// used only by the receiving goroutine
+	nextTargetSeqNum int
+	resendUntil      int
+	testRequestSent  bool
+	logoutReceived   bool
+}
+
+func (session *Session) RemoteAddr() net.Addr {
+	return session.conn.RemoteAddr()
+}
+
+// Send assigns the next sequence number to the message, stores it for resend
+// requests and sends it.
+func (session *Session) Send(message *Message) error {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	seqNum := session.nextSenderSeqNum
+	session.setHeader(message, seqNum)
+	data := message.Bytes()
+	if err := session.store.SaveFixMessage(session.id, int32(seqNum), data); err != nil {
+		return err
+	}
+	session.nextSenderSeqNum++
+
+	return session.write(data)
+}
+
+func (session *Session) setHeader(message *Message, seqNum int) {
+	message.Set(TagSenderCompID, session.SenderCompID)
+	message.Set(TagTargetCompID, session.TargetCompID)
+	message.SetInt(TagMsgSeqNum, seqNum)
+	message.Set(TagSendingTime, time.Now().UTC().Format(TimestampFormat))
+}
+
+// write must be called with the mutex held.
+func (session *Session) write(data []byte) error {
+	session.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := session.conn.Write(data); err != nil {
+		return err
+	}
+	session.lastSent = time.Now()
+	return nil
+}
+
+func (session *Session) logout(text string) {
+	logout := NewMessage(MsgTypeLogout)
+	if text != "" {
+		logout.Set(TagText, text)
+	}
+	if err := session.Send(logout); err != nil {
+		log.Printf("fix session %s: %v", session.TargetCompID, err)
+	}
+}
+
+// run receives messages until the session is logged out or disconnected. The
+// Logout of the initiator is answered by the caller.
+func (session *Session) run(handler Handler) {
+	stopHeartbeats := make(chan struct{})
+	defer close(stopHeartbeats)
+	go session.sendHeartbeats(stopHeartbeats)
+
+	for {
+		// a TestRequest is sent when nothing arrives within the heartbeat
+		// interval and some transmission time
+		session.conn.SetReadDeadline(time.Now().Add(session.HeartBtInt + session.HeartBtInt/5))
+		data, err := ReadMessage(session.reader)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			if session.testRequestSent {
+				log.Printf("fix session %s: heartbeat timeout", session.TargetCompID)
+				return
+			}
+			testRequest := NewMessage(MsgTypeTestRequest).Set(TagTestReqID, time.Now().UTC().Format(TimestampFormat))
+			if err := session.Send(testRequest); err != nil {
+				log.Printf("fix session %s: %v", session.TargetCompID, err)
+				return
+			}
+			session.testRequestSent = true
+			continue
+		}
+		if err != nil {
+			return
+		}
+		session.testRequestSent = false
+
+		message, err := Parse(data)
+		if err != nil {
+			// garbled messages are ignored, the gap is detected by the next one
+			continue
+		}
+
+		if !session.process(handler, message) {
+			return
+		}
+	}
+}
+
+func (session *Session) sendHeartbeats(stop <-chan struct{}) {
+	ticker := time.NewTicker(session.HeartBtInt / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			session.mutex.Lock()
+			idle := time.Since(session.lastSent) >= session.HeartBtInt
+			session.mutex.Unlock()
+			if idle {
+				if err := session.Send(NewMessage(MsgTypeHeartbeat)); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// process checks the sequence number of the message and handles it. It returns
+// false when the session should be disconnected.
+func (session *Session) process(handler Handler, message *Message) bool {
+	if message.Get(TagSenderCompID) != session.TargetCompID || message.Get(TagTargetCompID) != session.SenderCompID {
+		session.logout("incorrect CompID")
+		return false
+	}
+
+	seqNum, err := message.GetInt(TagMsgSeqNum)
+	if err != nil {
+		session.logout(err.Error())
+		return false
+	}
+
+	msgType := message.Type()
+	if msgType == MsgTypeSequenceReset && message.Get(TagGapFillFlag) != "Y" {
+		// the reset mode ignores the sequence number of the message
+		return session.resetSequence(message)
+	}
+
+	if seqNum > session.nextTargetSeqNum {
+		if msgType == MsgTypeLogout {
+			session.logoutReceived = true
+			return false
+		}
+		if session.resendUntil < session.nextTargetSeqNum {
+			resendRequest := NewMessage(MsgTypeResendRequest).
+				SetInt(TagBeginSeqNo, session.nextTargetSeqNum).
+				SetInt(TagEndSeqNo, 0)
+			if err := session.Send(resendRequest); err != nil {
+				log.Printf("fix session %s: %v", session.TargetCompID, err)
+				return false
+			}
+		}
+		if seqNum > session.resendUntil {
+			session.resendUntil = seqNum
+		}
+		// both sides may wait for the other to fill a gap
+		if msgType == MsgTypeResendRequest {
+			return session.resend(message)
+		}
+		return true
+	}
+
+	if seqNum < session.nextTargetSeqNum {
+		if message.Get(TagPossDupFlag) == "Y" {
+			return true
+		}
+		session.logout(fmt.Sprintf("MsgSeqNum too low, expecting %d but received %d", session.nextTargetSeqNum, seqNum))
+		return false
+	}
+
+	if msgType == MsgTypeSequenceReset {
+		return session.resetSequence(message)
+	}
+
+	if !session.setNextTargetSeqNum(seqNum + 1) {
+		return false
+	}
+
+	switch msgType {
+	case MsgTypeHeartbeat, MsgTypeLogon:
+	case MsgTypeTestRequest:
+		heartbeat := NewMessage(MsgTypeHeartbeat).Set(TagTestReqID, message.Get(TagTestReqID))
+		if err := session.Send(heartbeat); err != nil {
+			log.Printf("fix session %s: %v", session.TargetCompID, err)
+			return false
+		}
+	case MsgTypeResendRequest:
+		return session.resend(message)
+	case MsgTypeReject:
+		log.Printf("fix session %s: message %s rejected: %s", session.TargetCompID, message.Get(TagRefSeqNum),
+			message.Get(TagText))
+	case MsgTypeLogout:
+		session.logoutReceived = true
+		return false
+	default:
+		if err := handler.FromApp(message); err != nil {
+			return session.rejectBusinessMessage(message, err)
+		}
+	}
+	return true
+}
+
+func (session *Session) setNextTargetSeqNum(seqNum int) bool {
+	if err := session.store.SetFixTargetSeqNum(session.id, int32(seqNum)); err != nil {
+		log.Printf("fix session %s: %v", session.TargetCompID, err)
+		return false
+	}
+	session.nextTargetSeqNum = seqNum
+	return true
+}
+
+func (session *Session) resetSequence(message *Message) bool {
+	newSeqNo, err := message.GetInt(TagNewSeqNo)
+	if err != nil || newSeqNo < session.nextTargetSeqNum {
+		reject := NewMessage(MsgTypeReject).
+			Set(TagRefSeqNum, message.Get(TagMsgSeqNum)).
+			SetInt(TagRefTagID, TagNewSeqNo).
+			Set(TagText, "NewSeqNo must not lower the sequence number")
+		return session.Send(reject) == nil
+	}
+	return session.setNextTargetSeqNum(newSeqNo)
+}
+
+func (session *Session) rejectBusinessMessage(message *Message, err error) bool {
+	reason := BusinessRejectReasonOther
+	text := "internal error"
+	if errors.Is(err, ErrUnsupportedMessageType) {
+		reason = BusinessRejectReasonUnsupported
+		text = err.Error()
+	} else {
+		log.Printf("fix session %s: message %s failed: %v", session.TargetCompID, message.Get(TagMsgSeqNum), err)
+	}
+
+	reject := NewMessage(MsgTypeBusinessMessageReject).
+		Set(TagRefSeqNum, message.Get(TagMsgSeqNum)).
+		Set(TagRefMsgType, message.Type()).
+		Set(TagBusinessRejectReason, reason).
+		Set(TagText, text)
+	return session.Send(reject) == nil
+}
+
+// resend sends the stored business messages again as possible duplicates.
+// Session level messages and missing ones are skipped by gap fills.
+func (session *Session) resend(request *Message) bool {
+	beginSeqNo, err := request.GetInt(TagBeginSeqNo)
+	if err != nil {
+		session.logout(err.Error())
+		return false
+	}
+	endSeqNo, err := request.GetInt(TagEndSeqNo)
+	if err != nil {
+		session.logout(err.Error())
+		return false
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	lastSeqNo := session.nextSenderSeqNum - 1
+	if endSeqNo == 0 || endSeqNo > lastSeqNo {
+		endSeqNo = lastSeqNo
+	}
+	if beginSeqNo < 1 {
+		beginSeqNo = 1
+	}
+	if beginSeqNo > endSeqNo {
+		return true
+	}
+
+	stored, err := session.store.GetFixMessages(session.id, int32(beginSeqNo), int32(endSeqNo))
+	if err != nil {
+		log.Printf("fix session %s: %v", session.TargetCompID, err)
+		return false
+	}
+	messages := make(map[int]*Message, len(stored))
+	for _, record := range stored {
+		if message, err := Parse(record.Message); err == nil {
+			messages[int(record.SeqNum)] = message
+		}
+	}
+
+	gapStart := 0
+	fillGap := func(newSeqNo int) error {
+		if gapStart == 0 {
+			return nil
+		}
+		gapFill := NewMessage(MsgTypeSequenceReset).Set(TagGapFillFlag, "Y").SetInt(TagNewSeqNo, newSeqNo)
+		session.setHeader(gapFill, gapStart)
+		gapFill.Set(TagPossDupFlag, "Y")
+		gapStart = 0
+		return session.write(gapFill.Bytes())
+	}
+
+	for seqNum := beginSeqNo; seqNum <= endSeqNo; seqNum++ {
+		message, ok := messages[seqNum]
+		if !ok || isAdminMessage(message.Type()) {
+			if gapStart == 0 {
+				gapStart = seqNum
+			}
+			continue
+		}
+
+		if err := fillGap(seqNum); err != nil {
+			return false
+		}
+		message.Set(TagOrigSendingTime, message.Get(TagSendingTime))
+		message.Set(TagSendingTime, time.Now().UTC().Format(TimestampFormat))
+		message.Set(TagPossDupFlag, "Y")
+		if err := session.write(message.Bytes()); err != nil {
+			return false
+		}
+	}
+	return fillGap(endSeqNo+1) == nil
+}
@@ -0,0 +1,162 @@
+// Package ratelimit implements in-memory token bucket rate limiting.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit describes a token bucket refilled with Rate tokens per second up to
+// Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimit parses a limit in the form <rate>/<burst>, e.g. "10/20".
+func ParseLimit(value string) (Limit, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("malformed rate limit %q", value)
+	}
+
+	rate, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || rate <= 0 {
+		return Limit{}, fmt.Errorf("malformed rate in rate limit %q", value)
+	}
+	burst, err := strconv.Atoi(parts[1])
+	if err != nil || burst <= 0 {
+		return Limit{}, fmt.Errorf("malformed burst in rate limit %q", value)
+	}
+
+	return Limit{Rate: rate, Burst: burst}, nil
+}
+
+// ParseLimits parses comma separated <name>=<rate>/<burst> entries.
+func ParseLimits(value string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("malformed rate limit entry %q", entry)
+		}
+		limit, err := ParseLimit(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.TrimSpace(parts[0])] = limit
+	}
+	return limits, nil
+}
+
+// Result describes the state of a bucket after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the rejected request would be allowed.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.updated = now
+	}
+}
+
+func (b *bucket) durationUntil(tokens float64) time.Duration {
+	missing := tokens - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.limit.Rate * float64(time.Second))
+}
+
+const cleanupInterval = time.Minute
+
+// Limiter keeps one token bucket per key.
+type Limiter struct {
+	// Now returns the current time, time.Now is used when nil.
+	Now func() time.Time
+
+	mutex       sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{buckets: make(map[string]*bucket)}
+}
+
+// Allow takes cost tokens from the bucket of the key when enough are
+// available. The bucket is created full and adopts limit if it changes.
+func (limiter *Limiter) Allow(key string, limit Limit, cost int) Result {
+	now := time.Now()
+	if limiter.Now != nil {
+		now = limiter.Now()
+	}
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.cleanup(now)
+
+	b, ok := limiter.buckets[key]
+	if !ok {
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), updated: now}
+		limiter.buckets[key] = b
+	}
+	b.refill(now)
+	if b.limit != limit {
+		b.limit = limit
+		b.tokens = math.Min(b.tokens, float64(limit.Burst))
+	}
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= float64(cost) {
+		b.tokens -= float64(cost)
+		result.Allowed = true
+	} else if cost > limit.Burst {
+		// the request can never fit into the bucket
+		result.RetryAfter = b.durationUntil(float64(limit.Burst))
+	} else {
+		result.RetryAfter = b.durationUntil(float64(cost))
+	}
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = b.durationUntil(float64(limit.Burst))
+	return result
+}
+
+// cleanup drops buckets which are full again, they behave the same as new ones.
+func (limiter *Limiter) cleanup(now time.Time) {
+	if now.Sub(limiter.lastCleanup) < cleanupInterval {
+		return
+	}
+	limiter.lastCleanup = now
+
+	for key, b := range limiter.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) Advance(duration time.Duration) {
+	clock.now = clock.now.Add(duration)
+}
+
+func newTestLimiter() (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewLimiter()
+	limiter.Now = clock.Now
+	return limiter, clock
+}
+
+func TestAllow(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	limit := Limit{Rate: 2, Burst: 4}
+
+	for i := 0; i < 4; i++ {
+		result := limiter.Allow("a", limit, 1)
+		require.True(t, result.Allowed)
+		assert.Equal(t, 4, result.Limit)
+		assert.Equal(t, 3-i, result.Remaining)
+	}
+
+	result := limiter.Allow("a", limit, 1)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, 2*time.Second, result.Reset)
+
+	assert.True(t, limiter.Allow("b", limit, 1).Allowed, "buckets are independent")
+
+	clock.Advance(500 * time.Millisecond)
+	assert.True(t, limiter.Allow("a", limit, 1).Allowed)
+	assert.False(t, limiter.Allow("a", limit, 1).Allowed)
+}
+
+func TestAllowWeighted(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	limit := Limit{Rate: 1, Burst: 5}
+
+	assert.True(t, limiter.Allow("a", limit, 3).Allowed)
+	result := limiter.Allow("a", limit, 3)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	clock.Advance(time.Second)
+	assert.True(t, limiter.Allow("a", limit, 3).Allowed)
+
+	result = limiter.Allow("a", limit, 6)
+	assert.False(t, result.Allowed, "cost above burst is never allowed")
+	assert.Equal(t, 5*time.Second, result.RetryAfter)
+}
+
+func TestLimitChange(t *testing.T) {
+	limiter, _ := newTestLimiter()
+
+	assert.True(t, limiter.Allow("a", Limit{Rate: 1, Burst: 10}, 1).Allowed)
+	result := limiter.Allow("a", Limit{Rate: 1, Burst: 2}, 1)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestCleanup(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	limit := Limit{Rate: 1, Burst: 2}
+
+	limiter.Allow("a", limit, 1)
+	limiter.Allow("b", limit, 2)
+	clock.Advance(cleanupInterval + time.Second)
+	limiter.Allow("c", Limit{Rate: 0.001, Burst: 2}, 2)
+	assert.Equal(t, 1, len(limiter.buckets))
+
+	clock.Advance(cleanupInterval)
+	limiter.Allow("d", limit, 1)
+	assert.Equal(t, 2, len(limiter.buckets), "buckets that are not full are kept")
+}
+
+func TestParseLimits(t *testing.T) {
+	limit, err := ParseLimit("2.5/10")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Rate: 2.5, Burst: 10}, limit)
+
+	for _, value := range []string{"", "10", "0/10", "10/0", "a/10", "10/b", "1/2/3"} {
+		_, err := ParseLimit(value)
+		assert.Error(t, err, value)
+	}
+
+	limits, err := ParseLimits(" standard=10/20, pro=50/100,")
+	require.NoError(t, err)
+	assert.Equal(
+		t, map[string]Limit{"standard": {Rate: 10, Burst: 20}, "pro": {Rate: 50, Burst: 100}}, limits,
+	)
+
+	_, err = ParseLimits("standard")
+	assert.Error(t, err)
+	_, err = ParseLimits("=1/2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/galcik/vlexchange/pkg/signature"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Client delivers webhooks according to a Policy.
+type Client struct {
+	policy     Policy
+	httpClient *http.Client
+}
+
+func NewClient(policy Policy) *Client {
+	client := &Client{policy: policy}
+
+	// The dialer checks the address actually being connected to, so a host
+	// re-resolving to a blocked address after validation (DNS rebinding) is
+	// still refused.
+	dialer := &net.Dialer{
+		Timeout: policy.DialTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("unexpected dial address %q", address)
+			}
+			return client.policy.checkIP(ip)
+		},
+	}
+
+	client.httpClient = &http.Client{
+		Timeout: policy.RequestTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   policy.DialTimeout,
+			ResponseHeaderTimeout: policy.RequestTimeout,
+			DisableKeepAlives:     true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return client
+}
+
+// Secret derives the secret signing the webhooks of the account from the
+// server secret, so that webhook secrets are never stored.
+func Secret(serverSecret []byte, accountId int32) string {
+	mac := hmac.New(sha256.New, serverSecret)
+	mac.Write([]byte("webhook secret\n" + strconv.Itoa(int(accountId))))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Post validates the URL and sends the JSON body signed with the secret, see
+// package signature. At most MaxResponseBytes of the response are read.
+func (client *Client) Post(ctx context.Context, url string, body []byte, secret string) (int, error) {
+	if err := client.policy.ValidateURL(ctx, url); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	req.Header.Set(signature.HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(signature.HeaderWebhookSignature, signature.ComputeWebhook(secret, timestamp, body))
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(ioutil.Discard, io.LimitReader(resp.Body, client.policy.MaxResponseBytes)); err != nil {
+		return resp.StatusCode, fmt.Errorf("reading webhook response failed: %w", err)
+	}
+	return resp.StatusCode, nil
+}
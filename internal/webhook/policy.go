@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy describes which webhook URLs may be called from inside our network.
+type Policy struct {
+	// AllowHTTP permits plain http URLs. Intended for local development only.
+	AllowHTTP bool
+	// AllowedHosts restricts webhooks to the listed hosts when non-empty.
+	// A leading "*." matches any subdomain.
+	AllowedHosts []string
+	// AllowedNetworks are exempted from the blocked address ranges.
+	AllowedNetworks []*net.IPNet
+	// AllowedPorts restricts the target port when non-empty.
+	AllowedPorts []int
+
+	DialTimeout      time.Duration
+	RequestTimeout   time.Duration
+	MaxResponseBytes int64
+
+	// LookupIP resolves hostnames during URL validation.
+	LookupIP func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",       // "this" network
+	"10.0.0.0/8",      // private
+	"100.64.0.0/10",   // carrier-grade NAT
+	"127.0.0.0/8",     // loopback
+	"169.254.0.0/16",  // link-local, cloud metadata
+	"172.16.0.0/12",   // private
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // documentation
+	"192.168.0.0/16",  // private
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"224.0.0.0/4",     // multicast
+	"240.0.0.0/4",     // reserved, broadcast
+	"::/128",          // unspecified
+	"::1/128",         // loopback
+	"64:ff9b::/96",    // NAT64
+	"100::/64",        // discard
+	"2001:db8::/32",   // documentation
+	"fc00::/7",        // unique local
+	"fe80::/10",       // link-local
+	"ff00::/8",        // multicast
+)
+
+func DefaultPolicy() Policy {
+	return Policy{
+		DialTimeout:      3 * time.Second,
+		RequestTimeout:   10 * time.Second,
+		MaxResponseBytes: 64 * 1024,
+		LookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			ips := make([]net.IP, len(addrs))
+			for i := range addrs {
+				ips[i] = addrs[i].IP
+			}
+			return ips, nil
+		},
+	}
+}
+
+// ParseNetworks parses a comma separated list of CIDRs or single IPs.
+func ParseNetworks(list string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, item := range splitList(list) {
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid network %q", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", item, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ParseHosts parses a comma separated list of hosts.
+func ParseHosts(list string) []string {
+	return splitList(list)
+}
+
+// ValidateURL checks the URL syntax against the policy and verifies that all
+// addresses the host currently resolves to are allowed.
+func (policy *Policy) ValidateURL(ctx context.Context, rawURL string) error {
+	target, err := policy.checkURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := target.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		return policy.checkIP(ip)
+	}
+
+	ips, err := policy.LookupIP(ctx, host)
+	if err != nil {
+		return fmt.Errorf("unable to resolve webhook host %q: %w", host, err)
+	}
+	if len(ips) == 0 {
+		return fmt.Errorf("webhook host %q has no addresses", host)
+	}
+	for _, ip := range ips {
+		if err := policy.checkIP(ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (policy *Policy) checkURL(rawURL string) (*url.URL, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("malformed webhook url: %w", err)
+	}
+
+	switch target.Scheme {
+	case "https":
+	case "http":
+		if !policy.AllowHTTP {
+			return nil, fmt.Errorf("webhook url must use https")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported webhook url scheme %q", target.Scheme)
+	}
+
+	if target.User != nil {
+		return nil, fmt.Errorf("webhook url must not contain credentials")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+	if host == "" {
+		return nil, fmt.Errorf("webhook url has no host")
+	}
+	if !policy.isHostAllowed(host) {
+		return nil, fmt.Errorf("webhook host %q is not allowed", host)
+	}
+
+	port := target.Port()
+	if port == "" {
+		port = "443"
+		if target.Scheme == "http" {
+			port = "80"
+		}
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook port %q", port)
+	}
+	if !policy.isPortAllowed(portNumber) {
+		return nil, fmt.Errorf("webhook port %d is not allowed", portNumber)
+	}
+
+	return target, nil
+}
+
+func (policy *Policy) isHostAllowed(host string) bool {
+	if len(policy.AllowedHosts) == 0 {
+		return true
+	}
+	for _, allowed := range policy.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy *Policy) isPortAllowed(port int) bool {
+	if len(policy.AllowedPorts) == 0 {
+		return true
+	}
+	for _, allowed := range policy.AllowedPorts {
+		if port == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy *Policy) checkIP(ip net.IP) error {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range policy.AllowedNetworks {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return fmt.Errorf("webhook address %v is not allowed", ip)
+		}
+	}
+	return nil
+}
+
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
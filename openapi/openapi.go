@@ -0,0 +1,18 @@
+// Package openapi holds the OpenAPI descriptions of the exchange API versions.
+package openapi
+
+import (
+	"embed"
+)
+
+// LatestVersion is the newest version of the API, clients of older versions
+// are pointed to it.
+const LatestVersion = "v2"
+
+//go:embed v1.yaml v2.yaml
+var specs embed.FS
+
+// Spec returns the OpenAPI document of an API version, e.g. "v1".
+func Spec(version string) ([]byte, error) {
+	return specs.ReadFile(version + ".yaml")
+}
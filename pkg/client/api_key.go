@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// Scopes of the API keys.
+const (
+	ScopeRead     = "read"
+	ScopeTrade    = "trade"
+	ScopeDeposit  = "deposit"
+	ScopeWithdraw = "withdraw"
+)
+
+type PostApiKeyRequest struct {
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	AllowedIPs []string `json:"allowedIps,omitempty"`
+	// ExpiresAt is an RFC 3339 timestamp, keys without it do not expire.
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+// PostApiKeyResponse carries the token and the signing key, they are not
+// returned again.
+type PostApiKeyResponse struct {
+	ApiKeyResponse
+	Token      string `json:"token"`
+	SigningKey string `json:"signingKey"`
+}
+
+type ApiKeyResponse struct {
+	ID         int32    `json:"id"`
+	Prefix     string   `json:"prefix"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	AllowedIPs []string `json:"allowedIps"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+}
+
+func (client *Client) GetApiKeys(ctx context.Context) ([]ApiKeyResponse, error) {
+	var response []ApiKeyResponse
+	if err := client.get(ctx, "/api_keys", nil, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (client *Client) PostApiKey(ctx context.Context, request PostApiKeyRequest) (*PostApiKeyResponse, error) {
+	var response PostApiKeyResponse
+	if err := client.do(ctx, http.MethodPost, "/api_keys", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// DeleteApiKey revokes the key.
+func (client *Client) DeleteApiKey(ctx context.Context, keyId int32) error {
+	return client.do(ctx, http.MethodDelete, "/api_keys/"+strconv.Itoa(int(keyId)), nil, &successResponse{})
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type GetBalanceResponse struct {
+	BTC           string `json:"btc"`
+	USD           string `json:"usd"`
+	USDEquivalent string `json:"usdEquivalent"`
+	// PriceTimestamp is the time of the BTC price used for USDEquivalent.
+	PriceTimestamp string `json:"priceTimestamp"`
+	// PriceSources are the sources the price was aggregated from.
+	PriceSources []string `json:"priceSources,omitempty"`
+	// Balances holds the amounts of all assets the account held by their
+	// symbols.
+	Balances map[string]string `json:"balances,omitempty"`
+	// Display holds the amounts formatted for people when the client asks for
+	// units or a language.
+	Display *BalanceDisplay `json:"display,omitempty"`
+}
+
+type BalanceDisplay struct {
+	BTC           string            `json:"btc"`
+	USD           string            `json:"usd"`
+	USDEquivalent string            `json:"usdEquivalent"`
+	Balances      map[string]string `json:"balances,omitempty"`
+}
+
+// Currencies of PostBalanceRequest, other registered assets are accepted too.
+const (
+	CurrencyBTC = "btc"
+	CurrencyUSD = "usd"
+)
+
+type PostBalanceRequest struct {
+	TopupAmount string `json:"topupAmount"`
+	Currency    string `json:"currency"`
+}
+
+type PostBalanceResponse struct {
+	Success bool `json:"success"`
+}
+
+func (client *Client) GetBalance(ctx context.Context) (*GetBalanceResponse, error) {
+	var response GetBalanceResponse
+	if err := client.get(ctx, "/balance", nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetBalanceAt values the current BTC balance at the recorded price of the
+// past time.
+func (client *Client) GetBalanceAt(ctx context.Context, at time.Time) (*GetBalanceResponse, error) {
+	query := url.Values{}
+	query.Set("at", at.Format(time.RFC3339))
+	var response GetBalanceResponse
+	if err := client.get(ctx, "/balance", query, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// PostBalance tops up the balance of the account.
+func (client *Client) PostBalance(ctx context.Context, request PostBalanceRequest) (*PostBalanceResponse, error) {
+	var response PostBalanceResponse
+	if err := client.do(ctx, http.MethodPost, "/balance", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,245 @@
+// Package client is the Go client of the exchange HTTP API.
+//
+// Requests are authenticated by the account token or the API key token, or
+// signed with the signing key of an API key when Signer is set. Safe requests
+// and requests carrying an idempotency key are retried on network errors,
+// rate limiting and server errors.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/galcik/vlexchange/pkg/signature"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 200 * time.Millisecond
+	// maxRetryAfter caps the wait requested by the Retry-After header.
+	maxRetryAfter = 30 * time.Second
+)
+
+// Client calls the v2 API of the exchange at BaseURL.
+type Client struct {
+	BaseURL string
+	// Token is an account token or an API key token sent in X-Token.
+	Token string
+	// Signer signs the requests instead of sending the token when set.
+	Signer     *signature.Signer
+	HTTPClient *http.Client
+
+	MaxRetries int
+	// RetryBackoff is doubled after every retry.
+	RetryBackoff time.Duration
+
+	// Units, like sat or mBTC, and Language, an Accept-Language value, ask
+	// for the display amounts of balances and orders.
+	Units    []string
+	Language string
+}
+
+func New(baseURL string, token string) *Client {
+	return &Client{
+		BaseURL:      strings.TrimSuffix(baseURL, "/"),
+		Token:        token,
+		HTTPClient:   http.DefaultClient,
+		MaxRetries:   DefaultMaxRetries,
+		RetryBackoff: DefaultRetryBackoff,
+	}
+}
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int
+	Code       string       `json:"code"`
+	Message    string       `json:"message"`
+	Details    []FieldError `json:"details,omitempty"`
+	RequestID  string       `json:"requestId"`
+	// RetryAfter is the wait requested by a rate limited response.
+	RetryAfter time.Duration
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (err *Error) Error() string {
+	if err.Code == "" {
+		return fmt.Sprintf("exchange: status %d", err.StatusCode)
+	}
+	return fmt.Sprintf("exchange: %s: %s (request %s)", err.Code, err.Message, err.RequestID)
+}
+
+// Stable error codes of the API.
+const (
+	ErrorCodeMalformedRequest       = "malformed_request"
+	ErrorCodeValidationFailed       = "validation_failed"
+	ErrorCodeUnauthorized           = "unauthorized"
+	ErrorCodeInvalidSignature       = "invalid_signature"
+	ErrorCodeInsufficientScope      = "insufficient_scope"
+	ErrorCodeNotFound               = "not_found"
+	ErrorCodeDuplicateClientOrderId = "duplicate_client_order_id"
+	ErrorCodeOrderNotLive           = "order_not_live"
+	ErrorCodeAccountFrozen          = "account_frozen"
+	ErrorCodeTradingHalted          = "trading_halted"
+	ErrorCodeIdempotencyKeyReused   = "idempotency_key_reused"
+	ErrorCodeRequestInProgress      = "request_in_progress"
+	ErrorCodeRateLimited            = "rate_limited"
+	ErrorCodeInternal               = "internal_error"
+)
+
+// IsErrorCode tells whether err is an error response with the code.
+func IsErrorCode(err error, code string) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.Code == code
+}
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey sends the key with the POST requests made with the
+// context. The server replays the first response to repeated requests, so
+// they are retried like the safe ones.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+func (client *Client) get(ctx context.Context, path string, query url.Values, response interface{}) error {
+	if len(client.Units) > 0 {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("unit", strings.Join(client.Units, ","))
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return client.do(ctx, http.MethodGet, path, nil, response)
+}
+
+func (client *Client) do(ctx context.Context, method, path string, request, response interface{}) error {
+	var body []byte
+	if request != nil {
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			return err
+		}
+	}
+
+	idempotencyKey, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	retryable := method == http.MethodGet || method == http.MethodHead || idempotencyKey != ""
+
+	backoff := client.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := client.send(ctx, method, path, body, idempotencyKey, response)
+		if !retryable || attempt >= client.MaxRetries || !isTemporary(err) {
+			return err
+		}
+
+		wait := backoff
+		if apiErr, ok := err.(*Error); ok && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		backoff *= 2
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (client *Client) send(ctx context.Context, method, path string, body []byte, idempotencyKey string,
+	response interface{}) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, client.BaseURL+"/v2"+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" && method == http.MethodPost {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	if client.Language != "" {
+		req.Header.Set("Accept-Language", client.Language)
+	}
+
+	if client.Signer != nil {
+		if err := client.Signer.Sign(req); err != nil {
+			return err
+		}
+	} else if client.Token != "" {
+		req.Header.Set("X-Token", client.Token)
+	}
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newError(resp)
+	}
+	if response == nil {
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+func newError(resp *http.Response) error {
+	var envelope struct {
+		Error *Error `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Error == nil {
+		envelope.Error = &Error{RequestID: resp.Header.Get("X-Request-Id")}
+	}
+
+	apiErr := envelope.Error
+	apiErr.StatusCode = resp.StatusCode
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		if apiErr.RetryAfter > maxRetryAfter {
+			apiErr.RetryAfter = maxRetryAfter
+		}
+	}
+	return apiErr
+}
+
+// isTemporary tells the errors worth retrying. Requests cancelled by the
+// context are not retried.
+func isTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apiErr, ok := err.(*Error); ok {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return apiErr.Code == ErrorCodeRequestInProgress
+	}
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err != context.Canceled && urlErr.Err != context.DeadlineExceeded
+	}
+	return false
+}
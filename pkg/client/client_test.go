@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"github.com/galcik/vlexchange/pkg/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(handler http.HandlerFunc) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	client := New(server.URL, "token")
+	client.RetryBackoff = time.Millisecond
+	return client, server.Close
+}
+
+func TestClientRetriesSafeRequests(t *testing.T) {
+	var calls int32
+	client, closeServer := newTestClient(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v2/standing_orders/1", req.URL.Path)
+		assert.Equal(t, "token", req.Header.Get("X-Token"))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"state":"LIVE"}`))
+	})
+	defer closeServer()
+
+	order, err := client.GetStandingOrder(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), order.ID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	client.MaxRetries = 1
+	atomic.StoreInt32(&calls, 0)
+	_, err = client.GetStandingOrder(context.Background(), 1)
+	assert.Equal(t, http.StatusServiceUnavailable, err.(*Error).StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestClientSendsDisplayPreferences(t *testing.T) {
+	client, closeServer := newTestClient(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "sat", req.URL.Query().Get("unit"))
+		assert.Equal(t, "LIVE", req.URL.Query().Get("state"))
+		assert.Equal(t, "de-DE", req.Header.Get("Accept-Language"))
+		_, _ = w.Write([]byte(`{"orders":[{"id":1,"display":{"quantity":"150.000.000 sat"}}]}`))
+	})
+	defer closeServer()
+	client.Units = []string{"sat"}
+	client.Language = "de-DE"
+
+	response, err := client.GetStandingOrders(context.Background(), ListStandingOrdersParams{States: []string{"LIVE"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(response.Orders))
+	require.NotNil(t, response.Orders[0].Display)
+	assert.Equal(t, "150.000.000 sat", response.Orders[0].Display.Quantity)
+}
+
+func TestClientDoesNotRetryUnsafeRequests(t *testing.T) {
+	var calls int32
+	var idempotencyKeys []string
+	client, closeServer := newTestClient(func(w http.ResponseWriter, req *http.Request) {
+		idempotencyKeys = append(idempotencyKeys, req.Header.Get("Idempotency-Key"))
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"orderId":7}`))
+	})
+	defer closeServer()
+
+	request := PostStandingOrderRequest{Type: OrderTypeBuy, Quantity: "1", LimitPrice: "1"}
+	_, err := client.PostStandingOrder(context.Background(), request)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// the server replays the response of requests with the same key
+	atomic.StoreInt32(&calls, 0)
+	idempotencyKeys = nil
+	placed, err := client.PostStandingOrder(WithIdempotencyKey(context.Background(), "key-1"), request)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), placed.OrderId)
+	assert.Equal(t, []string{"key-1", "key-1"}, idempotencyKeys)
+}
+
+func TestClientDecodesErrors(t *testing.T) {
+	client, closeServer := newTestClient(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(
+			`{"error":{"code":"rate_limited","message":"rate limit exceeded","requestId":"abc"}}`,
+		))
+	})
+	defer closeServer()
+	client.MaxRetries = 0
+
+	_, err := client.GetBalance(context.Background())
+	require.Error(t, err)
+	apiErr := err.(*Error)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, ErrorCodeRateLimited, apiErr.Code)
+	assert.Equal(t, "abc", apiErr.RequestID)
+	assert.Equal(t, maxRetryAfter, apiErr.RetryAfter)
+	assert.True(t, IsErrorCode(err, ErrorCodeRateLimited))
+}
+
+func TestClientStopsRetryingWhenCancelled(t *testing.T) {
+	client, closeServer := newTestClient(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer closeServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err := client.GetBalance(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, int64(time.Since(started)), int64(5*time.Second))
+}
+
+func newWebhookRequest(secret string, sentAt time.Time, body string) *http.Request {
+	timestamp := sentAt.UnixNano() / int64(time.Millisecond)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set(signature.HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(signature.HeaderWebhookSignature, signature.ComputeWebhook(secret, timestamp, []byte(body)))
+	return req
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := `{"orderId":3,"clientOrderId":"abc"}`
+	event, err := VerifyWebhook(newWebhookRequest("secret", time.Now(), body), "secret", DefaultWebhookTolerance)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), event.OrderId)
+	assert.Equal(t, "abc", event.ClientOrderId)
+
+	testCases := []*http.Request{
+		newWebhookRequest("other", time.Now(), body),
+		newWebhookRequest("secret", time.Now().Add(-time.Hour), body),
+		httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)),
+	}
+	for _, req := range testCases {
+		_, err := VerifyWebhook(req, "secret", DefaultWebhookTolerance)
+		assert.ErrorIs(t, err, ErrInvalidWebhookSignature)
+	}
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"github.com/galcik/vlexchange/internal/api"
+	"github.com/galcik/vlexchange/internal/coinmarket"
+	"github.com/galcik/vlexchange/internal/currency"
+	"github.com/galcik/vlexchange/internal/datastore"
+	"github.com/galcik/vlexchange/internal/webhook"
+	"github.com/galcik/vlexchange/pkg/signature"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// integrationTestSuite runs the client against the API server backed by the
+// testing database.
+type integrationTestSuite struct {
+	suite.Suite
+	db         *sql.DB
+	httpServer *httptest.Server
+	client     *Client
+}
+
+func (suite *integrationTestSuite) BeforeTest(suiteName, testName string) {
+	var err error
+	suite.db, err = sql.Open(testingDb.TxDriver(), testingDb.GetTxDsn())
+	suite.Require().NoError(err)
+	store, err := datastore.NewStore(suite.db)
+	suite.Require().NoError(err)
+	// webhooks are received by a local test server
+	policy := webhook.DefaultPolicy()
+	policy.AllowHTTP = true
+	policy.AllowedNetworks, err = webhook.ParseNetworks("127.0.0.0/8")
+	suite.Require().NoError(err)
+	server, err := api.NewServer(store,
+		api.WithPriceService(coinmarket.NewFixedProvider(currency.NewUSD(50000))), api.WithWebhookPolicy(policy))
+	suite.Require().NoError(err)
+	suite.httpServer = httptest.NewServer(server)
+
+	suite.client = New(suite.httpServer.URL, "")
+	registered, err := suite.client.Register(context.Background(), RegisterRequest{Username: "sdk"})
+	suite.Require().NoError(err)
+	suite.client.Token = registered.Token
+}
+
+func (suite *integrationTestSuite) AfterTest(suiteName, testName string) {
+	suite.httpServer.Close()
+	suite.db.Close()
+}
+
+func (suite *integrationTestSuite) TestStandingOrders() {
+	ctx := context.Background()
+	deposited, err := suite.client.PostBalance(ctx, PostBalanceRequest{TopupAmount: "100", Currency: CurrencyUSD})
+	suite.Require().NoError(err)
+	suite.True(deposited.Success)
+
+	placed, err := suite.client.PostStandingOrder(ctx, PostStandingOrderRequest{
+		Type: OrderTypeBuy, Quantity: "0.5", LimitPrice: "100", ClientOrderId: "sdk-1",
+	})
+	suite.Require().NoError(err)
+
+	order, err := suite.client.GetStandingOrderByClientId(ctx, "sdk-1")
+	suite.Require().NoError(err)
+	suite.Equal(placed.OrderId, order.ID)
+	suite.Equal(OrderStateLive, order.State)
+	suite.Equal("0.50000000", order.Quantity)
+	suite.Equal("BTC-USD", order.Market)
+
+	_, err = suite.client.PostStandingOrder(ctx, PostStandingOrderRequest{
+		Type: OrderTypeBuy, Quantity: "0.1", LimitPrice: "100", ClientOrderId: "sdk-1",
+	})
+	suite.True(IsErrorCode(err, ErrorCodeDuplicateClientOrderId), err)
+
+	_, err = suite.client.PostStandingOrder(ctx, PostStandingOrderRequest{
+		Type: OrderTypeBuy, Quantity: "-1", LimitPrice: "100",
+	})
+	suite.Require().Error(err)
+	apiErr := err.(*Error)
+	suite.Equal(http.StatusBadRequest, apiErr.StatusCode)
+	suite.Equal(ErrorCodeValidationFailed, apiErr.Code)
+	suite.NotEmpty(apiErr.RequestID)
+
+	list, err := suite.client.GetStandingOrders(ctx, ListStandingOrdersParams{States: []string{OrderStateLive}})
+	suite.Require().NoError(err)
+	suite.Require().Len(list.Orders, 1)
+	suite.Equal(placed.OrderId, list.Orders[0].ID)
+	suite.Empty(list.NextCursor)
+
+	book, err := suite.client.GetOrderBook(ctx, 10)
+	suite.Require().NoError(err)
+	suite.Equal([]OrderBookLevel{{Price: "100.00", Quantity: "0.50000000", Orders: 1}}, book.Bids)
+	suite.Empty(book.Asks)
+
+	markets, err := suite.client.GetMarkets(ctx)
+	suite.Require().NoError(err)
+	suite.Equal([]Market{{Symbol: "BTC-USD", BaseAsset: "BTC", BasePrecision: 8, QuoteAsset: "USD", QuotePrecision: 2}},
+		markets.Markets)
+	_, err = suite.client.GetMarketOrderBook(ctx, "XYZ-USD", 10)
+	suite.True(IsErrorCode(err, ErrorCodeValidationFailed), err)
+
+	suite.Require().NoError(suite.client.DeleteStandingOrder(ctx, placed.OrderId))
+	_, err = suite.client.GetStandingOrder(ctx, placed.OrderId)
+	suite.True(IsErrorCode(err, ErrorCodeNotFound), err)
+}
+
+func (suite *integrationTestSuite) TestSignedWebhooks() {
+	ctx := context.Background()
+	secret, err := suite.client.GetWebhookSecret(ctx)
+	suite.Require().NoError(err)
+	suite.NotEmpty(secret)
+
+	events := make(chan *OrderChangedEvent, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		event, err := VerifyWebhook(req, secret, DefaultWebhookTolerance)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		events <- event
+	}))
+	defer receiver.Close()
+
+	_, err = suite.client.PostBalance(ctx, PostBalanceRequest{TopupAmount: "100", Currency: CurrencyUSD})
+	suite.Require().NoError(err)
+	placed, err := suite.client.PostStandingOrder(ctx, PostStandingOrderRequest{
+		Type: OrderTypeBuy, Quantity: "0.5", LimitPrice: "100", ClientOrderId: "hooked", WebhookUrl: receiver.URL,
+	})
+	suite.Require().NoError(err)
+
+	select {
+	case event := <-events:
+		suite.Equal(&OrderChangedEvent{OrderId: placed.OrderId, ClientOrderId: "hooked"}, event)
+	case <-time.After(5 * time.Second):
+		suite.Fail("no verified webhook received")
+	}
+}
+
+func (suite *integrationTestSuite) TestBalance() {
+	ctx := context.Background()
+	for _, request := range []PostBalanceRequest{
+		{TopupAmount: "100", Currency: CurrencyUSD},
+		{TopupAmount: "0.5", Currency: CurrencyBTC},
+	} {
+		_, err := suite.client.PostBalance(ctx, request)
+		suite.Require().NoError(err)
+	}
+
+	balance, err := suite.client.GetBalance(ctx)
+	suite.Require().NoError(err)
+	suite.Equal("100.00", balance.USD)
+	suite.Equal("0.50000000", balance.BTC)
+	suite.Equal(map[string]string{"BTC": "0.50000000", "USD": "100.00"}, balance.Balances)
+	suite.Equal("25000.00", balance.USDEquivalent)
+	suite.Equal([]string{"fixed"}, balance.PriceSources)
+	suite.NotEmpty(balance.PriceTimestamp)
+
+	// no prices are recorded in tests
+	history, err := suite.client.GetPriceHistory(ctx, PriceHistoryParams{Limit: 10})
+	suite.Require().NoError(err)
+	suite.Empty(history.Prices)
+	_, err = suite.client.GetBalanceAt(ctx, time.Now().Add(-time.Hour))
+	suite.True(IsErrorCode(err, ErrorCodeNotFound), err)
+}
+
+func (suite *integrationTestSuite) TestIdempotencyKey() {
+	ctx := WithIdempotencyKey(context.Background(), "sdk-deposit")
+	for i := 0; i < 2; i++ {
+		_, err := suite.client.PostBalance(ctx, PostBalanceRequest{TopupAmount: "1", Currency: CurrencyBTC})
+		suite.Require().NoError(err)
+	}
+
+	_, err := suite.client.PostBalance(ctx, PostBalanceRequest{TopupAmount: "2", Currency: CurrencyBTC})
+	suite.True(IsErrorCode(err, ErrorCodeIdempotencyKeyReused), err)
+}
+
+func (suite *integrationTestSuite) TestApiKeys() {
+	ctx := context.Background()
+	key, err := suite.client.PostApiKey(ctx, PostApiKeyRequest{Name: "sdk", Scopes: []string{ScopeRead}})
+	suite.Require().NoError(err)
+	suite.NotEmpty(key.Token)
+	suite.NotEmpty(key.SigningKey)
+
+	signed := New(suite.httpServer.URL, "")
+	signed.Signer = &signature.Signer{KeyPrefix: key.Prefix, SigningKey: key.SigningKey}
+	_, err = signed.GetStandingOrders(ctx, ListStandingOrdersParams{})
+	suite.NoError(err)
+
+	reader := New(suite.httpServer.URL, key.Token)
+	_, err = reader.PostStandingOrder(ctx, PostStandingOrderRequest{Type: OrderTypeBuy, Quantity: "1", LimitPrice: "1"})
+	suite.True(IsErrorCode(err, ErrorCodeInsufficientScope), err)
+
+	keys, err := suite.client.GetApiKeys(ctx)
+	suite.Require().NoError(err)
+	suite.Require().Len(keys, 1)
+	suite.Equal(key.ID, keys[0].ID)
+
+	suite.Require().NoError(suite.client.DeleteApiKey(ctx, key.ID))
+	_, err = reader.GetStandingOrders(ctx, ListStandingOrdersParams{})
+	suite.True(IsErrorCode(err, ErrorCodeUnauthorized), err)
+}
+
+func TestIntegration(t *testing.T) {
+	suite.Run(t, new(integrationTestSuite))
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"github.com/galcik/vlexchange/internal/testutils"
+	"os"
+	"testing"
+)
+
+var testingDb *testutils.TestingDb
+
+func TestMain(m *testing.M) {
+	os.Exit(runWithTemporaryDb(m))
+}
+
+func runWithTemporaryDb(m *testing.M) int {
+	var err error
+	testingDb, err = testutils.NewTestingDb()
+	if err != nil {
+		panic(err)
+	}
+	defer testingDb.Close()
+
+	if err := testingDb.ExecuteSQLFile("../../internal/datastore/schema/schema.sql"); err != nil {
+		panic(err)
+	}
+
+	if testingDb.TxDriver() == "" {
+		panic("missing tx driver for temporary db")
+	}
+
+	return m.Run()
+}
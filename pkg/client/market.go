@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+)
+
+type Market struct {
+	Symbol         string `json:"symbol"`
+	BaseAsset      string `json:"baseAsset"`
+	BasePrecision  int    `json:"basePrecision"`
+	QuoteAsset     string `json:"quoteAsset"`
+	QuotePrecision int    `json:"quotePrecision"`
+}
+
+type GetMarketsResponse struct {
+	Markets []Market `json:"markets"`
+}
+
+// GetMarkets returns the markets open for trading.
+func (client *Client) GetMarkets(ctx context.Context) (*GetMarketsResponse, error) {
+	var response GetMarketsResponse
+	if err := client.get(ctx, "/markets", nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
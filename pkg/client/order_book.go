@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+type OrderBookLevel struct {
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+	Orders   int32  `json:"orders"`
+}
+
+type GetOrderBookResponse struct {
+	Market string           `json:"market"`
+	Bids   []OrderBookLevel `json:"bids"`
+	Asks   []OrderBookLevel `json:"asks"`
+}
+
+// GetOrderBook returns the live orders of the default BTC-USD market
+// aggregated by price, depth limits the price levels of each side when
+// positive.
+func (client *Client) GetOrderBook(ctx context.Context, depth int) (*GetOrderBookResponse, error) {
+	return client.GetMarketOrderBook(ctx, "", depth)
+}
+
+// GetMarketOrderBook returns the order book of the market, the default market
+// when it is empty.
+func (client *Client) GetMarketOrderBook(ctx context.Context, market string, depth int) (
+	*GetOrderBookResponse,
+	error,
+) {
+	query := url.Values{}
+	if market != "" {
+		query.Set("market", market)
+	}
+	if depth > 0 {
+		query.Set("depth", strconv.Itoa(depth))
+	}
+	var response GetOrderBookResponse
+	if err := client.get(ctx, "/order_book", query, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type PricePoint struct {
+	Price     string `json:"price"`
+	Timestamp string `json:"timestamp"`
+}
+
+type GetPriceHistoryResponse struct {
+	Prices []PricePoint `json:"prices"`
+}
+
+// PriceHistoryParams selects the recorded prices, zero values are not sent.
+type PriceHistoryParams struct {
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+func (params PriceHistoryParams) query() url.Values {
+	query := url.Values{}
+	if !params.From.IsZero() {
+		query.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.To.IsZero() {
+		query.Set("to", params.To.Format(time.RFC3339))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	return query
+}
+
+// GetPriceHistory returns the recorded reference BTC prices, the oldest first.
+func (client *Client) GetPriceHistory(ctx context.Context, params PriceHistoryParams) (*GetPriceHistoryResponse, error) {
+	var response GetPriceHistoryResponse
+	if err := client.get(ctx, "/price_history", params.query(), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
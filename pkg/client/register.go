@@ -0,0 +1,23 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type RegisterRequest struct {
+	Username string `json:"username"`
+}
+
+type RegisterResponse struct {
+	Token string `json:"token"`
+}
+
+// Register creates an account, the client does not need a token.
+func (client *Client) Register(ctx context.Context, request RegisterRequest) (*RegisterResponse, error) {
+	var response RegisterResponse
+	if err := client.do(ctx, http.MethodPost, "/register", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
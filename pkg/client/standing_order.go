@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Order types of PostStandingOrderRequest.
+const (
+	OrderTypeBuy  = "buy"
+	OrderTypeSell = "sell"
+)
+
+// Order states of GetStandingOrderResponse.
+const (
+	OrderStateLive      = "LIVE"
+	OrderStateFulfilled = "FULFILLED"
+	OrderStateCancelled = "CANCELLED"
+)
+
+type PostStandingOrderRequest struct {
+	// Market is the symbol of the market, BTC-USD when empty.
+	Market        string `json:"market,omitempty"`
+	Quantity      string `json:"quantity"`
+	Type          string `json:"type"`
+	LimitPrice    string `json:"limitPrice"`
+	WebhookUrl    string `json:"webhookUrl,omitempty"`
+	ClientOrderId string `json:"clientOrderId,omitempty"`
+}
+
+type PostStandingOrderResponse struct {
+	OrderId int32 `json:"orderId"`
+}
+
+type GetStandingOrderResponse struct {
+	ID             int32  `json:"id"`
+	ClientOrderId  string `json:"clientOrderId,omitempty"`
+	Market         string `json:"market"`
+	Type           string `json:"type"`
+	State          string `json:"state"`
+	Quantity       string `json:"quantity"`
+	FilledQuantity string `json:"filledQuantity"`
+	LimitPrice     string `json:"limitPrice"`
+	AvgPrice       string `json:"avgPrice"`
+	CreatedAt      string `json:"createdAt"`
+	// Display holds the amounts formatted for people when the client asks for
+	// units or a language.
+	Display *OrderDisplay `json:"display,omitempty"`
+}
+
+type OrderDisplay struct {
+	Quantity       string `json:"quantity"`
+	FilledQuantity string `json:"filledQuantity"`
+	// LimitPrice is the price of one whole unit of the base asset.
+	LimitPrice string `json:"limitPrice"`
+}
+
+// ListStandingOrdersParams filters the orders, zero values are not sent.
+type ListStandingOrdersParams struct {
+	Market      string
+	States      []string
+	Sides       []string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Limit       int
+	Cursor      string
+}
+
+func (params ListStandingOrdersParams) query() url.Values {
+	query := url.Values{}
+	if params.Market != "" {
+		query.Set("market", params.Market)
+	}
+	if len(params.States) > 0 {
+		query.Set("state", strings.Join(params.States, ","))
+	}
+	if len(params.Sides) > 0 {
+		query.Set("side", strings.Join(params.Sides, ","))
+	}
+	if !params.CreatedFrom.IsZero() {
+		query.Set("createdFrom", params.CreatedFrom.Format(time.RFC3339))
+	}
+	if !params.CreatedTo.IsZero() {
+		query.Set("createdTo", params.CreatedTo.Format(time.RFC3339))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.Cursor != "" {
+		query.Set("cursor", params.Cursor)
+	}
+	return query
+}
+
+type GetStandingOrdersResponse struct {
+	Orders []GetStandingOrderResponse `json:"orders"`
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type successResponse struct {
+	Success bool `json:"success"`
+}
+
+func (client *Client) PostStandingOrder(
+	ctx context.Context,
+	request PostStandingOrderRequest,
+) (*PostStandingOrderResponse, error) {
+	var response PostStandingOrderResponse
+	if err := client.do(ctx, http.MethodPost, "/standing_orders", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (client *Client) GetStandingOrder(ctx context.Context, orderId int32) (*GetStandingOrderResponse, error) {
+	return client.getStandingOrder(ctx, standingOrderPath(orderId))
+}
+
+func (client *Client) GetStandingOrderByClientId(
+	ctx context.Context,
+	clientOrderId string,
+) (*GetStandingOrderResponse, error) {
+	return client.getStandingOrder(ctx, clientStandingOrderPath(clientOrderId))
+}
+
+func (client *Client) getStandingOrder(ctx context.Context, path string) (*GetStandingOrderResponse, error) {
+	var response GetStandingOrderResponse
+	if err := client.get(ctx, path, nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetStandingOrders lists a page of the orders, newest first.
+func (client *Client) GetStandingOrders(
+	ctx context.Context,
+	params ListStandingOrdersParams,
+) (*GetStandingOrdersResponse, error) {
+	var response GetStandingOrdersResponse
+	if err := client.get(ctx, "/standing_orders", params.query(), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// DeleteStandingOrder cancels the order.
+func (client *Client) DeleteStandingOrder(ctx context.Context, orderId int32) error {
+	return client.do(ctx, http.MethodDelete, standingOrderPath(orderId), nil, &successResponse{})
+}
+
+func (client *Client) DeleteStandingOrderByClientId(ctx context.Context, clientOrderId string) error {
+	return client.do(ctx, http.MethodDelete, clientStandingOrderPath(clientOrderId), nil, &successResponse{})
+}
+
+func standingOrderPath(orderId int32) string {
+	return "/standing_orders/" + strconv.Itoa(int(orderId))
+}
+
+func clientStandingOrderPath(clientOrderId string) string {
+	return "/standing_orders/by-client-id/" + url.PathEscape(clientOrderId)
+}
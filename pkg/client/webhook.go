@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/galcik/vlexchange/pkg/signature"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultWebhookTolerance is the accepted age of webhook timestamps.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+const maxWebhookBodySize = 1 << 16
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// OrderChangedEvent is the body of the webhook called when an order changes.
+type OrderChangedEvent struct {
+	OrderId       int32  `json:"orderId"`
+	ClientOrderId string `json:"clientOrderId,omitempty"`
+}
+
+type getWebhookSecretResponse struct {
+	Secret string `json:"secret"`
+}
+
+// GetWebhookSecret returns the secret the webhooks of the account are signed
+// with.
+func (client *Client) GetWebhookSecret(ctx context.Context) (string, error) {
+	var response getWebhookSecretResponse
+	if err := client.get(ctx, "/webhook_secret", nil, &response); err != nil {
+		return "", err
+	}
+	return response.Secret, nil
+}
+
+// VerifyWebhook checks the signature of a webhook request made with the
+// webhook secret of the account, see GetWebhookSecret, and decodes its body.
+// Requests with timestamps older than the tolerance are refused to prevent
+// replays.
+func VerifyWebhook(req *http.Request, secret string, tolerance time.Duration) (*OrderChangedEvent, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxWebhookBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(signature.HeaderWebhookTimestamp), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidWebhookSignature
+	}
+	sentAt := time.Unix(0, timestamp*int64(time.Millisecond))
+	if age := time.Since(sentAt); age > tolerance || age < -tolerance {
+		return nil, ErrInvalidWebhookSignature
+	}
+	if !signature.VerifyWebhook(secret, req.Header.Get(signature.HeaderWebhookSignature), timestamp, body) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var event OrderChangedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
@@ -0,0 +1,134 @@
+// Package signature implements HMAC request signing for the exchange API.
+//
+// The signature is the hex encoded HMAC-SHA256 of
+//
+//	<timestamp>\n<nonce>\n<METHOD>\n<request URI>\n<hex SHA-256 of body>
+//
+// keyed with the signing key of an API key. The timestamp is in unix
+// milliseconds.
+//
+// Webhooks are signed with the webhook secret of the account placing the
+// order. Their signature is the hex encoded HMAC-SHA256 of
+//
+//	<timestamp>\n<body>
+//
+// sent in the X-Webhook-Signature header along with the X-Webhook-Timestamp.
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderKey        = "X-Api-Key"
+	HeaderTimestamp  = "X-Timestamp"
+	HeaderNonce      = "X-Nonce"
+	HeaderSignature  = "X-Signature"
+	HeaderRecvWindow = "X-Recv-Window"
+
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+func Compute(signingKey string, timestamp int64, nonce, method, requestURI string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	payload := strings.Join(
+		[]string{
+			strconv.FormatInt(timestamp, 10),
+			nonce,
+			strings.ToUpper(method),
+			requestURI,
+			hex.EncodeToString(bodyHash[:]),
+		},
+		"\n",
+	)
+
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func Verify(signingKey, signature string, timestamp int64, nonce, method, requestURI string, body []byte) bool {
+	expected := Compute(signingKey, timestamp, nonce, method, requestURI, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+func ComputeWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifyWebhook(secret, signature string, timestamp int64, body []byte) bool {
+	expected := ComputeWebhook(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// Signer signs outgoing requests with an API key.
+type Signer struct {
+	KeyPrefix  string
+	SigningKey string
+	// RecvWindow overrides the server default receive window when positive.
+	RecvWindow time.Duration
+	// Now returns the current time, time.Now is used when nil.
+	Now func() time.Time
+}
+
+// Sign sets the signature headers on the request. The body is read and
+// replaced so that the request can still be sent.
+func (signer *Signer) Sign(req *http.Request) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	nonce, err := NewNonce()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now
+	if signer.Now != nil {
+		now = signer.Now
+	}
+	timestamp := now().UnixNano() / int64(time.Millisecond)
+
+	req.Header.Set(HeaderKey, signer.KeyPrefix)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderNonce, nonce)
+	if signer.RecvWindow > 0 {
+		req.Header.Set(HeaderRecvWindow, strconv.FormatInt(int64(signer.RecvWindow/time.Millisecond), 10))
+	}
+	req.Header.Set(
+		HeaderSignature,
+		Compute(signer.SigningKey, timestamp, nonce, req.Method, req.URL.RequestURI(), body),
+	)
+	return nil
+}
+
+func NewNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(nonce), nil
+}
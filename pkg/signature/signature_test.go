@@ -0,0 +1,88 @@
+package signature
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	signature := Compute("secret", 1613490000000, "abc", "post", "/standing_orders?x=1", []byte(`{"a":1}`))
+	assert.Equal(t, "3715e2c1144ecd4841b64d1256a50cfa4ca1fbb81bb7fd7ec7b40ec4f006d5dc", signature)
+	assert.Equal(t, signature, Compute("secret", 1613490000000, "abc", "POST", "/standing_orders?x=1", []byte(`{"a":1}`)))
+
+	testCases := []string{
+		Compute("other", 1613490000000, "abc", "POST", "/standing_orders?x=1", []byte(`{"a":1}`)),
+		Compute("secret", 1613490000001, "abc", "POST", "/standing_orders?x=1", []byte(`{"a":1}`)),
+		Compute("secret", 1613490000000, "abd", "POST", "/standing_orders?x=1", []byte(`{"a":1}`)),
+		Compute("secret", 1613490000000, "abc", "GET", "/standing_orders?x=1", []byte(`{"a":1}`)),
+		Compute("secret", 1613490000000, "abc", "POST", "/standing_orders?x=2", []byte(`{"a":1}`)),
+		Compute("secret", 1613490000000, "abc", "POST", "/standing_orders?x=1", []byte(`{"a":2}`)),
+	}
+	for i := range testCases {
+		assert.NotEqual(t, signature, testCases[i])
+	}
+}
+
+func TestVerify(t *testing.T) {
+	signature := Compute("secret", 1613490000000, "abc", "GET", "/balance", nil)
+	assert.True(t, Verify("secret", signature, 1613490000000, "abc", "GET", "/balance", nil))
+	assert.False(t, Verify("secret", signature, 1613490000000, "abc", "GET", "/balance", []byte("x")))
+	assert.False(t, Verify("secret", "", 1613490000000, "abc", "GET", "/balance", nil))
+}
+
+func TestWebhookSignature(t *testing.T) {
+	signature := ComputeWebhook("secret", 1613490000000, []byte(`{"orderId":1}`))
+	assert.Equal(t, "3e0b9f14a4dd5f042ef2c3b479fb7c306a53dec8fb64e44c6c76359f7137b1e0", signature)
+
+	assert.True(t, VerifyWebhook("secret", signature, 1613490000000, []byte(`{"orderId":1}`)))
+	assert.False(t, VerifyWebhook("other", signature, 1613490000000, []byte(`{"orderId":1}`)))
+	assert.False(t, VerifyWebhook("secret", signature, 1613490000001, []byte(`{"orderId":1}`)))
+	assert.False(t, VerifyWebhook("secret", signature, 1613490000000, []byte(`{"orderId":2}`)))
+}
+
+func TestSignerSign(t *testing.T) {
+	signer := &Signer{
+		KeyPrefix:  "0123456789ab",
+		SigningKey: "secret",
+		RecvWindow: 10 * time.Second,
+		Now: func() time.Time {
+			return time.Unix(1613490000, 0)
+		},
+	}
+
+	body := []byte(`{"quantity":"1.5"}`)
+	req, err := http.NewRequest(http.MethodPost, "https://exchange.test/standing_orders?dry=1", bytes.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, signer.Sign(req))
+
+	assert.Equal(t, "0123456789ab", req.Header.Get(HeaderKey))
+	assert.Equal(t, "1613490000000", req.Header.Get(HeaderTimestamp))
+	assert.Equal(t, "10000", req.Header.Get(HeaderRecvWindow))
+	assert.Len(t, req.Header.Get(HeaderNonce), 32)
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify(
+		"secret",
+		req.Header.Get(HeaderSignature),
+		timestamp,
+		req.Header.Get(HeaderNonce),
+		http.MethodPost,
+		"/standing_orders?dry=1",
+		body,
+	))
+
+	sentBody, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, sentBody)
+
+	firstNonce := req.Header.Get(HeaderNonce)
+	require.NoError(t, signer.Sign(req))
+	assert.NotEqual(t, firstNonce, req.Header.Get(HeaderNonce))
+}
@@ -0,0 +1,5 @@
+// Package trading holds the protobuf messages and the gRPC service of the
+// trading API.
+package trading
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative trading.proto
@@ -0,0 +1,412 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package trading
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// TradingClient is the client API for Trading service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TradingClient interface {
+	PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	CancelOrder(ctx context.Context, in *OrderRef, opts ...grpc.CallOption) (*Order, error)
+	GetOrder(ctx context.Context, in *OrderRef, opts ...grpc.CallOption) (*Order, error)
+	ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error)
+	Deposit(ctx context.Context, in *DepositRequest, opts ...grpc.CallOption) (*DepositResponse, error)
+	// StreamOrderEvents sends changes of the orders of the account as they happen.
+	StreamOrderEvents(ctx context.Context, in *StreamOrderEventsRequest, opts ...grpc.CallOption) (Trading_StreamOrderEventsClient, error)
+	// StreamTrades sends all trades of the exchange as they happen.
+	StreamTrades(ctx context.Context, in *StreamTradesRequest, opts ...grpc.CallOption) (Trading_StreamTradesClient, error)
+}
+
+type tradingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTradingClient(cc grpc.ClientConnInterface) TradingClient {
+	return &tradingClient{cc}
+}
+
+func (c *tradingClient) PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/vlexchange.trading.Trading/PlaceOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingClient) CancelOrder(ctx context.Context, in *OrderRef, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/vlexchange.trading.Trading/CancelOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingClient) GetOrder(ctx context.Context, in *OrderRef, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/vlexchange.trading.Trading/GetOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingClient) ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error) {
+	out := new(ListOrdersResponse)
+	err := c.cc.Invoke(ctx, "/vlexchange.trading.Trading/ListOrders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error) {
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, "/vlexchange.trading.Trading/GetBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingClient) Deposit(ctx context.Context, in *DepositRequest, opts ...grpc.CallOption) (*DepositResponse, error) {
+	out := new(DepositResponse)
+	err := c.cc.Invoke(ctx, "/vlexchange.trading.Trading/Deposit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingClient) StreamOrderEvents(ctx context.Context, in *StreamOrderEventsRequest, opts ...grpc.CallOption) (Trading_StreamOrderEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Trading_ServiceDesc.Streams[0], "/vlexchange.trading.Trading/StreamOrderEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tradingStreamOrderEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Trading_StreamOrderEventsClient interface {
+	Recv() (*Order, error)
+	grpc.ClientStream
+}
+
+type tradingStreamOrderEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tradingStreamOrderEventsClient) Recv() (*Order, error) {
+	m := new(Order)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tradingClient) StreamTrades(ctx context.Context, in *StreamTradesRequest, opts ...grpc.CallOption) (Trading_StreamTradesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Trading_ServiceDesc.Streams[1], "/vlexchange.trading.Trading/StreamTrades", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tradingStreamTradesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Trading_StreamTradesClient interface {
+	Recv() (*Trade, error)
+	grpc.ClientStream
+}
+
+type tradingStreamTradesClient struct {
+	grpc.ClientStream
+}
+
+func (x *tradingStreamTradesClient) Recv() (*Trade, error) {
+	m := new(Trade)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TradingServer is the server API for Trading service.
+// All implementations must embed UnimplementedTradingServer
+// for forward compatibility
+type TradingServer interface {
+	PlaceOrder(context.Context, *PlaceOrderRequest) (*Order, error)
+	CancelOrder(context.Context, *OrderRef) (*Order, error)
+	GetOrder(context.Context, *OrderRef) (*Order, error)
+	ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
+	GetBalance(context.Context, *GetBalanceRequest) (*Balance, error)
+	Deposit(context.Context, *DepositRequest) (*DepositResponse, error)
+	// StreamOrderEvents sends changes of the orders of the account as they happen.
+	StreamOrderEvents(*StreamOrderEventsRequest, Trading_StreamOrderEventsServer) error
+	// StreamTrades sends all trades of the exchange as they happen.
+	StreamTrades(*StreamTradesRequest, Trading_StreamTradesServer) error
+	mustEmbedUnimplementedTradingServer()
+}
+
+// UnimplementedTradingServer must be embedded to have forward compatible implementations.
+type UnimplementedTradingServer struct {
+}
+
+func (UnimplementedTradingServer) PlaceOrder(context.Context, *PlaceOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlaceOrder not implemented")
+}
+func (UnimplementedTradingServer) CancelOrder(context.Context, *OrderRef) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedTradingServer) GetOrder(context.Context, *OrderRef) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedTradingServer) ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrders not implemented")
+}
+func (UnimplementedTradingServer) GetBalance(context.Context, *GetBalanceRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedTradingServer) Deposit(context.Context, *DepositRequest) (*DepositResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deposit not implemented")
+}
+func (UnimplementedTradingServer) StreamOrderEvents(*StreamOrderEventsRequest, Trading_StreamOrderEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOrderEvents not implemented")
+}
+func (UnimplementedTradingServer) StreamTrades(*StreamTradesRequest, Trading_StreamTradesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTrades not implemented")
+}
+func (UnimplementedTradingServer) mustEmbedUnimplementedTradingServer() {}
+
+// UnsafeTradingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TradingServer will
+// result in compilation errors.
+type UnsafeTradingServer interface {
+	mustEmbedUnimplementedTradingServer()
+}
+
+func RegisterTradingServer(s grpc.ServiceRegistrar, srv TradingServer) {
+	s.RegisterService(&Trading_ServiceDesc, srv)
+}
+
+func _Trading_PlaceOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaceOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServer).PlaceOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vlexchange.trading.Trading/PlaceOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServer).PlaceOrder(ctx, req.(*PlaceOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Trading_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vlexchange.trading.Trading/CancelOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServer).CancelOrder(ctx, req.(*OrderRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Trading_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vlexchange.trading.Trading/GetOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServer).GetOrder(ctx, req.(*OrderRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Trading_ListOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServer).ListOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vlexchange.trading.Trading/ListOrders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServer).ListOrders(ctx, req.(*ListOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Trading_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vlexchange.trading.Trading/GetBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Trading_Deposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepositRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServer).Deposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vlexchange.trading.Trading/Deposit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServer).Deposit(ctx, req.(*DepositRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Trading_StreamOrderEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamOrderEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TradingServer).StreamOrderEvents(m, &tradingStreamOrderEventsServer{stream})
+}
+
+type Trading_StreamOrderEventsServer interface {
+	Send(*Order) error
+	grpc.ServerStream
+}
+
+type tradingStreamOrderEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tradingStreamOrderEventsServer) Send(m *Order) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Trading_StreamTrades_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTradesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TradingServer).StreamTrades(m, &tradingStreamTradesServer{stream})
+}
+
+type Trading_StreamTradesServer interface {
+	Send(*Trade) error
+	grpc.ServerStream
+}
+
+type tradingStreamTradesServer struct {
+	grpc.ServerStream
+}
+
+func (x *tradingStreamTradesServer) Send(m *Trade) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Trading_ServiceDesc is the grpc.ServiceDesc for Trading service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Trading_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vlexchange.trading.Trading",
+	HandlerType: (*TradingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PlaceOrder",
+			Handler:    _Trading_PlaceOrder_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _Trading_CancelOrder_Handler,
+		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _Trading_GetOrder_Handler,
+		},
+		{
+			MethodName: "ListOrders",
+			Handler:    _Trading_ListOrders_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _Trading_GetBalance_Handler,
+		},
+		{
+			MethodName: "Deposit",
+			Handler:    _Trading_Deposit_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamOrderEvents",
+			Handler:       _Trading_StreamOrderEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamTrades",
+			Handler:       _Trading_StreamTrades_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "trading.proto",
+}